@@ -1,13 +1,58 @@
 package command
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
 	"github.com/divy-sh/animus/types/arrays"
 )
 
+// parseIndex returns the array index held by an argument. Indexes are sent as bulk strings
+// by clients, integer values are accepted too.
+func parseIndex(arg resp.Value) (int64, error) {
+	if arg.Typ == common.INTEGER_TYPE {
+		return arg.Num, nil
+	}
+	index, err := strconv.ParseInt(arg.Bulk, 10, 64)
+	if err != nil {
+		return 0, errors.New(common.ERR_INVALID_INTEGER)
+	}
+	return index, nil
+}
+
+func parseRange(startArg, endArg resp.Value) (int64, int64, error) {
+	start, err := parseIndex(startArg)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseIndex(endArg)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// arrayElement returns the reply for an array element, null for an index holding no value.
+func arrayElement(value any) resp.Value {
+	if value == nil {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: fmt.Sprint(value)}
+}
+
+func arrayElements(values []any) resp.Value {
+	arrayValues := make([]resp.Value, len(values))
+	for i, val := range values {
+		arrayValues[i] = arrayElement(val)
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: arrayValues}
+}
+
 func ArCount(args []resp.Value) resp.Value {
 	if len(args) != 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
@@ -28,9 +73,12 @@ func ArDel(args []resp.Value) resp.Value {
 	}
 
 	key := args[0].Bulk
-	index := args[1].Num
+	index, err := parseIndex(args[1])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
 
-	err := arrays.ArDel(key, index)
+	err = arrays.ArDel(key, index)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -43,10 +91,12 @@ func ArDelRange(args []resp.Value) resp.Value {
 	}
 
 	key := args[0].Bulk
-	start := args[1].Num
-	end := args[2].Num
+	start, end, err := parseRange(args[1], args[2])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
 
-	err := arrays.ArDelRange(key, start, end)
+	err = arrays.ArDelRange(key, start, end)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -59,14 +109,17 @@ func ArGet(args []resp.Value) resp.Value {
 	}
 
 	key := args[0].Bulk
-	index := args[1].Num
+	index, err := parseIndex(args[1])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
 
 	value, err := arrays.ArGet(key, index)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
 
-	return resp.Value{Typ: common.BULK_TYPE, Bulk: fmt.Sprint(value)}
+	return arrayElement(value)
 }
 
 func ArGetRange(args []resp.Value) resp.Value {
@@ -75,20 +128,17 @@ func ArGetRange(args []resp.Value) resp.Value {
 	}
 
 	key := args[0].Bulk
-	start := args[1].Num
-	end := args[2].Num
-
-	values, err := arrays.ArGetRange(key, start, end)
+	start, end, err := parseRange(args[1], args[2])
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
 
-	arrayValues := make([]resp.Value, len(values))
-	for i, val := range values {
-		arrayValues[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: fmt.Sprint(val)}
+	values, err := arrays.ArGetRange(key, start, end)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
 
-	return resp.Value{Typ: common.ARRAY_TYPE, Array: arrayValues}
+	return arrayElements(values)
 }
 
 func ArGrep(args []resp.Value) resp.Value {
@@ -111,3 +161,414 @@ func ArGrep(args []resp.Value) resp.Value {
 
 	return resp.Value{Typ: common.ARRAY_TYPE, Array: arrayValues}
 }
+
+// ArSet implements ARSET key index value, setting an index past the end grows the array.
+func ArSet(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	index, err := parseIndex(args[1])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if err := arrays.ArSet(args[0].Bulk, index, args[2].Bulk); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// ArMSet implements ARMSET key index value [index value ...].
+func ArMSet(args []resp.Value) resp.Value {
+	if len(args) < 3 || len(args)%2 != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	indexes := make([]int64, 0, len(args)/2)
+	values := make([]any, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		index, err := parseIndex(args[i])
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		indexes = append(indexes, index)
+		values = append(values, args[i+1].Bulk)
+	}
+	if err := arrays.ArMSet(args[0].Bulk, indexes, values); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// ArMGet implements ARMGET key index [index ...].
+func ArMGet(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	indexes := make([]int64, len(args)-1)
+	for i, arg := range args[1:] {
+		index, err := parseIndex(arg)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		indexes[i] = index
+	}
+	values, err := arrays.ArMGet(args[0].Bulk, indexes)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return arrayElements(values)
+}
+
+// ArPush implements ARPUSH key value [value ...].
+func ArPush(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	length, err := arrays.ArPush(args[0].Bulk, bulkElements(args[1:]))
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: length}
+}
+
+// ArInsert implements ARINSERT key index value [value ...].
+func ArInsert(args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	index, err := parseIndex(args[1])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	length, err := arrays.ArInsert(args[0].Bulk, index, bulkElements(args[2:]))
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: length}
+}
+
+func bulkElements(args []resp.Value) []any {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Bulk
+	}
+	return values
+}
+
+// ArPop implements ARPOP key [count]. Without count the last element is returned, with count an array.
+func ArPop(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	count := int64(1)
+	if len(args) == 2 {
+		var err error
+		if count, err = parseIndex(args[1]); err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		if count < 0 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_OUT_OF_RANGE}
+		}
+	}
+	values, err := arrays.ArPop(args[0].Bulk, count)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if len(args) == 2 {
+		return arrayElements(values)
+	}
+	if len(values) == 0 {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return arrayElement(values[0])
+}
+
+// ArNext implements ARNEXT key cursor, it returns the index and value of the first element at or
+// after cursor that holds a value, or null.
+func ArNext(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	cursor, err := parseIndex(args[1])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	index, value, ok, err := arrays.ArNext(args[0].Bulk, cursor)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+		{Typ: common.INTEGER_TYPE, Num: index},
+		arrayElement(value),
+	}}
+}
+
+// ArScan implements ARSCAN key cursor [MATCH pattern] [COUNT count]. It replies with the cursor
+// to continue from, 0 once the whole array was scanned, and the indexes found followed each by its value.
+func ArScan(args []resp.Value) resp.Value {
+	if len(args) < 2 || len(args)%2 != 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	cursor, err := parseIndex(args[1])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	count, pattern := int64(10), ""
+	for i := 2; i < len(args); i += 2 {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "MATCH":
+			pattern = args[i+1].Bulk
+		case "COUNT":
+			if count, err = parseIndex(args[i+1]); err != nil {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+			}
+			if count <= 0 {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_OUT_OF_RANGE}
+			}
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	next, indexes, values, err := arrays.ArScan(args[0].Bulk, cursor, count, pattern)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	found := make([]resp.Value, 0, 2*len(indexes))
+	for i, index := range indexes {
+		found = append(found, resp.Value{Typ: common.INTEGER_TYPE, Num: index}, arrayElement(values[i]))
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: strconv.FormatInt(next, 10)},
+		{Typ: common.ARRAY_TYPE, Array: found},
+	}}
+}
+
+// arrayFlags returns the flags given in args, upper cased. It fails on a flag not in allowed.
+func arrayFlags(args []resp.Value, allowed ...string) (map[string]bool, error) {
+	flags := map[string]bool{}
+	for _, arg := range args {
+		flag := strings.ToUpper(arg.Bulk)
+		if !slices.Contains(allowed, flag) {
+			return nil, errors.New(common.ERR_SYNTAX)
+		}
+		flags[flag] = true
+	}
+	return flags, nil
+}
+
+// indexedElements returns the reply for array elements, their values or, with withIndex set,
+// the index of each element followed by its value.
+func indexedElements(elements []arrays.Element, withIndex bool) resp.Value {
+	values := make([]resp.Value, 0, len(elements))
+	for _, element := range elements {
+		if withIndex {
+			values = append(values, resp.Value{Typ: common.INTEGER_TYPE, Num: element.Index})
+		}
+		values = append(values, arrayElement(element.Value))
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+}
+
+func floatReply(n float64) resp.Value {
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: strconv.FormatFloat(n, 'f', -1, 64)}
+}
+
+// ArSort implements ARSORT key [ASC|DESC] [ALPHA] [STORE destination] [WITHINDEX]. It takes the
+// client to qualify the destination key with its database.
+func ArSort(client *Client, args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	var desc, alpha, withIndex bool
+	dest := ""
+	for i := 1; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "ASC":
+			desc = false
+		case "DESC":
+			desc = true
+		case "ALPHA":
+			alpha = true
+		case "WITHINDEX":
+			withIndex = true
+		case "STORE":
+			if i+1 == len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			i++
+			dest = common.DBKey(client.DB(), args[i].Bulk)
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	if dest != "" {
+		if withIndex {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		count, err := arrays.ArSortStore(args[0].Bulk, dest, desc, alpha)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: count}
+	}
+	elements, err := arrays.ArSort(args[0].Bulk, desc, alpha)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return indexedElements(elements, withIndex)
+}
+
+// ArSum implements ARSUM key [STRICT] [WITHINDEX]. With WITHINDEX the sum is returned along
+// with the indexes of the elements that were summed.
+func ArSum(args []resp.Value) resp.Value {
+	if len(args) < 1 || len(args) > 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	flags, err := arrayFlags(args[1:], "STRICT", "WITHINDEX")
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	sum, indexes, err := arrays.ArSum(args[0].Bulk, flags["STRICT"])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return aggregateReply(sum, indexes, flags["WITHINDEX"])
+}
+
+// ArAvg implements ARAVG key [STRICT] [WITHINDEX], it replies null if the array has no numeric
+// element. With WITHINDEX the average is returned along with the indexes of the elements averaged.
+func ArAvg(args []resp.Value) resp.Value {
+	if len(args) < 1 || len(args) > 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	flags, err := arrayFlags(args[1:], "STRICT", "WITHINDEX")
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	avg, indexes, err := arrays.ArAvg(args[0].Bulk, flags["STRICT"])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if len(indexes) == 0 {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return aggregateReply(avg, indexes, flags["WITHINDEX"])
+}
+
+// aggregateReply replies with an aggregate, followed by the array of the indexes it was
+// computed from if withIndex is set.
+func aggregateReply(n float64, indexes []int64, withIndex bool) resp.Value {
+	if !withIndex {
+		return floatReply(n)
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{floatReply(n), integerArray(indexes)}}
+}
+
+// ArMin implements ARMIN key [STRICT] [WITHINDEX].
+func ArMin(args []resp.Value) resp.Value {
+	return arrayExtreme(args, arrays.ArMin)
+}
+
+// ArMax implements ARMAX key [STRICT] [WITHINDEX].
+func ArMax(args []resp.Value) resp.Value {
+	return arrayExtreme(args, arrays.ArMax)
+}
+
+// arrayExtreme replies with the element found by extreme, or null if the array has no numeric element.
+// With WITHINDEX the index of the element is returned before its value.
+func arrayExtreme(args []resp.Value, extreme func(key string, strict bool) (arrays.Element, bool, error)) resp.Value {
+	if len(args) < 1 || len(args) > 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	flags, err := arrayFlags(args[1:], "STRICT", "WITHINDEX")
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	element, ok, err := extreme(args[0].Bulk, flags["STRICT"])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	if flags["WITHINDEX"] {
+		return indexedElements([]arrays.Element{element}, true)
+	}
+	return arrayElement(element.Value)
+}
+
+// ArDistinct implements ARDISTINCT key [WITHINDEX].
+func ArDistinct(args []resp.Value) resp.Value {
+	if len(args) < 1 || len(args) > 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	flags, err := arrayFlags(args[1:], "WITHINDEX")
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	elements, err := arrays.ArDistinct(args[0].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return indexedElements(elements, flags["WITHINDEX"])
+}
+
+// ArCountVal implements ARCOUNTVAL key value [WITHINDEX]. It replies with the number of elements
+// equal to value, or with WITHINDEX their indexes.
+func ArCountVal(args []resp.Value) resp.Value {
+	if len(args) < 2 || len(args) > 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	flags, err := arrayFlags(args[2:], "WITHINDEX")
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	indexes, err := arrays.ArCountVal(args[0].Bulk, args[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !flags["WITHINDEX"] {
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: int64(len(indexes))}
+	}
+	return integerArray(indexes)
+}
+
+// ArSeek implements ARSEEK key pattern [FROM index] [WITHVALUE]. It replies with the index of the
+// first element matching pattern, or null, with WITHVALUE followed by the value.
+func ArSeek(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	var from int64
+	withValue := false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "FROM":
+			if i+1 == len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			i++
+			var err error
+			if from, err = parseIndex(args[i]); err != nil {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+			}
+		case "WITHVALUE":
+			withValue = true
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	element, ok, err := arrays.ArSeek(args[0].Bulk, args[1].Bulk, from)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	if withValue {
+		return indexedElements([]arrays.Element{element}, true)
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: element.Index}
+}
@@ -1,6 +1,7 @@
 package command_test
 
 import (
+	"net"
 	"reflect"
 	"testing"
 
@@ -8,12 +9,13 @@ import (
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
 	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/arrays"
 )
 
 func TestArCount(t *testing.T) {
 	// Test case 1: Array exists
 	key := "testArray"
-	store.Set(key, []any{1, 2, 3, 4, 5})
+	store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4, 5}))
 
 	args := []resp.Value{
 		{Typ: common.BULK_TYPE, Bulk: key},
@@ -45,7 +47,7 @@ func TestArCount(t *testing.T) {
 func TestArCount_EmptyArray(t *testing.T) {
 	// Test case 3: Empty array
 	key := "emptyArray"
-	store.Set(key, []any{})
+	store.Set(key, arrays.NewArray())
 
 	args := []resp.Value{
 		{Typ: common.BULK_TYPE, Bulk: key},
@@ -79,7 +81,7 @@ func TestArCount_WrongArgumentCount(t *testing.T) {
 func TestArDel(t *testing.T) {
 	// Test case 1: Array exists and index is valid
 	key := "testArray"
-	store.Set(key, []any{1, 2, 3, 4, 5})
+	store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4, 5}))
 
 	args := []resp.Value{
 		{Typ: common.BULK_TYPE, Bulk: key},
@@ -94,13 +96,13 @@ func TestArDel(t *testing.T) {
 		t.Fatalf("Expected response to be 'OK', got '%s'", result.Bulk)
 	}
 
-	// Verify that the element at index 2 was deleted
-	arr, ok := store.Get[string, []any](key)
+	// Verify that the element at index 2 was deleted without moving the following ones
+	arr, ok := store.Get[string, *arrays.Array](key)
 	if !ok {
 		t.Fatalf("Expected array to exist after deletion")
 	}
-	if len(arr) != 4 || arr[2] != 4 {
-		t.Fatalf("Expected array to be [1, 2, 4, 5], got %v", arr)
+	if arr.Len() != 5 || arr.Get(2) != nil || arr.Get(3) != 4 {
+		t.Fatalf("Expected array to be [1, 2, <nil>, 4, 5], got %v", arr.Range(0, arr.Len()-1))
 	}
 
 	// Test case 2: Array does not exist
@@ -150,7 +152,7 @@ func TestArDel_WrongArgumentCount(t *testing.T) {
 func TestArGetRange(t *testing.T) {
 	t.Run("returns the values in the requested range", func(t *testing.T) {
 		key := "testArrayGetRange"
-		store.Set(key, []any{1, 2, 3, 4, 5})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4, 5}))
 
 		args := []resp.Value{
 			{Typ: common.BULK_TYPE, Bulk: key},
@@ -193,7 +195,7 @@ func TestArGetRange(t *testing.T) {
 
 	t.Run("returns an error for out-of-bounds range", func(t *testing.T) {
 		key := "outOfBoundsRangeArray"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		args := []resp.Value{
 			{Typ: common.BULK_TYPE, Bulk: key},
@@ -228,7 +230,7 @@ func TestArGetRange(t *testing.T) {
 func TestArDelRange(t *testing.T) {
 	t.Run("deletes a range successfully", func(t *testing.T) {
 		key := "testArrayRange"
-		store.Set(key, []any{1, 2, 3, 4, 5})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4, 5}))
 
 		args := []resp.Value{
 			{Typ: common.BULK_TYPE, Bulk: key},
@@ -244,12 +246,12 @@ func TestArDelRange(t *testing.T) {
 			t.Fatalf("Expected response to be 'OK', got '%s'", result.Bulk)
 		}
 
-		arr, ok := store.Get[string, []any](key)
+		arr, ok := store.Get[string, *arrays.Array](key)
 		if !ok {
 			t.Fatalf("Expected array to exist after deletion")
 		}
-		if !reflect.DeepEqual(arr, []any{1, 5}) {
-			t.Fatalf("Expected array to be [1 5], got %v", arr)
+		if values := arr.Range(0, arr.Len()-1); !reflect.DeepEqual(values, []any{1, nil, nil, nil, 5}) {
+			t.Fatalf("Expected array to be [1 <nil> <nil> <nil> 5], got %v", values)
 		}
 	})
 
@@ -271,7 +273,7 @@ func TestArDelRange(t *testing.T) {
 
 	t.Run("returns an error when start is greater than end", func(t *testing.T) {
 		key := "invalidRangeArray"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		args := []resp.Value{
 			{Typ: common.BULK_TYPE, Bulk: key},
@@ -290,7 +292,7 @@ func TestArDelRange(t *testing.T) {
 
 	t.Run("returns an error when end is out of bounds", func(t *testing.T) {
 		key := "outOfBoundsArray"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		args := []resp.Value{
 			{Typ: common.BULK_TYPE, Bulk: key},
@@ -326,7 +328,7 @@ func TestArDelRange(t *testing.T) {
 func TestArGet(t *testing.T) {
 	t.Run("returns the value at a valid index", func(t *testing.T) {
 		key := "testArrayGet"
-		store.Set(key, []any{1, 2, 3, 4, 5})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4, 5}))
 
 		args := []resp.Value{
 			{Typ: common.BULK_TYPE, Bulk: key},
@@ -359,7 +361,7 @@ func TestArGet(t *testing.T) {
 
 	t.Run("returns an error for an out-of-bounds index", func(t *testing.T) {
 		key := "outOfBoundsArray"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		args := []resp.Value{
 			{Typ: common.BULK_TYPE, Bulk: key},
@@ -387,3 +389,149 @@ func TestArGet(t *testing.T) {
 		}
 	})
 }
+
+func arrayArgs(args ...string) []resp.Value {
+	values := make([]resp.Value, len(args))
+	for i, arg := range args {
+		values[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: arg}
+	}
+	return values
+}
+
+func TestArrayMutators(t *testing.T) {
+	key := "testArrayMutators"
+	if result := command.ArSet(arrayArgs(key, "2", "c")); result.Str != "OK" {
+		t.Fatalf("Expected OK, got %v", result)
+	}
+	if result := command.ArGet(arrayArgs(key, "1")); result.Typ != common.NULL_TYPE {
+		t.Fatalf("Expected null for a hole, got %v", result)
+	}
+	if result := command.ArSet(arrayArgs(key, "x", "c")); result.Str != common.ERR_INVALID_INTEGER {
+		t.Fatalf("Expected error message '%s', got %v", common.ERR_INVALID_INTEGER, result)
+	}
+	if result := command.ArPush(arrayArgs(key, "d", "e")); result.Num != 5 {
+		t.Fatalf("Expected length 5, got %v", result)
+	}
+	if result := command.ArInsert(arrayArgs(key, "0", "a")); result.Num != 6 {
+		t.Fatalf("Expected length 6, got %v", result)
+	}
+	if result := command.ArMSet(arrayArgs(key, "2", "b", "0", "z")); result.Str != "OK" {
+		t.Fatalf("Expected OK, got %v", result)
+	}
+	result := command.ArMGet(arrayArgs(key, "0", "2", "3", "100"))
+	got := []string{result.Array[0].Bulk, result.Array[1].Bulk, result.Array[2].Bulk}
+	if !reflect.DeepEqual(got, []string{"z", "b", "c"}) || result.Array[3].Typ != common.NULL_TYPE {
+		t.Fatalf("Expected values [z b c <nil>], got %v", result)
+	}
+	if result := command.ArPop(arrayArgs(key)); result.Bulk != "e" {
+		t.Fatalf("Expected e, got %v", result)
+	}
+	if result := command.ArPop(arrayArgs(key, "2")); len(result.Array) != 2 || result.Array[0].Bulk != "d" {
+		t.Fatalf("Expected [d c], got %v", result)
+	}
+	if result := command.ArGetRange(arrayArgs(key, "0", "2")); len(result.Array) != 3 || result.Array[1].Typ != common.NULL_TYPE {
+		t.Fatalf("Expected [z <nil> b], got %v", result)
+	}
+}
+
+func TestArrayCursors(t *testing.T) {
+	key := "testArrayCursors"
+	command.ArMSet(arrayArgs(key, "3", "three", "7", "seven", "8", "eight"))
+
+	result := command.ArNext(arrayArgs(key, "4"))
+	if len(result.Array) != 2 || result.Array[0].Num != 7 || result.Array[1].Bulk != "seven" {
+		t.Fatalf("Expected [7 seven], got %v", result)
+	}
+	result = command.ArScan(arrayArgs(key, "0", "COUNT", "1"))
+	if result.Array[0].Bulk != "7" || len(result.Array[1].Array) != 2 || result.Array[1].Array[0].Num != 3 {
+		t.Fatalf("Expected [7 [3 three]], got %v", result)
+	}
+	result = command.ArScan(arrayArgs(key, "7", "MATCH", "e*"))
+	if result.Array[0].Bulk != "0" || len(result.Array[1].Array) != 2 || result.Array[1].Array[0].Num != 8 {
+		t.Fatalf("Expected [0 [8 eight]], got %v", result)
+	}
+	if result := command.ArScan(arrayArgs(key, "0", "COUNT", "0")); result.Str != common.ERR_OUT_OF_RANGE {
+		t.Fatalf("Expected error message '%s', got %v", common.ERR_OUT_OF_RANGE, result)
+	}
+}
+
+func TestArrayAnalytics(t *testing.T) {
+	key := "testArrayAnalytics"
+	command.ArPush(arrayArgs(key, "4", "x", "-1", "4", "2.5"))
+
+	if result := command.ArSum(arrayArgs(key)); result.Bulk != "9.5" {
+		t.Fatalf("Expected 9.5, got %v", result)
+	}
+	if result := command.ArSum(arrayArgs(key, "STRICT")); result.Str != common.ERR_ARRAY_VALUE_NOT_FLOAT {
+		t.Fatalf("Expected error message '%s', got %v", common.ERR_ARRAY_VALUE_NOT_FLOAT, result)
+	}
+	if result := command.ArAvg(arrayArgs(key)); result.Bulk != "2.375" {
+		t.Fatalf("Expected 2.375, got %v", result)
+	}
+	if result := command.ArSum(arrayArgs(key, "WITHINDEX")); len(result.Array) != 2 || result.Array[0].Bulk != "9.5" || len(result.Array[1].Array) != 4 || result.Array[1].Array[1].Num != 2 {
+		t.Fatalf("Expected 9.5 with the indexes [0 2 3 4], got %v", result)
+	}
+	if result := command.ArAvg(arrayArgs(key, "STRICT", "WITHINDEX")); result.Str != common.ERR_ARRAY_VALUE_NOT_FLOAT {
+		t.Fatalf("Expected error message '%s', got %v", common.ERR_ARRAY_VALUE_NOT_FLOAT, result)
+	}
+	if result := command.ArAvg(arrayArgs(key, "WITHINDEX")); len(result.Array) != 2 || result.Array[0].Bulk != "2.375" || len(result.Array[1].Array) != 4 {
+		t.Fatalf("Expected 2.375 with the indexes [0 2 3 4], got %v", result)
+	}
+	if result := command.ArMin(arrayArgs(key)); result.Bulk != "-1" {
+		t.Fatalf("Expected -1, got %v", result)
+	}
+	if result := command.ArMax(arrayArgs(key, "WITHINDEX")); len(result.Array) != 2 || result.Array[0].Num != 0 || result.Array[1].Bulk != "4" {
+		t.Fatalf("Expected [0 4], got %v", result)
+	}
+	if result := command.ArDistinct(arrayArgs(key, "WITHINDEX")); len(result.Array) != 8 || result.Array[6].Num != 4 {
+		t.Fatalf("Expected 4 distinct values with their index, got %v", result)
+	}
+	if result := command.ArCountVal(arrayArgs(key, "4")); result.Num != 2 {
+		t.Fatalf("Expected 2, got %v", result)
+	}
+	if result := command.ArCountVal(arrayArgs(key, "4", "WITHINDEX")); len(result.Array) != 2 || result.Array[1].Num != 3 {
+		t.Fatalf("Expected [0 3], got %v", result)
+	}
+	if result := command.ArSeek(arrayArgs(key, "4", "FROM", "1")); result.Num != 3 {
+		t.Fatalf("Expected 3, got %v", result)
+	}
+	if result := command.ArSeek(arrayArgs(key, "*.*", "WITHVALUE")); len(result.Array) != 2 || result.Array[1].Bulk != "2.5" {
+		t.Fatalf("Expected [4 2.5], got %v", result)
+	}
+	if result := command.ArSeek(arrayArgs(key, "y")); result.Typ != common.NULL_TYPE {
+		t.Fatalf("Expected null, got %v", result)
+	}
+	if result := command.ArMin(arrayArgs(key, "BOGUS")); result.Str != common.ERR_SYNTAX {
+		t.Fatalf("Expected error message '%s', got %v", common.ERR_SYNTAX, result)
+	}
+}
+
+func TestArSort(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	client := command.NewClient(serverConn)
+	defer clientConn.Close()
+	defer client.Close()
+	arsort := func(args ...string) resp.Value {
+		return command.Execute(client, "ARSORT", command.Handlers["ARSORT"], arrayArgs(args...))
+	}
+
+	key := "testArSort"
+	command.ArPush(arrayArgs(key, "b", "c", "a"))
+	if result := arsort(key); result.Str != common.ERR_ARRAY_VALUE_NOT_FLOAT {
+		t.Fatalf("Expected error message '%s', got %v", common.ERR_ARRAY_VALUE_NOT_FLOAT, result)
+	}
+	result := arsort(key, "ALPHA", "DESC", "WITHINDEX")
+	got := []any{result.Array[0].Num, result.Array[1].Bulk, result.Array[4].Num, result.Array[5].Bulk}
+	if !reflect.DeepEqual(got, []any{int64(1), "c", int64(2), "a"}) {
+		t.Fatalf("Expected [1 c 0 b 2 a], got %v", result)
+	}
+	if result := arsort(key, "ALPHA", "STORE", "testArSortDest"); result.Num != 3 {
+		t.Fatalf("Expected 3, got %v", result)
+	}
+	if result := command.ArGetRange(arrayArgs("testArSortDest", "0", "2")); result.Array[0].Bulk != "a" || result.Array[2].Bulk != "c" {
+		t.Fatalf("Expected [a b c], got %v", result)
+	}
+	if result := arsort(key, "STORE"); result.Str != common.ERR_SYNTAX {
+		t.Fatalf("Expected error message '%s', got %v", common.ERR_SYNTAX, result)
+	}
+}
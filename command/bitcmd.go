@@ -0,0 +1,266 @@
+package command
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	typestrings "github.com/divy-sh/animus/types/strings"
+)
+
+func parseBitOffset(arg string) (int64, error) {
+	offset, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || offset < 0 || offset > typestrings.MaxBitOffset {
+		return 0, errors.New(common.ERR_BIT_OFFSET)
+	}
+	return offset, nil
+}
+
+func parseBit(arg string) (int64, error) {
+	if arg != "0" && arg != "1" {
+		return 0, errors.New(common.ERR_BIT_VALUE)
+	}
+	return int64(arg[0] - '0'), nil
+}
+
+// parseBitRange parses the [start [end [BYTE|BIT]]] arguments of BITCOUNT and BITPOS,
+// it returns nil when none are given.
+func parseBitRange(args []resp.Value) (*typestrings.BitRange, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	if len(args) > 3 {
+		return nil, errors.New(common.ERR_SYNTAX)
+	}
+	r := &typestrings.BitRange{}
+	var err error
+	if r.Start, err = strconv.ParseInt(args[0].Bulk, 10, 64); err != nil {
+		return nil, errors.New(common.ERR_INVALID_INTEGER)
+	}
+	if len(args) > 1 {
+		if r.End, err = strconv.ParseInt(args[1].Bulk, 10, 64); err != nil {
+			return nil, errors.New(common.ERR_INVALID_INTEGER)
+		}
+		r.HasEnd = true
+	}
+	if len(args) > 2 {
+		switch strings.ToUpper(args[2].Bulk) {
+		case "BYTE":
+		case "BIT":
+			r.Bits = true
+		default:
+			return nil, errors.New(common.ERR_SYNTAX)
+		}
+	}
+	return r, nil
+}
+
+// SetBit implements SETBIT key offset value, it replies with the previous bit.
+func SetBit(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	offset, err := parseBitOffset(args[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	bit, err := parseBit(args[2].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: typestrings.SetBit(args[0].Bulk, offset, bit)}
+}
+
+// GetBit implements GETBIT key offset.
+func GetBit(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	offset, err := parseBitOffset(args[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: typestrings.GetBit(args[0].Bulk, offset)}
+}
+
+// BitCount implements BITCOUNT key [start end [BYTE|BIT]].
+func BitCount(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	if len(args) == 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	r, err := parseBitRange(args[1:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: typestrings.BitCount(args[0].Bulk, r)}
+}
+
+// BitPos implements BITPOS key bit [start [end [BYTE|BIT]]].
+func BitPos(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	bit, err := parseBit(args[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	r, err := parseBitRange(args[2:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: typestrings.BitPos(args[0].Bulk, bit, r)}
+}
+
+// BitOp implements BITOP AND|OR|XOR|NOT|DIFF destkey key [key ...], it replies with the length
+// of the string stored at destkey.
+func BitOp(args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	op := strings.ToUpper(args[0].Bulk)
+	switch op {
+	case typestrings.BITOP_AND, typestrings.BITOP_OR, typestrings.BITOP_XOR, typestrings.BITOP_NOT, typestrings.BITOP_DIFF:
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	keys := make([]string, len(args)-2)
+	for i, arg := range args[2:] {
+		keys[i] = arg.Bulk
+	}
+	length, err := typestrings.BitOp(op, args[1].Bulk, keys)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: length}
+}
+
+// parseBitFieldType parses a bitfield type, i1 to i64 for signed integers and u1 to u63 for unsigned ones.
+func parseBitFieldType(arg string) (typestrings.BitFieldType, error) {
+	t := typestrings.BitFieldType{}
+	if len(arg) < 2 {
+		return t, errors.New(common.ERR_BITFIELD_TYPE)
+	}
+	switch arg[0] {
+	case 'i', 'I':
+		t.Signed = true
+	case 'u', 'U':
+	default:
+		return t, errors.New(common.ERR_BITFIELD_TYPE)
+	}
+	width, err := strconv.Atoi(arg[1:])
+	if err != nil || width < 1 || width > 64 || (!t.Signed && width == 64) {
+		return t, errors.New(common.ERR_BITFIELD_TYPE)
+	}
+	t.Width = width
+	return t, nil
+}
+
+// parseBitFieldOffset parses the offset of a field, in bits or, prefixed with #, in fields of the type's width.
+func parseBitFieldOffset(arg string, t typestrings.BitFieldType) (int64, error) {
+	multiplier := int64(1)
+	if strings.HasPrefix(arg, "#") {
+		arg, multiplier = arg[1:], int64(t.Width)
+	}
+	offset, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || offset < 0 || offset > typestrings.MaxBitOffset/multiplier {
+		return 0, errors.New(common.ERR_BIT_OFFSET)
+	}
+	offset *= multiplier
+	if offset+int64(t.Width)-1 > typestrings.MaxBitOffset {
+		return 0, errors.New(common.ERR_BIT_OFFSET)
+	}
+	return offset, nil
+}
+
+// parseBitFieldOps parses the subcommands of BITFIELD, only GET ones if readOnly is set.
+func parseBitFieldOps(args []resp.Value, readOnly bool) ([]typestrings.BitFieldOp, error) {
+	ops := []typestrings.BitFieldOp{}
+	overflow := typestrings.OVERFLOW_WRAP
+	for i := 0; i < len(args); {
+		kind := strings.ToUpper(args[i].Bulk)
+		if readOnly && kind != "GET" {
+			return nil, errors.New(common.ERR_BITFIELD_RO)
+		}
+		if kind == "OVERFLOW" {
+			if i+1 >= len(args) {
+				return nil, errors.New(common.ERR_SYNTAX)
+			}
+			overflow = strings.ToUpper(args[i+1].Bulk)
+			switch overflow {
+			case typestrings.OVERFLOW_WRAP, typestrings.OVERFLOW_SAT, typestrings.OVERFLOW_FAIL:
+			default:
+				return nil, errors.New(common.ERR_BITFIELD_OVERFLOW)
+			}
+			i += 2
+			continue
+		}
+		argCount := 3
+		switch kind {
+		case "GET":
+			argCount = 2
+		case "SET", "INCRBY":
+		default:
+			return nil, errors.New(common.ERR_SYNTAX)
+		}
+		if i+argCount >= len(args) {
+			return nil, errors.New(common.ERR_SYNTAX)
+		}
+		t, err := parseBitFieldType(args[i+1].Bulk)
+		if err != nil {
+			return nil, err
+		}
+		offset, err := parseBitFieldOffset(args[i+2].Bulk, t)
+		if err != nil {
+			return nil, err
+		}
+		op := typestrings.BitFieldOp{Kind: kind, Type: t, Offset: offset, Overflow: overflow}
+		if kind != "GET" {
+			if op.Value, err = strconv.ParseInt(args[i+3].Bulk, 10, 64); err != nil {
+				return nil, errors.New(common.ERR_INVALID_INTEGER)
+			}
+		}
+		ops = append(ops, op)
+		i += argCount + 1
+	}
+	return ops, nil
+}
+
+// BitField implements BITFIELD key [GET type offset] [SET type offset value] [INCRBY type offset increment]
+// [OVERFLOW WRAP|SAT|FAIL] ... It replies with the result of each GET, SET and INCRBY, null for
+// the ones OVERFLOW FAIL prevented.
+func BitField(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	ops, err := parseBitFieldOps(args[1:], false)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	results := typestrings.BitField(args[0].Bulk, ops)
+	values := make([]resp.Value, len(results))
+	for i, result := range results {
+		if result == nil {
+			values[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			values[i] = resp.Value{Typ: common.INTEGER_TYPE, Num: *result}
+		}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+}
+
+// BitFieldRO implements BITFIELD_RO key [GET type offset] ..., the read only variant of BITFIELD.
+func BitFieldRO(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	ops, err := parseBitFieldOps(args[1:], true)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return integerArray(typestrings.BitFieldRO(args[0].Bulk, ops))
+}
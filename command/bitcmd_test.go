@@ -0,0 +1,100 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+)
+
+func TestSetBitAndGetBit(t *testing.T) {
+	if result := SetBit(bulkArgs("TestSetBitCmd", "7", "1")); result.Num != 0 {
+		t.Errorf("expected 0, got %v", result)
+	}
+	if result := GetBit(bulkArgs("TestSetBitCmd", "7")); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	cases := []struct {
+		result   string
+		expected string
+	}{
+		{SetBit(bulkArgs("TestSetBitCmd", "-1", "1")).Str, common.ERR_BIT_OFFSET},
+		{SetBit(bulkArgs("TestSetBitCmd", "4294967296", "1")).Str, common.ERR_BIT_OFFSET},
+		{SetBit(bulkArgs("TestSetBitCmd", "0", "2")).Str, common.ERR_BIT_VALUE},
+		{GetBit(bulkArgs("TestSetBitCmd", "x")).Str, common.ERR_BIT_OFFSET},
+	}
+	for _, c := range cases {
+		if c.result != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, c.result)
+		}
+	}
+}
+
+func TestBitCountAndBitPos(t *testing.T) {
+	Set(bulkArgs("TestBitCountCmd", "foobar"))
+	if result := BitCount(bulkArgs("TestBitCountCmd", "1", "1")); result.Num != 6 {
+		t.Errorf("expected 6, got %v", result)
+	}
+	if result := BitCount(bulkArgs("TestBitCountCmd", "5", "30", "bit")); result.Num != 17 {
+		t.Errorf("expected 17, got %v", result)
+	}
+	if result := BitCount(bulkArgs("TestBitCountCmd", "1")); result.Str != common.ERR_SYNTAX {
+		t.Errorf("expected %s, got %v", common.ERR_SYNTAX, result)
+	}
+	if result := BitCount(bulkArgs("TestBitCountCmd", "0", "1", "WORD")); result.Str != common.ERR_SYNTAX {
+		t.Errorf("expected %s, got %v", common.ERR_SYNTAX, result)
+	}
+	if result := BitPos(bulkArgs("TestBitCountCmd", "1", "2")); result.Num != 17 {
+		t.Errorf("expected 17, got %v", result)
+	}
+}
+
+func TestBitOp(t *testing.T) {
+	Set(bulkArgs("TestBitOpCmd1", "\x01"))
+	Set(bulkArgs("TestBitOpCmd2", "\x02\x04"))
+	if result := BitOp(bulkArgs("or", "TestBitOpCmdDest", "TestBitOpCmd1", "TestBitOpCmd2")); result.Num != 2 {
+		t.Errorf("expected 2, got %v", result)
+	}
+	if result := Get(bulkArgs("TestBitOpCmdDest")); result.Bulk != "\x03\x04" {
+		t.Errorf("expected %q, got %q", "\x03\x04", result.Bulk)
+	}
+	if result := BitOp(bulkArgs("NAND", "TestBitOpCmdDest", "TestBitOpCmd1")); result.Str != common.ERR_SYNTAX {
+		t.Errorf("expected %s, got %v", common.ERR_SYNTAX, result)
+	}
+	if result := BitOp(bulkArgs("NOT", "TestBitOpCmdDest", "TestBitOpCmd1", "TestBitOpCmd2")); result.Str != common.ERR_BITOP_NOT {
+		t.Errorf("expected %s, got %v", common.ERR_BITOP_NOT, result)
+	}
+}
+
+func TestBitField(t *testing.T) {
+	result := BitField(bulkArgs("TestBitFieldCmd", "SET", "u8", "#1", "255", "GET", "u4", "8",
+		"OVERFLOW", "FAIL", "INCRBY", "u8", "8", "1", "OVERFLOW", "SAT", "INCRBY", "i8", "#1", "1"))
+	if len(result.Array) != 4 || result.Array[0].Num != 0 || result.Array[1].Num != 15 ||
+		result.Array[2].Typ != common.NULL_TYPE || result.Array[3].Num != 0 {
+		t.Errorf("expected [0 15 <nil> 0], got %v", result)
+	}
+	if result := BitFieldRO(bulkArgs("TestBitFieldCmd", "GET", "u8", "8")); len(result.Array) != 1 || result.Array[0].Num != 0 {
+		t.Errorf("expected [0], got %v", result)
+	}
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"GET", "u64", "0"}, common.ERR_BITFIELD_TYPE},
+		{[]string{"GET", "i65", "0"}, common.ERR_BITFIELD_TYPE},
+		{[]string{"GET", "x8", "0"}, common.ERR_BITFIELD_TYPE},
+		{[]string{"GET", "u8", "-1"}, common.ERR_BIT_OFFSET},
+		{[]string{"GET", "u8", "4294967290"}, common.ERR_BIT_OFFSET},
+		{[]string{"SET", "u8", "0"}, common.ERR_SYNTAX},
+		{[]string{"SET", "u8", "0", "x"}, common.ERR_INVALID_INTEGER},
+		{[]string{"OVERFLOW", "NONE"}, common.ERR_BITFIELD_OVERFLOW},
+		{[]string{"DEL", "u8", "0"}, common.ERR_SYNTAX},
+	}
+	for _, c := range cases {
+		if result := BitField(bulkArgs(append([]string{"TestBitFieldCmd"}, c.args...)...)); result.Str != c.expected {
+			t.Errorf("expected %s for %v, got %v", c.expected, c.args, result)
+		}
+	}
+	if result := BitFieldRO(bulkArgs("TestBitFieldCmd", "SET", "u8", "0", "1")); result.Str != common.ERR_BITFIELD_RO {
+		t.Errorf("expected %s, got %v", common.ERR_BITFIELD_RO, result)
+	}
+}
@@ -0,0 +1,339 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+// Client classes used to pick the output buffer limits applied to a connection.
+const (
+	CLIENT_NORMAL  = "normal"
+	CLIENT_PUBSUB  = "pubsub"
+	CLIENT_REPLICA = "replica"
+)
+
+var errClientClosed = errors.New("ERR client connection closed")
+
+// closeFlushTimeout bounds the time Close waits for the pending replies to be written,
+// so a peer that stops reading can't hold the connection open.
+var closeFlushTimeout = 5 * time.Second
+
+var nextClientID atomic.Int64
+
+// Client holds the per-connection state of a connected client.
+// Replies are not written to the connection directly, they are queued in an
+// output buffer that is drained by a dedicated goroutine. This lets the server
+// account for the reply data a client has not consumed yet and disconnect slow
+// consumers once they exceed the configured output buffer limits.
+type Client struct {
+	ID   int64
+	Addr string
+
+	class string
+	name  string
+	db    int
+	conn  net.Conn
+	mutex sync.Mutex
+	cond  *sync.Cond
+	buf   []byte
+	// pending counts the bytes queued in buf plus the bytes being written.
+	pending   int64
+	softSince time.Time
+	closing   bool
+	closed    bool
+	closeOnce sync.Once
+	done      chan struct{}
+	// blocked is the time the running command spent waiting in a blocking read.
+	// It is only touched by the goroutine running the client's commands.
+	blocked time.Duration
+	// channels and patterns are the Pub/Sub subscriptions of the client.
+	channels map[string]struct{}
+	patterns map[string]struct{}
+}
+
+// NewClient wraps a connection and starts draining its output buffer.
+func NewClient(conn net.Conn) *Client {
+	c := &Client{
+		ID:       nextClientID.Add(1),
+		class:    CLIENT_NORMAL,
+		conn:     conn,
+		done:     make(chan struct{}),
+		channels: map[string]struct{}{},
+		patterns: map[string]struct{}{},
+	}
+	if addr := conn.RemoteAddr(); addr != nil {
+		c.Addr = addr.String()
+	}
+	c.cond = sync.NewCond(&c.mutex)
+	connectedClients.Add(1)
+	totalConnectionsReceived.Add(1)
+	go c.writeLoop()
+	return c
+}
+
+// Write queues a reply for the client. The reply is accounted against the
+// client's output buffer and the client is disconnected if that pushes it
+// over its class limits.
+func (c *Client) Write(v resp.Value) error {
+	data := v.Marshal()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed || c.closing {
+		return errClientClosed
+	}
+	c.buf = append(c.buf, data...)
+	c.pending += int64(len(data))
+	if c.overLimit() {
+		outputBufferLimitDisconnections.Add(1)
+		c.kill()
+		return errClientClosed
+	}
+	c.cond.Signal()
+	return nil
+}
+
+// Name returns the name set with CLIENT SETNAME.
+func (c *Client) Name() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.name
+}
+
+// SetName sets the name reported for the client.
+func (c *Client) SetName(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.name = name
+}
+
+// DB returns the database selected by the client, a nil client uses database 0.
+func (c *Client) DB() int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.db
+}
+
+// SetDB selects the database the client's commands run against.
+func (c *Client) SetDB(db int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.db = db
+}
+
+// SetClass changes the output buffer limits class of the client.
+func (c *Client) SetClass(class string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.class = class
+}
+
+// OutputBufferLength returns the number of reply bytes not yet sent to the client.
+func (c *Client) OutputBufferLength() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.pending
+}
+
+// Close flushes the pending replies, for at most closeFlushTimeout, and closes the connection.
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		removeMonitor(c)
+		unsubscribeAll(c)
+		c.mutex.Lock()
+		c.closing = true
+		c.cond.Signal()
+		c.mutex.Unlock()
+		c.conn.SetWriteDeadline(time.Now().Add(closeFlushTimeout))
+		<-c.done
+		c.conn.Close()
+		connectedClients.Add(-1)
+	})
+}
+
+// Hangup is called once the connection stops delivering requests. The replies already
+// queued are still sent, then the client is done, which wakes up a command blocked on it.
+func (c *Client) Hangup() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closing = true
+	c.cond.Signal()
+}
+
+// Done returns a channel closed once the client can't be sent replies anymore, a nil client
+// is never done.
+func (c *Client) Done() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.done
+}
+
+// takeBlocked returns the time the running command spent blocked and resets it.
+func (c *Client) takeBlocked() time.Duration {
+	if c == nil {
+		return 0
+	}
+	blocked := c.blocked
+	c.blocked = 0
+	return blocked
+}
+
+// overLimit checks the output buffer against the limits of the client's class.
+// The caller must hold c.mutex.
+func (c *Client) overLimit() bool {
+	limit := getOutputBufferLimit(c.class)
+	if limit.hard > 0 && c.pending >= limit.hard {
+		return true
+	}
+	if limit.soft <= 0 || c.pending < limit.soft {
+		c.softSince = time.Time{}
+		return false
+	}
+	now := time.Now()
+	if c.softSince.IsZero() {
+		c.softSince = now
+		return false
+	}
+	return now.Sub(c.softSince) >= limit.softSeconds
+}
+
+// kill drops the pending replies and closes the connection right away.
+// The caller must hold c.mutex.
+func (c *Client) kill() {
+	c.closed = true
+	c.buf = nil
+	c.conn.Close()
+	c.cond.Signal()
+}
+
+func (c *Client) writeLoop() {
+	defer close(c.done)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for {
+		for len(c.buf) == 0 && !c.closed && !c.closing {
+			c.cond.Wait()
+		}
+		if c.closed || len(c.buf) == 0 {
+			return
+		}
+		data := c.buf
+		c.buf = nil
+		c.mutex.Unlock()
+		_, err := c.conn.Write(data)
+		c.mutex.Lock()
+		c.pending -= int64(len(data))
+		if err != nil {
+			c.closed = true
+			c.buf = nil
+			return
+		}
+	}
+}
+
+type outputBufferLimit struct {
+	hard        int64
+	soft        int64
+	softSeconds time.Duration
+}
+
+var (
+	outputBufferLimits      = map[string]outputBufferLimit{}
+	outputBufferLimitsMutex sync.RWMutex
+)
+
+func init() {
+	setOutputBufferLimits(serverConfig["client-output-buffer-limit"])
+}
+
+func getOutputBufferLimit(class string) outputBufferLimit {
+	outputBufferLimitsMutex.RLock()
+	defer outputBufferLimitsMutex.RUnlock()
+	return outputBufferLimits[class]
+}
+
+// setOutputBufferLimits parses the client-output-buffer-limit config value.
+// The value is a list of "<class> <hard limit> <soft limit> <soft seconds>" groups,
+// limits accept the usual memory units (1k, 1kb, 1m, 1mb, 1g, 1gb) and 0 disables a limit.
+func setOutputBufferLimits(value string) error {
+	fields := strings.Fields(value)
+	if len(fields)%4 != 0 {
+		return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	parsed := map[string]outputBufferLimit{}
+	for i := 0; i < len(fields); i += 4 {
+		class := strings.ToLower(fields[i])
+		if class != CLIENT_NORMAL && class != CLIENT_PUBSUB && class != CLIENT_REPLICA {
+			return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+		}
+		hard, err := parseMemory(fields[i+1])
+		if err != nil {
+			return err
+		}
+		soft, err := parseMemory(fields[i+2])
+		if err != nil {
+			return err
+		}
+		seconds, err := strconv.ParseInt(fields[i+3], 10, 64)
+		if err != nil || seconds < 0 {
+			return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+		}
+		parsed[class] = outputBufferLimit{hard: hard, soft: soft, softSeconds: time.Duration(seconds) * time.Second}
+	}
+	outputBufferLimitsMutex.Lock()
+	defer outputBufferLimitsMutex.Unlock()
+	for class, limit := range parsed {
+		outputBufferLimits[class] = limit
+	}
+	return nil
+}
+
+// outputBufferLimitsConfig returns the client-output-buffer-limit config value of the limits
+// of every class, in bytes, since a value set with CONFIG SET may change only some of them.
+func outputBufferLimitsConfig() string {
+	outputBufferLimitsMutex.RLock()
+	defer outputBufferLimitsMutex.RUnlock()
+	groups := []string{}
+	for _, class := range []string{CLIENT_NORMAL, CLIENT_REPLICA, CLIENT_PUBSUB} {
+		limit := outputBufferLimits[class]
+		groups = append(groups, fmt.Sprintf("%s %d %d %d", class, limit.hard, limit.soft, int64(limit.softSeconds/time.Second)))
+	}
+	return strings.Join(groups, " ")
+}
+
+// parseMemory parses a memory amount such as "64mb" into bytes.
+func parseMemory(value string) (int64, error) {
+	value = strings.ToLower(value)
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+		{"g", 1000 * 1000 * 1000}, {"m", 1000 * 1000}, {"k", 1000}, {"b", 1},
+	}
+	multiplier := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSuffix(value, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func setConfig(t *testing.T, param, value string) {
+	t.Helper()
+	result := ConfigCmd([]resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "SET"},
+		{Typ: common.BULK_TYPE, Bulk: param},
+		{Typ: common.BULK_TYPE, Bulk: value}})
+	if result.Typ != common.STRING_TYPE || result.Str != "OK" {
+		t.Fatalf("expected OK setting %s, got %v", param, result)
+	}
+}
+
+func TestClient_WriteDeliversReplies(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	defer client.Close()
+
+	if err := client.Write(resp.Value{Typ: common.STRING_TYPE, Str: "OK"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf := make([]byte, 5)
+	if _, err := clientConn.Read(buf); err != nil || string(buf) != "+OK\r\n" {
+		t.Errorf("expected +OK, got %q (%v)", buf, err)
+	}
+}
+
+func TestClient_HardLimitDisconnects(t *testing.T) {
+	setConfig(t, "client-output-buffer-limit", "normal 64b 0 0")
+	defer setConfig(t, "client-output-buffer-limit", "normal 0 0 0")
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	defer client.Close()
+
+	before := outputBufferLimitDisconnections.Load()
+	reply := resp.Value{Typ: common.BULK_TYPE, Bulk: "0123456789012345678901234567890123456789"}
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = client.Write(reply)
+	}
+	if err == nil {
+		t.Fatalf("expected the client to be disconnected")
+	}
+	if outputBufferLimitDisconnections.Load() != before+1 {
+		t.Errorf("expected disconnection to be counted")
+	}
+}
+
+func TestClient_SoftLimitDisconnects(t *testing.T) {
+	setConfig(t, "client-output-buffer-limit", "normal 0 16b 0")
+	defer setConfig(t, "client-output-buffer-limit", "normal 0 0 0")
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	defer client.Close()
+
+	reply := resp.Value{Typ: common.BULK_TYPE, Bulk: "0123456789012345678901234567890123456789"}
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = client.Write(reply)
+		time.Sleep(time.Millisecond)
+	}
+	if err == nil {
+		t.Fatalf("expected the client to be disconnected")
+	}
+}
+
+func TestClient_NoLimit(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	// Closing the peer unblocks the write loop, the client is never read from.
+	client := NewClient(serverConn)
+
+	reply := resp.Value{Typ: common.BULK_TYPE, Bulk: "0123456789012345678901234567890123456789"}
+	for i := 0; i < 100; i++ {
+		if err := client.Write(reply); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if client.OutputBufferLength() == 0 {
+		t.Errorf("expected pending replies to be accounted")
+	}
+}
+
+func TestClient_CloseDoesNotWaitOnSlowConsumer(t *testing.T) {
+	defer func(timeout time.Duration) { closeFlushTimeout = timeout }(closeFlushTimeout)
+	closeFlushTimeout = 50 * time.Millisecond
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	client.Write(resp.Value{Typ: common.BULK_TYPE, Bulk: "never read"})
+
+	closed := make(chan struct{})
+	go func() {
+		client.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Close to give up on a peer that doesn't read")
+	}
+}
+
+func TestConfig_InvalidOutputBufferLimit(t *testing.T) {
+	for _, value := range []string{"normal 0 0", "unknown 0 0 0", "normal 1xb 0 0", "normal 0 0 -1"} {
+		result := ConfigCmd([]resp.Value{
+			{Typ: common.BULK_TYPE, Bulk: "SET"},
+			{Typ: common.BULK_TYPE, Bulk: "client-output-buffer-limit"},
+			{Typ: common.BULK_TYPE, Bulk: value}})
+		if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_INVALID_CONFIG_VALUE {
+			t.Errorf("expected %s for %q, got %v", common.ERR_INVALID_CONFIG_VALUE, value, result)
+		}
+	}
+}
+
+func TestConfig_GetOutputBufferLimitAfterPartialSet(t *testing.T) {
+	setConfig(t, "client-output-buffer-limit", "pubsub 1mb 1k 30")
+	defer setConfig(t, "client-output-buffer-limit", "pubsub 32mb 8mb 60")
+	result := ConfigCmd(bulkArgs("GET", "client-output-buffer-limit"))
+	expected := "normal 0 0 0 replica 268435456 67108864 60 pubsub 1048576 1000 30"
+	if len(result.Array) != 2 || result.Array[1].Bulk != expected {
+		t.Errorf("expected %q, got %v", expected, result)
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	cases := map[string]int64{"10": 10, "1k": 1000, "1kb": 1024, "2mb": 2 << 20, "1g": 1000 * 1000 * 1000, "1GB": 1 << 30}
+	for value, expected := range cases {
+		got, err := parseMemory(value)
+		if err != nil || got != expected {
+			t.Errorf("expected %d for %s, got %d (%v)", expected, value, got, err)
+		}
+	}
+}
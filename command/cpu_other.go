@@ -0,0 +1,8 @@
+//go:build !unix
+
+package command
+
+// cpuUsage is not supported on this platform.
+func cpuUsage() (float64, float64) {
+	return 0, 0
+}
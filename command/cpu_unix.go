@@ -0,0 +1,17 @@
+//go:build unix
+
+package command
+
+import "syscall"
+
+// cpuUsage returns the system and user CPU time consumed by the process, in seconds.
+func cpuUsage() (float64, float64) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, 0
+	}
+	toSeconds := func(tv syscall.Timeval) float64 {
+		return float64(tv.Sec) + float64(tv.Usec)/1e6
+	}
+	return toSeconds(usage.Stime), toSeconds(usage.Utime)
+}
@@ -0,0 +1,162 @@
+package command
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/types/generics"
+)
+
+// numDatabases returns the number of logical databases set by the databases config.
+func numDatabases() int {
+	n, _ := strconv.Atoi(GetConfig("databases"))
+	return n
+}
+
+func setDatabases(string) error {
+	return errors.New("ERR databases can only be set at startup")
+}
+
+func validateDatabases(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n < 1 {
+		return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	return nil
+}
+
+// parseDB parses a database index and checks it is in range.
+func parseDB(value string) (int, error) {
+	db, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New(common.ERR_INVALID_INTEGER)
+	}
+	if db < 0 || db >= numDatabases() {
+		return 0, errors.New(common.ERR_DB_INDEX_OUT_OF_RANGE)
+	}
+	return db, nil
+}
+
+// qualifyKeys returns args with the keys of the command renamed to the names they are
+// stored under in the client's database. The keys are found with the key specification
+// of the command, whose positions count the command name as 0 like the COMMAND reply.
+func qualifyKeys(client *Client, handler Command, args []resp.Value) []resp.Value {
+	if handler.FirstKey <= 0 {
+		return args
+	}
+	db := client.DB()
+	last := handler.LastKey
+	if last < 0 {
+		last = len(args) + 1 + last
+	}
+	step := max(handler.Step, 1)
+	var qualified []resp.Value
+	for pos := handler.FirstKey; pos <= last && pos <= len(args); pos += step {
+		key := args[pos-1].Bulk
+		dbKey := common.DBKey(db, key)
+		if dbKey == key {
+			continue
+		}
+		if qualified == nil {
+			qualified = append([]resp.Value{}, args...)
+		}
+		qualified[pos-1].Bulk = dbKey
+	}
+	if qualified == nil {
+		return args
+	}
+	return qualified
+}
+
+// Select implements the SELECT command, it changes the database of the connection.
+func Select(client *Client, args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	db, err := parseDB(args[0].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	client.SetDB(db)
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// SwapDB implements the SWAPDB command.
+// Connections using either database see the other one's data right away.
+func SwapDB(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	first, err := parseDB(args[0].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	second, err := parseDB(args[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	generics.SwapDB(first, second)
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// Move implements the MOVE command.
+// It returns 1 if the key was moved and 0 if it doesn't exist or the target database has it already.
+func Move(client *Client, args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	target, err := parseDB(args[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	// The key has been renamed to the one of the client's database already.
+	db, key := common.SplitDBKey(args[0].Bulk)
+	if target == db {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SAME_OBJECT}
+	}
+	moved := generics.Move(args[0].Bulk, common.DBKey(target, key))
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: moved}
+}
+
+// FlushDB implements the FLUSHDB [ASYNC|SYNC] command.
+// Both modes are accepted for compatibility, the keys are removed right away either way
+// and their memory is reclaimed by the garbage collector in the background.
+func FlushDB(client *Client, args []resp.Value) resp.Value {
+	if err := checkFlushMode(args); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	generics.FlushDB(client.DB())
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// FlushAll implements the FLUSHALL [ASYNC|SYNC] command.
+func FlushAll(args []resp.Value) resp.Value {
+	if err := checkFlushMode(args); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	generics.FlushAll()
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+func checkFlushMode(args []resp.Value) error {
+	if len(args) > 1 {
+		return errors.New(common.ERR_WRONG_ARGUMENT_COUNT)
+	}
+	if len(args) == 1 {
+		mode := strings.ToUpper(args[0].Bulk)
+		if mode != "ASYNC" && mode != "SYNC" {
+			return errors.New(common.ERR_SYNTAX)
+		}
+	}
+	return nil
+}
+
+// DBSize implements the DBSIZE command, it returns the number of keys in the connection's database.
+func DBSize(client *Client, args []resp.Value) resp.Value {
+	if len(args) != 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: generics.DBSize(client.DB())}
+}
@@ -0,0 +1,170 @@
+package command
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	clientConn, serverConn := net.Pipe()
+	client := NewClient(serverConn)
+	t.Cleanup(func() {
+		clientConn.Close()
+		client.Close()
+	})
+	return client
+}
+
+func run(client *Client, args ...string) resp.Value {
+	name := strings.ToUpper(args[0])
+	return Execute(client, name, Handlers[name], bulkArgs(args[1:]...))
+}
+
+func TestSelect_IsolatesDatabases(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestSelect", "db0")
+	if result := run(client, "SELECT", "1"); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "GET", "TestSelect"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected the key to be missing in db 1, got %v", result)
+	}
+	run(client, "MSET", "TestSelect", "db1", "TestSelect2", "db1")
+	if result := run(client, "MGET", "TestSelect", "TestSelect2"); result.Array[0].Bulk != "db1" || result.Array[1].Bulk != "db1" {
+		t.Errorf("unexpected values %v", result)
+	}
+	if result := run(client, "KEYS", "^TestSelect"); len(result.Array) != 2 {
+		t.Errorf("expected the db 1 keys without their prefix, got %v", result)
+	}
+
+	run(client, "SELECT", "0")
+	if result := run(client, "GET", "TestSelect"); result.Bulk != "db0" {
+		t.Errorf("expected db0, got %v", result)
+	}
+}
+
+func TestSelect_OutOfRange(t *testing.T) {
+	client := newTestClient(t)
+	for _, index := range []string{"-1", "16", "abc"} {
+		if result := run(client, "SELECT", index); result.Typ != common.ERROR_TYPE {
+			t.Errorf("expected an error for %s, got %v", index, result)
+		}
+	}
+	if client.DB() != 0 {
+		t.Errorf("expected the database to stay 0, got %d", client.DB())
+	}
+}
+
+func TestMove(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SETEX", "TestMove", "value", "100")
+	if result := run(client, "MOVE", "TestMove", "2"); result.Num != 1 {
+		t.Fatalf("expected the key to be moved, got %v", result)
+	}
+	if result := run(client, "EXISTS", "TestMove"); result.Num != 0 {
+		t.Errorf("expected the key to be gone from db 0")
+	}
+	run(client, "SELECT", "2")
+	if result := run(client, "EXPIRETIME", "TestMove"); result.Num <= 0 {
+		t.Errorf("expected the expiry to be kept, got %v", result)
+	}
+	run(client, "SELECT", "0")
+	run(client, "SET", "TestMove", "other")
+	if result := run(client, "MOVE", "TestMove", "2"); result.Num != 0 {
+		t.Errorf("expected no move onto an existing key, got %v", result)
+	}
+	if result := run(client, "MOVE", "TestMove", "0"); result.Str != common.ERR_SAME_OBJECT {
+		t.Errorf("expected %s, got %v", common.ERR_SAME_OBJECT, result)
+	}
+}
+
+func TestSwapDB(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "4")
+	run(client, "SET", "TestSwapDB", "db4")
+	run(client, "SELECT", "5")
+	run(client, "SET", "TestSwapDB", "db5")
+	run(client, "SET", "TestSwapDBOnly5", "db5")
+
+	if result := run(client, "SWAPDB", "4", "5"); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "GET", "TestSwapDB"); result.Bulk != "db4" {
+		t.Errorf("expected db4, got %v", result)
+	}
+	run(client, "SELECT", "4")
+	if result := run(client, "GET", "TestSwapDB"); result.Bulk != "db5" {
+		t.Errorf("expected db5, got %v", result)
+	}
+	if result := run(client, "DBSIZE"); result.Num != 2 {
+		t.Errorf("expected 2 keys, got %v", result)
+	}
+}
+
+func TestFlushDBAndDBSize(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestFlushDB", "db0")
+	run(client, "SELECT", "6")
+	run(client, "SET", "TestFlushDB1", "db6")
+	run(client, "SET", "TestFlushDB2", "db6")
+	if result := run(client, "DBSIZE"); result.Num != 2 {
+		t.Errorf("expected 2 keys, got %v", result)
+	}
+	if result := run(client, "FLUSHDB", "ASYNC"); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "DBSIZE"); result.Num != 0 {
+		t.Errorf("expected an empty database, got %v", result)
+	}
+	run(client, "SELECT", "0")
+	if result := run(client, "GET", "TestFlushDB"); result.Bulk != "db0" {
+		t.Errorf("expected db 0 to be untouched, got %v", result)
+	}
+	if result := run(client, "FLUSHDB", "LATER"); result.Str != common.ERR_SYNTAX {
+		t.Errorf("expected %s, got %v", common.ERR_SYNTAX, result)
+	}
+}
+
+func TestInfo_KeyspacePerDatabase(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "7")
+	run(client, "SET", "TestInfo_KeyspacePerDatabase", "value")
+	info := run(client, "INFO", "keyspace").Bulk
+	if !strings.Contains(info, "db7:keys=1,expires=0,avg_ttl=0") {
+		t.Errorf("expected a db7 line, got %q", info)
+	}
+}
+
+func TestKeyspaceNotifications_UseDatabase(t *testing.T) {
+	setConfig(t, "notify-keyspace-events", "K$")
+	defer setConfig(t, "notify-keyspace-events", "")
+
+	subscriberConn, serverConn := net.Pipe()
+	defer subscriberConn.Close()
+	subscriber := NewClient(serverConn)
+	defer subscriber.Close()
+	Subscribe(subscriber, bulkArgs("__keyspace@3__:TestKeyspaceNotifications_UseDatabase"))
+	expectReply(t, subscriberConn, subscriptionReply("subscribe", "__keyspace@3__:TestKeyspaceNotifications_UseDatabase", 1))
+
+	client := newTestClient(t)
+	run(client, "SELECT", "3")
+	run(client, "SET", "TestKeyspaceNotifications_UseDatabase", "value")
+	expectReply(t, subscriberConn, bulkArray("message", "__keyspace@3__:TestKeyspaceNotifications_UseDatabase", "set"))
+}
+
+func TestFlushAll(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "8")
+	run(client, "SET", "TestFlushAll", "value")
+	if result := run(client, "FLUSHALL"); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "DBSIZE"); result.Num != 0 {
+		t.Errorf("expected every database to be empty, got %v", result)
+	}
+}
@@ -1,16 +1,47 @@
 package command
 
 import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
 	"github.com/divy-sh/animus/types/generics"
 )
 
+// CopyVal implements COPY source destination [DB destination-db] [REPLACE].
 func CopyVal(args []resp.Value) resp.Value {
-	if len(args) != 2 {
+	if len(args) < 2 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	val, err := generics.Copy(args[0].Bulk, args[1].Bulk)
+	source, destination := args[0].Bulk, args[1].Bulk
+	replace := false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "REPLACE":
+			replace = true
+		case "DB":
+			if i+1 >= len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			db, err := parseDB(args[i+1].Bulk)
+			if err != nil {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+			}
+			// The keys have been renamed to the ones of the client's database already.
+			_, name := common.SplitDBKey(destination)
+			destination = common.DBKey(db, name)
+			i++
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	if source == destination {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SAME_OBJECT}
+	}
+	val, err := generics.Copy(source, destination, replace)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -22,11 +53,10 @@ func Del(args []resp.Value) resp.Value {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 	keys := make([]string, len(args))
-	for i := 0; i < len(args); i += 2 {
+	for i := range args {
 		keys[i] = args[i].Bulk
 	}
-	generics.Delete(&keys)
-	return resp.Value{Typ: common.BULK_TYPE, Bulk: "OK"}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: generics.Delete(&keys)}
 }
 
 func Exists(args []resp.Value) resp.Value {
@@ -34,7 +64,7 @@ func Exists(args []resp.Value) resp.Value {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 	keys := make([]string, len(args))
-	for i := 0; i < len(args); i += 2 {
+	for i := range args {
 		keys[i] = args[i].Bulk
 	}
 	validKeyCount := generics.Exists(&keys)
@@ -86,11 +116,11 @@ func ExpireTime(args []resp.Value) resp.Value {
 	return resp.Value{Typ: common.INTEGER_TYPE, Num: val}
 }
 
-func Keys(args []resp.Value) resp.Value {
+func Keys(client *Client, args []resp.Value) resp.Value {
 	if len(args) != 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	values, err := generics.Keys(args[0].Bulk)
+	values, err := generics.Keys(client.DB(), args[0].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -100,3 +130,156 @@ func Keys(args []resp.Value) resp.Value {
 	}
 	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
 }
+
+func Rename(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	_, err := generics.Rename(args[0].Bulk, args[1].Bulk, false)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+func RenameNx(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	renamed, err := generics.Rename(args[0].Bulk, args[1].Bulk, true)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: renamed}
+}
+
+func Persist(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: generics.Persist(args[0].Bulk)}
+}
+
+func TTL(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: generics.TTL(args[0].Bulk)}
+}
+
+func Type(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: generics.Type(args[0].Bulk)}
+}
+
+func RandomKey(client *Client, args []resp.Value) resp.Value {
+	if len(args) != 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	key, ok := generics.RandomKey(client.DB())
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: key}
+}
+
+func Touch(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	keys := make([]string, len(args))
+	for i := range args {
+		keys[i] = args[i].Bulk
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: generics.Touch(&keys)}
+}
+
+func Unlink(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	keys := make([]string, len(args))
+	for i := range args {
+		keys[i] = args[i].Bulk
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: generics.Unlink(&keys)}
+}
+
+func Dump(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	payload, ok, err := generics.Dump(args[0].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR " + err.Error()}
+	}
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: string(payload)}
+}
+
+// Restore implements RESTORE key ttl payload [REPLACE] [ABSTTL] [IDLETIME seconds] [FREQ frequency].
+// The ttl is in milliseconds, 0 for no expiry, and a unix timestamp in milliseconds with ABSTTL.
+func Restore(args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	ttl, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	if ttl < 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_TTL}
+	}
+	options := generics.RestoreOptions{ExpireAt: -1, IdleTime: -1, Freq: -1}
+	absTTL := false
+	for i := 3; i < len(args); i++ {
+		option := strings.ToUpper(args[i].Bulk)
+		switch option {
+		case "REPLACE":
+			options.Replace = true
+		case "ABSTTL":
+			absTTL = true
+		case "IDLETIME", "FREQ":
+			if i+1 >= len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			n, err := strconv.ParseInt(args[i+1].Bulk, 10, 64)
+			if err != nil || n < 0 {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+			}
+			if option == "IDLETIME" {
+				// Keep the idle time in nanoseconds from overflowing.
+				if n > math.MaxInt64/int64(time.Second) {
+					return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+				}
+				options.IdleTime = time.Duration(n) * time.Second
+			} else {
+				options.Freq = n
+			}
+			i++
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	if ttl > 0 {
+		var base int64
+		if !absTTL {
+			base = time.Now().UnixMilli()
+		}
+		// Keep the expiry time in milliseconds, rounded up below, from overflowing.
+		if ttl > math.MaxInt64-999-base {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_EXPIRE_TIME}
+		}
+		ttl += base
+		// Expiry times are kept in seconds, round up so the key doesn't expire early.
+		options.ExpireAt = (ttl + 999) / 1000
+	}
+	if err := generics.Restore(args[0].Bulk, []byte(args[2].Bulk), options); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
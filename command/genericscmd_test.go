@@ -718,7 +718,7 @@ func TestKeys(t *testing.T) {
 		{Typ: common.BULK_TYPE, Bulk: "value"},
 	})
 
-	result := Keys([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "Test.eys"}})
+	result := Keys(nil, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "Test.eys"}})
 
 	if result.Typ != common.ARRAY_TYPE {
 		t.Errorf("expected ARRAY_TYPE, got %v", result.Typ)
@@ -729,7 +729,7 @@ func TestKeys(t *testing.T) {
 }
 
 func TestKeysWrongArgCount(t *testing.T) {
-	result := Keys([]resp.Value{})
+	result := Keys(nil, []resp.Value{})
 
 	if result.Typ != common.ERROR_TYPE {
 		t.Errorf("expected ERROR_TYPE, got %v", result.Typ)
@@ -740,7 +740,7 @@ func TestKeysWrongArgCount(t *testing.T) {
 }
 
 func TestKeysInvalidRegex(t *testing.T) {
-	result := Keys([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "[a-b"}})
+	result := Keys(nil, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "[a-b"}})
 
 	if result.Typ != common.ERROR_TYPE {
 		t.Errorf("expected ERROR_TYPE, got %v", result.Typ)
@@ -755,7 +755,7 @@ func TestKeysMultipleMatches(t *testing.T) {
 	Set([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "apple:2"}, {Typ: common.BULK_TYPE, Bulk: "green"}})
 	Set([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "banana:1"}, {Typ: common.BULK_TYPE, Bulk: "yellow"}})
 
-	result := Keys([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "apple:*"}})
+	result := Keys(nil, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "apple:*"}})
 
 	if result.Typ != common.ARRAY_TYPE {
 		t.Errorf("expected ARRAY_TYPE, got %v", result.Typ)
@@ -772,7 +772,7 @@ func TestKeysMultipleMatches(t *testing.T) {
 }
 
 func TestKeysNoMatch(t *testing.T) {
-	result := Keys([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "nonexistent*"}})
+	result := Keys(nil, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "nonexistent*"}})
 
 	if result.Typ != common.ARRAY_TYPE {
 		t.Errorf("expected ARRAY_TYPE, got %v", result.Typ)
@@ -782,3 +782,129 @@ func TestKeysNoMatch(t *testing.T) {
 		t.Errorf("expected empty result, got %v", result.Array)
 	}
 }
+
+func TestGeneric_DelReturnsCount(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "MSET", "TestGeneric_DelReturnsCount1", "a", "TestGeneric_DelReturnsCount2", "b")
+	result := run(client, "DEL", "TestGeneric_DelReturnsCount1", "TestGeneric_DelReturnsCount2", "TestGeneric_DelReturnsCount3")
+	if result.Typ != common.INTEGER_TYPE || result.Num != 2 {
+		t.Errorf("expected 2, got %v", result)
+	}
+	if result := run(client, "EXISTS", "TestGeneric_DelReturnsCount1", "TestGeneric_DelReturnsCount2"); result.Num != 0 {
+		t.Errorf("expected both keys to be deleted, got %v", result)
+	}
+}
+
+func TestGeneric_CopyToDB(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestGeneric_CopyToDB", "value")
+	if result := run(client, "COPY", "TestGeneric_CopyToDB", "TestGeneric_CopyToDB", "DB", "11"); result.Num != 1 {
+		t.Fatalf("expected the key to be copied, got %v", result)
+	}
+	if result := run(client, "COPY", "TestGeneric_CopyToDB", "TestGeneric_CopyToDB", "DB", "11"); result.Num != 0 {
+		t.Errorf("expected no copy over an existing key, got %v", result)
+	}
+	if result := run(client, "COPY", "TestGeneric_CopyToDB", "TestGeneric_CopyToDB", "DB", "11", "REPLACE"); result.Num != 1 {
+		t.Errorf("expected the key to be replaced, got %v", result)
+	}
+	if result := run(client, "COPY", "TestGeneric_CopyToDB", "TestGeneric_CopyToDB"); result.Str != common.ERR_SAME_OBJECT {
+		t.Errorf("expected %s, got %v", common.ERR_SAME_OBJECT, result)
+	}
+	if result := run(client, "COPY", "TestGeneric_CopyToDB", "other", "DB"); result.Str != common.ERR_SYNTAX {
+		t.Errorf("expected %s, got %v", common.ERR_SYNTAX, result)
+	}
+	run(client, "SELECT", "11")
+	if result := run(client, "GET", "TestGeneric_CopyToDB"); result.Bulk != "value" {
+		t.Errorf("expected value, got %v", result)
+	}
+}
+
+func TestGeneric_RenameAndType(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "12")
+	run(client, "RPUSH", "TestGeneric_RenameAndType", "a")
+	if result := run(client, "RENAME", "TestGeneric_RenameAndType", "TestGeneric_RenameAndType2"); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "TYPE", "TestGeneric_RenameAndType2"); result.Str != "list" {
+		t.Errorf("expected list, got %v", result)
+	}
+	if result := run(client, "RENAMENX", "TestGeneric_RenameAndType2", "TestGeneric_RenameAndType2"); result.Num != 0 {
+		t.Errorf("expected 0, got %v", result)
+	}
+	if result := run(client, "RANDOMKEY"); result.Bulk != "TestGeneric_RenameAndType2" {
+		t.Errorf("expected the only key of the database, got %v", result)
+	}
+	if result := run(client, "TTL", "TestGeneric_RenameAndType2"); result.Num != -1 {
+		t.Errorf("expected -1, got %v", result)
+	}
+}
+
+func TestDumpAndRestore(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "RPUSH", "TestDumpAndRestore", "a", "b", "c")
+	payload := run(client, "DUMP", "TestDumpAndRestore")
+	if payload.Typ != common.BULK_TYPE {
+		t.Fatalf("expected a payload, got %v", payload)
+	}
+	if result := run(client, "RESTORE", "TestDumpAndRestore", "0", payload.Bulk); result.Str != common.ERR_BUSY_KEY {
+		t.Errorf("expected %s, got %v", common.ERR_BUSY_KEY, result)
+	}
+	result := run(client, "RESTORE", "TestDumpAndRestore2", "5000", payload.Bulk, "IDLETIME", "10", "FREQ", "7")
+	if result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "LRANGE", "TestDumpAndRestore2", "0", "-1"); len(result.Array) != 3 || result.Array[2].Bulk != "c" {
+		t.Errorf("expected the list to be restored, got %v", result)
+	}
+	if result := run(client, "TTL", "TestDumpAndRestore2"); result.Num < 4 || result.Num > 6 {
+		t.Errorf("expected a 5 second expiry, got %v", result)
+	}
+	if result := run(client, "OBJECT", "FREQ", "TestDumpAndRestore2"); result.Num < 7 {
+		t.Errorf("expected the frequency to be restored, got %v", result)
+	}
+
+	absTTL := fmt.Sprint(time.Now().Add(time.Hour).UnixMilli())
+	result = run(client, "RESTORE", "TestDumpAndRestore2", absTTL, payload.Bulk, "REPLACE", "ABSTTL")
+	if result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "TTL", "TestDumpAndRestore2"); result.Num < 3500 {
+		t.Errorf("expected an hour long expiry, got %v", result)
+	}
+	if result := run(client, "OBJECT", "IDLETIME", "TestDumpAndRestore2"); result.Num != 0 {
+		t.Errorf("expected a fresh idle time, got %v", result)
+	}
+}
+
+func TestRestore_Errors(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestRestore_Errors", "value")
+	payload := run(client, "DUMP", "TestRestore_Errors").Bulk
+	tests := [][]string{
+		{"RESTORE", "TestRestore_Errors2", "-1", payload},
+		{"RESTORE", "TestRestore_Errors2", "0", payload[:len(payload)-1] + "x"},
+		{"RESTORE", "TestRestore_Errors2", "0", payload, "IDLETIME"},
+		{"RESTORE", "TestRestore_Errors2", "0", payload, "KEEPTTL"},
+		{"RESTORE", "TestRestore_Errors2", "0", payload, "IDLETIME", "9223372036854775807"},
+	}
+	for _, args := range tests {
+		if result := run(client, args...); result.Typ != common.ERROR_TYPE {
+			t.Errorf("expected an error for %v, got %v", args[2:], result)
+		}
+	}
+	for _, args := range [][]string{
+		{"RESTORE", "TestRestore_Errors2", "9223372036854775807", payload},
+		{"RESTORE", "TestRestore_Errors2", "9223372036854775000", payload, "ABSTTL"},
+	} {
+		if result := run(client, args...); result.Str != common.ERR_INVALID_EXPIRE_TIME {
+			t.Errorf("expected %s for %v, got %v", common.ERR_INVALID_EXPIRE_TIME, args[2:], result)
+		}
+	}
+	if result := run(client, "EXISTS", "TestRestore_Errors2"); result.Num != 0 {
+		t.Errorf("expected the key not to be restored, got %v", result)
+	}
+	if result := run(client, "DUMP", "TestRestore_ErrorsMissing"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+}
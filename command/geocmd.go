@@ -0,0 +1,298 @@
+package command
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/types/geo"
+)
+
+func parseGeoFloat(arg string) (float64, error) {
+	f, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, errors.New(common.ERR_INVALID_FLOAT)
+	}
+	return f, nil
+}
+
+func parseCoordinates(longitudeArg, latitudeArg string) (float64, float64, error) {
+	longitude, err := parseGeoFloat(longitudeArg)
+	if err != nil {
+		return 0, 0, err
+	}
+	latitude, err := parseGeoFloat(latitudeArg)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !geo.ValidCoordinates(longitude, latitude) {
+		return 0, 0, errors.New(common.ERR_GEO_COORDINATES)
+	}
+	return longitude, latitude, nil
+}
+
+func parseUnit(arg string) (float64, error) {
+	conversion, ok := geo.UnitConversion(arg)
+	if !ok {
+		return 0, errors.New(common.ERR_GEO_UNIT)
+	}
+	return conversion, nil
+}
+
+// GeoAdd implements GEOADD key [NX|XX] [CH] longitude latitude member [longitude latitude member ...].
+func GeoAdd(args []resp.Value) resp.Value {
+	if len(args) < 4 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	var nx, xx, ch bool
+	i := 1
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "NX":
+			nx = true
+			continue
+		case "XX":
+			xx = true
+			continue
+		case "CH":
+			ch = true
+			continue
+		}
+		break
+	}
+	if nx && xx {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_NX_XX}
+	}
+	if len(args) == i || (len(args)-i)%3 != 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	locations := make([]geo.Location, 0, (len(args)-i)/3)
+	for ; i < len(args); i += 3 {
+		longitude, latitude, err := parseCoordinates(args[i].Bulk, args[i+1].Bulk)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		locations = append(locations, geo.Location{Longitude: longitude, Latitude: latitude, Member: args[i+2].Bulk})
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: geo.GeoAdd(args[0].Bulk, locations, nx, xx, ch)}
+}
+
+func coordinatesValue(longitude, latitude float64) resp.Value {
+	return bulkArray(geo.FormatCoordinate(longitude), geo.FormatCoordinate(latitude))
+}
+
+// GeoPos implements GEOPOS key [member ...].
+func GeoPos(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	members := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		members[i] = arg.Bulk
+	}
+	points := geo.GeoPos(args[0].Bulk, members)
+	response := make([]resp.Value, len(points))
+	for i, p := range points {
+		if p == nil {
+			response[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			response[i] = coordinatesValue(p.Longitude, p.Latitude)
+		}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+// GeoDist implements GEODIST key member1 member2 [M|KM|FT|MI].
+func GeoDist(args []resp.Value) resp.Value {
+	if len(args) != 3 && len(args) != 4 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	conversion := 1.0
+	if len(args) == 4 {
+		var err error
+		if conversion, err = parseUnit(args[3].Bulk); err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+	}
+	distance, ok := geo.GeoDist(args[0].Bulk, args[1].Bulk, args[2].Bulk)
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: strconv.FormatFloat(distance/conversion, 'f', 4, 64)}
+}
+
+// GeoHash implements GEOHASH key [member ...].
+func GeoHash(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	members := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		members[i] = arg.Bulk
+	}
+	hashes := geo.GeoHash(args[0].Bulk, members)
+	response := make([]resp.Value, len(hashes))
+	for i, hash := range hashes {
+		if hash == nil {
+			response[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			response[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: *hash}
+		}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+// geoSearch is a parsed GEOSEARCH or GEOSEARCHSTORE command.
+type geoSearch struct {
+	search    geo.Search
+	withCoord bool
+	withDist  bool
+	withHash  bool
+	storeDist bool
+}
+
+// parseGeoSearch parses the options of GEOSEARCH, or of GEOSEARCHSTORE with store set:
+// FROMMEMBER member | FROMLONLAT longitude latitude, BYRADIUS radius unit | BYBOX width height unit,
+// ASC | DESC, COUNT count [ANY], WITHCOORD, WITHDIST and WITHHASH, or STOREDIST.
+func parseGeoSearch(args []resp.Value, store bool) (geoSearch, error) {
+	var g geoSearch
+	var fromLonLat, byRadius bool
+	var err error
+	for i := 0; i < len(args); i++ {
+		option := strings.ToUpper(args[i].Bulk)
+		remaining := len(args) - i - 1
+		switch {
+		case option == "FROMMEMBER" && remaining >= 1:
+			g.search.FromMember, g.search.Member = true, args[i+1].Bulk
+			i++
+		case option == "FROMLONLAT" && remaining >= 2:
+			if g.search.Longitude, g.search.Latitude, err = parseCoordinates(args[i+1].Bulk, args[i+2].Bulk); err != nil {
+				return g, err
+			}
+			fromLonLat = true
+			i += 2
+		case option == "BYRADIUS" && remaining >= 2:
+			if g.search.Radius, err = parseGeoFloat(args[i+1].Bulk); err != nil {
+				return g, err
+			}
+			if g.search.Radius < 0 {
+				return g, errors.New(common.ERR_GEO_NEGATIVE)
+			}
+			if g.search.Conversion, err = parseUnit(args[i+2].Bulk); err != nil {
+				return g, err
+			}
+			byRadius = true
+			i += 2
+		case option == "BYBOX" && remaining >= 3:
+			if g.search.Width, err = parseGeoFloat(args[i+1].Bulk); err != nil {
+				return g, err
+			}
+			if g.search.Height, err = parseGeoFloat(args[i+2].Bulk); err != nil {
+				return g, err
+			}
+			if g.search.Width < 0 || g.search.Height < 0 {
+				return g, errors.New(common.ERR_GEO_NEGATIVE)
+			}
+			if g.search.Conversion, err = parseUnit(args[i+3].Bulk); err != nil {
+				return g, err
+			}
+			g.search.ByBox = true
+			i += 3
+		case option == geo.SORT_ASC || option == geo.SORT_DESC:
+			g.search.Sort = option
+		case option == "COUNT" && remaining >= 1:
+			if g.search.Count, err = strconv.ParseInt(args[i+1].Bulk, 10, 64); err != nil {
+				return g, errors.New(common.ERR_INVALID_INTEGER)
+			}
+			if g.search.Count <= 0 {
+				return g, errors.New(common.ERR_COUNT_NOT_POSITIVE)
+			}
+			i++
+			if i+1 < len(args) && strings.ToUpper(args[i+1].Bulk) == "ANY" {
+				g.search.Any = true
+				i++
+			}
+		case option == "ANY":
+			return g, errors.New(common.ERR_GEO_ANY)
+		case option == "WITHCOORD" && !store:
+			g.withCoord = true
+		case option == "WITHDIST" && !store:
+			g.withDist = true
+		case option == "WITHHASH" && !store:
+			g.withHash = true
+		case (option == "WITHCOORD" || option == "WITHDIST" || option == "WITHHASH") && store:
+			return g, errors.New(common.ERR_GEO_STORE_WITH)
+		case option == "STOREDIST" && store:
+			g.storeDist = true
+		default:
+			return g, errors.New(common.ERR_SYNTAX)
+		}
+	}
+	if g.search.FromMember == fromLonLat {
+		return g, errors.New(common.ERR_GEO_FROM)
+	}
+	if byRadius == g.search.ByBox {
+		return g, errors.New(common.ERR_GEO_BY)
+	}
+	return g, nil
+}
+
+func (g *geoSearch) matchesValue(matches []geo.Match) resp.Value {
+	response := make([]resp.Value, len(matches))
+	for i, m := range matches {
+		member := resp.Value{Typ: common.BULK_TYPE, Bulk: m.Member}
+		if !g.withCoord && !g.withDist && !g.withHash {
+			response[i] = member
+			continue
+		}
+		item := []resp.Value{member}
+		if g.withDist {
+			item = append(item, resp.Value{Typ: common.BULK_TYPE, Bulk: strconv.FormatFloat(m.Distance, 'f', 4, 64)})
+		}
+		if g.withHash {
+			item = append(item, resp.Value{Typ: common.INTEGER_TYPE, Num: int64(m.Score)})
+		}
+		if g.withCoord {
+			item = append(item, coordinatesValue(m.Longitude, m.Latitude))
+		}
+		response[i] = resp.Value{Typ: common.ARRAY_TYPE, Array: item}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+// GeoSearch implements GEOSEARCH key FROMMEMBER member | FROMLONLAT longitude latitude
+// BYRADIUS radius unit | BYBOX width height unit [ASC|DESC] [COUNT count [ANY]] [WITHCOORD] [WITHDIST] [WITHHASH].
+func GeoSearch(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	g, err := parseGeoSearch(args[1:], false)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	matches, err := geo.GeoSearch(args[0].Bulk, g.search)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return g.matchesValue(matches)
+}
+
+// GeoSearchStore implements GEOSEARCHSTORE destination source with the search options of GEOSEARCH
+// and [STOREDIST], it replies with the number of members stored.
+func GeoSearchStore(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	g, err := parseGeoSearch(args[2:], true)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	n, err := geo.GeoSearchStore(args[0].Bulk, args[1].Bulk, g.search, g.storeDist)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: n}
+}
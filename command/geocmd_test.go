@@ -0,0 +1,126 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+)
+
+func geoSicily(t *testing.T, key string) *Client {
+	t.Helper()
+	client := newTestClient(t)
+	result := run(client, "GEOADD", key, "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania")
+	if result.Num != 2 {
+		t.Fatalf("expected 2 members added, got %v", result)
+	}
+	return client
+}
+
+func TestGeoAdd(t *testing.T) {
+	client := geoSicily(t, "TestGeoAddCmd")
+	if result := run(client, "GEOADD", "TestGeoAddCmd", "XX", "CH", "13.4", "38.1", "Palermo", "1", "1", "new"); result.Num != 1 {
+		t.Errorf("expected 1 member moved, got %v", result)
+	}
+	if result := run(client, "TYPE", "TestGeoAddCmd"); result.Str != "zset" {
+		t.Errorf("expected zset, got %v", result)
+	}
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"GEOADD", "TestGeoAddCmd", "NX", "XX", "1", "1", "a"}, common.ERR_NX_XX},
+		{[]string{"GEOADD", "TestGeoAddCmd", "1", "1", "a", "2"}, common.ERR_SYNTAX},
+		{[]string{"GEOADD", "TestGeoAddCmd", "181", "1", "a"}, common.ERR_GEO_COORDINATES},
+		{[]string{"GEOADD", "TestGeoAddCmd", "1", "86", "a"}, common.ERR_GEO_COORDINATES},
+		{[]string{"GEOADD", "TestGeoAddCmd", "x", "1", "a"}, common.ERR_INVALID_FLOAT},
+	}
+	for _, c := range cases {
+		if result := run(client, c.args...); result.Str != c.expected {
+			t.Errorf("expected %s for %v, got %v", c.expected, c.args, result)
+		}
+	}
+}
+
+func TestGeoPosDistAndHash(t *testing.T) {
+	client := geoSicily(t, "TestGeoPosCmd")
+	pos := run(client, "GEOPOS", "TestGeoPosCmd", "Palermo", "missing")
+	if len(pos.Array) != 2 || pos.Array[0].Array[0].Bulk != "13.36138933897018433" || pos.Array[1].Typ != common.NULL_TYPE {
+		t.Errorf("unexpected GEOPOS reply %v", pos)
+	}
+	for unit, expected := range map[string]string{"": "166274.1516", "km": "166.2742", "MI": "103.3182"} {
+		args := []string{"GEODIST", "TestGeoPosCmd", "Palermo", "Catania"}
+		if unit != "" {
+			args = append(args, unit)
+		}
+		if result := run(client, args...); result.Bulk != expected {
+			t.Errorf("expected %s with unit %q, got %v", expected, unit, result)
+		}
+	}
+	if result := run(client, "GEODIST", "TestGeoPosCmd", "Palermo", "missing"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+	if result := run(client, "GEODIST", "TestGeoPosCmd", "Palermo", "Catania", "yd"); result.Str != common.ERR_GEO_UNIT {
+		t.Errorf("expected %s, got %v", common.ERR_GEO_UNIT, result)
+	}
+	hashes := run(client, "GEOHASH", "TestGeoPosCmd", "Palermo", "Catania")
+	if hashes.Array[0].Bulk != "sqc8b49rny0" || hashes.Array[1].Bulk != "sqdtr74hyu0" {
+		t.Errorf("unexpected GEOHASH reply %v", hashes)
+	}
+}
+
+func TestGeoSearch(t *testing.T) {
+	client := geoSicily(t, "TestGeoSearchCmd")
+	result := run(client, "GEOSEARCH", "TestGeoSearchCmd", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ASC")
+	if len(result.Array) != 2 || result.Array[0].Bulk != "Catania" || result.Array[1].Bulk != "Palermo" {
+		t.Errorf("expected Catania and Palermo, got %v", result)
+	}
+	result = run(client, "GEOSEARCH", "TestGeoSearchCmd", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km",
+		"COUNT", "1", "WITHCOORD", "WITHDIST", "WITHHASH")
+	if len(result.Array) != 1 {
+		t.Fatalf("expected 1 result, got %v", result)
+	}
+	item := result.Array[0].Array
+	if item[0].Bulk != "Catania" || item[1].Bulk != "56.4413" || item[2].Num != 3479447370796909 || item[3].Array[1].Bulk != "37.50266842333162032" {
+		t.Errorf("unexpected GEOSEARCH item %v", item)
+	}
+	if result := run(client, "GEOSEARCH", "TestGeoSearchCmd", "FROMMEMBER", "Palermo", "BYRADIUS", "100", "km"); len(result.Array) != 1 {
+		t.Errorf("expected only Palermo within 100km of itself, got %v", result)
+	}
+
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"BYRADIUS", "1", "km"}, common.ERR_GEO_FROM},
+		{[]string{"FROMMEMBER", "Palermo", "FROMLONLAT", "1", "1", "BYRADIUS", "1", "km"}, common.ERR_GEO_FROM},
+		{[]string{"FROMMEMBER", "Palermo"}, common.ERR_GEO_BY},
+		{[]string{"FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "BYBOX", "1", "1", "km"}, common.ERR_GEO_BY},
+		{[]string{"FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "ANY"}, common.ERR_GEO_ANY},
+		{[]string{"FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "COUNT", "0"}, common.ERR_COUNT_NOT_POSITIVE},
+		{[]string{"FROMMEMBER", "Palermo", "BYRADIUS", "-1", "km"}, common.ERR_GEO_NEGATIVE},
+		{[]string{"FROMMEMBER", "Palermo", "BYRADIUS", "1", "yd"}, common.ERR_GEO_UNIT},
+		{[]string{"FROMMEMBER", "missing", "BYRADIUS", "1", "km"}, common.ERR_GEO_MEMBER},
+		{[]string{"FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "STOREDIST"}, common.ERR_SYNTAX},
+	}
+	for _, c := range cases {
+		args := append([]string{"GEOSEARCH", "TestGeoSearchCmd"}, c.args...)
+		if result := run(client, args...); result.Str != c.expected {
+			t.Errorf("expected %s for %v, got %v", c.expected, c.args, result)
+		}
+	}
+}
+
+func TestGeoSearchStore(t *testing.T) {
+	client := geoSicily(t, "TestGeoSearchStoreCmd")
+	result := run(client, "GEOSEARCHSTORE", "TestGeoSearchStoreCmdDest", "TestGeoSearchStoreCmd", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km")
+	if result.Num != 2 {
+		t.Errorf("expected 2 members stored, got %v", result)
+	}
+	if result := run(client, "GEODIST", "TestGeoSearchStoreCmdDest", "Palermo", "Catania", "km"); result.Bulk != "166.2742" {
+		t.Errorf("expected the stored members to keep their positions, got %v", result)
+	}
+	result = run(client, "GEOSEARCHSTORE", "TestGeoSearchStoreCmdDest", "TestGeoSearchStoreCmd", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "WITHDIST")
+	if result.Str != common.ERR_GEO_STORE_WITH {
+		t.Errorf("expected %s, got %v", common.ERR_GEO_STORE_WITH, result)
+	}
+}
@@ -1,18 +1,22 @@
 package command
 
 import (
+	"time"
+
 	"github.com/divy-sh/animus/resp"
 )
 
 // Command represents a command with an associated function and documentation.
 type Command struct {
-	Func     func([]resp.Value) resp.Value
-	Doc      string
-	Arity    int
-	Flags    []string
-	FirstKey int
-	LastKey  int
-	Step     int
+	Func func([]resp.Value) resp.Value
+	// ClientFunc is used instead of Func by commands that need the calling connection.
+	ClientFunc func(*Client, []resp.Value) resp.Value
+	Doc        string
+	Arity      int
+	Flags      []string
+	FirstKey   int
+	LastKey    int
+	Step       int
 }
 
 // Handlers maps command names to their implementations.
@@ -23,6 +27,34 @@ func RegisterCommand(name string, fn func([]resp.Value) resp.Value, doc string,
 	Handlers[name] = Command{Func: fn, Doc: doc, Flags: flags, Arity: arity, FirstKey: firstKey, LastKey: lastKey, Step: step}
 }
 
+// RegisterClientCommand registers a command that operates on the calling client.
+func RegisterClientCommand(name string, fn func(*Client, []resp.Value) resp.Value, doc string, flags []string, arity, firstKey, lastKey, step int) {
+	Handlers[name] = Command{ClientFunc: fn, Doc: doc, Flags: flags, Arity: arity, FirstKey: firstKey, LastKey: lastKey, Step: step}
+}
+
+// Execute runs a command on behalf of a client, feeding it to the monitors
+// and recording how long it took in the command statistics and the slow log.
+// The time a command spends blocked waiting for data isn't counted.
+// The keys of the command are renamed to the ones of the client's database.
+func Execute(client *Client, name string, handler Command, args []resp.Value) resp.Value {
+	feedMonitors(client, name, args)
+	if reply, ok := subscribedContextReply(client, name, args); ok {
+		return reply
+	}
+	keyArgs := qualifyKeys(client, handler, args)
+	start := time.Now()
+	var result resp.Value
+	if handler.ClientFunc != nil {
+		result = handler.ClientFunc(client, keyArgs)
+	} else {
+		result = handler.Func(keyArgs)
+	}
+	duration := time.Since(start) - client.takeBlocked()
+	recordCommand(name, duration, result)
+	logSlowCommand(client, name, args, start, duration)
+	return result
+}
+
 // Initialize commands with their documentation.
 // Arguments apart from name, function and documentation are for metadata purposes. They may not be completely correct.
 func init() {
@@ -31,140 +63,490 @@ func init() {
 	Returns PONG to test server responsiveness.`, []string{"readonly", "fast"}, -1, 0, 0, 0)
 	RegisterCommand("COMMAND", CommandCmd, `COMMAND
 	Returns metadata about all registered commands.`, []string{"readonly", "fast"}, 0, 0, 0, 0)
-	RegisterCommand("INFO", Info, `INFO
-    Returns information and statistics about the server.`, []string{"readonly", "fast"}, 0, 0, 0, 0)
-	RegisterCommand("CONFIG", ConfigCmd, `CONFIG
-	command to handle server configuration`, []string{"readonly", "fast"}, -1, 0, 0, 0)
+	RegisterCommand("INFO", Info, `INFO [SECTION ...]
+	Returns information and statistics about the server.
+	Sections are server, clients, memory, persistence, stats, replication, cpu, commandstats and keyspace.
+	Without arguments the default sections are returned, all returns every section.`, []string{"readonly", "fast"}, -1, 0, 0, 0)
+	RegisterCommand("CONFIG", ConfigCmd, `CONFIG GET parameter | SET parameter value | RESETSTAT
+	command to handle server configuration, RESETSTAT resets the statistics reported by INFO`, []string{"readonly", "fast"}, -1, 0, 0, 0)
+	RegisterClientCommand("CLIENT", ClientCmd, `CLIENT SETNAME name | GETNAME | ID
+	Sets or returns the name of the current connection, or returns its id.`, []string{"fast"}, -2, 0, 0, 0)
+	RegisterClientCommand("MONITOR", Monitor, `MONITOR
+	Streams back every command processed by the server, with its timestamp, database and client address.`, []string{"admin"}, 1, 0, 0, 0)
+	RegisterClientCommand("SELECT", Select, `SELECT [INDEX]
+	Selects the database used by the connection, the number of databases is set by the databases config.`, []string{"fast"}, 2, 0, 0, 0)
+	RegisterCommand("SLOWLOG", SlowLog, `SLOWLOG GET [COUNT] | LEN | RESET
+	GET returns the most recent entries of the slow log, all of them if COUNT is -1 and 10 by default.
+	LEN returns the number of entries in the slow log.
+	RESET clears the slow log.`, []string{"fast"}, -2, 0, 0, 0)
+
+	// Pub/Sub
+	RegisterClientCommand("SUBSCRIBE", Subscribe, `SUBSCRIBE [CHANNEL ...]
+	Subscribes the connection to the given channels, it then only accepts Pub/Sub commands and PING.`, []string{"pubsub", "fast"}, -2, 0, 0, 0)
+	RegisterClientCommand("UNSUBSCRIBE", Unsubscribe, `UNSUBSCRIBE [CHANNEL ...]
+	Unsubscribes the connection from the given channels, or from all of them if none is given.`, []string{"pubsub", "fast"}, -1, 0, 0, 0)
+	RegisterClientCommand("PSUBSCRIBE", PSubscribe, `PSUBSCRIBE [PATTERN ...]
+	Subscribes the connection to every channel matching the given glob-style patterns.`, []string{"pubsub", "fast"}, -2, 0, 0, 0)
+	RegisterClientCommand("PUNSUBSCRIBE", PUnsubscribe, `PUNSUBSCRIBE [PATTERN ...]
+	Unsubscribes the connection from the given patterns, or from all of them if none is given.`, []string{"pubsub", "fast"}, -1, 0, 0, 0)
+	RegisterCommand("PUBLISH", Publish, `PUBLISH [CHANNEL] [MESSAGE]
+	Posts a message to a channel and returns the number of clients that received it.`, []string{"pubsub", "fast"}, 3, 0, 0, 0)
+	RegisterCommand("PUBSUB", PubSub, `PUBSUB CHANNELS [PATTERN] | NUMSUB [CHANNEL ...] | NUMPAT
+	CHANNELS lists the active channels, optionally only the ones matching pattern.
+	NUMSUB returns the number of subscribers of each channel.
+	NUMPAT returns the number of subscribed patterns.`, []string{"pubsub", "fast"}, -2, 0, 0, 0)
 
 	// Arrays
 	RegisterCommand("ARCOUNT", ArCount, `ARCOUNT [KEY]
-	Returns the number of elements in the array stored at key.`, []string{"readonly", "fast"}, 2, 0, 0, 0)
+	Returns the length of the array stored at key, one past its highest index holding a value.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
 	RegisterCommand("ARDEL", ArDel, `ARDEL [KEY] [INDEX]
-	Deletes the element at the specified index from the array stored at key.`, []string{}, 3, 0, 0, 0)
+	Deletes the element at the specified index from the array stored at key. The following elements keep their index.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("ARDELRANGE", ArDelRange, `ARDELRANGE [KEY] [START] [END]
-	Deletes elements in the specified range from the array stored at key.`, []string{}, 4, 0, 0, 0)
+	Deletes elements in the specified range from the array stored at key. The following elements keep their index.`, []string{}, 4, 1, 1, 1)
 	RegisterCommand("ARGET", ArGet, `ARGET [KEY] [INDEX]
-	Returns the element at the specified index from the array stored at key.`, []string{"readonly", "fast"}, 3, 0, 0, 0)
+	Returns the element at the specified index from the array stored at key.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
 	RegisterCommand("ARGREP", ArGrep, `ARGREP [KEY] [PATTERN]
-	Returns elements from the array stored at key that match the specified pattern.`, []string{"readonly", "fast"}, 3, 0, 0, 0)
+	Returns elements from the array stored at key that match the specified pattern.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
+	RegisterCommand("ARGETRANGE", ArGetRange, `ARGETRANGE [KEY] [START] [END]
+	Returns the elements between START and END, both inclusive, from the array stored at key.
+	The range can span at most 1048576 indexes, ARSCAN walks the values of sparse arrays.`, []string{"readonly"}, 4, 1, 1, 1)
+	RegisterCommand("ARSET", ArSet, `ARSET [KEY] [INDEX] [VALUE]
+	Sets the element at INDEX in the array stored at key, creating the array if needed.
+	Setting an index past the end grows the array, the indexes in between hold no value.`, []string{}, 4, 1, 1, 1)
+	RegisterCommand("ARMSET", ArMSet, `ARMSET [KEY] [INDEX] [VALUE] [INDEX VALUE ...]
+	Sets the elements at several indexes in the array stored at key.`, []string{}, -4, 1, 1, 1)
+	RegisterCommand("ARMGET", ArMGet, `ARMGET [KEY] [INDEX] [INDEX ...]
+	Returns the elements at several indexes in the array stored at key, null for the indexes holding no value.`, []string{"readonly", "fast"}, -3, 1, 1, 1)
+	RegisterCommand("ARPUSH", ArPush, `ARPUSH [KEY] [VALUE] [VALUE ...]
+	Appends values to the array stored at key and returns its new length.`, []string{}, -3, 1, 1, 1)
+	RegisterCommand("ARINSERT", ArInsert, `ARINSERT [KEY] [INDEX] [VALUE] [VALUE ...]
+	Inserts values before the element at INDEX in the array stored at key and returns its new length.`, []string{}, -4, 1, 1, 1)
+	RegisterCommand("ARPOP", ArPop, `ARPOP [KEY] [COUNT]
+	Removes and returns the last element, or the last COUNT elements, of the array stored at key.`, []string{}, -2, 1, 1, 1)
+	RegisterCommand("ARNEXT", ArNext, `ARNEXT [KEY] [CURSOR]
+	Returns the index and value of the first element at or after CURSOR holding a value, or null.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
+	RegisterCommand("ARSCAN", ArScan, `ARSCAN [KEY] [CURSOR] [MATCH pattern] [COUNT count]
+	Scans COUNT elements of the array stored at key from CURSOR, skipping the indexes holding no value,
+	and returns the next cursor with the indexes and values found.`, []string{"readonly"}, -3, 1, 1, 1)
+	RegisterClientCommand("ARSORT", ArSort, `ARSORT [KEY] [ASC|DESC] [ALPHA] [STORE destination] [WITHINDEX]
+	Returns the values of the array stored at key sorted numerically, or as strings with ALPHA.
+	With STORE the sorted values are stored as a new array at destination and their number is returned.
+	With WITHINDEX the index of each value is returned before it.`, []string{}, -2, 1, 1, 1)
+	RegisterCommand("ARSUM", ArSum, `ARSUM [KEY] [STRICT] [WITHINDEX]
+	Returns the sum of the numeric elements of the array stored at key. Other elements are skipped,
+	or fail the command with STRICT. With WITHINDEX the indexes of the summed elements are returned after it.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("ARAVG", ArAvg, `ARAVG [KEY] [STRICT] [WITHINDEX]
+	Returns the average of the numeric elements of the array stored at key, or null if there are none.
+	Other elements are skipped, or fail the command with STRICT.
+	With WITHINDEX the indexes of the averaged elements are returned after it.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("ARMIN", ArMin, `ARMIN [KEY] [STRICT] [WITHINDEX]
+	Returns the smallest numeric element of the array stored at key, or null if there are none.
+	With WITHINDEX its index is returned before it.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("ARMAX", ArMax, `ARMAX [KEY] [STRICT] [WITHINDEX]
+	Returns the largest numeric element of the array stored at key, or null if there are none.
+	With WITHINDEX its index is returned before it.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("ARDISTINCT", ArDistinct, `ARDISTINCT [KEY] [WITHINDEX]
+	Returns the distinct values of the array stored at key in index order.
+	With WITHINDEX the index of the first occurrence of each value is returned before it.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("ARCOUNTVAL", ArCountVal, `ARCOUNTVAL [KEY] [VALUE] [WITHINDEX]
+	Returns the number of elements of the array stored at key equal to VALUE, or with WITHINDEX their indexes.`, []string{"readonly"}, -3, 1, 1, 1)
+	RegisterCommand("ARSEEK", ArSeek, `ARSEEK [KEY] [PATTERN] [FROM index] [WITHVALUE]
+	Returns the index of the first element at or after FROM of the array stored at key matching the glob PATTERN, or null.
+	With WITHVALUE the value of the element is returned after its index.`, []string{"readonly"}, -3, 1, 1, 1)
 
 	// Strings
 	RegisterCommand("APPEND", Append, `APPEND [KEY] [VALUE]
-	Appends a value to a key and returns the new length of the string.`, []string{}, 3, 0, 0, 0)
+	Appends a value to a key and returns the new length of the string.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("DECR", Decr, `DECR [KEY]
-	Decrements the integer value of a key by one.`, []string{}, 2, 0, 0, 0)
+	Decrements the integer value of a key by one and returns the new value.`, []string{}, 2, 1, 1, 1)
 	RegisterCommand("DECRBY", DecrBy, `DECRBY [KEY] [DECREMENT]
-	Decrements the integer value of a key by the given amount.`, []string{}, 3, 0, 0, 0)
+	Decrements the integer value of a key by the given amount and returns the new value.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("GET", Get, `GET [KEY]
-	Gets the value of a key.`, []string{"readonly", "fast"}, 2, 0, 0, 0)
+	Gets the value of a key.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
 	RegisterCommand("GETDEL", GetDel, `GETDEL [KEY]
-	Gets the value of a key and deletes it.`, []string{}, 2, 0, 0, 0)
-	RegisterCommand("GETEX", GetEx, `GETEX [KEY] [EXPIRATION]
-	Gets the value of a key and sets an expiration.`, []string{}, 3, 0, 0, 0)
+	Gets the value of a key and deletes it.`, []string{}, 2, 1, 1, 1)
+	RegisterCommand("GETEX", GetEx, `GETEX [KEY] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]
+	Gets the value of a key and sets its expiration, or removes it with PERSIST.
+	Expirations are kept in seconds, millisecond ones are rounded up.`, []string{}, -2, 1, 1, 1)
 	RegisterCommand("GETRANGE", GetRange, `GETRANGE [KEY] [START] [END]
-	Gets a substring of the string stored at a key.`, []string{"readonly", "fast"}, 4, 0, 0, 0)
+	Gets a substring of the string stored at a key.`, []string{"readonly", "fast"}, 4, 1, 1, 1)
 	RegisterCommand("GETSET", GetSet, `GETSET [KEY] [VALUE]
-	Gets the previous key value and then sets it to the passed value.`, []string{}, 3, 0, 0, 0)
+	Gets the previous key value and then sets it to the passed value.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("INCR", Incr, `INCR [KEY]
-	Increments the integer value of a key by one.`, []string{}, 2, 0, 0, 0)
+	Increments the integer value of a key by one and returns the new value.`, []string{}, 2, 1, 1, 1)
 	RegisterCommand("INCRBY", IncrBy, `INCRBY [KEY] [INCREMENT]
-	Increments the integer value of a key by the given amount.`, []string{}, 3, 0, 0, 0)
+	Increments the integer value of a key by the given amount and returns the new value.
+	Fails rather than overflowing a 64 bit integer, as do INCR, DECR and DECRBY.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("INCRBYFLOAT", IncrByFloat, `INCRBYFLOAT [KEY] [INCREMENT]
-	Increments the float value of a key by the given amount.`, []string{}, 3, 0, 0, 0)
-	RegisterCommand("LCS", LCS, `LCS [KEY1] [KEY2] LEN
+	Increments the float value of a key by the given amount.`, []string{}, 3, 1, 1, 1)
+	RegisterCommand("LCS", LCS, `LCS [KEY1] [KEY2] [LEN] [IDX] [MINMATCHLEN len] [WITHMATCHLEN]
 	Finds the Longest Common Subsequence between the value of two keys.
-	Send the optional LEN argument to get just the length`, []string{"readonly", "fast"}, 4, 0, 0, 0)
+	Send the optional LEN argument to get just the length.
+	With IDX the ranges of the matching substrings in both values are returned along with the length,
+	skipping matches shorter than MINMATCHLEN. WITHMATCHLEN adds the length of each match.`, []string{"readonly", "fast"}, -3, 1, 2, 1)
 	RegisterCommand("MGET", MGet, `MGET key [key ...]
 	Returns the values for all the keys.
-	Returns nil for a non-existing key.`, []string{"readonly", "fast"}, -2, 0, 0, 0)
+	Returns nil for a non-existing key.`, []string{"readonly", "fast"}, -2, 1, -1, 1)
 	RegisterCommand("MSET", MSet, `MSET key value [key1 value1 ...]
-	Sets the values for all the keys value pair.`, []string{}, -3, 0, 0, 0)
+	Sets the values for all the keys value pair.`, []string{}, -3, 1, -1, 2)
+	RegisterCommand("MSETNX", MSetNx, `MSETNX key value [key1 value1 ...]
+	Sets the values for all the keys value pair, only if none of the keys exists.
+	Returns 1 if the keys were set, 0 otherwise.`, []string{}, -3, 1, -1, 2)
 	RegisterCommand("SET", Set, `SET [KEY] [VALUE]
-	Sets the value of a key.`, []string{}, -3, 0, 0, 0)
-	RegisterCommand("SETRANGE", SetRange, `SETRANGE key offset value`, []string{}, -3, 0, 0, 0)
+	Sets the value of a key.`, []string{}, -3, 1, 1, 1)
+	RegisterCommand("SETNX", SetNx, `SETNX [KEY] [VALUE]
+	Sets the value of a key only if it doesn't exist. Returns 1 if the key was set, 0 otherwise.`, []string{"fast"}, 3, 1, 1, 1)
+	RegisterCommand("SETRANGE", SetRange, `SETRANGE key offset value`, []string{}, -3, 1, 1, 1)
 	RegisterCommand("SETEX", SetEx, `SET [KEY] [VALUE] [EX SECONDS]
-	Sets the value of a key with expiration in seconds.`, []string{}, 4, 0, 0, 0)
+	Sets the value of a key with expiration in seconds.`, []string{}, 4, 1, 1, 1)
+	RegisterCommand("SUBSTR", Substr, `SUBSTR [KEY] [START] [END]
+	Gets a substring of the string stored at a key, from START to END inclusive.
+	Negative positions count from the end, and the range is clamped to the length of the string.`, []string{"readonly"}, 4, 1, 1, 1)
 	RegisterCommand("STRLEN", StrLen, `STRLEN [KEY]
-	Returns the length of the string value stored at key.`, []string{"readonly", "fast"}, 2, 0, 0, 0)
+	Returns the length of the string value stored at key.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
+
+	// Bitmaps
+	RegisterCommand("SETBIT", SetBit, `SETBIT [KEY] [OFFSET] [VALUE]
+	Sets or clears the bit at OFFSET of the string stored at key, padding it with zero bytes as needed.
+	Returns the previous bit.`, []string{}, 4, 1, 1, 1)
+	RegisterCommand("GETBIT", GetBit, `GETBIT [KEY] [OFFSET]
+	Returns the bit at OFFSET of the string stored at key, 0 past its end.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
+	RegisterCommand("BITCOUNT", BitCount, `BITCOUNT [KEY] [START END [BYTE|BIT]]
+	Returns the number of bits set in the string stored at key, or in the range from START to END,
+	in bytes or with BIT in bits. Negative positions count from the end of the string.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("BITPOS", BitPos, `BITPOS [KEY] [BIT] [START [END [BYTE|BIT]]]
+	Returns the position of the first bit set to BIT in the string stored at key, or in the given range, or -1.`, []string{"readonly"}, -3, 1, 1, 1)
+	RegisterCommand("BITOP", BitOp, `BITOP [AND|OR|XOR|NOT|DIFF] [DESTKEY] [KEY] [KEY ...]
+	Stores at DESTKEY the result of a bitwise operation between the strings stored at the keys and returns its length.
+	DIFF keeps the bits set in the first string and in none of the others.`, []string{}, -4, 2, -1, 1)
+	RegisterCommand("BITFIELD", BitField, `BITFIELD [KEY] [GET type offset] [SET type offset value] [INCRBY type offset increment] [OVERFLOW WRAP|SAT|FAIL] ...
+	Reads and writes integer fields of the string stored at key. Types are i1 to i64 and u1 to u63,
+	offsets prefixed with # count in fields of the type's width. OVERFLOW sets how the following SET and INCRBY
+	handle values out of range.`, []string{}, -2, 1, 1, 1)
+	RegisterCommand("BITFIELD_RO", BitFieldRO, `BITFIELD_RO [KEY] [GET type offset] ...
+	Reads integer fields of the string stored at key.`, []string{"readonly", "fast"}, -2, 1, 1, 1)
+
+	// HyperLogLogs
+	RegisterCommand("PFADD", PfAdd, `PFADD [KEY] [ELEMENT ...]
+	Adds elements to the HyperLogLog stored at key, creating it if needed.
+	Returns 1 if the HyperLogLog was created or its estimate may have changed, 0 otherwise.`, []string{"fast"}, -2, 1, 1, 1)
+	RegisterCommand("PFCOUNT", PfCount, `PFCOUNT [KEY] [KEY ...]
+	Returns the approximate number of distinct elements added to the HyperLogLogs stored at the keys,
+	with a standard error of 0.81%. The count of a single HyperLogLog is cached until it changes.`, []string{}, -2, 1, -1, 1)
+	RegisterCommand("PFMERGE", PfMerge, `PFMERGE [DESTKEY] [SOURCEKEY ...]
+	Stores at DESTKEY the union of the HyperLogLogs stored at DESTKEY and the source keys.`, []string{}, -2, 1, -1, 1)
+	RegisterCommand("PFDEBUG", PfDebug, `PFDEBUG [GETREG|DECODE|ENCODING|TODENSE] [KEY]
+	Inspects the HyperLogLog stored at key. GETREG returns its 16384 registers, converting it to the dense encoding,
+	DECODE describes the opcodes of a sparse HyperLogLog, ENCODING returns sparse or dense and TODENSE converts it
+	to the dense encoding, returning 1 if it was sparse.`, []string{}, 3, 2, 2, 1)
 
 	// Hashes
-	RegisterCommand("HSET", HSet, `HSET [KEY] [FIELD] [VALUE]
-	Sets a field in the hash stored at key to a value.`, []string{}, -4, 0, 0, 0)
+	RegisterCommand("HSET", HSet, `HSET [KEY] [FIELD] [VALUE] [FIELD VALUE ...]
+	Sets one or more fields in the hash stored at key to their values. Returns the number of fields added.`, []string{}, -4, 1, 1, 1)
+	RegisterCommand("HMSET", HMSet, `HMSET [KEY] [FIELD] [VALUE] [FIELD VALUE ...]
+	Sets one or more fields in the hash stored at key to their values, same as HSET but replies OK.`, []string{}, -4, 1, 1, 1)
+	RegisterCommand("HSETNX", HSetNx, `HSETNX [KEY] [FIELD] [VALUE]
+	Sets a field in the hash stored at key only if it doesn't exist. Returns 1 if the field was set, 0 otherwise.`, []string{"fast"}, 4, 1, 1, 1)
+	RegisterCommand("HMGET", HMGet, `HMGET [KEY] [FIELD] [FIELD ...]
+	Gets the values of the fields in the hash stored at key, nil for the fields that don't exist.`, []string{"readonly", "fast"}, -3, 1, 1, 1)
+	RegisterCommand("HINCRBY", HIncrBy, `HINCRBY [KEY] [FIELD] [INCREMENT]
+	Increments the integer stored in a field of the hash by increment and returns the new value.
+	A missing field is set to the increment.`, []string{"fast"}, 4, 1, 1, 1)
+	RegisterCommand("HINCRBYFLOAT", HIncrByFloat, `HINCRBYFLOAT [KEY] [FIELD] [INCREMENT]
+	Increments the number stored in a field of the hash by a floating point increment and returns the new value.
+	A missing field is set to the increment.`, []string{"fast"}, 4, 1, 1, 1)
+	RegisterCommand("HKEYS", HKeys, `HKEYS [KEY]
+	Returns all the fields of the hash stored at key.`, []string{"readonly"}, 2, 1, 1, 1)
+	RegisterCommand("HVALS", HVals, `HVALS [KEY]
+	Returns all the values of the hash stored at key.`, []string{"readonly"}, 2, 1, 1, 1)
+	RegisterCommand("HLEN", HLen, `HLEN [KEY]
+	Returns the number of fields in the hash stored at key.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
+	RegisterCommand("HSTRLEN", HStrlen, `HSTRLEN [KEY] [FIELD]
+	Returns the length of the value of a field in the hash stored at key, 0 if the field doesn't exist.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
+	RegisterCommand("HRANDFIELD", HRandField, `HRANDFIELD [KEY] [COUNT [WITHVALUES]]
+	Returns random fields from the hash stored at key, followed each by its value with WITHVALUES.
+	A positive count returns distinct fields, a negative count may return the same field several times.`, []string{"readonly"}, -2, 1, 1, 1)
 	RegisterCommand("HGET", HGet, `HGET [KEY] [FIELD]
-	Gets the value of a field in the hash stored at key.`, []string{"readonly", "fast"}, 3, 0, 0, 0)
-	RegisterCommand("HEXISTS", HGet, `HEXISTS [KEY] [FIELD]
-	Checks if the hash and the field combination exists in the store.`, []string{"readonly", "fast"}, 3, 0, 0, 0)
-	RegisterCommand("HEXPIRE", HExpire, `HEXPIRE key seconds [NX XX GT LT]
-	Sets a timeout on hash key. After the timeout, the key gets deleted.
-	NX - Only set timeout if the key has no previous expiry.
-	XX - Only set timeout if the key has a previous expiry.
+	Gets the value of a field in the hash stored at key.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
+	RegisterCommand("HEXISTS", HExists, `HEXISTS [KEY] [FIELD]
+	Checks if the hash and the field combination exists in the store. Returns 1 if the field exists, 0 otherwise.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
+	RegisterCommand("HEXPIRE", HExpire, `HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+	Sets a timeout on fields of the hash stored at key. After the timeout, the fields get deleted.
+	NX - Only set timeout if the field has no previous expiry.
+	XX - Only set timeout if the field has a previous expiry.
 	GT - Only set timeout if the new time is greater than the existing expiry.
-	LT - Only set timeout if the new time is less than the existing expiry.`, []string{}, 3, 0, 0, 0)
+	LT - Only set timeout if the new time is less than the existing expiry.
+	Returns for every field: -2 if the field doesn't exist, 0 if the condition isn't met,
+	1 if the timeout was set and 2 if the field was deleted because the time is in the past.`, []string{"fast"}, -6, 1, 1, 1)
+	RegisterCommand("HPEXPIRE", HPExpire, `HPEXPIRE key milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+	Same as HEXPIRE with a timeout in milliseconds.`, []string{"fast"}, -6, 1, 1, 1)
+	RegisterCommand("HEXPIREAT", HExpireAt, `HEXPIREAT key unix-time-seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+	Same as HEXPIRE with the unix time in seconds the fields expire at.`, []string{"fast"}, -6, 1, 1, 1)
+	RegisterCommand("HTTL", HTTL, `HTTL key FIELDS numfields field [field ...]
+	Returns the remaining time to live of fields of the hash stored at key in seconds.
+	-1 if the field has no expiry, -2 if the field doesn't exist.`, []string{"readonly", "fast"}, -5, 1, 1, 1)
+	RegisterCommand("HPTTL", HPTTL, `HPTTL key FIELDS numfields field [field ...]
+	Same as HTTL in milliseconds.`, []string{"readonly", "fast"}, -5, 1, 1, 1)
+	RegisterCommand("HEXPIRETIME", HExpireTime, `HEXPIRETIME key FIELDS numfields field [field ...]
+	Returns the unix time in seconds fields of the hash stored at key expire at.
+	-1 if the field has no expiry, -2 if the field doesn't exist.`, []string{"readonly", "fast"}, -5, 1, 1, 1)
+	RegisterCommand("HPERSIST", HPersist, `HPERSIST key FIELDS numfields field [field ...]
+	Removes the expiry of fields of the hash stored at key.
+	Returns for every field: -2 if the field doesn't exist, -1 if it has no expiry and 1 if the expiry was removed.`, []string{"fast"}, -5, 1, 1, 1)
 	RegisterCommand("HDEL", HDel, `HDEL [KEY] [FIELD]
-	Deletes a field from the hash stored at key.`, []string{}, 3, 0, 0, 0)
+	Deletes a field from the hash stored at key.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("HGETALL", HGetAll, `HGETALL [KEY]
-	Returns all fields and values of the hash stored at key.`, []string{"readonly", "fast"}, 2, 0, 0, 0)
+	Returns all fields and values of the hash stored at key.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
 
 	// Lists
 	RegisterCommand("RPOP", RPop, `RPOP [KEY] [COUNT]
-	Removes and returns the last element(s) of the list stored at key.`, []string{}, -2, 0, 0, 0)
+	Removes and returns the last element(s) of the list stored at key.`, []string{}, -2, 1, 1, 1)
 	RegisterCommand("RPUSH", RPush, `RPUSH [KEY] [VALUE] [VALUE ...]
-	Inserts one or more elements at the end of the list stored at key.`, []string{}, -3, 0, 0, 0)
+	Inserts one or more elements at the end of the list stored at key.`, []string{}, -3, 1, 1, 1)
 	RegisterCommand("LINDEX", LIndex, `LINDEX [KEY] [INDEX]
-	Returns the element at index INDEX in the list stored at key.`, []string{"readonly", "fast"}, 3, 0, 0, 0)
+	Returns the element at index INDEX in the list stored at key.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
 	RegisterCommand("LINSERT", LInsert, `LINSERT [KEY] [BEFORE|AFTER] [PIVOT] [VALUE]
-	Inserts VALUE in the list stored at KEY either before or after the PIVOT element.`, []string{}, 5, 0, 0, 0)
+	Inserts VALUE in the list stored at KEY either before or after the PIVOT element.`, []string{}, 5, 1, 1, 1)
 	RegisterCommand("LMOVE", LMove, `LMOVE [SOURCE] [DESTINATION] [LEFT|RIGHT]
-	Removes an element from the source list and pushes it to the destination list from the specified direction.`, []string{}, 4, 0, 0, 0)
+	Removes an element from the source list and pushes it to the destination list from the specified direction.`, []string{}, 4, 1, 2, 1)
 	RegisterCommand("LRANGE", LRange, `LRANGE [KEY] [START] [END]
-	Returns the specified elements of the list stored at key.`, []string{"readonly", "fast"}, 4, 0, 0, 0)
+	Returns the specified elements of the list stored at key.`, []string{"readonly", "fast"}, 4, 1, 1, 1)
 	RegisterCommand("LLEN", LLen, `LLEN [KEY]
-	Returns the length of the list stored at key.`, []string{"readonly", "fast"}, 2, 0, 0, 0)
+	Returns the length of the list stored at key.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
 	RegisterCommand("LPOP", LPop, `LPOP [KEY] [COUNT]
-	Removes and returns the first element(s) of the list stored at key.`, []string{}, -2, 0, 0, 0)
+	Removes and returns the first element(s) of the list stored at key.`, []string{}, -2, 1, 1, 1)
 	RegisterCommand("LPUSH", LPush, `LPUSH [KEY] [VALUE] [VALUE ...]
-	Inserts one or more elements at the beginning of the list stored at key.`, []string{}, -3, 0, 0, 0)
+	Inserts one or more elements at the beginning of the list stored at key.`, []string{}, -3, 1, 1, 1)
+	RegisterCommand("LPUSHX", LPushX, `LPUSHX [KEY] [VALUE] [VALUE ...]
+	Inserts one or more elements at the beginning of the list stored at key, only if the list exists.`, []string{}, -3, 1, 1, 1)
+	RegisterCommand("RPUSHX", RPushX, `RPUSHX [KEY] [VALUE] [VALUE ...]
+	Inserts one or more elements at the end of the list stored at key, only if the list exists.`, []string{}, -3, 1, 1, 1)
+	RegisterCommand("LPOS", LPos, `LPOS [KEY] [ELEMENT] [RANK rank] [COUNT count] [MAXLEN len]
+	Returns the index of the first match of element in the list stored at key, or of the RANK-th match.
+	With COUNT an array of up to COUNT indexes is returned, all of them if COUNT is 0. MAXLEN limits the elements compared.`, []string{"readonly"}, -3, 1, 1, 1)
+	RegisterCommand("LREM", LRem, `LREM [KEY] [COUNT] [ELEMENT]
+	Removes the first COUNT occurrences of element from the list stored at key, the last ones if COUNT is negative, all of them if it is 0.`, []string{}, 4, 1, 1, 1)
+	RegisterCommand("LSET", LSet, `LSET [KEY] [INDEX] [ELEMENT]
+	Sets the element at index INDEX in the list stored at key.`, []string{}, 4, 1, 1, 1)
+	RegisterCommand("LTRIM", LTrim, `LTRIM [KEY] [START] [STOP]
+	Trims the list stored at key to the elements between START and STOP, both inclusive.`, []string{}, 4, 1, 1, 1)
+	RegisterClientCommand("LMPOP", LMpop, `LMPOP numkeys key [key ...] LEFT|RIGHT [COUNT count]
+	Pops up to COUNT elements from the first non-empty list among the keys and returns the key with the elements.`, []string{}, -4, 0, 0, 0)
 
 	// Sets
 	RegisterCommand("SADD", Sadd, `SADD [KEY] [MEMBER] [MEMBER ...]
-	Adds one or more members to the set stored at key.`, []string{}, -3, 0, 0, 0)
+	Adds one or more members to the set stored at key.`, []string{}, -3, 1, 1, 1)
 	RegisterCommand("SCARD", Scard, `SCARD [KEY]
-	Returns the number of members in the set stored at key.`, []string{"readonly", "fast"}, 2, 0, 0, 0)
+	Returns the number of members in the set stored at key.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
 	RegisterCommand("SDIFF", Sdiff, `SDIFF [KEY] [KEY ...]
-	Returns the members of the set resulting from the difference between the first set and all the successive sets.`, []string{"readonly", "fast"}, -2, 0, 0, 0)
+	Returns the members of the set resulting from the difference between the first set and all the successive sets.`, []string{"readonly", "fast"}, -2, 1, -1, 1)
 	RegisterCommand("SDIFFSTORE", SdiffStore, `SDIFFSTORE [DESTINATION] [KEY] [KEY ...]
-	Stores the result of the difference between the first set and all the successive sets in the destination set.`, []string{}, -3, 0, 0, 0)
+	Stores the result of the difference between the first set and all the successive sets in the destination set.`, []string{}, -3, 1, -1, 1)
 	RegisterCommand("SISMEMBER", Sismember, `SISMEMBER [KEY] [MEMBER]
-	Returns if member is a member of the set stored at key.`, []string{"readonly", "fast"}, 3, 0, 0, 0)
+	Returns if member is a member of the set stored at key.`, []string{"readonly", "fast"}, 3, 1, 1, 1)
+	RegisterCommand("SMISMEMBER", Smismember, `SMISMEMBER [KEY] [MEMBER] [MEMBER ...]
+	Returns for each member 1 if it is a member of the set stored at key, 0 otherwise.`, []string{"readonly", "fast"}, -3, 1, 1, 1)
+	RegisterCommand("SREM", Srem, `SREM [KEY] [MEMBER] [MEMBER ...]
+	Removes one or more members from the set stored at key, the set is deleted once it is empty.`, []string{"fast"}, -3, 1, 1, 1)
+	RegisterCommand("SMEMBERS", Smembers, `SMEMBERS [KEY]
+	Returns all the members of the set stored at key.`, []string{"readonly"}, 2, 1, 1, 1)
+	RegisterCommand("SINTER", Sinter, `SINTER [KEY] [KEY ...]
+	Returns the members of the set resulting from the intersection of all the given sets.`, []string{"readonly"}, -2, 1, -1, 1)
+	RegisterCommand("SINTERSTORE", SinterStore, `SINTERSTORE [DESTINATION] [KEY] [KEY ...]
+	Stores the intersection of all the given sets in the destination set and returns its size.`, []string{}, -3, 1, -1, 1)
+	RegisterClientCommand("SINTERCARD", Sintercard, `SINTERCARD numkeys key [key ...] [LIMIT limit]
+	Returns the number of members in the intersection of the given sets, counting no further than LIMIT if it isn't 0.`, []string{"readonly"}, -3, 0, 0, 0)
+	RegisterCommand("SUNION", Sunion, `SUNION [KEY] [KEY ...]
+	Returns the members of the set resulting from the union of all the given sets.`, []string{"readonly"}, -2, 1, -1, 1)
+	RegisterCommand("SUNIONSTORE", SunionStore, `SUNIONSTORE [DESTINATION] [KEY] [KEY ...]
+	Stores the union of all the given sets in the destination set and returns its size.`, []string{}, -3, 1, -1, 1)
+	RegisterCommand("SPOP", Spop, `SPOP [KEY] [COUNT]
+	Removes and returns one or COUNT random members of the set stored at key.`, []string{"fast"}, -2, 1, 1, 1)
+	RegisterCommand("SRANDMEMBER", Srandmember, `SRANDMEMBER [KEY] [COUNT]
+	Returns one or COUNT random members of the set stored at key. A negative COUNT may return the same member several times.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("SMOVE", Smove, `SMOVE [SOURCE] [DESTINATION] [MEMBER]
+	Moves member from the source set to the destination set.`, []string{"fast"}, 4, 1, 2, 1)
+
+	// Streams
+	RegisterCommand("XADD", XAdd, `XADD [KEY] [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id [FIELD] [VALUE] [FIELD VALUE ...]
+	Appends an entry to the stream stored at key and returns its ID. * generates the ID from the current time,
+	ms-* only its sequence number. MAXLEN and MINID trim the oldest entries, LIMIT caps how many ~ trims.`, []string{"fast"}, -5, 1, 1, 1)
+	RegisterCommand("XLEN", XLen, `XLEN [KEY]
+	Returns the number of entries of the stream stored at key.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
+	RegisterCommand("XRANGE", XRange, `XRANGE [KEY] [START] [END] [COUNT count]
+	Returns the entries of the stream stored at key with IDs from start to end. - and + are the lowest and highest IDs,
+	an ID prefixed with ( is excluded.`, []string{"readonly"}, -4, 1, 1, 1)
+	RegisterCommand("XREVRANGE", XRevRange, `XREVRANGE [KEY] [END] [START] [COUNT count]
+	Returns the entries of the stream stored at key with IDs from end down to start.`, []string{"readonly"}, -4, 1, 1, 1)
+	RegisterCommand("XDEL", XDel, `XDEL [KEY] [ID] [ID ...]
+	Deletes entries of the stream stored at key and returns the number deleted.`, []string{"fast"}, -3, 1, 1, 1)
+	RegisterCommand("XTRIM", XTrim, `XTRIM [KEY] MAXLEN|MINID [=|~] threshold [LIMIT count]
+	Removes the oldest entries of the stream stored at key, keeping MAXLEN entries or the ones from MINID,
+	and returns the number removed.`, []string{}, -4, 1, 1, 1)
+	RegisterClientCommand("XREAD", XRead, `XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...]
+	Returns the entries of each stream with IDs greater than the given one, $ being the last entry of the stream.
+	BLOCK waits up to the given time, or forever with 0, for entries when there are none.`, []string{"readonly"}, -4, 0, 0, 0)
+	RegisterClientCommand("XREADGROUP", XReadGroup, `XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...]
+	Reads entries on behalf of a consumer of a group. > reads the entries never delivered to the group and adds them
+	to the consumer's pending entries unless NOACK is given, any other ID rereads the consumer's pending entries after it.`, []string{}, -7, 0, 0, 0)
+	RegisterCommand("XGROUP", XGroup, `XGROUP CREATE key group id|$ [MKSTREAM] [ENTRIESREAD n] | SETID key group id|$ [ENTRIESREAD n] | DESTROY key group | CREATECONSUMER key group consumer | DELCONSUMER key group consumer
+	Manages the consumer groups of the stream stored at key. DELCONSUMER returns the number of entries that were pending for the consumer.`, []string{}, -2, 2, 2, 1)
+	RegisterCommand("XACK", XAck, `XACK [KEY] [GROUP] [ID] [ID ...]
+	Acknowledges entries pending in a consumer group and returns the number acknowledged.`, []string{"fast"}, -4, 1, 1, 1)
+	RegisterCommand("XPENDING", XPending, `XPENDING [KEY] [GROUP] [[IDLE min-idle-time] start end count [consumer]]
+	Returns the number of entries pending in a consumer group, their lowest and highest IDs and the count per consumer,
+	or with a range the ID, consumer, idle time and delivery count of each pending entry.`, []string{"readonly"}, -3, 1, 1, 1)
+	RegisterCommand("XCLAIM", XClaim, `XCLAIM [KEY] [GROUP] [CONSUMER] [MIN-IDLE-TIME] [ID] [ID ...] [IDLE ms] [TIME unix-time-milliseconds] [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID id]
+	Gives to consumer the entries pending in a group for at least min-idle-time milliseconds and returns them.`, []string{"fast"}, -6, 1, 1, 1)
+	RegisterCommand("XAUTOCLAIM", XAutoClaim, `XAUTOCLAIM [KEY] [GROUP] [CONSUMER] [MIN-IDLE-TIME] [START] [COUNT count] [JUSTID]
+	Claims up to COUNT entries pending for at least min-idle-time milliseconds from start, and returns the ID to continue
+	from, the claimed entries and the IDs of the entries deleted from the stream.`, []string{"fast"}, -6, 1, 1, 1)
+	RegisterCommand("XINFO", XInfo, `XINFO STREAM key | GROUPS key | CONSUMERS key group
+	Describes the stream stored at key, its consumer groups or the consumers of a group.`, []string{"readonly"}, -2, 2, 2, 1)
+
+	// Geospatial
+	RegisterCommand("GEOADD", GeoAdd, `GEOADD [KEY] [NX|XX] [CH] [LONGITUDE] [LATITUDE] [MEMBER] [LONGITUDE LATITUDE MEMBER ...]
+	Adds members at their positions to the geospatial index stored at key, or moves them. NX only adds new members,
+	XX only moves existing ones. Returns the number of members added, or added and moved with CH.`, []string{}, -5, 1, 1, 1)
+	RegisterCommand("GEOPOS", GeoPos, `GEOPOS [KEY] [MEMBER ...]
+	Returns the longitude and latitude of members of the geospatial index stored at key, nil for missing members.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("GEODIST", GeoDist, `GEODIST [KEY] [MEMBER1] [MEMBER2] [M|KM|FT|MI]
+	Returns the distance between two members of the geospatial index stored at key, in meters by default.`, []string{"readonly"}, -4, 1, 1, 1)
+	RegisterCommand("GEOHASH", GeoHash, `GEOHASH [KEY] [MEMBER ...]
+	Returns the 11 characters geohash strings of members of the geospatial index stored at key.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("GEOSEARCH", GeoSearch, `GEOSEARCH [KEY] [FROMMEMBER member|FROMLONLAT longitude latitude] [BYRADIUS radius M|KM|FT|MI|BYBOX width height M|KM|FT|MI] [ASC|DESC] [COUNT count [ANY]] [WITHCOORD] [WITHDIST] [WITHHASH]
+	Returns the members of the geospatial index stored at key within a radius or a box centered on a member or a position.
+	COUNT returns the closest members, or with ANY the first ones found. WITHDIST, WITHHASH and WITHCOORD add the
+	distance to the center, the geohash score and the position of each member.`, []string{"readonly"}, -7, 1, 1, 1)
+	RegisterCommand("GEOSEARCHSTORE", GeoSearchStore, `GEOSEARCHSTORE [DESTINATION] [SOURCE] [FROMMEMBER member|FROMLONLAT longitude latitude] [BYRADIUS radius M|KM|FT|MI|BYBOX width height M|KM|FT|MI] [ASC|DESC] [COUNT count [ANY]] [STOREDIST]
+	Stores at destination the members of the geospatial index stored at source found like GEOSEARCH does,
+	scored by their distance to the center with STOREDIST. Returns the number of members stored.`, []string{}, -8, 1, 2, 1)
+
+	// JSON
+	RegisterCommand("JSON.SET", JsonSet, `JSON.SET [KEY] [PATH] [VALUE] [NX|XX]
+	Sets the values selected by path in the JSON document stored at key to the JSON value, and adds it to the objects
+	missing the member a path ending with a name selects. A new key must be set at the root path $.
+	NX only adds values, XX only replaces them. Returns OK, or nil if nothing was set.`, []string{}, -4, 1, 1, 1)
+	RegisterCommand("JSON.GET", JsonGet, `JSON.GET [KEY] [INDENT indent] [NEWLINE newline] [SPACE space] [PATH ...]
+	Returns the JSON text of the values selected by the paths in the document stored at key, the whole document
+	without path. A JSONPath starting with $ returns an array of all the values it selects, several paths return
+	an object keyed by path. INDENT, NEWLINE and SPACE format the JSON text.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("JSON.MGET", JsonMGet, `JSON.MGET [KEY] [KEY ...] [PATH]
+	Returns the JSON text of the values selected by path in the documents stored at each key, nil for missing keys.`, []string{"readonly"}, -3, 1, -2, 1)
+	RegisterCommand("JSON.DEL", JsonDel, `JSON.DEL [KEY] [PATH]
+	Deletes the values selected by path in the JSON document stored at key, or the key for the root path.
+	Returns the number of values deleted.`, []string{}, -2, 1, 1, 1)
+	RegisterCommand("JSON.FORGET", JsonDel, `JSON.FORGET [KEY] [PATH]
+	Deletes the values selected by path in the JSON document stored at key, same as JSON.DEL.`, []string{}, -2, 1, 1, 1)
+	RegisterCommand("JSON.TYPE", JsonType, `JSON.TYPE [KEY] [PATH]
+	Returns the types of the values selected by path in the JSON document stored at key:
+	object, array, string, integer, number, boolean or null.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("JSON.NUMINCRBY", JsonNumIncrBy, `JSON.NUMINCRBY [KEY] [PATH] [VALUE]
+	Increments the numbers selected by path in the JSON document stored at key by value. Returns the new values.`, []string{}, 4, 1, 1, 1)
+	RegisterCommand("JSON.STRAPPEND", JsonStrAppend, `JSON.STRAPPEND [KEY] [PATH] [VALUE]
+	Appends the JSON string value to the strings selected by path in the JSON document stored at key.
+	Returns the new lengths of the strings.`, []string{}, -3, 1, 1, 1)
+	RegisterCommand("JSON.ARRAPPEND", JsonArrAppend, `JSON.ARRAPPEND [KEY] [PATH] [VALUE] [VALUE ...]
+	Appends the JSON values to the arrays selected by path in the JSON document stored at key.
+	Returns the new lengths of the arrays.`, []string{}, -4, 1, 1, 1)
+	RegisterCommand("JSON.ARRINSERT", JsonArrInsert, `JSON.ARRINSERT [KEY] [PATH] [INDEX] [VALUE] [VALUE ...]
+	Inserts the JSON values before index in the arrays selected by path in the JSON document stored at key,
+	counting from the end when index is negative. Returns the new lengths of the arrays.`, []string{}, -5, 1, 1, 1)
+	RegisterCommand("JSON.ARRLEN", JsonArrLen, `JSON.ARRLEN [KEY] [PATH]
+	Returns the lengths of the arrays selected by path in the JSON document stored at key.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("JSON.ARRPOP", JsonArrPop, `JSON.ARRPOP [KEY] [PATH] [INDEX]
+	Removes and returns the element at index, the last one by default, of the arrays selected by path
+	in the JSON document stored at key.`, []string{}, -2, 1, 1, 1)
+	RegisterCommand("JSON.OBJKEYS", JsonObjKeys, `JSON.OBJKEYS [KEY] [PATH]
+	Returns the keys of the objects selected by path in the JSON document stored at key.`, []string{"readonly"}, -2, 1, 1, 1)
+	RegisterCommand("JSON.MERGE", JsonMerge, `JSON.MERGE [KEY] [PATH] [VALUE]
+	Merges the JSON value into the values selected by path in the JSON document stored at key as a JSON merge patch,
+	null members deleting the ones they merge into.`, []string{}, 4, 1, 1, 1)
 
 	// Help
 	RegisterCommand("HELP", Help, `HELP [COMMAND]
 	Provides details on how to use a command and what the command actually does.`, []string{"readonly", "fast"}, -1, 0, 0, 0)
 
 	// Generics
-	RegisterCommand("COPY", CopyVal, `COPY [key1] [key2]
-	Copies value(s) of key1 into key2.
-	If key2 doesn't exist, creates key2 and copies the value of key1 into key2.`, []string{}, 3, 0, 0, 0)
+	RegisterCommand("COPY", CopyVal, `COPY [key1] [key2] [DB destination-db] [REPLACE]
+	Copies value(s) of key1 into key2, along with its expiry.
+	DB copies into key2 of another database, REPLACE overwrites key2 if it exists, otherwise 0 is returned.`, []string{}, -3, 1, 2, 1)
 	RegisterCommand("DEL", Del, `DEL key1 [keys...]
-	Deletes all the keys passed as argument. Ignores the keys in the argument that don't exist.`, []string{}, -2, 0, 0, 0)
+	Deletes all the keys passed as argument and returns how many of them existed.`, []string{}, -2, 1, -1, 1)
 	RegisterCommand("EXISTS", Exists, `EXISTS key1 [keys...]
-	Returns an integer denoting how many of the passed keys exist in the cache.`, []string{"readonly", "fast"}, -2, 0, 0, 0)
+	Returns an integer denoting how many of the passed keys exist in the cache.`, []string{"readonly", "fast"}, -2, 1, -1, 1)
 	RegisterCommand("EXPIRE", Expire, `EXPIRE key seconds [NX XX GT LT]
 	Sets a timeout on key. After the timeout, the key gets deleted.
 	NX - Only set timeout if the key has no previous expiry.
 	XX - Only set timeout if the key has a previous expiry.
 	GT - Only set timeout if the new time is greater than the existing expiry.
-	LT - Only set timeout if the new time is less than the existing expiry.`, []string{}, 3, 0, 0, 0)
+	LT - Only set timeout if the new time is less than the existing expiry.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("EXPIREAT", ExpireAt, `EXPIREAT key unix-time-seconds [NX XX GT LT]
 	Sets the timeout of a key to the unix time stamp in seconds. After the timeout, the key gets deleted.
 	NX - Only set timeout if the key has no previous expiry.
 	XX - Only set timeout if the key has a previous expiry.
 	GT - Only set timeout if the new time is greater than the existing expiry.
-	LT - Only set timeout if the new time is less than the existing expiry.`, []string{}, 3, 0, 0, 0)
+	LT - Only set timeout if the new time is less than the existing expiry.`, []string{}, 3, 1, 1, 1)
 	RegisterCommand("EXPIRETIME", ExpireTime, `EXPIRETIME key
 	Returns the expire time of a key in unix epoch seconds.
 	-1 If the key doesn't have an expiry set
-	-2 If the key doesn't exist`, []string{"readonly", "fast"}, 2, 0, 0, 0)
-	RegisterCommand("KEYS", Keys, `KEYS
-	Returns the keys that exist in the store.`, []string{"readonly", "fast"}, 1, 0, 0, 0)
+	-2 If the key doesn't exist`, []string{"readonly", "fast"}, 2, 1, 1, 1)
+	RegisterClientCommand("KEYS", Keys, `KEYS [PATTERN]
+	Returns the keys of the current database whose names match the regular expression pattern.`, []string{"readonly", "fast"}, 1, 0, 0, 0)
+	RegisterCommand("RENAME", Rename, `RENAME key newkey
+	Renames key to newkey, keeping its expiry. An existing newkey is overwritten.`, []string{}, 3, 1, 2, 1)
+	RegisterCommand("RENAMENX", RenameNx, `RENAMENX key newkey
+	Renames key to newkey only if newkey doesn't exist. Returns 1 if key was renamed, 0 otherwise.`, []string{"fast"}, 3, 1, 2, 1)
+	RegisterCommand("PERSIST", Persist, `PERSIST key
+	Removes the expiry of a key. Returns 1 if the expiry was removed, 0 if the key doesn't exist or has no expiry.`, []string{"fast"}, 2, 1, 1, 1)
+	RegisterCommand("TTL", TTL, `TTL key
+	Returns the remaining time to live of a key in seconds.
+	-1 If the key doesn't have an expiry set
+	-2 If the key doesn't exist`, []string{"readonly", "fast"}, 2, 1, 1, 1)
+	RegisterCommand("TYPE", Type, `TYPE key
+	Returns the data type of the value stored at key, or none if it doesn't exist.`, []string{"readonly", "fast"}, 2, 1, 1, 1)
+	RegisterClientCommand("RANDOMKEY", RandomKey, `RANDOMKEY
+	Returns a random key of the current database, nil if it is empty.`, []string{"readonly"}, 1, 0, 0, 0)
+	RegisterCommand("TOUCH", Touch, `TOUCH key [key ...]
+	Marks the keys as recently used so they are evicted last. Returns the number of keys that exist.`, []string{"readonly", "fast"}, -2, 1, -1, 1)
+	RegisterCommand("UNLINK", Unlink, `UNLINK key [key ...]
+	Deletes the keys like DEL, but frees the memory of large values in the background.
+	Returns the number of keys that existed.`, []string{"fast"}, -2, 1, -1, 1)
+	RegisterCommand("DUMP", Dump, `DUMP key
+	Returns the value stored at key serialized in a versioned and checksummed format, to be recreated with RESTORE.
+	Returns nil if the key doesn't exist.`, []string{"readonly"}, 2, 1, 1, 1)
+	RegisterCommand("RESTORE", Restore, `RESTORE key ttl serialized-value [REPLACE] [ABSTTL] [IDLETIME seconds] [FREQ frequency]
+	Creates a key from a value serialized with DUMP. The ttl is in milliseconds, 0 for no expiry.
+	REPLACE overwrites the key if it exists, otherwise a BUSYKEY error is returned.
+	ABSTTL makes the ttl a unix time in milliseconds.
+	IDLETIME and FREQ set the idle time and access frequency reported by OBJECT.`, []string{"write"}, -4, 1, 1, 1)
+	RegisterClientCommand("MIGRATE", Migrate, `MIGRATE host port key|"" destination-db timeout [COPY] [REPLACE] [AUTH password] [AUTH2 username password] [KEYS key [key ...]]
+	Moves keys to another instance, each key is restored on the target with RESTORE and then deleted here.
+	The timeout is in milliseconds. Up to 4 connections per target are kept for reuse for 10 seconds.
+	COPY keeps the local keys, REPLACE overwrites existing keys on the target.
+	AUTH and AUTH2 authenticate on Redis targets only, an animus target rejects them.
+	KEYS migrates several keys at once, the key argument must be an empty string then.
+	Returns OK, or NOKEY if none of the keys exist.`, []string{"write"}, -6, 0, 0, 0)
+	RegisterCommand("OBJECT", Object, `OBJECT ENCODING|IDLETIME|FREQ|REFCOUNT key
+	ENCODING returns the internal representation of the value stored at key: int for strings holding
+	a 64-bit integer, embstr or raw for other strings, listpack or deque for lists, listpack or hashtable for hashes, intset or hashtable for sets,
+	skiplist for sorted sets.
+	IDLETIME returns the seconds since the key was last read or written.
+	FREQ returns the logarithmic access frequency counter of the key.
+	REFCOUNT returns the number of references to the value, always 1.`, []string{"readonly"}, 3, 2, 2, 1)
+	RegisterCommand("MEMORY", Memory, `MEMORY USAGE key [SAMPLES count] | STATS | DOCTOR
+	USAGE estimates the bytes used by a key and its value, from count elements of a container (5 by default, 0 for all).
+	STATS reports the memory used by the dataset versus the server overhead.
+	DOCTOR describes the memory issues found, if any.`, []string{"readonly"}, -2, 2, 2, 1)
+	RegisterClientCommand("MOVE", Move, `MOVE [KEY] [DB]
+	Moves a key of the current database to the given database, keeping its expiry.
+	Returns 1 if the key was moved, 0 if it doesn't exist or the target database already has it.`, []string{"fast"}, 3, 1, 1, 1)
+	RegisterCommand("SWAPDB", SwapDB, `SWAPDB [INDEX1] [INDEX2]
+	Swaps the contents of two databases.`, []string{"fast"}, 3, 0, 0, 0)
+	RegisterClientCommand("FLUSHDB", FlushDB, `FLUSHDB [ASYNC|SYNC]
+	Deletes all the keys of the current database.`, []string{}, -1, 0, 0, 0)
+	RegisterCommand("FLUSHALL", FlushAll, `FLUSHALL [ASYNC|SYNC]
+	Deletes all the keys of every database.`, []string{}, -1, 0, 0, 0)
+	RegisterClientCommand("DBSIZE", DBSize, `DBSIZE
+	Returns the number of keys in the current database.`, []string{"readonly", "fast"}, 1, 0, 0, 0)
 }
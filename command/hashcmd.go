@@ -1,6 +1,12 @@
 package command
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
 	"github.com/divy-sh/animus/types/hashes"
@@ -18,21 +24,118 @@ func HExists(args []resp.Value) resp.Value {
 	return resp.Value{Typ: common.INTEGER_TYPE, Num: val}
 }
 
+// HExpire implements HEXPIRE key seconds [NX|XX|GT|LT] FIELDS numfields field [field ...].
 func HExpire(args []resp.Value) resp.Value {
-	if len(args) == 2 {
-		err := hashes.HExpire(args[0].Bulk, args[1].Bulk, "")
-		if err != nil {
-			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
-		}
-	} else if len(args) == 3 {
-		err := hashes.HExpire(args[0].Bulk, args[1].Bulk, args[2].Bulk)
-		if err != nil {
-			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	return hashFieldExpire(args, func(n int64) int64 { return time.Now().UnixMilli() + n*1000 }, 1000)
+}
+
+// HPExpire implements HPEXPIRE key milliseconds [NX|XX|GT|LT] FIELDS numfields field [field ...].
+func HPExpire(args []resp.Value) resp.Value {
+	return hashFieldExpire(args, func(n int64) int64 { return time.Now().UnixMilli() + n }, 1)
+}
+
+// HExpireAt implements HEXPIREAT key unix-time-seconds [NX|XX|GT|LT] FIELDS numfields field [field ...].
+func HExpireAt(args []resp.Value) resp.Value {
+	return hashFieldExpire(args, func(n int64) int64 { return n * 1000 }, 1000)
+}
+
+// hashFieldExpire sets the expiry of hash fields, at returns the unix time in milliseconds
+// they expire at from the time argument, which is in units of unit milliseconds.
+func hashFieldExpire(args []resp.Value, at func(n int64) int64, unit int64) resp.Value {
+	if len(args) < 5 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	n, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	// Keep the expiry time in milliseconds from overflowing.
+	if n < 0 || n > (math.MaxInt64-time.Now().UnixMilli())/unit {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_EXPIRE_TIME}
+	}
+	condition, rest := "", args[2:]
+	switch strings.ToUpper(rest[0].Bulk) {
+	case "NX", "XX", "GT", "LT":
+		condition, rest = strings.ToUpper(rest[0].Bulk), rest[1:]
+	}
+	fields, err := parseHashFields(rest)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return integerArray(hashes.HExpire(args[0].Bulk, at(n), condition, fields))
+}
+
+// parseHashFields parses the FIELDS numfields field [field ...] arguments of the field expiry commands.
+func parseHashFields(args []resp.Value) ([]string, error) {
+	if len(args) < 2 || strings.ToUpper(args[0].Bulk) != "FIELDS" {
+		return nil, errors.New(common.ERR_FIELDS_MISSING)
+	}
+	n, err := strconv.Atoi(args[1].Bulk)
+	if err != nil || n <= 0 {
+		return nil, errors.New(common.ERR_NUMFIELDS_INVALID)
+	}
+	if n != len(args)-2 {
+		return nil, errors.New(common.ERR_NUMFIELDS_MISMATCH)
+	}
+	fields := make([]string, n)
+	for i := range fields {
+		fields[i] = args[i+2].Bulk
+	}
+	return fields, nil
+}
+
+func integerArray(values []int64) resp.Value {
+	array := make([]resp.Value, len(values))
+	for i, n := range values {
+		array[i] = resp.Value{Typ: common.INTEGER_TYPE, Num: n}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: array}
+}
+
+// HTTL implements HTTL key FIELDS numfields field [field ...], the remaining time to live of fields in seconds.
+func HTTL(args []resp.Value) resp.Value {
+	return hashFieldExpireTimes(args, func(at int64) int64 { return (at - time.Now().UnixMilli() + 500) / 1000 })
+}
+
+// HPTTL implements HPTTL key FIELDS numfields field [field ...], the remaining time to live of fields in milliseconds.
+func HPTTL(args []resp.Value) resp.Value {
+	return hashFieldExpireTimes(args, func(at int64) int64 { return at - time.Now().UnixMilli() })
+}
+
+// HExpireTime implements HEXPIRETIME key FIELDS numfields field [field ...], the unix time in seconds fields expire at.
+func HExpireTime(args []resp.Value) resp.Value {
+	return hashFieldExpireTimes(args, func(at int64) int64 { return (at + 500) / 1000 })
+}
+
+// hashFieldExpireTimes replies the expiry of hash fields converted by convert from unix
+// milliseconds, or the negative code when a field has no expiry or doesn't exist.
+func hashFieldExpireTimes(args []resp.Value, convert func(at int64) int64) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	fields, err := parseHashFields(args[1:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	times := hashes.HExpireTimes(args[0].Bulk, fields)
+	for i, at := range times {
+		if at >= 0 {
+			times[i] = max(convert(at), 0)
 		}
-	} else {
+	}
+	return integerArray(times)
+}
+
+// HPersist implements HPERSIST key FIELDS numfields field [field ...].
+func HPersist(args []resp.Value) resp.Value {
+	if len(args) < 3 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	return resp.Value{Typ: common.BULK_TYPE, Bulk: "OK"}
+	fields, err := parseHashFields(args[1:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return integerArray(hashes.HPersist(args[0].Bulk, fields))
 }
 
 func HGet(args []resp.Value) resp.Value {
@@ -47,15 +150,164 @@ func HGet(args []resp.Value) resp.Value {
 	return resp.Value{Typ: common.BULK_TYPE, Bulk: value}
 }
 
+// HSet sets one or more fields at once and returns the number of fields added.
 func HSet(args []resp.Value) resp.Value {
-	if len(args) != 3 {
+	if len(args) < 3 || len(args)%2 == 0 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	hashes.HSet(args[0].Bulk, args[1].Bulk, args[2].Bulk)
+	pairs := make([]string, len(args)-1)
+	for i := range pairs {
+		pairs[i] = args[i+1].Bulk
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: hashes.HMSet(args[0].Bulk, &pairs)}
+}
+
+// HMSet is the deprecated form of HSET that replies OK.
+func HMSet(args []resp.Value) resp.Value {
+	if result := HSet(args); result.Typ == common.ERROR_TYPE {
+		return result
+	}
 	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
 }
 
+func HSetNx(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: hashes.HSetNx(args[0].Bulk, args[1].Bulk, args[2].Bulk)}
+}
+
+func HMGet(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	fields := make([]string, len(args)-1)
+	for i := range fields {
+		fields[i] = args[i+1].Bulk
+	}
+	values, err := hashes.HMGet(args[0].Bulk, &fields)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	response := make([]resp.Value, len(values))
+	for i, value := range values {
+		if value == nil {
+			response[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			response[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: *value}
+		}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+func HIncrBy(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	value, err := hashes.HIncrBy(args[0].Bulk, args[1].Bulk, args[2].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: value}
+}
+
+func HIncrByFloat(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	value, err := hashes.HIncrByFloat(args[0].Bulk, args[1].Bulk, args[2].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: value}
+}
+
+func HKeys(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	keys, err := hashes.HKeys(args[0].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return bulkArray(keys...)
+}
+
+func HVals(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	values, err := hashes.HVals(args[0].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return bulkArray(values...)
+}
+
+func HLen(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	length, err := hashes.HLen(args[0].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: length}
+}
+
+func HStrlen(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	length, err := hashes.HStrlen(args[0].Bulk, args[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: length}
+}
+
+// HRandField implements HRANDFIELD key [count [WITHVALUES]].
+// Without a count a single field is returned as a bulk string rather than an array.
+func HRandField(args []resp.Value) resp.Value {
+	if len(args) < 1 || len(args) > 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+
+	count := int64(1)
+	if len(args) > 1 {
+		var err error
+		if count, err = strconv.ParseInt(args[1].Bulk, 10, 64); err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+		}
+		if count < -hashes.MaxRandomCount {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_OUT_OF_RANGE}
+		}
+	}
+	withValues := false
+	if len(args) == 3 {
+		if strings.ToUpper(args[2].Bulk) != "WITHVALUES" {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		withValues = true
+	}
+	fields, err := hashes.HRandField(args[0].Bulk, count, withValues)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if len(args) == 1 {
+		return resp.Value{Typ: common.BULK_TYPE, Bulk: fields[0]}
+	}
+	return bulkArray(fields...)
+}
+
 func HDel(args []resp.Value) resp.Value {
 	if len(args) != 2 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
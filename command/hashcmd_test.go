@@ -2,6 +2,7 @@ package command
 
 import (
 	"testing"
+	"time"
 
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
@@ -23,7 +24,7 @@ func TestHsetAndHGet(t *testing.T) {
 		},
 	}
 	result := HSet(input)
-	if result.Typ != common.STRING_TYPE || result.Str != "OK" {
+	if result.Typ != common.INTEGER_TYPE {
 		t.Errorf("Expected success but got type: %s, value: %s", result.Typ, result.Str)
 	}
 	result = HGet([]resp.Value{
@@ -145,287 +146,144 @@ func TestHExistsInvalidCommandSize(t *testing.T) {
 	}
 }
 
-func Test_Hashes_HExpire_InvalidArgumentCount1(t *testing.T) {
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_WRONG_ARGUMENT_COUNT, result)
-	}
-}
-
-func Test_Hashes_HExpire_InvalidArgumentCount2(t *testing.T) {
+func Test_Hashes_HExpire_InvalidArgumentCount(t *testing.T) {
 	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"}})
+		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpire_InvalidArgumentCount"},
+		{Typ: common.BULK_TYPE, Bulk: "10"},
+		{Typ: common.BULK_TYPE, Bulk: "FIELDS"},
+		{Typ: common.BULK_TYPE, Bulk: "1"}})
 	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
 		t.Errorf("Expected error: %s, got: %v", common.ERR_WRONG_ARGUMENT_COUNT, result)
 	}
 }
 
-func Test_Hashes_HExpireNoFlagKeyWithNoExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "0"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-	result = Get([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithNoExpiry"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_STRING_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_STRING_NOT_FOUND, result)
+func Test_Hashes_HExpire_InvalidFields(t *testing.T) {
+	client := newTestClient(t)
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"HEXPIRE", "key", "10", "FIELD", "1", "a"}, common.ERR_FIELDS_MISSING},
+		{[]string{"HEXPIRE", "key", "10", "FIELDS", "0", "a"}, common.ERR_NUMFIELDS_INVALID},
+		{[]string{"HEXPIRE", "key", "10", "FIELDS", "2", "a"}, common.ERR_NUMFIELDS_MISMATCH},
+		{[]string{"HEXPIRE", "key", "10", "NX", "XX", "FIELDS", "1", "a"}, common.ERR_FIELDS_MISSING},
+		{[]string{"HEXPIRE", "key", "ten", "FIELDS", "1", "a"}, common.ERR_INVALID_INTEGER},
+		{[]string{"HEXPIRE", "key", "-1", "FIELDS", "1", "a"}, common.ERR_INVALID_EXPIRE_TIME},
+		{[]string{"HTTL", "key", "FIELDS", "2", "a"}, common.ERR_NUMFIELDS_MISMATCH},
+	}
+	for _, test := range tests {
+		if result := run(client, test.args...); result.Typ != common.ERROR_TYPE || result.Str != test.expected {
+			t.Errorf("%v: expected %s, got %v", test.args, test.expected, result)
+		}
 	}
 }
 
-func Test_Hashes_HExpireNoFlagKeyWithExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "100"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-	result = HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "0"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-	result = Get([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagKeyWithExpiry"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_STRING_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_STRING_NOT_FOUND, result)
+func Test_Hashes_HExpireConditions(t *testing.T) {
+	client := newTestClient(t)
+	tests := []struct {
+		condition string
+		current   string // "" for no expiry
+		new       string
+		expected  int64
+	}{
+		{"", "", "10", 1},
+		{"", "100", "10", 1},
+		{"NX", "", "10", 1},
+		{"NX", "100", "10", 0},
+		{"XX", "", "10", 0},
+		{"XX", "100", "10", 1},
+		{"GT", "", "10", 0},
+		{"GT", "100", "10", 0},
+		{"GT", "100", "200", 1},
+		{"LT", "", "10", 1},
+		{"LT", "100", "10", 1},
+		{"LT", "100", "200", 0},
+	}
+	for _, test := range tests {
+		run(client, "HSET", "Test_Hashes_HExpireConditions", "field", "value")
+		if test.current != "" {
+			run(client, "HEXPIRE", "Test_Hashes_HExpireConditions", test.current, "FIELDS", "1", "field")
+		}
+		args := []string{"HEXPIRE", "Test_Hashes_HExpireConditions", test.new}
+		if test.condition != "" {
+			args = append(args, test.condition)
+		}
+		result := run(client, append(args, "FIELDS", "2", "field", "missing")...)
+		if len(result.Array) != 2 || result.Array[0].Num != test.expected || result.Array[1].Num != -2 {
+			t.Errorf("%s from %q to %s: expected [%d -2], got %v", test.condition, test.current, test.new, test.expected, result)
+		}
 	}
 }
 
-func Test_Hashes_HExpireNoFlagInvalidKey(t *testing.T) {
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNoFlagInvalidKey"},
-		{Typ: common.BULK_TYPE, Bulk: "10"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, result)
+func Test_Hashes_HExpireInvalidKey(t *testing.T) {
+	client := newTestClient(t)
+	result := run(client, "HEXPIRE", "Test_Hashes_HExpireInvalidKey", "10", "FIELDS", "2", "a", "b")
+	if len(result.Array) != 2 || result.Array[0].Num != -2 || result.Array[1].Num != -2 {
+		t.Errorf("Expected [-2 -2], got: %v", result)
 	}
 }
 
-func Test_Hashes_HExpireNXKeyWithNoExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "NX"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
+func Test_Hashes_HExpirePastTimeDeletesFields(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "HSET", "Test_Hashes_HExpirePastTimeDeletesFields", "a", "1", "b", "2")
+	result := run(client, "HEXPIREAT", "Test_Hashes_HExpirePastTimeDeletesFields", "1", "FIELDS", "1", "a")
+	if len(result.Array) != 1 || result.Array[0].Num != 2 {
+		t.Errorf("Expected [2], got: %v", result)
 	}
-}
-
-func Test_Hashes_HExpireNXKeyWithExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "100"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
+	if result := run(client, "HLEN", "Test_Hashes_HExpirePastTimeDeletesFields"); result.Num != 1 {
+		t.Errorf("Expected 1 field left, got: %v", result)
 	}
-	result = HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "NX"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, result)
+	run(client, "HEXPIRE", "Test_Hashes_HExpirePastTimeDeletesFields", "0", "FIELDS", "1", "b")
+	if result := run(client, "EXISTS", "Test_Hashes_HExpirePastTimeDeletesFields"); result.Num != 0 {
+		t.Errorf("Expected the empty hash to be deleted, got: %v", result)
 	}
 }
 
-func Test_Hashes_HExpireNXInvalidKey(t *testing.T) {
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXInvalidKey"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "NX"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, result)
+func Test_Hashes_HPExpireExpiresField(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "HSET", "Test_Hashes_HPExpireExpiresField", "token", "secret", "name", "value")
+	run(client, "HPEXPIRE", "Test_Hashes_HPExpireExpiresField", "20", "FIELDS", "1", "token")
+	time.Sleep(30 * time.Millisecond)
+	if result := run(client, "HGET", "Test_Hashes_HPExpireExpiresField", "token"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("Expected the field to have expired, got: %v", result)
 	}
-}
-
-func Test_Hashes_HExpireXXKeyWithNoExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireXXKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireXXKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireXXKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "XX"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, result)
+	if result := run(client, "HGET", "Test_Hashes_HPExpireExpiresField", "name"); result.Bulk != "value" {
+		t.Errorf("Expected the other field to be kept, got: %v", result)
 	}
 }
 
-func Test_Hashes_HExpireXXKeyWithExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireXXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireXXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireXXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "100"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
+func Test_Hashes_HTTLAndHPersist(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "HSET", "Test_Hashes_HTTLAndHPersist", "a", "1", "b", "2")
+	run(client, "HEXPIRE", "Test_Hashes_HTTLAndHPersist", "100", "FIELDS", "1", "a")
+	result := run(client, "HTTL", "Test_Hashes_HTTLAndHPersist", "FIELDS", "3", "a", "b", "c")
+	if len(result.Array) != 3 || result.Array[0].Num < 99 || result.Array[0].Num > 100 || result.Array[1].Num != -1 || result.Array[2].Num != -2 {
+		t.Errorf("Unexpected HTTL reply: %v", result)
 	}
-	result = HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireXXKeyWithExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "XX"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
+	result = run(client, "HPTTL", "Test_Hashes_HTTLAndHPersist", "FIELDS", "1", "a")
+	if len(result.Array) != 1 || result.Array[0].Num <= 99000 || result.Array[0].Num > 100000 {
+		t.Errorf("Unexpected HPTTL reply: %v", result)
 	}
-}
-
-func Test_Hashes_HExpireXXInvalidKey(t *testing.T) {
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireNXInvalidKey"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "XX"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, result)
+	result = run(client, "HEXPIRETIME", "Test_Hashes_HTTLAndHPersist", "FIELDS", "1", "a")
+	if len(result.Array) != 1 || result.Array[0].Num < time.Now().Unix()+99 {
+		t.Errorf("Unexpected HEXPIRETIME reply: %v", result)
 	}
-}
-
-func Test_Hashes_HExpireGTKeyWithNoExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "GT"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
+	result = run(client, "HPERSIST", "Test_Hashes_HTTLAndHPersist", "FIELDS", "3", "a", "b", "c")
+	if len(result.Array) != 3 || result.Array[0].Num != 1 || result.Array[1].Num != -1 || result.Array[2].Num != -2 {
+		t.Errorf("Unexpected HPERSIST reply: %v", result)
 	}
-}
-
-func Test_Hashes_HExpireGTKeyWithExpiryNewTimeSmaller(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "100"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-	result = HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "GT"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, result)
+	if result := run(client, "TTL", "Test_Hashes_HTTLAndHPersist"); result.Num != -1 {
+		t.Errorf("Expected field expiry not to set a key expiry, got: %v", result)
 	}
 }
 
-func Test_Hashes_HExpireGTKeyWithExpiryNewTimeGreater(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "100"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-	result = HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "200"},
-		{Typ: common.BULK_TYPE, Bulk: "GT"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-}
-
-func Test_Hashes_HExpireGTInvalidKey(t *testing.T) {
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireGTInvalidKey"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "GT"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, result)
-	}
-}
-
-func Test_Hashes_HExpireLTKeyWithNoExpiry(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithNoExpiry"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "LT"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, result)
-	}
-}
-
-func Test_Hashes_HExpireLTKeyWithExpiryNewTimeSmaller(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "100"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-	result = HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeSmaller"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "LT"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-}
-
-func Test_Hashes_HExpireLTKeyWithExpiryNewTimeGreater(t *testing.T) {
-	HSet([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "value"}})
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "100"}})
-	if result.Typ == common.ERROR_TYPE {
-		t.Errorf("Expected no error, got: %s", result.Str)
-	}
-	result = HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTKeyWithExpiryNewTimeGreater"},
-		{Typ: common.BULK_TYPE, Bulk: "200"},
-		{Typ: common.BULK_TYPE, Bulk: "LT"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, result)
-	}
-}
-
-func Test_Hashes_HExpireLTInvalidKey(t *testing.T) {
-	result := HExpire([]resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "Test_Hashes_HExpireLTInvalidKey"},
-		{Typ: common.BULK_TYPE, Bulk: "10"},
-		{Typ: common.BULK_TYPE, Bulk: "LT"}})
-	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, result)
+func Test_Hashes_HSetRemovesFieldExpiry(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "HSET", "Test_Hashes_HSetRemovesFieldExpiry", "a", "1")
+	run(client, "HEXPIRE", "Test_Hashes_HSetRemovesFieldExpiry", "100", "FIELDS", "1", "a")
+	run(client, "HSET", "Test_Hashes_HSetRemovesFieldExpiry", "a", "2")
+	if result := run(client, "HTTL", "Test_Hashes_HSetRemovesFieldExpiry", "FIELDS", "1", "a"); len(result.Array) != 1 || result.Array[0].Num != -1 {
+		t.Errorf("Expected HSET to remove the field expiry, got: %v", result)
 	}
 }
 
@@ -582,3 +440,76 @@ func TestHGetAll_InvalidCommandSize(t *testing.T) {
 		t.Errorf("Expected ERR wrong number of arguments for 'HGetAll' command but got %v", result)
 	}
 }
+
+func TestHashCommands_MultiField(t *testing.T) {
+	client := newTestClient(t)
+	if result := run(client, "HSET", "TestHashCommands_MultiField", "a", "1", "b", "2"); result.Typ != common.INTEGER_TYPE || result.Num != 2 {
+		t.Fatalf("expected 2 fields added, got %v", result)
+	}
+	if result := run(client, "HSET", "TestHashCommands_MultiField", "a", "1", "c", "3"); result.Num != 1 {
+		t.Fatalf("expected 1 field added, got %v", result)
+	}
+	if result := run(client, "HMSET", "TestHashCommands_MultiField", "c", "3"); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "HMSET", "TestHashCommands_MultiField", "c"); result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
+		t.Errorf("expected %s, got %v", common.ERR_WRONG_ARGUMENT_COUNT, result)
+	}
+	if result := run(client, "HSET", "TestHashCommands_MultiField", "a", "1", "b"); result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
+		t.Errorf("expected %s, got %v", common.ERR_WRONG_ARGUMENT_COUNT, result)
+	}
+	result := run(client, "HMGET", "TestHashCommands_MultiField", "a", "missing", "c")
+	if len(result.Array) != 3 || result.Array[0].Bulk != "1" || result.Array[1].Typ != common.NULL_TYPE || result.Array[2].Bulk != "3" {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "HLEN", "TestHashCommands_MultiField"); result.Num != 3 {
+		t.Errorf("expected 3 fields, got %v", result)
+	}
+	if result := run(client, "HKEYS", "TestHashCommands_MultiField"); len(result.Array) != 3 {
+		t.Errorf("expected 3 fields, got %v", result)
+	}
+	if result := run(client, "HVALS", "TestHashCommands_MultiField"); len(result.Array) != 3 {
+		t.Errorf("expected 3 values, got %v", result)
+	}
+	if result := run(client, "HSTRLEN", "TestHashCommands_MultiField", "a"); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	if result := run(client, "HEXISTS", "TestHashCommands_MultiField", "missing"); result.Typ != common.INTEGER_TYPE || result.Num != 0 {
+		t.Errorf("expected 0, got %v", result)
+	}
+}
+
+func TestHashCommands_Counters(t *testing.T) {
+	client := newTestClient(t)
+	if result := run(client, "HINCRBY", "TestHashCommands_Counters", "visits", "3"); result.Num != 3 {
+		t.Errorf("expected 3, got %v", result)
+	}
+	if result := run(client, "HINCRBYFLOAT", "TestHashCommands_Counters", "score", "1.25"); result.Bulk != "1.25" {
+		t.Errorf("expected 1.25, got %v", result)
+	}
+	if result := run(client, "HINCRBY", "TestHashCommands_Counters", "score", "1"); result.Str != common.ERR_HASH_VALUE_NOT_INTEGER {
+		t.Errorf("expected %s, got %v", common.ERR_HASH_VALUE_NOT_INTEGER, result)
+	}
+	if result := run(client, "HSETNX", "TestHashCommands_Counters", "visits", "0"); result.Num != 0 {
+		t.Errorf("expected the field to be kept, got %v", result)
+	}
+}
+
+func TestHRandField(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "HSET", "TestHRandField", "a", "1", "b", "2")
+	if result := run(client, "HRANDFIELD", "TestHRandField"); result.Typ != common.BULK_TYPE {
+		t.Errorf("expected a single field, got %v", result)
+	}
+	if result := run(client, "HRANDFIELD", "TestHRandField", "-3", "WITHVALUES"); len(result.Array) != 6 {
+		t.Errorf("expected 3 field value pairs, got %v", result)
+	}
+	if result := run(client, "HRANDFIELD", "TestHRandField", "1", "VALUES"); result.Str != common.ERR_SYNTAX {
+		t.Errorf("expected %s, got %v", common.ERR_SYNTAX, result)
+	}
+	for _, count := range []string{"-100000000000000", "-9223372036854775808"} {
+		if result := run(client, "HRANDFIELD", "TestHRandField", count); result.Str != common.ERR_OUT_OF_RANGE {
+			t.Errorf("expected %s for count %s, got %v", common.ERR_OUT_OF_RANGE, count, result)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	typestrings "github.com/divy-sh/animus/types/strings"
+)
+
+// PfAdd implements PFADD key [element ...], it replies 1 if the HyperLogLog was created or changed.
+func PfAdd(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	elements := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		elements[i] = arg.Bulk
+	}
+	updated, err := typestrings.PfAdd(args[0].Bulk, elements)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if updated {
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: 1}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: 0}
+}
+
+// PfCount implements PFCOUNT key [key ...].
+func PfCount(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = arg.Bulk
+	}
+	count, err := typestrings.PfCount(keys)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: count}
+}
+
+// PfMerge implements PFMERGE destkey [sourcekey ...].
+func PfMerge(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	sources := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		sources[i] = arg.Bulk
+	}
+	if err := typestrings.PfMerge(args[0].Bulk, sources); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// PfDebug implements PFDEBUG GETREG|DECODE|ENCODING|TODENSE key.
+func PfDebug(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	key := args[1].Bulk
+	switch strings.ToUpper(args[0].Bulk) {
+	case "GETREG":
+		regs, err := typestrings.PfDebugGetReg(key)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		values := make([]resp.Value, len(regs))
+		for i, v := range regs {
+			values[i] = resp.Value{Typ: common.INTEGER_TYPE, Num: v}
+		}
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+	case "DECODE":
+		decoded, err := typestrings.PfDebugDecode(key)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		return resp.Value{Typ: common.STRING_TYPE, Str: decoded}
+	case "ENCODING":
+		encoding, err := typestrings.PfDebugEncoding(key)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		return resp.Value{Typ: common.STRING_TYPE, Str: encoding}
+	case "TODENSE":
+		converted, err := typestrings.PfDebugToDense(key)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		if converted {
+			return resp.Value{Typ: common.INTEGER_TYPE, Num: 1}
+		}
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: 0}
+	}
+	return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR unknown subcommand, must be GETREG, DECODE, ENCODING or TODENSE"}
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+)
+
+func TestPfAddCountAndMerge(t *testing.T) {
+	client := newTestClient(t)
+	if result := run(client, "PFADD", "TestPfCmd1", "a", "b", "c"); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	if result := run(client, "PFADD", "TestPfCmd1", "a"); result.Num != 0 {
+		t.Errorf("expected 0, got %v", result)
+	}
+	run(client, "PFADD", "TestPfCmd2", "c", "d")
+	if result := run(client, "PFCOUNT", "TestPfCmd1", "TestPfCmd2"); result.Num != 4 {
+		t.Errorf("expected 4, got %v", result)
+	}
+	if result := run(client, "PFMERGE", "TestPfCmdDest", "TestPfCmd1", "TestPfCmd2"); result.Str != "OK" {
+		t.Errorf("expected OK, got %v", result)
+	}
+	if result := run(client, "PFCOUNT", "TestPfCmdDest"); result.Num != 4 {
+		t.Errorf("expected 4, got %v", result)
+	}
+	if result := run(client, "GET", "TestPfCmdDest"); result.Typ != common.BULK_TYPE || result.Bulk[:4] != "HYLL" {
+		t.Errorf("expected the HyperLogLog to be readable as a string, got %v", result)
+	}
+	run(client, "SET", "TestPfCmdString", "value")
+	if result := run(client, "PFADD", "TestPfCmdString", "a"); result.Str != common.ERR_HLL_WRONG_TYPE {
+		t.Errorf("expected %s, got %v", common.ERR_HLL_WRONG_TYPE, result)
+	}
+}
+
+func TestPfDebug(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "PFADD", "TestPfDebugCmd", "a")
+	if result := run(client, "PFDEBUG", "ENCODING", "TestPfDebugCmd"); result.Str != "sparse" {
+		t.Errorf("expected sparse, got %v", result)
+	}
+	if result := run(client, "PFDEBUG", "DECODE", "TestPfDebugCmd"); result.Typ != common.STRING_TYPE || result.Str == "" {
+		t.Errorf("expected the opcodes, got %v", result)
+	}
+	if result := run(client, "PFDEBUG", "TODENSE", "TestPfDebugCmd"); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	if result := run(client, "PFDEBUG", "GETREG", "TestPfDebugCmd"); len(result.Array) != 16384 {
+		t.Errorf("expected 16384 registers, got %d", len(result.Array))
+	}
+	if result := run(client, "PFDEBUG", "NOPE", "TestPfDebugCmd"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error, got %v", result)
+	}
+}
+
+func TestConfig_HllSparseMaxBytes(t *testing.T) {
+	client := newTestClient(t)
+	setConfig(t, "hll-sparse-max-bytes", "0")
+	defer setConfig(t, "hll-sparse-max-bytes", "3000")
+	run(client, "PFADD", "TestConfig_HllSparseMaxBytes", "a")
+	if result := run(client, "PFDEBUG", "ENCODING", "TestConfig_HllSparseMaxBytes"); result.Str != "dense" {
+		t.Errorf("expected dense, got %v", result)
+	}
+}
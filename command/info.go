@@ -0,0 +1,215 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/generics"
+)
+
+const serverVersion = "0.0.1-animus"
+
+type infoSection struct {
+	name string
+	// inDefault tells if the section is part of the reply to a bare INFO.
+	inDefault bool
+	lines     func() []string
+}
+
+var infoSections []infoSection
+
+// usedMemoryStartup is the heap in use once the server is initialized,
+// everything allocated afterwards is accounted to the dataset.
+var usedMemoryStartup uint64
+
+func init() {
+	infoSections = []infoSection{
+		{"server", true, serverInfo},
+		{"clients", true, clientsInfo},
+		{"memory", true, memoryInfo},
+		{"persistence", true, persistenceInfo},
+		{"stats", true, statsInfo},
+		{"replication", true, replicationInfo},
+		{"cpu", true, cpuInfo},
+		{"commandstats", false, commandStatsInfo},
+		{"keyspace", true, keyspaceInfo},
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	usedMemoryStartup = mem.HeapAlloc
+}
+
+// Info implements the INFO [section ...] command.
+// Without arguments it returns the default sections, "all" and "everything" return every section.
+func Info(args []resp.Value) resp.Value {
+	requested := map[string]bool{}
+	for _, arg := range args {
+		requested[strings.ToLower(arg.Bulk)] = true
+	}
+	all := requested["all"] || requested["everything"]
+	useDefault := len(args) == 0 || requested["default"]
+
+	var info strings.Builder
+	for _, section := range infoSections {
+		if !all && !requested[section.name] && !(useDefault && section.inDefault) {
+			continue
+		}
+		if info.Len() > 0 {
+			info.WriteString("\r\n")
+		}
+		info.WriteString("# " + strings.ToUpper(section.name[:1]) + section.name[1:] + "\r\n")
+		for _, line := range section.lines() {
+			info.WriteString(line + "\r\n")
+		}
+	}
+
+	return resp.Value{
+		Typ:  common.BULK_TYPE,
+		Bulk: info.String(),
+	}
+}
+
+func serverInfo() []string {
+	uptime := int64(time.Since(startTime).Seconds())
+	return []string{
+		"redis_version:" + serverVersion,
+		"redis_mode:standalone",
+		"os:" + runtime.GOOS + "-" + runtime.GOARCH,
+		"arch_bits:" + strconv.Itoa(strconv.IntSize),
+		"go_version:" + runtime.Version(),
+		"process_id:" + strconv.Itoa(os.Getpid()),
+		"tcp_port:" + GetConfig("port"),
+		"uptime_in_seconds:" + strconv.FormatInt(uptime, 10),
+		"uptime_in_days:" + strconv.FormatInt(uptime/86400, 10),
+	}
+}
+
+func clientsInfo() []string {
+	return []string{
+		"connected_clients:" + strconv.FormatInt(connectedClients.Load(), 10),
+		"maxclients:" + strconv.FormatInt(maxClients.Load(), 10),
+		"monitor_clients:" + strconv.FormatInt(monitorCount.Load(), 10),
+	}
+}
+
+func memoryInfo() []string {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	dataset := uint64(0)
+	if mem.HeapAlloc > usedMemoryStartup {
+		dataset = mem.HeapAlloc - usedMemoryStartup
+	}
+	return []string{
+		"used_memory:" + strconv.FormatUint(mem.HeapAlloc, 10),
+		"used_memory_human:" + bytesToHuman(mem.HeapAlloc),
+		"used_memory_rss:" + strconv.FormatUint(mem.Sys, 10),
+		"used_memory_rss_human:" + bytesToHuman(mem.Sys),
+		"used_memory_startup:" + strconv.FormatUint(usedMemoryStartup, 10),
+		"used_memory_dataset:" + strconv.FormatUint(dataset, 10),
+		"maxmemory:" + GetConfig("maxmemory"),
+		"heap_objects:" + strconv.FormatUint(mem.HeapObjects, 10),
+		"heap_inuse:" + strconv.FormatUint(mem.HeapInuse, 10),
+		"heap_idle:" + strconv.FormatUint(mem.HeapIdle, 10),
+		"heap_released:" + strconv.FormatUint(mem.HeapReleased, 10),
+		"gc_cycles:" + strconv.FormatUint(uint64(mem.NumGC), 10),
+		"gc_pause_total_ms:" + strconv.FormatUint(mem.PauseTotalNs/uint64(time.Millisecond), 10),
+		"lazyfree_pending_objects:" + strconv.FormatInt(generics.LazyfreePendingObjects(), 10),
+	}
+}
+
+func persistenceInfo() []string {
+	aofEnabled := "0"
+	if GetConfig("appendonly") == "yes" {
+		aofEnabled = "1"
+	}
+	return []string{
+		"loading:0",
+		"rdb_bgsave_in_progress:0",
+		"aof_enabled:" + aofEnabled,
+	}
+}
+
+func statsInfo() []string {
+	stats := store.GetStats()
+	return []string{
+		"total_connections_received:" + strconv.FormatInt(totalConnectionsReceived.Load(), 10),
+		"total_commands_processed:" + strconv.FormatInt(totalCommandsProcessed.Load(), 10),
+		"instantaneous_ops_per_sec:" + strconv.FormatInt(instantaneousOps.Load(), 10),
+		"rejected_connections:" + strconv.FormatInt(rejectedConnections.Load(), 10),
+		"expired_keys:" + strconv.FormatInt(stats.ExpiredKeys, 10),
+		"evicted_keys:" + strconv.FormatInt(stats.EvictedKeys, 10),
+		"keyspace_hits:" + strconv.FormatInt(stats.KeyspaceHits, 10),
+		"keyspace_misses:" + strconv.FormatInt(stats.KeyspaceMisses, 10),
+		"client_output_buffer_limit_disconnections:" + strconv.FormatInt(outputBufferLimitDisconnections.Load(), 10),
+	}
+}
+
+func replicationInfo() []string {
+	return []string{
+		"role:master",
+		"connected_slaves:0",
+		"master_repl_offset:0",
+	}
+}
+
+func cpuInfo() []string {
+	sys, user := cpuUsage()
+	return []string{
+		fmt.Sprintf("used_cpu_sys:%.6f", sys),
+		fmt.Sprintf("used_cpu_user:%.6f", user),
+	}
+}
+
+func commandStatsInfo() []string {
+	lines := []string{}
+	for _, name := range sortedCommandStats() {
+		stat, _ := commandStats.Load(name)
+		cmdStat := stat.(*commandStat)
+		calls := cmdStat.calls.Load()
+		usec := cmdStat.usec.Load()
+		perCall := 0.0
+		if calls > 0 {
+			perCall = float64(usec) / float64(calls)
+		}
+		lines = append(lines, fmt.Sprintf("cmdstat_%s:calls=%d,usec=%d,usec_per_call=%.2f,rejected_calls=0,failed_calls=%d",
+			strings.ToLower(name), calls, usec, perCall, cmdStat.failedCalls.Load()))
+	}
+	return lines
+}
+
+func keyspaceInfo() []string {
+	infos := store.GetKeyspaceInfo()
+	dbs := make([]int, 0, len(infos))
+	for db := range infos {
+		dbs = append(dbs, db)
+	}
+	sort.Ints(dbs)
+	lines := make([]string, 0, len(dbs))
+	for _, db := range dbs {
+		info := infos[db]
+		lines = append(lines, fmt.Sprintf("db%d:keys=%d,expires=%d,avg_ttl=%d", db, info.Keys, info.Expires, info.AvgTTL))
+	}
+	return lines
+}
+
+// bytesToHuman formats a byte count the way INFO does, e.g. 1.50M.
+func bytesToHuman(n uint64) string {
+	switch {
+	case n < 1<<10:
+		return strconv.FormatUint(n, 10) + "B"
+	case n < 1<<20:
+		return fmt.Sprintf("%.2fK", float64(n)/(1<<10))
+	case n < 1<<30:
+		return fmt.Sprintf("%.2fM", float64(n)/(1<<20))
+	default:
+		return fmt.Sprintf("%.2fG", float64(n)/(1<<30))
+	}
+}
@@ -0,0 +1,147 @@
+package command
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func infoFields(t *testing.T, sections ...string) map[string]string {
+	t.Helper()
+	args := make([]resp.Value, len(sections))
+	for i, section := range sections {
+		args[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: section}
+	}
+	result := Info(args)
+	if result.Typ != common.BULK_TYPE {
+		t.Fatalf("expected bulk reply, got %v", result)
+	}
+	fields := map[string]string{}
+	for _, line := range strings.Split(result.Bulk, "\r\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			fields[line] = ""
+			continue
+		}
+		key, value, _ := strings.Cut(line, ":")
+		fields[key] = value
+	}
+	return fields
+}
+
+func TestInfo_DefaultSections(t *testing.T) {
+	fields := infoFields(t)
+	for _, header := range []string{"# Server", "# Clients", "# Memory", "# Persistence", "# Stats", "# Replication", "# Cpu", "# Keyspace"} {
+		if _, ok := fields[header]; !ok {
+			t.Errorf("expected section %s in default INFO", header)
+		}
+	}
+	if _, ok := fields["# Commandstats"]; ok {
+		t.Errorf("commandstats should not be a default section")
+	}
+	if fields["redis_version"] != serverVersion || fields["tcp_port"] != "6379" {
+		t.Errorf("unexpected server fields %v", fields)
+	}
+	if strings.Contains(Info([]resp.Value{}).Bulk, `\n`) {
+		t.Errorf("INFO should not contain escaped newlines")
+	}
+}
+
+func TestInfo_SelectedSections(t *testing.T) {
+	fields := infoFields(t, "STATS", "cpu")
+	if len(fields) == 0 {
+		t.Fatal("expected fields")
+	}
+	if _, ok := fields["# Server"]; ok {
+		t.Errorf("server section should not be returned")
+	}
+	if _, ok := fields["total_commands_processed"]; !ok {
+		t.Errorf("expected stats fields, got %v", fields)
+	}
+	if _, ok := fields["used_cpu_user"]; !ok {
+		t.Errorf("expected cpu fields, got %v", fields)
+	}
+}
+
+func TestInfo_CommandStatsAndResetStat(t *testing.T) {
+	resetStats()
+	Execute(nil, "PING", Handlers["PING"], []resp.Value{})
+	Execute(nil, "GET", Handlers["GET"], []resp.Value{{Typ: common.BULK_TYPE, Bulk: "TestInfo_CommandStatsMissing"}})
+	fields := infoFields(t, "commandstats")
+	if !strings.HasPrefix(fields["cmdstat_ping"], "calls=") {
+		t.Errorf("expected ping stats, got %v", fields)
+	}
+	if !strings.HasSuffix(fields["cmdstat_get"], "failed_calls=1") {
+		t.Errorf("expected a failed get call, got %v", fields["cmdstat_get"])
+	}
+
+	result := ConfigCmd([]resp.Value{{Typ: common.BULK_TYPE, Bulk: "RESETSTAT"}})
+	if result.Typ != common.STRING_TYPE || result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	fields = infoFields(t, "everything")
+	if _, ok := fields["cmdstat_ping"]; ok {
+		t.Errorf("expected command stats to be reset")
+	}
+	if fields["total_commands_processed"] != "0" || fields["keyspace_misses"] != "0" {
+		t.Errorf("expected counters to be reset, got %v", fields)
+	}
+}
+
+func TestInfo_Keyspace(t *testing.T) {
+	Set([]resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "TestInfo_Keyspace"},
+		{Typ: common.BULK_TYPE, Bulk: "value"}})
+	fields := infoFields(t, "keyspace")
+	if !strings.HasPrefix(fields["db0"], "keys=") {
+		t.Errorf("expected db0 keyspace line, got %v", fields)
+	}
+}
+
+func TestAdmitClient_MaxClients(t *testing.T) {
+	setConfig(t, "maxclients", "1")
+	defer setConfig(t, "maxclients", "10000")
+
+	// Two connected clients are over the limit of one.
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	first := NewClient(serverConn)
+	defer first.Close()
+	otherConn, otherServerConn := net.Pipe()
+	defer otherConn.Close()
+	go otherConn.Read(make([]byte, 64))
+	second := NewClient(otherServerConn)
+	defer second.Close()
+
+	before := rejectedConnections.Load()
+	if AdmitClient(second) {
+		t.Errorf("expected the client to be rejected")
+	}
+	if rejectedConnections.Load() != before+1 {
+		t.Errorf("expected the rejection to be counted")
+	}
+}
+
+func TestConfig_ReadOnlyPort(t *testing.T) {
+	result := ConfigCmd([]resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "SET"},
+		{Typ: common.BULK_TYPE, Bulk: "port"},
+		{Typ: common.BULK_TYPE, Bulk: "7000"}})
+	if result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected error, got %v", result)
+	}
+}
+
+func TestBytesToHuman(t *testing.T) {
+	cases := map[uint64]string{512: "512B", 1536: "1.50K", 3 << 20: "3.00M", 2 << 30: "2.00G"}
+	for n, expected := range cases {
+		if got := bytesToHuman(n); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
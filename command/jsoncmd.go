@@ -0,0 +1,392 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/types/json"
+)
+
+// rootPath is the path of the commands whose path argument is optional, the legacy root path
+// whose replies hold a single value.
+const rootPath = "."
+
+// optionalPath parses args[i] as a path, or the root path if there are not that many arguments.
+func optionalPath(args []resp.Value, i int) (*json.Path, error) {
+	if i >= len(args) {
+		return json.ParsePath(rootPath)
+	}
+	return json.ParsePath(args[i].Bulk)
+}
+
+func jsonError(err error) resp.Value {
+	return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+}
+
+// jsonValues parses the JSON texts of args.
+func jsonValues(args []resp.Value) ([]any, error) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		v, err := json.Parse(arg.Bulk)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// lengthsValue replies with the first length for a legacy path, and all of them for a JSONPath,
+// null standing for the values the command doesn't apply to.
+func lengthsValue(path *json.Path, lengths []*int64) resp.Value {
+	response := make([]resp.Value, len(lengths))
+	for i, n := range lengths {
+		if n == nil {
+			response[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			response[i] = resp.Value{Typ: common.INTEGER_TYPE, Num: *n}
+		}
+	}
+	if path.IsLegacy() {
+		if len(response) == 0 {
+			return resp.Value{Typ: common.NULL_TYPE}
+		}
+		return response[0]
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+// JsonSet implements JSON.SET key path value [NX|XX].
+func JsonSet(args []resp.Value) resp.Value {
+	if len(args) != 3 && len(args) != 4 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	var nx, xx bool
+	if len(args) == 4 {
+		switch strings.ToUpper(args[3].Bulk) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	path, err := json.ParsePath(args[1].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	value, err := json.Parse(args[2].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	set, err := json.Set(args[0].Bulk, path, value, nx, xx)
+	if err != nil {
+		return jsonError(err)
+	}
+	if !set {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// JsonGet implements JSON.GET key [INDENT indent] [NEWLINE newline] [SPACE space] [path ...].
+func JsonGet(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	var f json.Format
+	paths := []*json.Path{}
+	for i := 1; i < len(args); i++ {
+		option := strings.ToUpper(args[i].Bulk)
+		if i+1 < len(args) {
+			switch option {
+			case "INDENT":
+				f.Indent = args[i+1].Bulk
+				i++
+				continue
+			case "NEWLINE":
+				f.Newline = args[i+1].Bulk
+				i++
+				continue
+			case "SPACE":
+				f.Space = args[i+1].Bulk
+				i++
+				continue
+			}
+		}
+		path, err := json.ParsePath(args[i].Bulk)
+		if err != nil {
+			return jsonError(err)
+		}
+		paths = append(paths, path)
+	}
+	text, ok, err := json.Get(args[0].Bulk, paths, f)
+	if err != nil {
+		return jsonError(err)
+	}
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: text}
+}
+
+// JsonMGet implements JSON.MGET key [key ...] path.
+func JsonMGet(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := json.ParsePath(args[len(args)-1].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	keys := make([]string, len(args)-1)
+	for i, arg := range args[:len(args)-1] {
+		keys[i] = arg.Bulk
+	}
+	texts := json.MGet(keys, path)
+	response := make([]resp.Value, len(texts))
+	for i, text := range texts {
+		if text == nil {
+			response[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			response[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: *text}
+		}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+// JsonDel implements JSON.DEL key [path], deleting the key when the path is the root.
+func JsonDel(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := optionalPath(args, 1)
+	if err != nil {
+		return jsonError(err)
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: json.Del(args[0].Bulk, path)}
+}
+
+// JsonType implements JSON.TYPE key [path].
+func JsonType(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := optionalPath(args, 1)
+	if err != nil {
+		return jsonError(err)
+	}
+	types, ok := json.Type(args[0].Bulk, path)
+	if !ok || (path.IsLegacy() && len(types) == 0) {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	if path.IsLegacy() {
+		return resp.Value{Typ: common.STRING_TYPE, Str: types[0]}
+	}
+	return bulkArray(types...)
+}
+
+// JsonNumIncrBy implements JSON.NUMINCRBY key path value. It replies with the new value for a
+// legacy path, and with a JSON array of the new values for a JSONPath, null for non-numbers.
+func JsonNumIncrBy(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := json.ParsePath(args[1].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	increment, err := json.Parse(args[2].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	switch increment.(type) {
+	case int64, float64:
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_FLOAT}
+	}
+	results, err := json.NumIncrBy(args[0].Bulk, path, increment)
+	if err != nil {
+		return jsonError(err)
+	}
+	if path.IsLegacy() {
+		return resp.Value{Typ: common.BULK_TYPE, Bulk: json.Serialize(results[0], json.Format{})}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: json.Serialize(json.NewArray(results), json.Format{})}
+}
+
+// JsonStrAppend implements JSON.STRAPPEND key [path] value, where value is a JSON string.
+func JsonStrAppend(args []resp.Value) resp.Value {
+	if len(args) != 2 && len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := optionalPath(args[:len(args)-1], 1)
+	if err != nil {
+		return jsonError(err)
+	}
+	value, err := json.Parse(args[len(args)-1].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_JSON_WRONG_TYPE}
+	}
+	lengths, err := json.StrAppend(args[0].Bulk, path, s)
+	if err != nil {
+		return jsonError(err)
+	}
+	return lengthsValue(path, lengths)
+}
+
+// JsonArrAppend implements JSON.ARRAPPEND key path value [value ...].
+func JsonArrAppend(args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := json.ParsePath(args[1].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	values, err := jsonValues(args[2:])
+	if err != nil {
+		return jsonError(err)
+	}
+	lengths, err := json.ArrAppend(args[0].Bulk, path, values)
+	if err != nil {
+		return jsonError(err)
+	}
+	return lengthsValue(path, lengths)
+}
+
+// JsonArrInsert implements JSON.ARRINSERT key path index value [value ...].
+func JsonArrInsert(args []resp.Value) resp.Value {
+	if len(args) < 4 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := json.ParsePath(args[1].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	index, err := strconv.Atoi(args[2].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	values, err := jsonValues(args[3:])
+	if err != nil {
+		return jsonError(err)
+	}
+	lengths, err := json.ArrInsert(args[0].Bulk, path, index, values)
+	if err != nil {
+		return jsonError(err)
+	}
+	return lengthsValue(path, lengths)
+}
+
+// JsonArrLen implements JSON.ARRLEN key [path].
+func JsonArrLen(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := optionalPath(args, 1)
+	if err != nil {
+		return jsonError(err)
+	}
+	lengths, ok, err := json.ArrLen(args[0].Bulk, path)
+	if err != nil {
+		return jsonError(err)
+	}
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return lengthsValue(path, lengths)
+}
+
+// JsonArrPop implements JSON.ARRPOP key [path [index]], index defaulting to the last element.
+func JsonArrPop(args []resp.Value) resp.Value {
+	if len(args) < 1 || len(args) > 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := optionalPath(args, 1)
+	if err != nil {
+		return jsonError(err)
+	}
+	index := -1
+	if len(args) == 3 {
+		if index, err = strconv.Atoi(args[2].Bulk); err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+		}
+	}
+	popped, ok, err := json.ArrPop(args[0].Bulk, path, index)
+	if err != nil {
+		return jsonError(err)
+	}
+	if !ok || (path.IsLegacy() && len(popped) == 0) {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	response := make([]resp.Value, len(popped))
+	for i, text := range popped {
+		if text == nil {
+			response[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			response[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: *text}
+		}
+	}
+	if path.IsLegacy() {
+		return response[0]
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+// JsonObjKeys implements JSON.OBJKEYS key [path].
+func JsonObjKeys(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := optionalPath(args, 1)
+	if err != nil {
+		return jsonError(err)
+	}
+	keys, ok, err := json.ObjKeys(args[0].Bulk, path)
+	if err != nil {
+		return jsonError(err)
+	}
+	if !ok || (path.IsLegacy() && len(keys) == 0) {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	response := make([]resp.Value, len(keys))
+	for i, k := range keys {
+		if k == nil {
+			response[i] = resp.Value{Typ: common.NULL_TYPE}
+		} else {
+			response[i] = bulkArray(k...)
+		}
+	}
+	if path.IsLegacy() {
+		return response[0]
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: response}
+}
+
+// JsonMerge implements JSON.MERGE key path value, merging value into the values the path
+// selects as a JSON merge patch.
+func JsonMerge(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	path, err := json.ParsePath(args[1].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	patch, err := json.Parse(args[2].Bulk)
+	if err != nil {
+		return jsonError(err)
+	}
+	if err := json.Merge(args[0].Bulk, path, patch); err != nil {
+		return jsonError(err)
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
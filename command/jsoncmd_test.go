@@ -0,0 +1,223 @@
+package command
+
+import (
+	"net"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+)
+
+func TestJsonSetAndGet(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestJsonSetAndGetCmd"
+	if result := run(client, "JSON.SET", key, "$.a", "1"); result.Str != common.ERR_JSON_ROOT {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_ROOT, result)
+	}
+	if result := run(client, "JSON.SET", key, "$", `{"a":{"b":[1,2]},"c":"x"}`); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	if result := run(client, "JSON.SET", key, "$", "1", "NX"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+	if result := run(client, "JSON.SET", key, "$.d", "true", "XX"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+	if result := run(client, "JSON.SET", key, "$.d", "true"); result.Str != "OK" {
+		t.Errorf("expected OK, got %v", result)
+	}
+	if result := run(client, "TYPE", key); result.Str != "ReJSON-RL" {
+		t.Errorf("expected ReJSON-RL, got %v", result)
+	}
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{}, `{"a":{"b":[1,2]},"c":"x","d":true}`},
+		{[]string{"$.a.b[*]"}, `[1,2]`},
+		{[]string{".c"}, `"x"`},
+		{[]string{"$.c", "$.d"}, `{"$.c":["x"],"$.d":[true]}`},
+		{[]string{"INDENT", "\t", "NEWLINE", "\n", "SPACE", " ", "$.a"}, "[\n\t{\n\t\t\"b\": [\n\t\t\t1,\n\t\t\t2\n\t\t]\n\t}\n]"},
+	}
+	for _, c := range cases {
+		args := append([]string{"JSON.GET", key}, c.args...)
+		if result := run(client, args...); result.Bulk != c.expected {
+			t.Errorf("expected %q for %v, got %v", c.expected, c.args, result)
+		}
+	}
+	errors := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"JSON.GET", key, ".missing"}, common.ERR_JSON_PATH_MISSING},
+		{[]string{"JSON.GET", key, "$["}, common.ERR_JSON_PATH},
+		{[]string{"JSON.SET", key, "$", "{"}, common.ERR_JSON_INVALID},
+		{[]string{"JSON.SET", key, "$", "1", "YY"}, common.ERR_SYNTAX},
+	}
+	for _, c := range errors {
+		if result := run(client, c.args...); result.Str != c.expected {
+			t.Errorf("expected %s for %v, got %v", c.expected, c.args, result)
+		}
+	}
+	if result := run(client, "JSON.GET", "TestJsonSetAndGetCmdMissing"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+}
+
+func TestJsonMGet(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "5")
+	run(client, "JSON.SET", "TestJsonMGetCmd1", "$", `{"a":1}`)
+	run(client, "JSON.SET", "TestJsonMGetCmd2", "$", `{"a":"x"}`)
+	result := run(client, "JSON.MGET", "TestJsonMGetCmd1", "TestJsonMGetCmd2", "TestJsonMGetCmdMissing", "$.a")
+	if len(result.Array) != 3 || result.Array[0].Bulk != `[1]` || result.Array[1].Bulk != `["x"]` || result.Array[2].Typ != common.NULL_TYPE {
+		t.Errorf("unexpected reply %v", result)
+	}
+}
+
+func TestJsonDelAndType(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestJsonDelAndTypeCmd"
+	run(client, "JSON.SET", key, "$", `{"a":[1,"x",null],"b":{"a":2.5}}`)
+	if result := run(client, "JSON.TYPE", key, "$..a"); len(result.Array) != 2 || result.Array[0].Bulk != "array" || result.Array[1].Bulk != "number" {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.TYPE", key); result.Str != "object" {
+		t.Errorf("expected object, got %v", result)
+	}
+	if result := run(client, "JSON.TYPE", key, "$.a[*]"); len(result.Array) != 3 || result.Array[2].Bulk != "null" {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.DEL", key, "$.a[0]"); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	if result := run(client, "JSON.FORGET", key, "$..a"); result.Num != 2 {
+		t.Errorf("expected 2, got %v", result)
+	}
+	if result := run(client, "JSON.GET", key); result.Bulk != `{"b":{}}` {
+		t.Errorf("unexpected document %v", result)
+	}
+	if result := run(client, "JSON.DEL", key); result.Num != 1 {
+		t.Errorf("expected the key to be deleted, got %v", result)
+	}
+	if result := run(client, "EXISTS", key); result.Num != 0 {
+		t.Errorf("expected the key to be deleted, got %v", result)
+	}
+	if result := run(client, "JSON.TYPE", key); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+}
+
+func TestJsonNumIncrByAndStrAppend(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestJsonNumIncrByCmd"
+	if result := run(client, "JSON.NUMINCRBY", key, "$.a", "1"); result.Str != common.ERR_JSON_NO_KEY {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_NO_KEY, result)
+	}
+	run(client, "JSON.SET", key, "$", `{"a":1,"b":{"a":"s"},"c":{"a":0.5}}`)
+	if result := run(client, "JSON.NUMINCRBY", key, "$..a", "2"); result.Bulk != `[3,null,2.5]` {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.NUMINCRBY", key, ".a", "0.5"); result.Bulk != `3.5` {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.NUMINCRBY", key, ".b.a", "1"); result.Str != common.ERR_JSON_WRONG_TYPE {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_WRONG_TYPE, result)
+	}
+	if result := run(client, "JSON.NUMINCRBY", key, "$.a", `"1"`); result.Str != common.ERR_INVALID_FLOAT {
+		t.Errorf("expected %s, got %v", common.ERR_INVALID_FLOAT, result)
+	}
+	if result := run(client, "JSON.STRAPPEND", key, "$..a", `"xy"`); len(result.Array) != 3 || result.Array[0].Typ != common.NULL_TYPE || result.Array[1].Num != 3 {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.STRAPPEND", key, ".b.a", `"z"`); result.Num != 4 {
+		t.Errorf("expected 4, got %v", result)
+	}
+	if result := run(client, "JSON.STRAPPEND", key, "z"); result.Str != common.ERR_JSON_INVALID {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_INVALID, result)
+	}
+	if result := run(client, "JSON.GET", key, "$.b.a"); result.Bulk != `["sxyz"]` {
+		t.Errorf("unexpected document %v", result)
+	}
+}
+
+func TestJsonArrays(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestJsonArraysCmd"
+	run(client, "JSON.SET", key, "$", `{"a":[1],"b":{"a":[]},"c":{"a":"x"}}`)
+	if result := run(client, "JSON.ARRAPPEND", key, "$..a", "2", `{"k":null}`); len(result.Array) != 3 ||
+		result.Array[0].Num != 3 || result.Array[1].Num != 2 || result.Array[2].Typ != common.NULL_TYPE {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.ARRINSERT", key, ".a", "0", "0"); result.Num != 4 {
+		t.Errorf("expected 4, got %v", result)
+	}
+	if result := run(client, "JSON.ARRINSERT", key, "$.a", "9", "0"); result.Str != common.ERR_INDEX_OUT_OF_BOUNDS {
+		t.Errorf("expected %s, got %v", common.ERR_INDEX_OUT_OF_BOUNDS, result)
+	}
+	if result := run(client, "JSON.ARRINSERT", key, "$.a", "x", "0"); result.Str != common.ERR_INVALID_INTEGER {
+		t.Errorf("expected %s, got %v", common.ERR_INVALID_INTEGER, result)
+	}
+	if result := run(client, "JSON.ARRLEN", key, "$..a"); len(result.Array) != 3 || result.Array[0].Num != 4 || result.Array[2].Typ != common.NULL_TYPE {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.ARRLEN", key, ".c.a"); result.Str != common.ERR_JSON_WRONG_TYPE {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_WRONG_TYPE, result)
+	}
+	if result := run(client, "JSON.ARRPOP", key, ".a"); result.Bulk != `{"k":null}` {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.ARRPOP", key, "$..a", "0"); len(result.Array) != 3 || result.Array[0].Bulk != "0" || result.Array[1].Bulk != "2" || result.Array[2].Typ != common.NULL_TYPE {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.ARRPOP", key, "$.b.a"); result.Array[0].Bulk != `{"k":null}` {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.ARRPOP", key, "$.b.a"); result.Array[0].Typ != common.NULL_TYPE {
+		t.Errorf("expected null for an empty array, got %v", result)
+	}
+	if result := run(client, "JSON.ARRLEN", "TestJsonArraysCmdMissing", "$"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+	if result := run(client, "JSON.GET", key); result.Bulk != `{"a":[1,2],"b":{"a":[]},"c":{"a":"x"}}` {
+		t.Errorf("unexpected document %v", result)
+	}
+}
+
+func TestJsonObjKeysAndMerge(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestJsonObjKeysCmd"
+	run(client, "JSON.SET", key, "$", `{"z":{"y":1,"x":2},"a":[]}`)
+	if result := run(client, "JSON.OBJKEYS", key); len(result.Array) != 2 || result.Array[0].Bulk != "z" || result.Array[1].Bulk != "a" {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.OBJKEYS", key, "$.*"); len(result.Array) != 2 || result.Array[0].Array[1].Bulk != "x" || result.Array[1].Typ != common.NULL_TYPE {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "JSON.MERGE", key, "$", `{"z":{"y":null,"w":[3]},"a":null,"b":"new"}`); result.Str != "OK" {
+		t.Errorf("expected OK, got %v", result)
+	}
+	if result := run(client, "JSON.GET", key); result.Bulk != `{"z":{"x":2,"w":[3]},"b":"new"}` {
+		t.Errorf("unexpected document %v", result)
+	}
+	if result := run(client, "JSON.MERGE", "TestJsonObjKeysCmdMissing", "$.a", "1"); result.Str != common.ERR_JSON_ROOT {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_ROOT, result)
+	}
+}
+
+func TestJsonKeyspaceNotifications(t *testing.T) {
+	setConfig(t, "notify-keyspace-events", "Kd")
+	defer setConfig(t, "notify-keyspace-events", "")
+
+	subscriberConn, serverConn := net.Pipe()
+	defer subscriberConn.Close()
+	subscriber := NewClient(serverConn)
+	defer subscriber.Close()
+	Subscribe(subscriber, bulkArgs("__keyspace@0__:TestJsonKeyspaceNotifications"))
+	expectReply(t, subscriberConn, subscriptionReply("subscribe", "__keyspace@0__:TestJsonKeyspaceNotifications", 1))
+
+	client := newTestClient(t)
+	run(client, "JSON.SET", "TestJsonKeyspaceNotifications", "$", `[]`)
+	expectReply(t, subscriberConn, bulkArray("message", "__keyspace@0__:TestJsonKeyspaceNotifications", "json.set"))
+	run(client, "JSON.ARRAPPEND", "TestJsonKeyspaceNotifications", "$", "1")
+	expectReply(t, subscriberConn, bulkArray("message", "__keyspace@0__:TestJsonKeyspaceNotifications", "json.arrappend"))
+}
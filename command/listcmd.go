@@ -1,7 +1,9 @@
 package command
 
 import (
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
@@ -56,26 +58,96 @@ func LMove(args []resp.Value) resp.Value {
 	return resp.Value{Typ: common.BULK_TYPE, Bulk: val}
 }
 
-// func LMpop(args []resp.Value) resp.Value {
-// 	if len(args) < 1 || len(args) > 2 {
-// 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
-// 	}
-// 	var countStr string
-// 	if len(args) == 1 {
-// 		countStr = "1"
-// 	} else {
-// 		countStr = args[1].Bulk
-// 	}
-// 	values, err := lists.Lmpop(args[0].Bulk, countStr)
-// 	if err != nil {
-// 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
-// 	}
-// 	respArr := make([]resp.Value, len(values))
-// 	for i, val := range values {
-// 		respArr[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: val}
-// 	}
-// 	return resp.Value{Typ: common.ARRAY_TYPE, Array: respArr}
-// }
+// LMpop implements LMPOP numkeys key [key ...] LEFT|RIGHT [COUNT count].
+// The keys follow numkeys, so they are qualified here rather than through the key specification.
+func LMpop(client *Client, args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	numKeys, err := strconv.Atoi(args[0].Bulk)
+	if err != nil || numKeys <= 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_NUMKEYS_INVALID}
+	}
+	if numKeys > len(args)-2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	direction := strings.ToUpper(args[1+numKeys].Bulk)
+	if direction != "LEFT" && direction != "RIGHT" {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	count := int64(1)
+	if rest := args[2+numKeys:]; len(rest) > 0 {
+		if len(rest) != 2 || strings.ToUpper(rest[0].Bulk) != "COUNT" {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		count, err = strconv.ParseInt(rest[1].Bulk, 10, 64)
+		if err != nil || count <= 0 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_COUNT_NOT_POSITIVE}
+		}
+	}
+	keys := make([]string, numKeys)
+	for i, arg := range args[1 : 1+numKeys] {
+		keys[i] = common.DBKey(client.DB(), arg.Bulk)
+	}
+	key, values, ok := lists.Lmpop(keys, direction, count)
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	_, name := common.SplitDBKey(key)
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: name},
+		bulkArray(values...),
+	}}
+}
+
+// LPos implements LPOS key element [RANK rank] [COUNT count] [MAXLEN len].
+// Without COUNT the index of the match is returned, or null, with COUNT an array of indexes.
+func LPos(args []resp.Value) resp.Value {
+	if len(args) < 2 || len(args)%2 != 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	options := lists.LposOptions{Rank: 1}
+	withCount := false
+	for i := 2; i < len(args); i += 2 {
+		n, err := strconv.ParseInt(args[i+1].Bulk, 10, 64)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+		}
+		switch strings.ToUpper(args[i].Bulk) {
+		case "RANK":
+			if n == 0 || n == math.MinInt64 {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_RANK_ZERO}
+			}
+			options.Rank = n
+		case "COUNT":
+			if n < 0 {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_COUNT_NEGATIVE}
+			}
+			options.Count, withCount = n, true
+		case "MAXLEN":
+			if n < 0 {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_MAXLEN_NEGATIVE}
+			}
+			options.MaxLen = n
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	if !withCount {
+		options.Count = 1
+	}
+	matches, err := lists.Lpos(args[0].Bulk, args[1].Bulk, options)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if withCount {
+		return integerArray(matches)
+	}
+	if len(matches) == 0 {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: matches[0]}
+}
 
 func LRange(args []resp.Value) resp.Value {
 	if len(args) != 3 {
@@ -165,3 +237,85 @@ func RPush(args []resp.Value) resp.Value {
 	lists.RPush(args[0].Bulk, &values)
 	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
 }
+
+// LPushX implements LPUSHX key element [element ...], it only pushes to an existing list.
+func LPushX(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	values := make([]string, len(args)-1)
+	for i, val := range args[1:] {
+		values[i] = val.Bulk
+	}
+	length, err := lists.LPushx(args[0].Bulk, &values)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: length}
+}
+
+// RPushX implements RPUSHX key element [element ...], it only pushes to an existing list.
+func RPushX(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	values := make([]string, len(args)-1)
+	for i, val := range args[1:] {
+		values[i] = val.Bulk
+	}
+	length, err := lists.RPushx(args[0].Bulk, &values)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: length}
+}
+
+// LRem implements LREM key count element.
+func LRem(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	count, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil || count == math.MinInt64 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	removed, err := lists.Lrem(args[0].Bulk, count, args[2].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: removed}
+}
+
+// LSet implements LSET key index element.
+func LSet(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	index, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	if err := lists.Lset(args[0].Bulk, index, args[2].Bulk); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+// LTrim implements LTRIM key start stop.
+func LTrim(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	start, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	stop, err := strconv.ParseInt(args[2].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	if err := lists.Ltrim(args[0].Bulk, start, stop); err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
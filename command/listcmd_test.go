@@ -79,3 +79,83 @@ func TestRPopMultiple(t *testing.T) {
 		t.Errorf("Expected [val3 val2], got %v", result)
 	}
 }
+
+func TestListCommands(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "RPUSH", "TestListCommands", "a", "b", "c", "b")
+
+	if result := run(client, "LPUSHX", "TestListCommands", "x"); result.Num != 5 {
+		t.Errorf("expected length 5, got %v", result)
+	}
+	for _, name := range []string{"LPUSHX", "RPUSHX"} {
+		if result := run(client, name, "TestListCommandsMissing", "x"); result.Typ != common.INTEGER_TYPE || result.Num != 0 {
+			t.Errorf("expected 0 from %s, got %v", name, result)
+		}
+	}
+	if result := run(client, "EXISTS", "TestListCommandsMissing"); result.Num != 0 {
+		t.Errorf("expected the missing list not to be created, got %v", result)
+	}
+	if result := run(client, "LREM", "TestListCommandsMissing", "0", "x"); result.Typ != common.INTEGER_TYPE || result.Num != 0 {
+		t.Errorf("expected 0 removed, got %v", result)
+	}
+	if result := run(client, "LTRIM", "TestListCommandsMissing", "0", "1"); result.Str != "OK" {
+		t.Errorf("expected OK, got %v", result)
+	}
+	if result := run(client, "LPOS", "TestListCommands", "b"); result.Typ != common.INTEGER_TYPE || result.Num != 2 {
+		t.Errorf("expected 2, got %v", result)
+	}
+	if result := run(client, "LPOS", "TestListCommands", "b", "RANK", "-1", "COUNT", "0"); len(result.Array) != 2 || result.Array[0].Num != 4 {
+		t.Errorf("expected [4 2], got %v", result)
+	}
+	if result := run(client, "LPOS", "TestListCommands", "missing"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+	if result := run(client, "LPOS", "TestListCommands", "b", "RANK", "0"); result.Str != common.ERR_RANK_ZERO {
+		t.Errorf("expected %s, got %v", common.ERR_RANK_ZERO, result)
+	}
+	if result := run(client, "LREM", "TestListCommands", "0", "b"); result.Num != 2 {
+		t.Errorf("expected 2 removed, got %v", result)
+	}
+	if result := run(client, "LSET", "TestListCommands", "0", "y"); result.Str != "OK" {
+		t.Errorf("expected OK, got %v", result)
+	}
+	if result := run(client, "LTRIM", "TestListCommands", "0", "1"); result.Str != "OK" {
+		t.Errorf("expected OK, got %v", result)
+	}
+	result := run(client, "LRANGE", "TestListCommands", "0", "-1")
+	if len(result.Array) != 2 || result.Array[0].Bulk != "y" || result.Array[1].Bulk != "a" {
+		t.Errorf("expected [y a], got %v", result)
+	}
+	run(client, "LTRIM", "TestListCommands", "1", "0")
+	if result := run(client, "EXISTS", "TestListCommands"); result.Num != 0 {
+		t.Errorf("expected the empty list to be deleted, got %v", result)
+	}
+}
+
+func TestLMpop(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "2")
+	run(client, "RPUSH", "TestLMpop2", "a", "b", "c")
+
+	result := run(client, "LMPOP", "2", "TestLMpop1", "TestLMpop2", "RIGHT", "COUNT", "2")
+	if len(result.Array) != 2 || result.Array[0].Bulk != "TestLMpop2" || len(result.Array[1].Array) != 2 || result.Array[1].Array[0].Bulk != "c" {
+		t.Errorf("expected [TestLMpop2 [c b]], got %v", result)
+	}
+	if result := run(client, "LMPOP", "1", "TestLMpop1", "LEFT"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"LMPOP", "0", "TestLMpop2", "LEFT"}, common.ERR_NUMKEYS_INVALID},
+		{[]string{"LMPOP", "3", "TestLMpop2", "LEFT"}, common.ERR_SYNTAX},
+		{[]string{"LMPOP", "1", "TestLMpop2", "UP"}, common.ERR_SYNTAX},
+		{[]string{"LMPOP", "1", "TestLMpop2", "LEFT", "COUNT", "0"}, common.ERR_COUNT_NOT_POSITIVE},
+	}
+	for _, test := range tests {
+		if result := run(client, test.args...); result.Str != test.expected {
+			t.Errorf("%v: expected %s, got %v", test.args, test.expected, result)
+		}
+	}
+}
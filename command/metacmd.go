@@ -1,12 +1,20 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
 	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/types/hashes"
+	"github.com/divy-sh/animus/types/lists"
+	"github.com/divy-sh/animus/types/sets"
+	typestrings "github.com/divy-sh/animus/types/strings"
 )
 
 // CommandCmd implements the Redis COMMAND command.
@@ -52,17 +60,127 @@ func CommandCmd(args []resp.Value) resp.Value {
 
 // A simple in-memory config store
 var serverConfig = map[string]string{
-	"maxmemory":  "0",
-	"timeout":    "0",
-	"save":       "", // simulate default Redis RDB save points
-	"appendonly": "no",
+	"maxmemory":                  "0",
+	"timeout":                    "0",
+	"save":                       "", // simulate default Redis RDB save points
+	"appendonly":                 "no",
+	"client-output-buffer-limit": "normal 0 0 0 replica 256mb 64mb 60 pubsub 32mb 8mb 60",
+	"slowlog-log-slower-than":    "10000",
+	"slowlog-max-len":            "128",
+	"maxclients":                 "10000",
+	"port":                       "6379",
+	"metrics-port":               "0",
+	"notify-keyspace-events":     "",
+	"databases":                  "16",
+	"hash-max-listpack-entries":  "128",
+	"hash-max-listpack-value":    "64",
+	"set-max-intset-entries":     "512",
+	"list-max-listpack-size":     "-2",
+	"hll-sparse-max-bytes":       "3000",
+}
+
+var configMutex sync.RWMutex
+
+// configSetters validate and apply parameters that need more than being stored as is.
+var configSetters = map[string]func(string) error{
+	"client-output-buffer-limit": setOutputBufferLimits,
+	"slowlog-log-slower-than":    setSlowLogSlowerThan,
+	"slowlog-max-len":            setSlowLogMaxLen,
+	"maxclients":                 setMaxClients,
+	"port":                       func(string) error { return errors.New("ERR port can only be set at startup") },
+	"metrics-port":               setMetricsPort,
+	"notify-keyspace-events":     pubsub.SetNotifyKeyspaceEvents,
+	"databases":                  setDatabases,
+	"hash-max-listpack-entries":  encodingLimit(hashes.SetMaxListpackEntries, 0),
+	"hash-max-listpack-value":    encodingLimit(hashes.SetMaxListpackValue, 0),
+	"set-max-intset-entries":     encodingLimit(sets.SetMaxIntsetEntries, 0),
+	"list-max-listpack-size":     encodingLimit(lists.SetMaxListpackSize, -5),
+	"hll-sparse-max-bytes":       encodingLimit(typestrings.SetHllSparseMaxBytes, 0),
+}
+
+// configGetters return the value of the parameters that isn't the one last set.
+var configGetters = map[string]func() string{
+	"client-output-buffer-limit": outputBufferLimitsConfig,
+}
+
+// startupSetters validate the parameters CONFIG SET refuses to change once the server runs.
+var startupSetters = map[string]func(string) error{
+	"port":      validatePort,
+	"databases": validateDatabases,
+}
+
+var maxClients atomic.Int64
+
+// GetConfig returns the current value of a configuration parameter.
+func GetConfig(param string) string {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	value, _ := configValue(param)
+	return value
+}
+
+// configValue returns the value of a parameter, false if it doesn't exist.
+// The caller must hold configMutex.
+func configValue(param string) (string, bool) {
+	value, ok := serverConfig[param]
+	if getter, found := configGetters[param]; ok && found {
+		value = getter()
+	}
+	return value, ok
+}
+
+// SetStartupConfig sets a configuration parameter before the server starts listening,
+// including the ones that can only be set at startup.
+func SetStartupConfig(param, value string) error {
+	param = strings.ToLower(param)
+	setters := configSetters
+	if _, ok := startupSetters[param]; ok {
+		setters = startupSetters
+	}
+	return applyConfig(param, value, setters)
+}
+
+// applyConfig validates and applies a configuration parameter with the setter for it in setters.
+func applyConfig(param, value string, setters map[string]func(string) error) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	if _, ok := serverConfig[param]; !ok {
+		return errors.New("ERR unknown configuration parameter")
+	}
+	if setter, ok := setters[param]; ok {
+		if err := setter(value); err != nil {
+			return err
+		}
+	}
+	serverConfig[param] = value
+	return nil
+}
+
+func init() {
+	setMaxClients(serverConfig["maxclients"])
+}
+
+func validatePort(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+		return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	return nil
+}
+
+func setMaxClients(value string) error {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 1 {
+		return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	maxClients.Store(n)
+	return nil
 }
 
 // This is here just so that redis-benchmark doesn't complain.
 // ConfigCmd implements the Redis CONFIG command.
-// It supports CONFIG GET <parameter> and CONFIG SET <parameter> <value>
+// It supports CONFIG GET <parameter>, CONFIG SET <parameter> <value> and CONFIG RESETSTAT
 func ConfigCmd(args []resp.Value) resp.Value {
-	if len(args) < 2 {
+	if len(args) < 1 {
 		return resp.Value{
 			Typ: common.ERROR_TYPE,
 			Str: common.ERR_WRONG_ARGUMENT_COUNT,
@@ -80,10 +198,13 @@ func ConfigCmd(args []resp.Value) resp.Value {
 		}
 
 		param := strings.ToLower(args[1].Bulk)
+		configMutex.RLock()
+		defer configMutex.RUnlock()
 		// support wildcard "*"
 		if param == "*" {
 			array := make([]resp.Value, 0, len(serverConfig)*2)
-			for k, v := range serverConfig {
+			for k := range serverConfig {
+				v, _ := configValue(k)
 				array = append(array, resp.Value{Typ: common.BULK_TYPE, Bulk: k})
 				array = append(array, resp.Value{Typ: common.BULK_TYPE, Bulk: v})
 			}
@@ -93,7 +214,7 @@ func ConfigCmd(args []resp.Value) resp.Value {
 			}
 		}
 
-		value, ok := serverConfig[param]
+		value, ok := configValue(param)
 		if !ok {
 			return resp.Value{
 				Typ: common.ERROR_TYPE,
@@ -120,15 +241,23 @@ func ConfigCmd(args []resp.Value) resp.Value {
 		param := strings.ToLower(args[1].Bulk)
 		value := args[2].Bulk // fix: value is at index 2
 
-		if _, ok := serverConfig[param]; !ok {
+		if err := applyConfig(param, value, configSetters); err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+
+		return resp.Value{
+			Typ: common.STRING_TYPE,
+			Str: "OK",
+		}
+
+	case "RESETSTAT":
+		if len(args) != 1 {
 			return resp.Value{
 				Typ: common.ERROR_TYPE,
-				Str: "ERR unknown configuration parameter",
+				Str: common.ERR_WRONG_ARGUMENT_COUNT,
 			}
 		}
-
-		serverConfig[param] = value
-
+		resetStats()
 		return resp.Value{
 			Typ: common.STRING_TYPE,
 			Str: "OK",
@@ -137,7 +266,7 @@ func ConfigCmd(args []resp.Value) resp.Value {
 	default:
 		return resp.Value{
 			Typ: common.ERROR_TYPE,
-			Str: "ERR unknown subcommand, must be GET or SET",
+			Str: "ERR unknown subcommand, must be GET, SET or RESETSTAT",
 		}
 	}
 }
@@ -158,15 +287,31 @@ func Help(args []resp.Value) resp.Value {
 	return resp.Value{Typ: common.ERROR_TYPE, Str: "Unknown command: " + cmd}
 }
 
-func Info(args []resp.Value) resp.Value {
-	info := `# Server\n
-		redis_version:0.0.1-animus\n
-		redis_mode:standalone\n
-		os:darwin-arm64\n`
-
-	return resp.Value{
-		Typ:  common.BULK_TYPE,
-		Bulk: info,
+// ClientCmd implements the CLIENT command for the calling connection.
+func ClientCmd(client *Client, args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	switch strings.ToUpper(args[0].Bulk) {
+	case "SETNAME":
+		if len(args) != 2 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		if strings.ContainsAny(args[1].Bulk, " \n") {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR Client names cannot contain spaces, newlines or special characters."}
+		}
+		client.SetName(args[1].Bulk)
+		return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+	case "GETNAME":
+		name := client.Name()
+		if name == "" {
+			return resp.Value{Typ: common.NULL_TYPE}
+		}
+		return resp.Value{Typ: common.BULK_TYPE, Bulk: name}
+	case "ID":
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: client.ID}
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR unknown subcommand, must be SETNAME, GETNAME or ID"}
 	}
 }
 
@@ -0,0 +1,167 @@
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/generics"
+)
+
+var (
+	metricsServer      *http.Server
+	metricsServerMutex sync.Mutex
+)
+
+// setMetricsPort starts the Prometheus metrics endpoint on the given port,
+// replacing the one already running. Port 0 disables it.
+func setMetricsPort(value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 0 || port > 65535 {
+		return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	metricsServerMutex.Lock()
+	defer metricsServerMutex.Unlock()
+	if metricsServer != nil {
+		metricsServer.Close()
+		metricsServer = nil
+	}
+	if port == 0 {
+		return nil
+	}
+	listener, err := net.Listen("tcp", ":"+value)
+	if err != nil {
+		return fmt.Errorf("ERR could not listen on metrics port: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteMetrics(w)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	metricsServer = server
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Print("Metrics endpoint stopped: ", err)
+		}
+	}()
+	return nil
+}
+
+type metricSample struct {
+	labels string
+	value  float64
+}
+
+// metricsWriter writes metrics in the Prometheus text exposition format.
+type metricsWriter struct {
+	w *bufio.Writer
+}
+
+func (m *metricsWriter) write(name, typ, help string, samples ...metricSample) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	for _, sample := range samples {
+		m.w.WriteString(name + sample.labels + " " + strconv.FormatFloat(sample.value, 'g', -1, 64) + "\n")
+	}
+}
+
+func (m *metricsWriter) single(name, typ, help string, value float64) {
+	m.write(name, typ, help, metricSample{value: value})
+}
+
+// labels formats label pairs such as labels("cmd", "get") as {cmd="get"}.
+func labels(pairs ...string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+escaper.Replace(pairs[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// WriteMetrics writes the server metrics in the Prometheus text format.
+// The values come from the same counters that are reported by INFO.
+func WriteMetrics(out io.Writer) error {
+	m := &metricsWriter{w: bufio.NewWriter(out)}
+	stats := store.GetStats()
+
+	m.single("animus_uptime_seconds", "gauge", "Seconds since the server started.", time.Since(startTime).Seconds())
+	m.single("animus_connected_clients", "gauge", "Number of connected clients.", float64(connectedClients.Load()))
+	m.single("animus_connections_received_total", "counter", "Connections accepted by the server.", float64(totalConnectionsReceived.Load()))
+	m.single("animus_rejected_connections_total", "counter", "Connections rejected because of maxclients.", float64(rejectedConnections.Load()))
+	m.single("animus_client_output_buffer_limit_disconnections_total", "counter", "Clients disconnected for exceeding their output buffer limits.", float64(outputBufferLimitDisconnections.Load()))
+	m.single("animus_commands_processed_total", "counter", "Commands processed by the server.", float64(totalCommandsProcessed.Load()))
+
+	names := sortedCommandStats()
+	calls := make([]metricSample, 0, len(names))
+	failed := make([]metricSample, 0, len(names))
+	for _, name := range names {
+		stat, _ := commandStats.Load(name)
+		cmdStat := stat.(*commandStat)
+		cmd := labels("cmd", strings.ToLower(name))
+		calls = append(calls, metricSample{cmd, float64(cmdStat.calls.Load())})
+		failed = append(failed, metricSample{cmd, float64(cmdStat.failedCalls.Load())})
+	}
+	m.write("animus_command_calls_total", "counter", "Calls per command.", calls...)
+	m.write("animus_command_failed_calls_total", "counter", "Calls per command that returned an error.", failed...)
+
+	fmt.Fprintf(m.w, "# HELP animus_command_duration_seconds Command execution latency.\n# TYPE animus_command_duration_seconds histogram\n")
+	for _, name := range names {
+		stat, _ := commandStats.Load(name)
+		cmdStat := stat.(*commandStat)
+		cmd := strings.ToLower(name)
+		var cumulative int64
+		for i, bound := range latencyBuckets {
+			cumulative += cmdStat.latency[i].Load()
+			le := strconv.FormatFloat(float64(bound)/1e6, 'g', -1, 64)
+			fmt.Fprintf(m.w, "animus_command_duration_seconds_bucket%s %d\n", labels("cmd", cmd, "le", le), cumulative)
+		}
+		cumulative += cmdStat.latency[len(latencyBuckets)].Load()
+		fmt.Fprintf(m.w, "animus_command_duration_seconds_bucket%s %d\n", labels("cmd", cmd, "le", "+Inf"), cumulative)
+		fmt.Fprintf(m.w, "animus_command_duration_seconds_sum%s %s\n", labels("cmd", cmd),
+			strconv.FormatFloat(float64(cmdStat.usec.Load())/1e6, 'g', -1, 64))
+		fmt.Fprintf(m.w, "animus_command_duration_seconds_count%s %d\n", labels("cmd", cmd), cumulative)
+	}
+
+	keysPerType := generics.KeysPerType()
+	types := make([]string, 0, len(keysPerType))
+	for typ := range keysPerType {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	keys := make([]metricSample, 0, len(types))
+	for _, typ := range types {
+		keys = append(keys, metricSample{labels("type", typ), float64(keysPerType[typ])})
+	}
+	m.write("animus_keys", "gauge", "Number of keys per data type.", keys...)
+	m.single("animus_expired_keys_total", "counter", "Keys removed because their time to live expired.", float64(stats.ExpiredKeys))
+	m.single("animus_evicted_keys_total", "counter", "Keys evicted from the LRU cache.", float64(stats.EvictedKeys))
+	m.single("animus_keyspace_hits_total", "counter", "Successful key lookups.", float64(stats.KeyspaceHits))
+	m.single("animus_keyspace_misses_total", "counter", "Failed key lookups.", float64(stats.KeyspaceMisses))
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	m.single("animus_memory_used_bytes", "gauge", "Bytes of allocated heap objects.", float64(mem.HeapAlloc))
+	m.single("animus_memory_rss_bytes", "gauge", "Bytes of memory obtained from the OS.", float64(mem.Sys))
+
+	aofEnabled := 0.0
+	if GetConfig("appendonly") == "yes" {
+		aofEnabled = 1
+	}
+	m.single("animus_persistence_loading", "gauge", "Whether the server is loading a dump.", 0)
+	m.single("animus_persistence_aof_enabled", "gauge", "Whether the append only file is enabled.", aofEnabled)
+
+	return m.w.Flush()
+}
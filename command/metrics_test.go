@@ -0,0 +1,79 @@
+package command
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	Set([]resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "TestWriteMetrics"},
+		{Typ: common.BULK_TYPE, Bulk: "value"}})
+	Execute(nil, "PING", Handlers["PING"], []resp.Value{})
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	metrics := buf.String()
+	for _, expected := range []string{
+		"# TYPE animus_command_calls_total counter\n",
+		`animus_command_calls_total{cmd="ping"} `,
+		`animus_command_duration_seconds_bucket{cmd="ping",le="+Inf"} `,
+		`animus_command_duration_seconds_count{cmd="ping"} `,
+		`animus_keys{type="string"} `,
+		"animus_connected_clients ",
+		"animus_memory_used_bytes ",
+		"animus_persistence_aof_enabled 0\n",
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("expected metrics to contain %q", expected)
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if got := labels("cmd", `a"b\c`, "le", "0.5"); got != `{cmd="a\"b\\c",le="0.5"}` {
+		t.Errorf("unexpected labels %s", got)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not find a free port: %v", err)
+	}
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	setConfig(t, "metrics-port", port)
+	defer setConfig(t, "metrics-port", "0")
+
+	res, err := http.Get("http://127.0.0.1:" + port + "/metrics")
+	if err != nil {
+		t.Fatalf("could not scrape metrics: %v", err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "animus_uptime_seconds") {
+		t.Errorf("unexpected response %d: %s", res.StatusCode, body)
+	}
+}
+
+func TestMetricsPort_Invalid(t *testing.T) {
+	result := ConfigCmd([]resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "SET"},
+		{Typ: common.BULK_TYPE, Bulk: "metrics-port"},
+		{Typ: common.BULK_TYPE, Bulk: "70000"}})
+	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_INVALID_CONFIG_VALUE {
+		t.Errorf("expected %s, got %v", common.ERR_INVALID_CONFIG_VALUE, result)
+	}
+}
@@ -0,0 +1,261 @@
+package command
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/types/generics"
+)
+
+// Limits of the connections to MIGRATE targets kept for reuse: how long one is kept and how
+// many are kept per target.
+const (
+	migrateConnIdleTime = 10 * time.Second
+	migrateMaxIdleConns = 4
+)
+
+var migrateConns = struct {
+	sync.Mutex
+	idle map[string][]*migrateConn
+}{idle: map[string][]*migrateConn{}}
+
+// migrateConn is a connection to another instance used by MIGRATE.
+type migrateConn struct {
+	conn    net.Conn
+	reader  *resp.Reader
+	timeout time.Duration
+	// db is the database selected on the target, -1 until one is selected.
+	db      int
+	reused  bool
+	lastUse time.Time
+}
+
+// targetError is an error reply sent by the target instance.
+type targetError struct {
+	reply string
+}
+
+func (e targetError) Error() string {
+	return "ERR Target instance replied with error: " + e.reply
+}
+
+// takeMigrateConn returns an idle connection to addr, or opens a new one.
+func takeMigrateConn(addr string, timeout time.Duration) (*migrateConn, error) {
+	migrateConns.Lock()
+	pruneMigrateConns()
+	conns := migrateConns.idle[addr]
+	if len(conns) > 0 {
+		mc := conns[len(conns)-1]
+		migrateConns.idle[addr] = conns[:len(conns)-1]
+		migrateConns.Unlock()
+		mc.timeout, mc.reused = timeout, true
+		return mc, nil
+	}
+	migrateConns.Unlock()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, errors.New(common.ERR_MIGRATE_IO)
+	}
+	return &migrateConn{conn: conn, reader: resp.NewReader(conn), timeout: timeout, db: -1}, nil
+}
+
+// release puts the connection back in the pool, closing the oldest idle connection to addr
+// if it already holds migrateMaxIdleConns of them.
+func (mc *migrateConn) release(addr string) {
+	mc.lastUse = time.Now()
+	migrateConns.Lock()
+	defer migrateConns.Unlock()
+	pruneMigrateConns()
+	conns := migrateConns.idle[addr]
+	if len(conns) >= migrateMaxIdleConns {
+		conns[0].conn.Close()
+		conns = conns[1:]
+	}
+	migrateConns.idle[addr] = append(conns, mc)
+}
+
+// pruneMigrateConns closes the connections of every target idle for too long.
+// The caller must hold migrateConns.
+func pruneMigrateConns() {
+	for addr, conns := range migrateConns.idle {
+		// Connections are released in order, the ones idle the longest come first.
+		fresh := 0
+		for fresh < len(conns) && time.Since(conns[fresh].lastUse) >= migrateConnIdleTime {
+			conns[fresh].conn.Close()
+			fresh++
+		}
+		if fresh == len(conns) {
+			delete(migrateConns.idle, addr)
+		} else {
+			migrateConns.idle[addr] = conns[fresh:]
+		}
+	}
+}
+
+// call sends a command to the target and returns its reply. Error replies are returned as a targetError.
+func (mc *migrateConn) call(args ...string) (resp.Value, error) {
+	mc.conn.SetDeadline(time.Now().Add(mc.timeout))
+	if err := resp.NewWriter(mc.conn).Write(resp.Value{Typ: common.ARRAY_TYPE, Array: bulkValues(args)}); err != nil {
+		return resp.Value{}, errors.New(common.ERR_MIGRATE_IO)
+	}
+	reply, err := mc.reader.ReadReply()
+	if err != nil {
+		return resp.Value{}, errors.New(common.ERR_MIGRATE_IO)
+	}
+	if reply.Typ == common.ERROR_TYPE {
+		return reply, targetError{reply.Str}
+	}
+	return reply, nil
+}
+
+// callOK sends a command that must reply OK.
+func (mc *migrateConn) callOK(args ...string) error {
+	reply, err := mc.call(args...)
+	if err != nil {
+		return err
+	}
+	if reply.Typ != common.STRING_TYPE || reply.Str != "OK" {
+		return targetError{reply.Str}
+	}
+	return nil
+}
+
+func bulkValues(args []string) []resp.Value {
+	values := make([]resp.Value, len(args))
+	for i, arg := range args {
+		values[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: arg}
+	}
+	return values
+}
+
+type migrateOptions struct {
+	db      int
+	copy    bool
+	replace bool
+	auth    []string
+}
+
+// Migrate implements MIGRATE host port key|"" destination-db timeout [COPY] [REPLACE] [AUTH password]
+// [AUTH2 username password] [KEYS key [key ...]].
+// Every key is restored on the target while it is locked here, then deleted unless COPY is given.
+// AUTH and AUTH2 only work against Redis targets, animus has no AUTH command and replies with an error.
+// MIGRATE finds its keys itself rather than through its key specification, since they can follow KEYS.
+func Migrate(client *Client, args []resp.Value) resp.Value {
+	if len(args) < 5 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	addr := net.JoinHostPort(args[0].Bulk, args[1].Bulk)
+	db, err := strconv.Atoi(args[3].Bulk)
+	if err != nil || db < 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	timeout, err := strconv.ParseInt(args[4].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	if timeout <= 0 {
+		timeout = 1000
+	}
+	options := migrateOptions{db: db}
+	keys := []string{args[2].Bulk}
+	for i := 5; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "COPY":
+			options.copy = true
+		case "REPLACE":
+			options.replace = true
+		case "AUTH":
+			if i+1 >= len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			options.auth = []string{args[i+1].Bulk}
+			i++
+		case "AUTH2":
+			if i+2 >= len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			options.auth = []string{args[i+1].Bulk, args[i+2].Bulk}
+			i += 2
+		case "KEYS":
+			if args[2].Bulk != "" {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_MIGRATE_KEYS}
+			}
+			keys = keys[:0]
+			for _, key := range args[i+1:] {
+				keys = append(keys, key.Bulk)
+			}
+			i = len(args)
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	localKeys := make([]string, len(keys))
+	for i, key := range keys {
+		localKeys[i] = common.DBKey(client.DB(), key)
+	}
+	if generics.Exists(&localKeys) == 0 {
+		return resp.Value{Typ: common.STRING_TYPE, Str: "NOKEY"}
+	}
+
+	timeoutDuration := time.Duration(timeout) * time.Millisecond
+	for {
+		mc, err := takeMigrateConn(addr, timeoutDuration)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		moved, err := migrateKeys(mc, keys, localKeys, options)
+		if err == nil {
+			mc.release(addr)
+			return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+		}
+		var replyErr targetError
+		if errors.As(err, &replyErr) {
+			mc.release(addr)
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		mc.conn.Close()
+		// A pooled connection may have been closed by the target while idle, try again
+		// once with a new one if nothing was sent over it successfully.
+		if !mc.reused || moved > 0 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+	}
+}
+
+// migrateKeys restores the keys on the target and returns the number of keys that were moved.
+func migrateKeys(mc *migrateConn, keys, localKeys []string, options migrateOptions) (int, error) {
+	if len(options.auth) > 0 {
+		if err := mc.callOK(append([]string{"AUTH"}, options.auth...)...); err != nil {
+			return 0, err
+		}
+	}
+	if mc.db != options.db {
+		if err := mc.callOK("SELECT", strconv.Itoa(options.db)); err != nil {
+			return 0, err
+		}
+		mc.db = options.db
+	}
+	moved := 0
+	for i, key := range keys {
+		found, err := generics.Transfer(localKeys[i], options.copy, func(payload []byte, ttl int64) error {
+			restore := []string{"RESTORE", key, strconv.FormatInt(ttl, 10), string(payload)}
+			if options.replace {
+				restore = append(restore, "REPLACE")
+			}
+			return mc.callOK(restore...)
+		})
+		if err != nil {
+			return moved, err
+		}
+		if found {
+			moved++
+		}
+	}
+	return moved, nil
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestMigrateConns_CapAndPrune(t *testing.T) {
+	migrateConns.Lock()
+	clear(migrateConns.idle)
+	migrateConns.Unlock()
+
+	conns := []*migrateConn{}
+	for range migrateMaxIdleConns + 1 {
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		mc := &migrateConn{conn: conn, db: -1}
+		mc.release("target")
+		conns = append(conns, mc)
+	}
+	if n := len(migrateConns.idle["target"]); n != migrateMaxIdleConns {
+		t.Fatalf("expected %d idle connections, got %d", migrateMaxIdleConns, n)
+	}
+	if _, err := conns[0].conn.Write([]byte("x")); err == nil {
+		t.Errorf("expected the oldest connection to be closed")
+	}
+
+	stale := time.Now().Add(-migrateConnIdleTime)
+	for _, mc := range migrateConns.idle["target"] {
+		mc.lastUse = stale
+	}
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	(&migrateConn{conn: conn, db: -1}).release("other")
+	if _, ok := migrateConns.idle["target"]; ok {
+		t.Errorf("expected the stale connections to be pruned")
+	}
+	mc, err := takeMigrateConn("other", time.Second)
+	if err != nil || mc.conn != conn || !mc.reused {
+		t.Errorf("expected the idle connection to be reused, got %v", err)
+	}
+	mc.conn.Close()
+}
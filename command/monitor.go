@@ -0,0 +1,142 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+var (
+	monitors      = map[*Client]struct{}{}
+	monitorsMutex sync.RWMutex
+	// monitorCount lets Execute skip formatting the feed when nobody is listening.
+	monitorCount atomic.Int64
+)
+
+// Monitor implements the MONITOR command.
+// It turns the calling connection into a feed of every command processed by the server.
+// Monitors use the replica output buffer limits, so a monitor that can't keep up
+// is disconnected instead of slowing down the clients it is watching.
+func Monitor(client *Client, args []resp.Value) resp.Value {
+	if len(args) != 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	client.SetClass(CLIENT_REPLICA)
+	monitorsMutex.Lock()
+	defer monitorsMutex.Unlock()
+	if _, ok := monitors[client]; !ok {
+		monitors[client] = struct{}{}
+		monitorCount.Add(1)
+	}
+	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+}
+
+func removeMonitor(client *Client) {
+	monitorsMutex.Lock()
+	defer monitorsMutex.Unlock()
+	if _, ok := monitors[client]; ok {
+		delete(monitors, client)
+		monitorCount.Add(-1)
+	}
+}
+
+// feedMonitors sends a command to every monitor, formatted like
+// +1339518083.107412 [0 127.0.0.1:60866] "set" "key" "value"
+func feedMonitors(client *Client, name string, args []resp.Value) {
+	if monitorCount.Load() == 0 {
+		return
+	}
+	monitorsMutex.RLock()
+	targets := make([]*Client, 0, len(monitors))
+	for monitor := range monitors {
+		targets = append(targets, monitor)
+	}
+	monitorsMutex.RUnlock()
+
+	now := time.Now()
+	var line strings.Builder
+	line.WriteString(fmt.Sprintf("%d.%06d [%d", now.Unix(), now.Nanosecond()/1000, client.DB()))
+	if client != nil {
+		line.WriteString(" " + client.Addr)
+	}
+	line.WriteString("] " + quoteArg(strings.ToLower(name)))
+	for _, arg := range redactArgs(name, args) {
+		line.WriteString(" " + quoteArg(arg))
+	}
+
+	feed := resp.Value{Typ: common.STRING_TYPE, Str: line.String()}
+	for _, monitor := range targets {
+		// A failed write means the monitor was disconnected, it is removed when its connection closes.
+		monitor.Write(feed)
+	}
+}
+
+// redactArgs returns the arguments of a command with its passwords replaced by (redacted).
+// MIGRATE is the only registered command taking a password, in its AUTH and AUTH2 options.
+// They are looked for past its positional arguments, so keys that happen to read AUTH are left alone.
+func redactArgs(name string, args []resp.Value) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = arg.Bulk
+	}
+	if name != "MIGRATE" {
+		return redacted
+	}
+	hide := func(from, n int) {
+		for i := from; i < min(from+n, len(redacted)); i++ {
+			redacted[i] = "(redacted)"
+		}
+	}
+	// The options follow host port key db timeout.
+	for i := 5; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "AUTH":
+			hide(i+1, 1)
+			i++
+		case "AUTH2":
+			hide(i+1, 2)
+			i += 2
+		case "KEYS":
+			// Every argument left is a key.
+			return redacted
+		}
+	}
+	return redacted
+}
+
+// quoteArg returns arg as a double quoted string with non printable characters escaped.
+func quoteArg(arg string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+		switch c {
+		case '\\', '"':
+			quoted.WriteByte('\\')
+			quoted.WriteByte(c)
+		case '\n':
+			quoted.WriteString(`\n`)
+		case '\r':
+			quoted.WriteString(`\r`)
+		case '\t':
+			quoted.WriteString(`\t`)
+		case '\a':
+			quoted.WriteString(`\a`)
+		case '\b':
+			quoted.WriteString(`\b`)
+		default:
+			if c < 0x20 || c > 0x7e {
+				quoted.WriteString(fmt.Sprintf(`\x%02x`, c))
+			} else {
+				quoted.WriteByte(c)
+			}
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
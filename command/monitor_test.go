@@ -0,0 +1,94 @@
+package command
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func TestMonitor_FeedsCommands(t *testing.T) {
+	monitorConn, serverConn := net.Pipe()
+	defer monitorConn.Close()
+	monitor := NewClient(serverConn)
+	reader := bufio.NewReader(monitorConn)
+
+	monitor.Write(Monitor(monitor, []resp.Value{}))
+	if line, err := reader.ReadString('\n'); err != nil || line != "+OK\r\n" {
+		t.Fatalf("expected +OK, got %q (%v)", line, err)
+	}
+
+	Execute(nil, "SET", Handlers["SET"], []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "TestMonitor_FeedsCommands"},
+		{Typ: common.BULK_TYPE, Bulk: "a \"quoted\"\nvalue"}})
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := regexp.MustCompile(`^\+\d+\.\d{6} \[0\] "set" "TestMonitor_FeedsCommands" "a \\"quoted\\"\\nvalue"\r\n$`)
+	if !expected.MatchString(line) {
+		t.Errorf("unexpected monitor line %q", line)
+	}
+
+	monitorConn.Close()
+	monitor.Close()
+	if monitorCount.Load() != 0 {
+		t.Errorf("expected monitor to be removed on close")
+	}
+}
+
+func TestMonitor_InvalidArgs(t *testing.T) {
+	result := Monitor(nil, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "extra"}})
+	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
+		t.Errorf("expected %s, got %v", common.ERR_WRONG_ARGUMENT_COUNT, result)
+	}
+}
+
+func TestMonitor_RedactsPasswords(t *testing.T) {
+	toValues := func(args ...string) []resp.Value {
+		values := make([]resp.Value, len(args))
+		for i, arg := range args {
+			values[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: arg}
+		}
+		return values
+	}
+	cases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"MIGRATE", []string{"host", "6379", "key", "0", "1000", "AUTH", "secret"},
+			[]string{"host", "6379", "key", "0", "1000", "AUTH", "(redacted)"}},
+		{"MIGRATE", []string{"host", "6379", "AUTH", "0", "1000", "AUTH2", "user", "secret"},
+			[]string{"host", "6379", "AUTH", "0", "1000", "AUTH2", "(redacted)", "(redacted)"}},
+		{"MIGRATE", []string{"AUTH", "AUTH", "", "0", "1000", "COPY", "KEYS", "AUTH", "AUTH2"},
+			[]string{"AUTH", "AUTH", "", "0", "1000", "COPY", "KEYS", "AUTH", "AUTH2"}},
+		{"SET", []string{"AUTH", "value"}, []string{"AUTH", "value"}},
+	}
+	for _, c := range cases {
+		got := redactArgs(c.name, toValues(c.args...))
+		for i := range c.expected {
+			if got[i] != c.expected[i] {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	cases := map[string]string{
+		"plain":     `"plain"`,
+		"tab\there": `"tab\there"`,
+		"\x00\xff":  `"\x00\xff"`,
+		`back\`:     `"back\\"`,
+	}
+	for arg, expected := range cases {
+		if got := quoteArg(arg); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/generics"
+)
+
+// Object implements OBJECT ENCODING|IDLETIME|FREQ|REFCOUNT key.
+// Inspecting a key with OBJECT doesn't change its idle time or access frequency.
+func Object(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	key := args[1].Bulk
+	switch strings.ToUpper(args[0].Bulk) {
+	case "ENCODING":
+		encoding, ok := generics.ObjectEncoding(key)
+		if !ok {
+			return resp.Value{Typ: common.NULL_TYPE}
+		}
+		return resp.Value{Typ: common.BULK_TYPE, Bulk: encoding}
+	case "IDLETIME":
+		return objectInteger(generics.ObjectIdleTime(key))
+	case "FREQ":
+		return objectInteger(generics.ObjectFreq(key))
+	case "REFCOUNT":
+		return objectInteger(generics.ObjectRefCount(key))
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR unknown subcommand, must be ENCODING, IDLETIME, FREQ or REFCOUNT"}
+	}
+}
+
+// encodingLimit returns the setter of a parameter limiting the size of a compact encoding,
+// which takes an integer no lower than low.
+func encodingLimit(apply func(int64), low int64) func(string) error {
+	return func(value string) error {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < low {
+			return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+		}
+		apply(n)
+		return nil
+	}
+}
+
+func objectInteger(n int64, ok bool) resp.Value {
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: n}
+}
+
+// Memory implements MEMORY USAGE key [SAMPLES count] | STATS | DOCTOR.
+func Memory(args []resp.Value) resp.Value {
+	if len(args) == 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	switch strings.ToUpper(args[0].Bulk) {
+	case "USAGE":
+		return memoryUsage(args[1:])
+	case "STATS":
+		if len(args) != 1 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		return memoryStatsReply(getMemoryStats())
+	case "DOCTOR":
+		if len(args) != 1 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		return resp.Value{Typ: common.BULK_TYPE, Bulk: memoryDoctor(getMemoryStats())}
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR unknown subcommand, must be USAGE, STATS or DOCTOR"}
+	}
+}
+
+func memoryUsage(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	samples := generics.DefaultMemorySamples
+	if len(args) == 3 {
+		if strings.ToUpper(args[1].Bulk) != "SAMPLES" {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		n, err := strconv.Atoi(args[2].Bulk)
+		if err != nil || n < 0 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+		}
+		samples = n
+	}
+	usage, ok := generics.MemoryUsage(args[0].Bulk, samples)
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: usage}
+}
+
+type memoryStats struct {
+	total         int64
+	startup       int64
+	rss           int64
+	keys          int64
+	dataset       int64
+	lazyfree      int64
+	fragmentation float64
+}
+
+func getMemoryStats() memoryStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	stats := memoryStats{
+		total:    int64(mem.HeapAlloc),
+		startup:  int64(usedMemoryStartup),
+		rss:      int64(mem.Sys),
+		dataset:  generics.DatasetBytes(),
+		lazyfree: generics.LazyfreePendingObjects(),
+	}
+	for _, info := range store.GetKeyspaceInfo() {
+		stats.keys += info.Keys
+	}
+	if mem.HeapAlloc > 0 {
+		stats.fragmentation = float64(mem.Sys) / float64(mem.HeapAlloc)
+	}
+	return stats
+}
+
+// overhead is the memory used by the server itself rather than by the keys.
+func (s memoryStats) overhead() int64 {
+	return max(s.total-s.dataset, 0)
+}
+
+func memoryStatsReply(s memoryStats) resp.Value {
+	bytesPerKey, datasetPercentage := int64(0), 0.0
+	if s.keys > 0 {
+		bytesPerKey = max(s.total-s.startup, 0) / s.keys
+	}
+	if s.total > s.startup {
+		datasetPercentage = float64(s.dataset) * 100 / float64(s.total-s.startup)
+	}
+	fields := []resp.Value{}
+	add := func(name string, value resp.Value) {
+		fields = append(fields, resp.Value{Typ: common.BULK_TYPE, Bulk: name}, value)
+	}
+	integer := func(n int64) resp.Value { return resp.Value{Typ: common.INTEGER_TYPE, Num: n} }
+	float := func(f float64) resp.Value {
+		return resp.Value{Typ: common.BULK_TYPE, Bulk: strconv.FormatFloat(f, 'f', 2, 64)}
+	}
+	add("total.allocated", integer(s.total))
+	add("startup.allocated", integer(s.startup))
+	add("rss.allocated", integer(s.rss))
+	add("overhead.total", integer(s.overhead()))
+	add("keys.count", integer(s.keys))
+	add("keys.bytes-per-key", integer(bytesPerKey))
+	add("dataset.bytes", integer(s.dataset))
+	add("dataset.percentage", float(datasetPercentage))
+	add("lazyfree.pending_objects", integer(s.lazyfree))
+	add("fragmentation", float(s.fragmentation))
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: fields}
+}
+
+// memoryDoctor reports the memory issues it can spot in the stats, in plain words.
+func memoryDoctor(s memoryStats) string {
+	if s.total < 5<<20 && s.keys == 0 {
+		return "This instance is empty or uses very little memory, there is nothing to diagnose yet."
+	}
+	issues := []string{}
+	if s.fragmentation > 1.4 {
+		issues = append(issues, fmt.Sprintf(
+			"High fragmentation: the process holds %.2f times the memory used by live objects (%s for %s). "+
+				"The Go runtime returns freed memory to the OS over time, it should go down once the heap shrinks.",
+			s.fragmentation, bytesToHuman(uint64(s.rss)), bytesToHuman(uint64(s.total))))
+	}
+	if s.dataset > 0 && s.overhead() > s.dataset {
+		issues = append(issues, fmt.Sprintf(
+			"High overhead: the server uses %s on top of the %s estimated for the dataset. "+
+				"Many small keys cost more in per-key overhead than in data, consider grouping them in hashes.",
+			bytesToHuman(uint64(s.overhead())), bytesToHuman(uint64(s.dataset))))
+	}
+	if s.lazyfree > 0 {
+		issues = append(issues, fmt.Sprintf(
+			"%d unlinked values are still being freed in the background.", s.lazyfree))
+	}
+	if len(issues) == 0 {
+		return "No memory issues found. Dataset " + bytesToHuman(uint64(s.dataset)) +
+			", overhead " + bytesToHuman(uint64(s.overhead())) + "."
+	}
+	return "Memory issues found:\n\n * " + strings.Join(issues, "\n\n * ")
+}
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func TestObject(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "11")
+	run(client, "SET", "TestObject", "42")
+	if result := run(client, "OBJECT", "ENCODING", "TestObject"); result.Bulk != "int" {
+		t.Errorf("expected int, got %v", result)
+	}
+	if result := run(client, "OBJECT", "IDLETIME", "TestObject"); result.Typ != common.INTEGER_TYPE || result.Num != 0 {
+		t.Errorf("expected 0 seconds, got %v", result)
+	}
+	if result := run(client, "OBJECT", "FREQ", "TestObject"); result.Num < 1 {
+		t.Errorf("expected a frequency counter, got %v", result)
+	}
+	if result := run(client, "OBJECT", "REFCOUNT", "TestObject"); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	if result := run(client, "OBJECT", "ENCODING", "TestObjectMissing"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+	if result := run(client, "OBJECT", "SIZE", "TestObject"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error, got %v", result)
+	}
+}
+
+func TestObject_RefCountKeepsIdleTime(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestObject_RefCountKeepsIdleTime", "value")
+	payload := run(client, "DUMP", "TestObject_RefCountKeepsIdleTime").Bulk
+	run(client, "RESTORE", "TestObject_RefCountKeepsIdleTime", "0", payload, "REPLACE", "IDLETIME", "100")
+	freq := run(client, "OBJECT", "FREQ", "TestObject_RefCountKeepsIdleTime").Num
+	if result := run(client, "OBJECT", "REFCOUNT", "TestObject_RefCountKeepsIdleTime"); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	if result := run(client, "OBJECT", "IDLETIME", "TestObject_RefCountKeepsIdleTime"); result.Num < 100 {
+		t.Errorf("expected the idle time to survive REFCOUNT, got %v", result)
+	}
+	if result := run(client, "OBJECT", "FREQ", "TestObject_RefCountKeepsIdleTime"); result.Num != freq {
+		t.Errorf("expected the frequency to stay %d, got %v", freq, result)
+	}
+	if result := run(client, "OBJECT", "REFCOUNT", "TestObject_RefCountMissing"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestMemoryUsage", "value")
+	if result := run(client, "MEMORY", "USAGE", "TestMemoryUsage"); result.Typ != common.INTEGER_TYPE || result.Num <= 0 {
+		t.Errorf("expected a size, got %v", result)
+	}
+	if result := run(client, "MEMORY", "USAGE", "TestMemoryUsage", "SAMPLES", "0"); result.Typ != common.INTEGER_TYPE {
+		t.Errorf("expected a size, got %v", result)
+	}
+	if result := run(client, "MEMORY", "USAGE", "TestMemoryUsage", "SAMPLES", "-1"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error, got %v", result)
+	}
+	if result := run(client, "MEMORY", "USAGE", "TestMemoryUsageMissing"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+}
+
+func TestMemoryStatsAndDoctor(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestMemoryStats", "value")
+	stats := run(client, "MEMORY", "STATS")
+	fields := map[string]bool{}
+	for i := 0; i+1 < len(stats.Array); i += 2 {
+		fields[stats.Array[i].Bulk] = true
+	}
+	for _, name := range []string{"total.allocated", "keys.count", "dataset.bytes", "overhead.total", "fragmentation"} {
+		if !fields[name] {
+			t.Errorf("expected %s in %v", name, stats)
+		}
+	}
+	if doctor := run(client, "MEMORY", "DOCTOR"); doctor.Typ != common.BULK_TYPE || doctor.Bulk == "" {
+		t.Errorf("expected a report, got %v", doctor)
+	}
+}
+
+func TestMemoryDoctor_ReportsIssues(t *testing.T) {
+	report := memoryDoctor(memoryStats{total: 64 << 20, keys: 10, dataset: 1 << 20, rss: 128 << 20, fragmentation: 2, lazyfree: 3})
+	for _, issue := range []string{"High fragmentation", "High overhead", "3 unlinked values"} {
+		if !strings.Contains(report, issue) {
+			t.Errorf("expected %q in %q", issue, report)
+		}
+	}
+}
+
+func TestObject_EncodingThresholds(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "11")
+	setConfig(t, "hash-max-listpack-entries", "1")
+	defer setConfig(t, "hash-max-listpack-entries", "128")
+	setConfig(t, "set-max-intset-entries", "1")
+	defer setConfig(t, "set-max-intset-entries", "512")
+	setConfig(t, "list-max-listpack-size", "1")
+	defer setConfig(t, "list-max-listpack-size", "-2")
+
+	run(client, "HSET", "TestObject_EncodingHash", "a", "1")
+	run(client, "SADD", "TestObject_EncodingSet", "1")
+	run(client, "RPUSH", "TestObject_EncodingList", "a")
+	for key, expected := range map[string]string{
+		"TestObject_EncodingHash": "listpack",
+		"TestObject_EncodingSet":  "intset",
+		"TestObject_EncodingList": "listpack",
+	} {
+		if result := run(client, "OBJECT", "ENCODING", key); result.Bulk != expected {
+			t.Errorf("expected %s for %s, got %v", expected, key, result)
+		}
+	}
+
+	run(client, "HSET", "TestObject_EncodingHash", "b", "2")
+	run(client, "SADD", "TestObject_EncodingSet", "2")
+	run(client, "RPUSH", "TestObject_EncodingList", "b")
+	for key, expected := range map[string]string{
+		"TestObject_EncodingHash": "hashtable",
+		"TestObject_EncodingSet":  "hashtable",
+		"TestObject_EncodingList": "deque",
+	} {
+		if result := run(client, "OBJECT", "ENCODING", key); result.Bulk != expected {
+			t.Errorf("expected %s for %s, got %v", expected, key, result)
+		}
+	}
+
+	result := ConfigCmd([]resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "SET"},
+		{Typ: common.BULK_TYPE, Bulk: "list-max-listpack-size"},
+		{Typ: common.BULK_TYPE, Bulk: "-6"}})
+	if result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error, got %v", result)
+	}
+}
@@ -0,0 +1,218 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/resp"
+)
+
+// subscribedCommands are the only commands a client can run while it is subscribed to a channel or pattern.
+var subscribedCommands = map[string]bool{
+	"SUBSCRIBE": true, "UNSUBSCRIBE": true, "PSUBSCRIBE": true, "PUNSUBSCRIBE": true,
+	"PING": true, "QUIT": true,
+}
+
+// Message delivers a message published on a channel the client subscribed to.
+func (c *Client) Message(channel, message string) {
+	c.Write(bulkArray("message", channel, message))
+}
+
+// PMessage delivers a message published on a channel matching a pattern the client subscribed to.
+func (c *Client) PMessage(pattern, channel, message string) {
+	c.Write(bulkArray("pmessage", pattern, channel, message))
+}
+
+// Subscriptions returns the number of channels and patterns the client is subscribed to.
+func (c *Client) Subscriptions() int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.channels) + len(c.patterns)
+}
+
+// updateSubscription adds or removes a channel or pattern from the client's subscriptions
+// and returns the resulting subscription count. Subscribed clients use the pubsub output
+// buffer limits.
+func (c *Client) updateSubscription(set map[string]struct{}, name string, subscribe bool) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if subscribe {
+		set[name] = struct{}{}
+	} else {
+		delete(set, name)
+	}
+	count := len(c.channels) + len(c.patterns)
+	if count > 0 && c.class == CLIENT_NORMAL {
+		c.class = CLIENT_PUBSUB
+	} else if count == 0 && c.class == CLIENT_PUBSUB {
+		c.class = CLIENT_NORMAL
+	}
+	return int64(count)
+}
+
+func (c *Client) subscriptionNames(set map[string]struct{}) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Subscribe implements the SUBSCRIBE command.
+// The confirmations are written to the client directly, one per channel, so the returned
+// value is empty. Each one is written before the channel is registered so no message can overtake it.
+func Subscribe(client *Client, args []resp.Value) resp.Value {
+	if len(args) == 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	for _, arg := range args {
+		count := client.updateSubscription(client.channels, arg.Bulk, true)
+		client.Write(subscriptionReply("subscribe", arg.Bulk, count))
+		pubsub.Subscribe(client, arg.Bulk)
+	}
+	return resp.Value{}
+}
+
+// PSubscribe implements the PSUBSCRIBE command.
+func PSubscribe(client *Client, args []resp.Value) resp.Value {
+	if len(args) == 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	for _, arg := range args {
+		count := client.updateSubscription(client.patterns, arg.Bulk, true)
+		client.Write(subscriptionReply("psubscribe", arg.Bulk, count))
+		pubsub.PSubscribe(client, arg.Bulk)
+	}
+	return resp.Value{}
+}
+
+// Unsubscribe implements the UNSUBSCRIBE command.
+// Without arguments the client is unsubscribed from all of its channels.
+func Unsubscribe(client *Client, args []resp.Value) resp.Value {
+	return unsubscribe(client, args, "unsubscribe", client.channels, pubsub.Unsubscribe)
+}
+
+// PUnsubscribe implements the PUNSUBSCRIBE command.
+// Without arguments the client is unsubscribed from all of its patterns.
+func PUnsubscribe(client *Client, args []resp.Value) resp.Value {
+	return unsubscribe(client, args, "punsubscribe", client.patterns, pubsub.PUnsubscribe)
+}
+
+func unsubscribe(client *Client, args []resp.Value, kind string, set map[string]struct{}, remove func(pubsub.Subscriber, string) bool) resp.Value {
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		names = append(names, arg.Bulk)
+	}
+	if len(names) == 0 {
+		names = client.subscriptionNames(set)
+	}
+	if len(names) == 0 {
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+			{Typ: common.BULK_TYPE, Bulk: kind},
+			{Typ: common.NULL_TYPE},
+			{Typ: common.INTEGER_TYPE, Num: int64(client.Subscriptions())},
+		}}
+	}
+	for _, name := range names {
+		remove(client, name)
+		count := client.updateSubscription(set, name, false)
+		client.Write(subscriptionReply(kind, name, count))
+	}
+	return resp.Value{}
+}
+
+// unsubscribeAll removes every subscription of a client that is disconnecting.
+func unsubscribeAll(client *Client) {
+	for _, channel := range client.subscriptionNames(client.channels) {
+		pubsub.Unsubscribe(client, channel)
+	}
+	for _, pattern := range client.subscriptionNames(client.patterns) {
+		pubsub.PUnsubscribe(client, pattern)
+	}
+}
+
+// Publish implements the PUBLISH command.
+// It returns the number of clients that received the message.
+func Publish(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: pubsub.Publish(args[0].Bulk, args[1].Bulk)}
+}
+
+// PubSub implements the PUBSUB CHANNELS [pattern] | NUMSUB [channel ...] | NUMPAT command.
+func PubSub(args []resp.Value) resp.Value {
+	if len(args) == 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	switch strings.ToUpper(args[0].Bulk) {
+	case "CHANNELS":
+		if len(args) > 2 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		pattern := ""
+		if len(args) == 2 {
+			pattern = args[1].Bulk
+		}
+		return bulkArray(pubsub.Channels(pattern)...)
+	case "NUMSUB":
+		result := make([]resp.Value, 0, 2*(len(args)-1))
+		for _, arg := range args[1:] {
+			result = append(result,
+				resp.Value{Typ: common.BULK_TYPE, Bulk: arg.Bulk},
+				resp.Value{Typ: common.INTEGER_TYPE, Num: pubsub.NumSub(arg.Bulk)})
+		}
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: result}
+	case "NUMPAT":
+		if len(args) != 1 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: pubsub.NumPat()}
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR unknown subcommand, must be CHANNELS, NUMSUB or NUMPAT"}
+	}
+}
+
+// subscribedContextReply returns the error for commands a subscribed client is not allowed to run,
+// and the PING reply used in that context. ok is false for commands that run normally.
+func subscribedContextReply(client *Client, name string, args []resp.Value) (resp.Value, bool) {
+	if client.Subscriptions() == 0 {
+		return resp.Value{}, false
+	}
+	if name == "PING" {
+		message := ""
+		if len(args) > 0 {
+			message = args[0].Bulk
+		}
+		return bulkArray("pong", message), true
+	}
+	if !subscribedCommands[name] {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: fmt.Sprintf(
+			"ERR Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context",
+			strings.ToLower(name))}, true
+	}
+	return resp.Value{}, false
+}
+
+func subscriptionReply(kind, name string, count int64) resp.Value {
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: kind},
+		{Typ: common.BULK_TYPE, Bulk: name},
+		{Typ: common.INTEGER_TYPE, Num: count},
+	}}
+}
+
+func bulkArray(values ...string) resp.Value {
+	array := make([]resp.Value, len(values))
+	for i, value := range values {
+		array[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: value}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: array}
+}
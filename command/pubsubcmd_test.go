@@ -0,0 +1,143 @@
+package command
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func bulkArgs(args ...string) []resp.Value {
+	values := make([]resp.Value, len(args))
+	for i, arg := range args {
+		values[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: arg}
+	}
+	return values
+}
+
+// expectReply reads the next reply sent to conn and compares it with expected.
+func expectReply(t *testing.T, conn net.Conn, expected resp.Value) {
+	t.Helper()
+	want := expected.Marshal()
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSubscribe_ReceivesMessages(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	defer client.Close()
+
+	Subscribe(client, bulkArgs("TestSubscribe.a", "TestSubscribe.b"))
+	expectReply(t, clientConn, subscriptionReply("subscribe", "TestSubscribe.a", 1))
+	expectReply(t, clientConn, subscriptionReply("subscribe", "TestSubscribe.b", 2))
+	PSubscribe(client, bulkArgs("TestSubscribe.*"))
+	expectReply(t, clientConn, subscriptionReply("psubscribe", "TestSubscribe.*", 3))
+
+	result := Publish(bulkArgs("TestSubscribe.a", "hello"))
+	if result.Num != 2 {
+		t.Errorf("expected 2 receivers, got %v", result)
+	}
+	expectReply(t, clientConn, bulkArray("message", "TestSubscribe.a", "hello"))
+	expectReply(t, clientConn, bulkArray("pmessage", "TestSubscribe.*", "TestSubscribe.a", "hello"))
+
+	numsub := PubSub(bulkArgs("NUMSUB", "TestSubscribe.b"))
+	if len(numsub.Array) != 2 || numsub.Array[1].Num != 1 {
+		t.Errorf("expected 1 subscriber, got %v", numsub)
+	}
+
+	Unsubscribe(client, bulkArgs("TestSubscribe.a"))
+	expectReply(t, clientConn, subscriptionReply("unsubscribe", "TestSubscribe.a", 2))
+	PUnsubscribe(client, []resp.Value{})
+	expectReply(t, clientConn, subscriptionReply("punsubscribe", "TestSubscribe.*", 1))
+	if client.Subscriptions() != 1 {
+		t.Errorf("expected 1 subscription left, got %d", client.Subscriptions())
+	}
+}
+
+func TestSubscribe_RestrictsCommands(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	defer client.Close()
+
+	Subscribe(client, bulkArgs("TestSubscribe_RestrictsCommands"))
+	expectReply(t, clientConn, subscriptionReply("subscribe", "TestSubscribe_RestrictsCommands", 1))
+	if client.class != CLIENT_PUBSUB {
+		t.Errorf("expected the pubsub class, got %s", client.class)
+	}
+
+	result := Execute(client, "GET", Handlers["GET"], bulkArgs("key"))
+	if result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error, got %v", result)
+	}
+	result = Execute(client, "PING", Handlers["PING"], []resp.Value{})
+	if len(result.Array) != 2 || result.Array[0].Bulk != "pong" {
+		t.Errorf("expected a pong array, got %v", result)
+	}
+
+	client.Close()
+	if n := PubSub(bulkArgs("NUMSUB", "TestSubscribe_RestrictsCommands")).Array[1].Num; n != 0 {
+		t.Errorf("expected the subscriptions to be removed on close, got %d", n)
+	}
+}
+
+func TestKeyspaceNotifications(t *testing.T) {
+	setConfig(t, "notify-keyspace-events", "KEA")
+	defer setConfig(t, "notify-keyspace-events", "")
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	defer client.Close()
+
+	PSubscribe(client, bulkArgs("__key*@0__:TestKeyspaceNotifications*"))
+	expectReply(t, clientConn, subscriptionReply("psubscribe", "__key*@0__:TestKeyspaceNotifications*", 1))
+
+	Set(bulkArgs("TestKeyspaceNotifications", "value"))
+	pattern := "__key*@0__:TestKeyspaceNotifications*"
+	expectReply(t, clientConn, bulkArray("pmessage", pattern, "__keyspace@0__:TestKeyspaceNotifications", "set"))
+
+	LPush(bulkArgs("TestKeyspaceNotificationsList", "a"))
+	expectReply(t, clientConn, bulkArray("pmessage", pattern, "__keyspace@0__:TestKeyspaceNotificationsList", "lpush"))
+}
+
+func TestKeyspaceNotifications_KeyMissAndNew(t *testing.T) {
+	setConfig(t, "notify-keyspace-events", "Kmn")
+	defer setConfig(t, "notify-keyspace-events", "")
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	defer client.Close()
+
+	pattern := "__keyspace@0__:TestKeyspaceNotifications_KeyMissAndNew*"
+	PSubscribe(client, bulkArgs(pattern))
+	expectReply(t, clientConn, subscriptionReply("psubscribe", pattern, 1))
+
+	Get(bulkArgs("TestKeyspaceNotifications_KeyMissAndNew"))
+	expectReply(t, clientConn, bulkArray("pmessage", pattern, "__keyspace@0__:TestKeyspaceNotifications_KeyMissAndNew", "keymiss"))
+
+	Set(bulkArgs("TestKeyspaceNotifications_KeyMissAndNew", "value"))
+	expectReply(t, clientConn, bulkArray("pmessage", pattern, "__keyspace@0__:TestKeyspaceNotifications_KeyMissAndNew", "new"))
+
+	// Overwriting a key isn't a new key, so the next event is the one of the other key.
+	Set(bulkArgs("TestKeyspaceNotifications_KeyMissAndNew", "other"))
+	Set(bulkArgs("TestKeyspaceNotifications_KeyMissAndNew2", "value"))
+	expectReply(t, clientConn, bulkArray("pmessage", pattern, "__keyspace@0__:TestKeyspaceNotifications_KeyMissAndNew2", "new"))
+}
+
+func TestConfig_InvalidNotifyKeyspaceEvents(t *testing.T) {
+	result := ConfigCmd(bulkArgs("SET", "notify-keyspace-events", "KE?"))
+	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_INVALID_CONFIG_VALUE {
+		t.Errorf("expected %s, got %v", common.ERR_INVALID_CONFIG_VALUE, result)
+	}
+}
@@ -1,6 +1,9 @@
 package command
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
 	"github.com/divy-sh/animus/types/sets"
@@ -72,3 +75,158 @@ func Sismember(args []resp.Value) resp.Value {
 	}
 	return resp.Value{Typ: common.INTEGER_TYPE, Num: num}
 }
+
+// setKeys returns the bulk strings of args.
+func setKeys(args []resp.Value) []string {
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = arg.Bulk
+	}
+	return keys
+}
+
+// Srem implements SREM key member [member ...].
+func Srem(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: sets.Srem(args[0].Bulk, setKeys(args[1:]))}
+}
+
+// Smembers implements SMEMBERS key.
+func Smembers(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return bulkArray(sets.Smembers(args[0].Bulk)...)
+}
+
+// Sinter implements SINTER key [key ...].
+func Sinter(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return bulkArray(sets.Sinter(setKeys(args))...)
+}
+
+// SinterStore implements SINTERSTORE destination key [key ...].
+func SinterStore(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: sets.SinterStore(args[0].Bulk, setKeys(args[1:]))}
+}
+
+// Sintercard implements SINTERCARD numkeys key [key ...] [LIMIT limit].
+// The keys follow numkeys, so they are qualified here rather than through the key specification.
+func Sintercard(client *Client, args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	numKeys, err := strconv.Atoi(args[0].Bulk)
+	if err != nil || numKeys <= 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_NUMKEYS_INVALID}
+	}
+	if numKeys > len(args)-1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_NUMKEYS_MISMATCH}
+	}
+	limit := 0
+	rest := args[1+numKeys:]
+	if len(rest) > 0 {
+		if len(rest) != 2 || strings.ToUpper(rest[0].Bulk) != "LIMIT" {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		limit, err = strconv.Atoi(rest[1].Bulk)
+		if err != nil || limit < 0 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_LIMIT_NEGATIVE}
+		}
+	}
+	keys := setKeys(args[1 : 1+numKeys])
+	for i, key := range keys {
+		keys[i] = common.DBKey(client.DB(), key)
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: sets.Sintercard(keys, limit)}
+}
+
+// Sunion implements SUNION key [key ...].
+func Sunion(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return bulkArray(sets.Sunion(setKeys(args))...)
+}
+
+// SunionStore implements SUNIONSTORE destination key [key ...].
+func SunionStore(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: sets.SunionStore(args[0].Bulk, setKeys(args[1:]))}
+}
+
+// Spop implements SPOP key [count]. Without count a single member is returned, or null.
+func Spop(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	if len(args) == 1 {
+		return singleMember(sets.Spop(args[0].Bulk, 1))
+	}
+	count, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	if count < 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_OUT_OF_RANGE}
+	}
+	return bulkArray(sets.Spop(args[0].Bulk, count)...)
+}
+
+// Srandmember implements SRANDMEMBER key [count]. Without count a single member is returned, or null.
+func Srandmember(args []resp.Value) resp.Value {
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	if len(args) == 1 {
+		return singleMember(sets.Srandmember(args[0].Bulk, 1))
+	}
+	count, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	if count < -sets.MaxRandomCount {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_OUT_OF_RANGE}
+	}
+	return bulkArray(sets.Srandmember(args[0].Bulk, count)...)
+}
+
+func singleMember(members []string) resp.Value {
+	if len(members) == 0 {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: members[0]}
+}
+
+// Smove implements SMOVE source destination member.
+func Smove(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: sets.Smove(args[0].Bulk, args[1].Bulk, args[2].Bulk)}
+}
+
+// Smismember implements SMISMEMBER key member [member ...].
+func Smismember(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	found := sets.Smismember(args[0].Bulk, setKeys(args[1:]))
+	array := make([]resp.Value, len(found))
+	for i, ok := range found {
+		array[i] = resp.Value{Typ: common.INTEGER_TYPE}
+		if ok {
+			array[i].Num = 1
+		}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: array}
+}
@@ -325,3 +325,92 @@ func TestSismemberInvalidArgs(t *testing.T) {
 		t.Errorf("Expected ERROR_TYPE for too many arguments, got %v", result.Typ)
 	}
 }
+
+func TestSetCommands(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SADD", "TestSetCommands1", "a", "b", "c")
+	run(client, "SADD", "TestSetCommands2", "b", "c", "d")
+
+	if result := run(client, "SREM", "TestSetCommands1", "a", "missing"); result.Num != 1 {
+		t.Errorf("expected 1 member removed, got %v", result)
+	}
+	if result := run(client, "SMEMBERS", "TestSetCommands1"); len(result.Array) != 2 {
+		t.Errorf("expected 2 members, got %v", result)
+	}
+	if result := run(client, "SINTER", "TestSetCommands1", "TestSetCommands2"); len(result.Array) != 2 {
+		t.Errorf("expected 2 members, got %v", result)
+	}
+	if result := run(client, "SUNIONSTORE", "TestSetCommandsDest", "TestSetCommands1", "TestSetCommands2"); result.Num != 3 {
+		t.Errorf("expected 3 members stored, got %v", result)
+	}
+	if result := run(client, "SINTERSTORE", "TestSetCommandsDest", "TestSetCommands1", "TestSetCommandsMissing"); result.Num != 0 {
+		t.Errorf("expected an empty intersection, got %v", result)
+	}
+	if result := run(client, "EXISTS", "TestSetCommandsDest"); result.Num != 0 {
+		t.Errorf("expected the destination to be deleted, got %v", result)
+	}
+	result := run(client, "SMISMEMBER", "TestSetCommands2", "b", "a")
+	if len(result.Array) != 2 || result.Array[0].Num != 1 || result.Array[1].Num != 0 {
+		t.Errorf("unexpected reply %v", result)
+	}
+	if result := run(client, "SMOVE", "TestSetCommands2", "TestSetCommands1", "d"); result.Num != 1 {
+		t.Errorf("expected the member to be moved, got %v", result)
+	}
+}
+
+func TestSintercard(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SELECT", "1")
+	run(client, "SADD", "TestSintercard1", "a", "b", "c")
+	run(client, "SADD", "TestSintercard2", "a", "b")
+
+	if result := run(client, "SINTERCARD", "2", "TestSintercard1", "TestSintercard2"); result.Num != 2 {
+		t.Errorf("expected 2, got %v", result)
+	}
+	if result := run(client, "SINTERCARD", "2", "TestSintercard1", "TestSintercard2", "LIMIT", "1"); result.Num != 1 {
+		t.Errorf("expected 1, got %v", result)
+	}
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"SINTERCARD", "0", "TestSintercard1"}, common.ERR_NUMKEYS_INVALID},
+		{[]string{"SINTERCARD", "3", "TestSintercard1", "TestSintercard2"}, common.ERR_NUMKEYS_MISMATCH},
+		{[]string{"SINTERCARD", "1", "TestSintercard1", "LIMIT", "-1"}, common.ERR_LIMIT_NEGATIVE},
+		{[]string{"SINTERCARD", "1", "TestSintercard1", "TestSintercard2"}, common.ERR_SYNTAX},
+	}
+	for _, test := range tests {
+		if result := run(client, test.args...); result.Str != test.expected {
+			t.Errorf("%v: expected %s, got %v", test.args, test.expected, result)
+		}
+	}
+}
+
+func TestSpopAndSrandmember(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SADD", "TestSpopAndSrandmember", "a", "b", "c")
+
+	if result := run(client, "SRANDMEMBER", "TestSpopAndSrandmember"); result.Typ != common.BULK_TYPE {
+		t.Errorf("expected a single member, got %v", result)
+	}
+	if result := run(client, "SRANDMEMBER", "TestSpopAndSrandmember", "-5"); len(result.Array) != 5 {
+		t.Errorf("expected 5 members, got %v", result)
+	}
+	for _, count := range []string{"-100000000000000", "-9223372036854775808"} {
+		if result := run(client, "SRANDMEMBER", "TestSpopAndSrandmember", count); result.Str != common.ERR_OUT_OF_RANGE {
+			t.Errorf("expected %s for count %s, got %v", common.ERR_OUT_OF_RANGE, count, result)
+		}
+	}
+	if result := run(client, "SPOP", "TestSpopAndSrandmember", "-1"); result.Str != common.ERR_OUT_OF_RANGE {
+		t.Errorf("expected %s, got %v", common.ERR_OUT_OF_RANGE, result)
+	}
+	if result := run(client, "SPOP", "TestSpopAndSrandmember", "2"); len(result.Array) != 2 {
+		t.Errorf("expected 2 members, got %v", result)
+	}
+	if result := run(client, "SPOP", "TestSpopAndSrandmember"); result.Typ != common.BULK_TYPE {
+		t.Errorf("expected the last member, got %v", result)
+	}
+	if result := run(client, "SPOP", "TestSpopAndSrandmember"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+}
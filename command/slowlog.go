@@ -0,0 +1,201 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+// Limits applied to the arguments recorded for a slow log entry.
+const (
+	slowLogMaxArgs   = 32
+	slowLogMaxArgLen = 128
+)
+
+type slowLogEntry struct {
+	id         int64
+	timestamp  int64
+	duration   int64
+	args       []string
+	clientAddr string
+	clientName string
+}
+
+// slowLogRing holds the most recent slow log entries, at most capacity of them. Entries are
+// appended until the ring is full, then each new entry overwrites the oldest one.
+type slowLogRing struct {
+	entries []slowLogEntry
+	// head is the index of the oldest entry once the ring is full, 0 until then.
+	head     int
+	capacity int
+}
+
+func (r *slowLogRing) add(entry slowLogEntry) {
+	if r.capacity == 0 {
+		return
+	}
+	if len(r.entries) < r.capacity {
+		r.entries = append(r.entries, entry)
+		return
+	}
+	r.entries[r.head] = entry
+	r.head = (r.head + 1) % len(r.entries)
+}
+
+// newest returns the n most recent entries, the most recent first.
+func (r *slowLogRing) newest(n int) []slowLogEntry {
+	size := len(r.entries)
+	entries := make([]slowLogEntry, min(n, size))
+	for i := range entries {
+		entries[i] = r.entries[(r.head-1-i+2*size)%size]
+	}
+	return entries
+}
+
+// resize changes the capacity of the ring, dropping the oldest entries that don't fit.
+func (r *slowLogRing) resize(capacity int) {
+	kept := r.newest(capacity)
+	slices.Reverse(kept)
+	r.entries, r.head, r.capacity = kept, 0, capacity
+}
+
+func (r *slowLogRing) reset() {
+	r.entries, r.head = nil, 0
+}
+
+var (
+	slowLog           slowLogRing
+	slowLogMutex      sync.Mutex
+	slowLogNextID     int64
+	slowLogSlowerThan atomic.Int64
+)
+
+func init() {
+	setSlowLogSlowerThan(serverConfig["slowlog-log-slower-than"])
+	setSlowLogMaxLen(serverConfig["slowlog-max-len"])
+}
+
+// setSlowLogSlowerThan sets the execution time, in microseconds, a command has to exceed to be logged.
+// A negative value disables the slow log and zero logs every command.
+func setSlowLogSlowerThan(value string) error {
+	micros, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	slowLogSlowerThan.Store(micros)
+	return nil
+}
+
+func setSlowLogMaxLen(value string) error {
+	maxLen, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || maxLen < 0 {
+		return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+	}
+	slowLogMutex.Lock()
+	defer slowLogMutex.Unlock()
+	slowLog.resize(int(min(maxLen, math.MaxInt32)))
+	return nil
+}
+
+func logSlowCommand(client *Client, name string, args []resp.Value, start time.Time, duration time.Duration) {
+	slowerThan := slowLogSlowerThan.Load()
+	if slowerThan < 0 || duration.Microseconds() < slowerThan {
+		return
+	}
+	entry := slowLogEntry{
+		timestamp: start.Unix(),
+		duration:  duration.Microseconds(),
+		args:      truncateSlowLogArgs(name, args),
+	}
+	if client != nil {
+		entry.clientAddr = client.Addr
+		entry.clientName = client.Name()
+	}
+
+	slowLogMutex.Lock()
+	defer slowLogMutex.Unlock()
+	if slowLog.capacity == 0 {
+		return
+	}
+	entry.id = slowLogNextID
+	slowLogNextID++
+	slowLog.add(entry)
+}
+
+// truncateSlowLogArgs keeps the command name and its arguments, at most slowLogMaxArgs of them
+// and each cut to slowLogMaxArgLen bytes, so huge commands don't bloat the slow log.
+// Passwords are redacted like they are for MONITOR.
+func truncateSlowLogArgs(name string, args []resp.Value) []string {
+	argv := make([]string, 0, min(len(args)+1, slowLogMaxArgs))
+	argv = append(argv, name)
+	for i, val := range redactArgs(name, args) {
+		if len(argv) == slowLogMaxArgs-1 && len(args)-i > 1 {
+			argv = append(argv, fmt.Sprintf("... (%d more arguments)", len(args)-i))
+			break
+		}
+		if len(val) > slowLogMaxArgLen {
+			val = fmt.Sprintf("%s... (%d more bytes)", val[:slowLogMaxArgLen], len(val)-slowLogMaxArgLen)
+		}
+		argv = append(argv, val)
+	}
+	return argv
+}
+
+// SlowLog implements the SLOWLOG command.
+func SlowLog(args []resp.Value) resp.Value {
+	if len(args) < 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	slowLogMutex.Lock()
+	defer slowLogMutex.Unlock()
+
+	switch strings.ToUpper(args[0].Bulk) {
+	case "GET":
+		if len(args) > 2 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		count := int64(10)
+		if len(args) == 2 {
+			var err error
+			count, err = strconv.ParseInt(args[1].Bulk, 10, 64)
+			if err != nil || count < -1 {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+			}
+		}
+		if count == -1 || count > int64(len(slowLog.entries)) {
+			count = int64(len(slowLog.entries))
+		}
+		entries := make([]resp.Value, count)
+		for i, entry := range slowLog.newest(int(count)) {
+			entryArgs := make([]resp.Value, len(entry.args))
+			for j, arg := range entry.args {
+				entryArgs[j] = resp.Value{Typ: common.BULK_TYPE, Bulk: arg}
+			}
+			entries[i] = resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+				{Typ: common.INTEGER_TYPE, Num: entry.id},
+				{Typ: common.INTEGER_TYPE, Num: entry.timestamp},
+				{Typ: common.INTEGER_TYPE, Num: entry.duration},
+				{Typ: common.ARRAY_TYPE, Array: entryArgs},
+				{Typ: common.BULK_TYPE, Bulk: entry.clientAddr},
+				{Typ: common.BULK_TYPE, Bulk: entry.clientName},
+			}}
+		}
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: entries}
+	case "LEN":
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: int64(len(slowLog.entries))}
+	case "RESET":
+		slowLog.reset()
+		return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: "ERR unknown subcommand, must be GET, LEN or RESET"}
+	}
+}
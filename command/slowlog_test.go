@@ -0,0 +1,181 @@
+package command
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+func slowLogCmd(args ...string) resp.Value {
+	values := make([]resp.Value, len(args))
+	for i, arg := range args {
+		values[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: arg}
+	}
+	return SlowLog(values)
+}
+
+func TestSlowLog_RecordsCommands(t *testing.T) {
+	setConfig(t, "slowlog-log-slower-than", "0")
+	defer setConfig(t, "slowlog-log-slower-than", "10000")
+	slowLogCmd("RESET")
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+	client.SetName("worker")
+
+	Execute(client, "SET", Handlers["SET"], []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "TestSlowLog_RecordsCommands"},
+		{Typ: common.BULK_TYPE, Bulk: "value"}})
+
+	if result := slowLogCmd("LEN"); result.Num != 1 {
+		t.Fatalf("expected 1 entry, got %v", result)
+	}
+	result := slowLogCmd("GET")
+	if result.Typ != common.ARRAY_TYPE || len(result.Array) != 1 {
+		t.Fatalf("expected 1 entry, got %v", result)
+	}
+	entry := result.Array[0].Array
+	if len(entry) != 6 {
+		t.Fatalf("expected 6 fields in entry, got %v", entry)
+	}
+	if entry[3].Array[0].Bulk != "SET" || entry[3].Array[1].Bulk != "TestSlowLog_RecordsCommands" {
+		t.Errorf("unexpected arguments %v", entry[3])
+	}
+	if entry[4].Bulk != client.Addr || entry[5].Bulk != "worker" {
+		t.Errorf("unexpected client %v %v", entry[4], entry[5])
+	}
+
+	slowLogCmd("RESET")
+	if result := slowLogCmd("LEN"); result.Num != 0 {
+		t.Errorf("expected empty slow log after reset, got %v", result)
+	}
+}
+
+func TestSlowLog_Disabled(t *testing.T) {
+	setConfig(t, "slowlog-log-slower-than", "-1")
+	defer setConfig(t, "slowlog-log-slower-than", "10000")
+	slowLogCmd("RESET")
+
+	Execute(nil, "PING", Handlers["PING"], []resp.Value{})
+	if result := slowLogCmd("LEN"); result.Num != 0 {
+		t.Errorf("expected no entries, got %v", result)
+	}
+}
+
+func TestSlowLog_MaxLenAndGetCount(t *testing.T) {
+	setConfig(t, "slowlog-log-slower-than", "0")
+	setConfig(t, "slowlog-max-len", "3")
+	defer setConfig(t, "slowlog-log-slower-than", "10000")
+	defer setConfig(t, "slowlog-max-len", "128")
+	slowLogCmd("RESET")
+
+	for i := 0; i < 5; i++ {
+		Execute(nil, "PING", Handlers["PING"], []resp.Value{{Typ: common.BULK_TYPE, Bulk: fmt.Sprint(i)}})
+	}
+	if result := slowLogCmd("LEN"); result.Num != 3 {
+		t.Fatalf("expected 3 entries, got %v", result)
+	}
+	result := slowLogCmd("GET", "2")
+	if len(result.Array) != 2 || result.Array[0].Array[3].Array[1].Bulk != "4" {
+		t.Errorf("expected the 2 newest entries, got %v", result)
+	}
+	if result := slowLogCmd("GET", "-1"); len(result.Array) != 3 {
+		t.Errorf("expected all entries, got %v", result)
+	}
+	if result := slowLogCmd("GET", "-2"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected error for invalid count, got %v", result)
+	}
+}
+
+func TestSlowLogRing(t *testing.T) {
+	ring := slowLogRing{capacity: 3}
+	ids := func(entries []slowLogEntry) []int64 {
+		result := []int64{}
+		for _, entry := range entries {
+			result = append(result, entry.id)
+		}
+		return result
+	}
+	for id := range int64(5) {
+		ring.add(slowLogEntry{id: id})
+	}
+	if got := ids(ring.newest(10)); !slices.Equal(got, []int64{4, 3, 2}) {
+		t.Errorf("expected [4 3 2], got %v", got)
+	}
+	ring.resize(2)
+	if got := ids(ring.newest(10)); !slices.Equal(got, []int64{4, 3}) {
+		t.Errorf("expected [4 3] after shrinking, got %v", got)
+	}
+	ring.resize(4)
+	ring.add(slowLogEntry{id: 5})
+	ring.add(slowLogEntry{id: 6})
+	ring.add(slowLogEntry{id: 7})
+	if got := ids(ring.newest(10)); !slices.Equal(got, []int64{7, 6, 5, 4}) {
+		t.Errorf("expected [7 6 5 4] after growing, got %v", got)
+	}
+	ring.reset()
+	if got := ring.newest(10); len(got) != 0 {
+		t.Errorf("expected an empty ring, got %v", got)
+	}
+}
+
+func TestSlowLog_TruncatesArguments(t *testing.T) {
+	args := make([]resp.Value, 40)
+	for i := range args {
+		args[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: "v"}
+	}
+	args[0].Bulk = strings.Repeat("x", 200)
+	argv := truncateSlowLogArgs("MSET", args)
+	if len(argv) != slowLogMaxArgs {
+		t.Fatalf("expected %d arguments, got %d", slowLogMaxArgs, len(argv))
+	}
+	if argv[1] != strings.Repeat("x", 128)+"... (72 more bytes)" {
+		t.Errorf("unexpected truncated argument %q", argv[1])
+	}
+	if argv[len(argv)-1] != "... (10 more arguments)" {
+		t.Errorf("unexpected last argument %q", argv[len(argv)-1])
+	}
+}
+
+func TestSlowLog_RedactsPasswords(t *testing.T) {
+	argv := truncateSlowLogArgs("MIGRATE", bulkArgs("host", "6379", "key", "0", "1000", "AUTH2", "user", "secret"))
+	expected := []string{"MIGRATE", "host", "6379", "key", "0", "1000", "AUTH2", "(redacted)", "(redacted)"}
+	if !slices.Equal(argv, expected) {
+		t.Errorf("expected %v, got %v", expected, argv)
+	}
+}
+
+func TestSlowLog_InvalidSubcommand(t *testing.T) {
+	if result := slowLogCmd("FOO"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected error, got %v", result)
+	}
+	if result := SlowLog([]resp.Value{}); result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
+		t.Errorf("expected %s, got %v", common.ERR_WRONG_ARGUMENT_COUNT, result)
+	}
+}
+
+func TestClientCmd(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	client := NewClient(serverConn)
+
+	if result := ClientCmd(client, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "GETNAME"}}); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null name, got %v", result)
+	}
+	ClientCmd(client, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "SETNAME"}, {Typ: common.BULK_TYPE, Bulk: "conn1"}})
+	if result := ClientCmd(client, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "GETNAME"}}); result.Bulk != "conn1" {
+		t.Errorf("expected conn1, got %v", result)
+	}
+	if result := ClientCmd(client, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "SETNAME"}, {Typ: common.BULK_TYPE, Bulk: "a b"}}); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected error for name with spaces, got %v", result)
+	}
+	if result := ClientCmd(client, []resp.Value{{Typ: common.BULK_TYPE, Bulk: "ID"}}); result.Num != client.ID {
+		t.Errorf("expected id %d, got %v", client.ID, result)
+	}
+}
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/store"
+)
+
+// Server wide counters reported by INFO.
+var (
+	startTime                       = time.Now()
+	connectedClients                atomic.Int64
+	totalConnectionsReceived        atomic.Int64
+	rejectedConnections             atomic.Int64
+	totalCommandsProcessed          atomic.Int64
+	outputBufferLimitDisconnections atomic.Int64
+	// instantaneousOps is the number of commands processed per second, averaged over the last samples.
+	instantaneousOps atomic.Int64
+
+	commandStats sync.Map // command name -> *commandStat
+)
+
+const (
+	opsSampleInterval = 100 * time.Millisecond
+	opsSamples        = 16
+)
+
+// latencyBuckets are the upper bounds, in microseconds, of the command latency histogram.
+var latencyBuckets = [...]int64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 25000, 50000, 100000, 250000, 500000, 1000000}
+
+type commandStat struct {
+	calls       atomic.Int64
+	usec        atomic.Int64
+	failedCalls atomic.Int64
+	// latency counts the calls per latencyBuckets bucket, the last one counts the slower calls.
+	latency [len(latencyBuckets) + 1]atomic.Int64
+}
+
+func init() {
+	go sampleOps()
+}
+
+// recordCommand accounts a processed command in the command statistics.
+func recordCommand(name string, duration time.Duration, result resp.Value) {
+	totalCommandsProcessed.Add(1)
+	stat, ok := commandStats.Load(name)
+	if !ok {
+		stat, _ = commandStats.LoadOrStore(name, &commandStat{})
+	}
+	cmdStat := stat.(*commandStat)
+	cmdStat.calls.Add(1)
+	cmdStat.usec.Add(duration.Microseconds())
+	bucket := sort.Search(len(latencyBuckets), func(i int) bool {
+		return duration.Microseconds() <= latencyBuckets[i]
+	})
+	cmdStat.latency[bucket].Add(1)
+	if result.Typ == common.ERROR_TYPE {
+		cmdStat.failedCalls.Add(1)
+	}
+}
+
+// sortedCommandStats returns the names of the commands that have statistics, sorted.
+func sortedCommandStats() []string {
+	names := []string{}
+	commandStats.Range(func(key, _ any) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// sampleOps periodically samples the processed command count to compute the ops per second.
+func sampleOps() {
+	samples := make([]int64, 0, opsSamples)
+	last := totalCommandsProcessed.Load()
+	lastTime := time.Now()
+	ticker := time.NewTicker(opsSampleInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		current := totalCommandsProcessed.Load()
+		elapsed := now.Sub(lastTime).Seconds()
+		sample := int64(0)
+		if elapsed > 0 && current >= last {
+			sample = int64(float64(current-last) / elapsed)
+		}
+		last, lastTime = current, now
+		if len(samples) == opsSamples {
+			samples = samples[1:]
+		}
+		samples = append(samples, sample)
+		var sum int64
+		for _, s := range samples {
+			sum += s
+		}
+		instantaneousOps.Store(sum / int64(len(samples)))
+	}
+}
+
+// AdmitClient registers a new connection, refusing it when maxclients is reached.
+func AdmitClient(client *Client) bool {
+	if connectedClients.Load() > maxClients.Load() {
+		rejectedConnections.Add(1)
+		client.Write(resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_MAX_CLIENTS})
+		return false
+	}
+	return true
+}
+
+// resetStats implements CONFIG RESETSTAT.
+func resetStats() {
+	totalConnectionsReceived.Store(0)
+	rejectedConnections.Store(0)
+	totalCommandsProcessed.Store(0)
+	outputBufferLimitDisconnections.Store(0)
+	commandStats.Range(func(key, _ any) bool {
+		commandStats.Delete(key)
+		return true
+	})
+	store.ResetStats()
+}
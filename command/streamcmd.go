@@ -0,0 +1,746 @@
+package command
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+	"github.com/divy-sh/animus/types/streams"
+)
+
+func streamEntryValue(e streams.Entry) resp.Value {
+	fields := resp.Value{Typ: common.NULL_TYPE}
+	if e.Fields != nil {
+		fields = bulkArray(e.Fields...)
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{{Typ: common.BULK_TYPE, Bulk: e.ID.String()}, fields}}
+}
+
+func streamEntriesValue(entries []streams.Entry) resp.Value {
+	values := make([]resp.Value, len(entries))
+	for i, e := range entries {
+		values[i] = streamEntryValue(e)
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+}
+
+func streamIDsValue(ids []streams.ID) resp.Value {
+	values := make([]resp.Value, len(ids))
+	for i, id := range ids {
+		values[i] = resp.Value{Typ: common.BULK_TYPE, Bulk: id.String()}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+}
+
+// streamResultsValue replies with the entries read from each stream, the keys stripped of their database.
+func streamResultsValue(results []streams.StreamEntries) resp.Value {
+	values := make([]resp.Value, len(results))
+	for i, result := range results {
+		_, name := common.SplitDBKey(result.Key)
+		values[i] = resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+			{Typ: common.BULK_TYPE, Bulk: name},
+			streamEntriesValue(result.Entries),
+		}}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+}
+
+func parseStreamIDs(args []resp.Value) ([]streams.ID, error) {
+	ids := make([]streams.ID, len(args))
+	for i, arg := range args {
+		id, err := streams.ParseID(arg.Bulk)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// parseTrim parses MAXLEN|MINID [=|~] threshold [LIMIT count] from the start of args,
+// it returns the number of arguments it consumed.
+func parseTrim(args []resp.Value) (*streams.Trim, int, error) {
+	trim := &streams.Trim{Strategy: strings.ToUpper(args[0].Bulk)}
+	i := 1
+	if i < len(args) && (args[i].Bulk == "=" || args[i].Bulk == "~") {
+		trim.Approx = args[i].Bulk == "~"
+		i++
+	}
+	if i >= len(args) {
+		return nil, 0, errors.New(common.ERR_SYNTAX)
+	}
+	var err error
+	if trim.Strategy == streams.TRIM_MAXLEN {
+		if trim.MaxLen, err = strconv.ParseInt(args[i].Bulk, 10, 64); err != nil {
+			return nil, 0, errors.New(common.ERR_INVALID_INTEGER)
+		}
+		if trim.MaxLen < 0 {
+			return nil, 0, errors.New(common.ERR_MAXLEN_NEGATIVE)
+		}
+	} else if trim.MinID, err = streams.ParseID(args[i].Bulk); err != nil {
+		return nil, 0, err
+	}
+	i++
+	if i < len(args) && strings.ToUpper(args[i].Bulk) == "LIMIT" {
+		if i+1 >= len(args) {
+			return nil, 0, errors.New(common.ERR_SYNTAX)
+		}
+		if trim.Limit, err = strconv.ParseInt(args[i+1].Bulk, 10, 64); err != nil || trim.Limit < 0 {
+			return nil, 0, errors.New(common.ERR_INVALID_INTEGER)
+		}
+		if !trim.Approx {
+			return nil, 0, errors.New(common.ERR_STREAM_LIMIT)
+		}
+		i += 2
+	}
+	return trim, i, nil
+}
+
+// XAdd implements XADD key [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id field value [field value ...].
+// It replies with the ID of the entry, or null if the stream doesn't exist and NOMKSTREAM is given.
+func XAdd(args []resp.Value) resp.Value {
+	if len(args) < 4 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	noMkStream := false
+	var trim *streams.Trim
+	i := 1
+	for i < len(args) {
+		option := strings.ToUpper(args[i].Bulk)
+		if option == "NOMKSTREAM" {
+			noMkStream = true
+			i++
+			continue
+		}
+		if option != streams.TRIM_MAXLEN && option != streams.TRIM_MINID {
+			break
+		}
+		var n int
+		var err error
+		if trim, n, err = parseTrim(args[i:]); err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		i += n
+	}
+	if i >= len(args) || (len(args)-i-1)%2 != 0 || len(args)-i-1 == 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	fields := make([]string, len(args)-i-1)
+	for j, arg := range args[i+1:] {
+		fields[j] = arg.Bulk
+	}
+	id, ok, err := streams.XAdd(args[0].Bulk, args[i].Bulk, fields, noMkStream, trim)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !ok {
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: id.String()}
+}
+
+func XLen(args []resp.Value) resp.Value {
+	if len(args) != 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: streams.XLen(args[0].Bulk)}
+}
+
+// xrange implements XRANGE and XREVRANGE, whose arguments are the key, the start and end
+// of the interval and an optional COUNT.
+func xrange(args []resp.Value, start, end string, rev bool) resp.Value {
+	count := int64(-1)
+	if len(args) == 5 {
+		if strings.ToUpper(args[3].Bulk) != "COUNT" {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		n, err := strconv.ParseInt(args[4].Bulk, 10, 64)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+		}
+		count = max(n, 0)
+	}
+	from, to, ok, err := streams.ParseRange(start, end)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !ok {
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{}}
+	}
+	return streamEntriesValue(streams.XRange(args[0].Bulk, from, to, count, rev))
+}
+
+// XRange implements XRANGE key start end [COUNT count].
+func XRange(args []resp.Value) resp.Value {
+	if len(args) != 3 && len(args) != 5 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return xrange(args, args[1].Bulk, args[2].Bulk, false)
+}
+
+// XRevRange implements XREVRANGE key end start [COUNT count].
+func XRevRange(args []resp.Value) resp.Value {
+	if len(args) != 3 && len(args) != 5 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	return xrange(args, args[2].Bulk, args[1].Bulk, true)
+}
+
+// XDel implements XDEL key id [id ...].
+func XDel(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	ids, err := parseStreamIDs(args[1:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: streams.XDel(args[0].Bulk, ids)}
+}
+
+// XTrim implements XTRIM key MAXLEN|MINID [=|~] threshold [LIMIT count].
+func XTrim(args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	strategy := strings.ToUpper(args[1].Bulk)
+	if strategy != streams.TRIM_MAXLEN && strategy != streams.TRIM_MINID {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	trim, n, err := parseTrim(args[1:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if n != len(args)-1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: streams.XTrim(args[0].Bulk, *trim)}
+}
+
+// streamRead holds the options of XREAD and XREADGROUP. A count of -1 reads every entry, a
+// timeout of -1 doesn't block and 0 blocks until entries arrive.
+type streamRead struct {
+	count   int64
+	timeout time.Duration
+	noAck   bool
+	keys    []string
+	ids     []string
+}
+
+// parseStreamRead parses [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...],
+// NOACK only being accepted by XREADGROUP. The keys are qualified with the database of the client.
+func parseStreamRead(client *Client, args []resp.Value, group bool) (*streamRead, error) {
+	read := &streamRead{count: -1, timeout: -1}
+	for i := 0; i < len(args); i++ {
+		option := strings.ToUpper(args[i].Bulk)
+		switch {
+		case option == "STREAMS":
+			rest := args[i+1:]
+			if len(rest) == 0 || len(rest)%2 != 0 {
+				return nil, errors.New(common.ERR_STREAM_UNBALANCED)
+			}
+			n := len(rest) / 2
+			for j := range n {
+				read.keys = append(read.keys, common.DBKey(client.DB(), rest[j].Bulk))
+				read.ids = append(read.ids, rest[n+j].Bulk)
+			}
+			return read, nil
+		case option == "NOACK" && group:
+			read.noAck = true
+		case (option == "COUNT" || option == "BLOCK") && i+1 < len(args):
+			n, err := strconv.ParseInt(args[i+1].Bulk, 10, 64)
+			if err != nil {
+				return nil, errors.New(common.ERR_INVALID_INTEGER)
+			}
+			if option == "COUNT" {
+				if n > 0 {
+					read.count = n
+				}
+			} else if n < 0 {
+				return nil, errors.New(common.ERR_TIMEOUT_NEGATIVE)
+			} else {
+				read.timeout = time.Duration(n) * time.Millisecond
+			}
+			i++
+		default:
+			return nil, errors.New(common.ERR_SYNTAX)
+		}
+	}
+	return nil, errors.New(common.ERR_SYNTAX)
+}
+
+// blockOnStreams calls read until it returns a reply. Unless timeout is -1 it waits for the
+// streams stored at keys to change in between, for up to timeout or forever if it is 0.
+// It replies with null if the timeout elapses or the client goes away. The time spent waiting
+// is added to the blocked time of the client.
+func blockOnStreams(client *Client, keys []string, timeout time.Duration, read func() (resp.Value, bool)) resp.Value {
+	if timeout < 0 {
+		if reply, ok := read(); ok {
+			return reply
+		}
+		return resp.Value{Typ: common.NULL_TYPE}
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for {
+		changed, stop := streams.Watch(keys)
+		reply, ok := read()
+		if ok {
+			stop()
+			return reply
+		}
+		waited := time.Now()
+		select {
+		case <-changed:
+		case <-expired:
+			reply, ok = resp.Value{Typ: common.NULL_TYPE}, true
+		case <-client.Done():
+			reply, ok = resp.Value{Typ: common.NULL_TYPE}, true
+		}
+		stop()
+		if client != nil {
+			client.blocked += time.Since(waited)
+		}
+		if ok {
+			return reply
+		}
+	}
+}
+
+// XRead implements XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...].
+// It replies with the entries of each stream with IDs greater than the given one, $ standing
+// for the last entry of the stream, or null if there are none once the BLOCK timeout elapses.
+func XRead(client *Client, args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	read, err := parseStreamRead(client, args, false)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	lastIDs := streams.LastIDs(read.keys)
+	ids := make([]streams.ID, len(read.ids))
+	for i, id := range read.ids {
+		switch id {
+		case "$":
+			ids[i] = lastIDs[i]
+		case ">":
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_XREAD_GROUP_ID}
+		default:
+			if ids[i], err = streams.ParseID(id); err != nil {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+			}
+		}
+	}
+	return blockOnStreams(client, read.keys, read.timeout, func() (resp.Value, bool) {
+		results := streams.XRead(read.keys, ids, read.count)
+		return streamResultsValue(results), len(results) > 0
+	})
+}
+
+// XReadGroup implements XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK]
+// STREAMS key [key ...] id [id ...]. The ID > reads the entries never delivered to the group,
+// any other ID the history of the entries pending for the consumer.
+func XReadGroup(client *Client, args []resp.Value) resp.Value {
+	if len(args) < 6 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	if strings.ToUpper(args[0].Bulk) != "GROUP" {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	group, consumer := args[1].Bulk, args[2].Bulk
+	read, err := parseStreamRead(client, args[3:], true)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	for _, id := range read.ids {
+		if id == "$" {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_XREADGROUP_LAST_ID}
+		}
+	}
+	return blockOnStreams(client, read.keys, read.timeout, func() (resp.Value, bool) {
+		results, err := streams.XReadGroup(group, consumer, read.keys, read.ids, read.count, read.noAck)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}, true
+		}
+		return streamResultsValue(results), len(results) > 0
+	})
+}
+
+// parseEntriesRead parses the value of ENTRIESREAD, -1 standing for an unknown count.
+func parseEntriesRead(arg string) (*int64, error) {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || n < -1 {
+		return nil, errors.New(common.ERR_ENTRIES_READ)
+	}
+	return &n, nil
+}
+
+// XGroup implements XGROUP CREATE key group id|$ [MKSTREAM] [ENTRIESREAD entries-read],
+// SETID key group id|$ [ENTRIESREAD entries-read], DESTROY key group,
+// CREATECONSUMER key group consumer and DELCONSUMER key group consumer.
+func XGroup(args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	key, group := args[1].Bulk, args[2].Bulk
+	subcommand := strings.ToUpper(args[0].Bulk)
+	switch subcommand {
+	case "CREATE", "SETID":
+		if len(args) < 4 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		mkStream := false
+		var entriesRead *int64
+		for i := 4; i < len(args); i++ {
+			switch option := strings.ToUpper(args[i].Bulk); {
+			case option == "MKSTREAM" && subcommand == "CREATE":
+				mkStream = true
+			case option == "ENTRIESREAD" && i+1 < len(args):
+				var err error
+				if entriesRead, err = parseEntriesRead(args[i+1].Bulk); err != nil {
+					return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+				}
+				i++
+			default:
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+		}
+		var err error
+		if subcommand == "CREATE" {
+			err = streams.XGroupCreate(key, group, args[3].Bulk, mkStream, entriesRead)
+		} else {
+			err = streams.XGroupSetID(key, group, args[3].Bulk, entriesRead)
+		}
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+	case "DESTROY":
+		if len(args) != 3 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		destroyed, err := streams.XGroupDestroy(key, group)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: destroyed}
+	case "CREATECONSUMER", "DELCONSUMER":
+		if len(args) != 4 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		var n int64
+		var err error
+		if subcommand == "CREATECONSUMER" {
+			n, err = streams.XGroupCreateConsumer(key, group, args[3].Bulk)
+		} else {
+			n, err = streams.XGroupDelConsumer(key, group, args[3].Bulk)
+		}
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: n}
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+}
+
+// XAck implements XACK key group id [id ...].
+func XAck(args []resp.Value) resp.Value {
+	if len(args) < 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	ids, err := parseStreamIDs(args[2:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: streams.XAck(args[0].Bulk, args[1].Bulk, ids)}
+}
+
+// XPending implements XPENDING key group [[IDLE min-idle-time] start end count [consumer]].
+// Without a range it replies with the number of pending entries, their lowest and highest IDs
+// and the number pending for each consumer. With one it replies with the ID, consumer, idle
+// time and delivery count of each entry.
+func XPending(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	key, group := args[0].Bulk, args[1].Bulk
+	if len(args) == 2 {
+		summary, err := streams.XPendingSummary(key, group)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		if summary.Count == 0 {
+			null := resp.Value{Typ: common.NULL_TYPE}
+			return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{{Typ: common.INTEGER_TYPE, Num: 0}, null, null, null}}
+		}
+		consumers := make([]resp.Value, len(summary.Consumers))
+		for i, c := range summary.Consumers {
+			consumers[i] = bulkArray(c.Name, strconv.FormatInt(c.Count, 10))
+		}
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+			{Typ: common.INTEGER_TYPE, Num: summary.Count},
+			{Typ: common.BULK_TYPE, Bulk: summary.Smallest.String()},
+			{Typ: common.BULK_TYPE, Bulk: summary.Largest.String()},
+			{Typ: common.ARRAY_TYPE, Array: consumers},
+		}}
+	}
+	rest := args[2:]
+	var minIdle int64
+	if strings.ToUpper(rest[0].Bulk) == "IDLE" {
+		if len(rest) < 2 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		var err error
+		if minIdle, err = strconv.ParseInt(rest[1].Bulk, 10, 64); err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+		}
+		rest = rest[2:]
+	}
+	if len(rest) != 3 && len(rest) != 4 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+	count, err := strconv.ParseInt(rest[2].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	consumer := ""
+	if len(rest) == 4 {
+		consumer = rest[3].Bulk
+	}
+	start, end, ok, err := streams.ParseRange(rest[0].Bulk, rest[1].Bulk)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !ok {
+		count = 0
+	}
+	pending, err := streams.XPending(key, group, start, end, count, consumer, minIdle)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	values := make([]resp.Value, len(pending))
+	for i, p := range pending {
+		values[i] = resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+			{Typ: common.BULK_TYPE, Bulk: p.ID.String()},
+			{Typ: common.BULK_TYPE, Bulk: p.Consumer},
+			{Typ: common.INTEGER_TYPE, Num: p.Idle},
+			{Typ: common.INTEGER_TYPE, Num: p.Deliveries},
+		}}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+}
+
+// claimedValue replies with the claimed entries, or only their IDs with JUSTID.
+func claimedValue(entries []streams.Entry, justID bool) resp.Value {
+	if !justID {
+		return streamEntriesValue(entries)
+	}
+	ids := make([]streams.ID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return streamIDsValue(ids)
+}
+
+// XClaim implements XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms]
+// [TIME unix-time-milliseconds] [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID lastid].
+func XClaim(args []resp.Value) resp.Value {
+	if len(args) < 5 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	minIdle, err := strconv.ParseInt(args[3].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	ids := []streams.ID{}
+	i := 4
+	for ; i < len(args); i++ {
+		id, err := streams.ParseID(args[i].Bulk)
+		if err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_STREAM_ID_INVALID}
+	}
+	opts := streams.ClaimOptions{RetryCount: -1}
+	for ; i < len(args); i++ {
+		option := strings.ToUpper(args[i].Bulk)
+		switch option {
+		case "FORCE":
+			opts.Force = true
+			continue
+		case "JUSTID":
+			opts.JustID = true
+			continue
+		case "IDLE", "TIME", "RETRYCOUNT", "LASTID":
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		if i+1 >= len(args) {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		i++
+		if option == "LASTID" {
+			if opts.LastID, err = streams.ParseID(args[i].Bulk); err != nil {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+			}
+			continue
+		}
+		n, err := strconv.ParseInt(args[i].Bulk, 10, 64)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+		}
+		switch option {
+		case "IDLE":
+			opts.DeliveredAt = time.Now().UnixMilli() - max(n, 0)
+		case "TIME":
+			opts.DeliveredAt = max(n, 1)
+		case "RETRYCOUNT":
+			opts.RetryCount = max(n, 0)
+		}
+	}
+	claimed, err := streams.XClaim(args[0].Bulk, args[1].Bulk, args[2].Bulk, max(minIdle, 0), ids, opts)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return claimedValue(claimed, opts.JustID)
+}
+
+// XAutoClaim implements XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID].
+// It replies with the ID to start the next call from, the claimed entries and the IDs of the
+// entries deleted from the stream.
+func XAutoClaim(args []resp.Value) resp.Value {
+	if len(args) < 5 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	minIdle, err := strconv.ParseInt(args[3].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	start, _, ok, err := streams.ParseRange(args[4].Bulk, "+")
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	if !ok {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_STREAM_INTERVAL}
+	}
+	count, justID := int64(100), false
+	for i := 5; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "JUSTID":
+			justID = true
+		case "COUNT":
+			if i+1 >= len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			if count, err = strconv.ParseInt(args[i+1].Bulk, 10, 64); err != nil {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+			}
+			if count <= 0 {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_COUNT_NOT_POSITIVE}
+			}
+			i++
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	next, claimed, deleted, err := streams.XAutoClaim(args[0].Bulk, args[1].Bulk, args[2].Bulk, max(minIdle, 0), start, count, justID)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: next.String()},
+		claimedValue(claimed, justID),
+		streamIDsValue(deleted),
+	}}
+}
+
+// XInfo implements XINFO STREAM key, XINFO GROUPS key and XINFO CONSUMERS key group.
+func XInfo(args []resp.Value) resp.Value {
+	if len(args) < 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	key := args[1].Bulk
+	switch strings.ToUpper(args[0].Bulk) {
+	case "STREAM":
+		if len(args) != 2 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		info, err := streams.XInfoStream(key)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		first, last := resp.Value{Typ: common.NULL_TYPE}, resp.Value{Typ: common.NULL_TYPE}
+		if info.First != nil {
+			first, last = streamEntryValue(*info.First), streamEntryValue(*info.Last)
+		}
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+			{Typ: common.BULK_TYPE, Bulk: "length"}, {Typ: common.INTEGER_TYPE, Num: info.Length},
+			{Typ: common.BULK_TYPE, Bulk: "last-generated-id"}, {Typ: common.BULK_TYPE, Bulk: info.LastID.String()},
+			{Typ: common.BULK_TYPE, Bulk: "max-deleted-entry-id"}, {Typ: common.BULK_TYPE, Bulk: info.MaxDeletedID.String()},
+			{Typ: common.BULK_TYPE, Bulk: "entries-added"}, {Typ: common.INTEGER_TYPE, Num: info.EntriesAdded},
+			{Typ: common.BULK_TYPE, Bulk: "recorded-first-entry-id"}, {Typ: common.BULK_TYPE, Bulk: info.FirstID.String()},
+			{Typ: common.BULK_TYPE, Bulk: "groups"}, {Typ: common.INTEGER_TYPE, Num: info.Groups},
+			{Typ: common.BULK_TYPE, Bulk: "first-entry"}, first,
+			{Typ: common.BULK_TYPE, Bulk: "last-entry"}, last,
+		}}
+	case "GROUPS":
+		if len(args) != 2 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+		groups, err := streams.XInfoGroups(key)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		values := make([]resp.Value, len(groups))
+		for i, g := range groups {
+			entriesRead, lag := resp.Value{Typ: common.NULL_TYPE}, resp.Value{Typ: common.NULL_TYPE}
+			if g.EntriesRead >= 0 {
+				entriesRead = resp.Value{Typ: common.INTEGER_TYPE, Num: g.EntriesRead}
+			}
+			if g.HasLag {
+				lag = resp.Value{Typ: common.INTEGER_TYPE, Num: g.Lag}
+			}
+			values[i] = resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+				{Typ: common.BULK_TYPE, Bulk: "name"}, {Typ: common.BULK_TYPE, Bulk: g.Name},
+				{Typ: common.BULK_TYPE, Bulk: "consumers"}, {Typ: common.INTEGER_TYPE, Num: g.Consumers},
+				{Typ: common.BULK_TYPE, Bulk: "pending"}, {Typ: common.INTEGER_TYPE, Num: g.Pending},
+				{Typ: common.BULK_TYPE, Bulk: "last-delivered-id"}, {Typ: common.BULK_TYPE, Bulk: g.LastID.String()},
+				{Typ: common.BULK_TYPE, Bulk: "entries-read"}, entriesRead,
+				{Typ: common.BULK_TYPE, Bulk: "lag"}, lag,
+			}}
+		}
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+	case "CONSUMERS":
+		if len(args) != 3 {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+		}
+		consumers, err := streams.XInfoConsumers(key, args[2].Bulk)
+		if err != nil {
+			return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+		}
+		values := make([]resp.Value, len(consumers))
+		for i, c := range consumers {
+			values[i] = resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+				{Typ: common.BULK_TYPE, Bulk: "name"}, {Typ: common.BULK_TYPE, Bulk: c.Name},
+				{Typ: common.BULK_TYPE, Bulk: "pending"}, {Typ: common.INTEGER_TYPE, Num: c.Pending},
+				{Typ: common.BULK_TYPE, Bulk: "idle"}, {Typ: common.INTEGER_TYPE, Num: c.Idle},
+				{Typ: common.BULK_TYPE, Bulk: "inactive"}, {Typ: common.INTEGER_TYPE, Num: c.Inactive},
+			}}
+		}
+		return resp.Value{Typ: common.ARRAY_TYPE, Array: values}
+	default:
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+	}
+}
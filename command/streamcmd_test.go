@@ -0,0 +1,219 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/resp"
+)
+
+// entryIDs returns the IDs of the entries of an XRANGE or XREAD reply.
+func entryIDs(entries resp.Value) []string {
+	ids := []string{}
+	for _, entry := range entries.Array {
+		ids = append(ids, entry.Array[0].Bulk)
+	}
+	return ids
+}
+
+func expectIDs(t *testing.T, entries resp.Value, expected ...string) {
+	t.Helper()
+	ids := entryIDs(entries)
+	if len(ids) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, ids)
+		}
+	}
+}
+
+func TestXAddAndRange(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestXAddAndRange"
+	for _, id := range []string{"1", "2", "3-1", "3-*"} {
+		if result := run(client, "XADD", key, id, "field", "value"); result.Typ != common.BULK_TYPE {
+			t.Fatalf("expected an ID, got %v", result)
+		}
+	}
+	if result := run(client, "XADD", key, "MAXLEN", "~", "3", "LIMIT", "1", "4", "field", "value"); result.Bulk != "4-0" {
+		t.Errorf("expected 4-0, got %v", result)
+	}
+	if result := run(client, "XLEN", key); result.Num != 4 {
+		t.Errorf("expected 4 entries after trimming 1, got %v", result)
+	}
+	expectIDs(t, run(client, "XRANGE", key, "-", "+"), "2-0", "3-1", "3-2", "4-0")
+	expectIDs(t, run(client, "XRANGE", key, "(2", "3", "COUNT", "1"), "3-1")
+	expectIDs(t, run(client, "XREVRANGE", key, "+", "3", "COUNT", "2"), "4-0", "3-2")
+	if result := run(client, "XRANGE", key, "-", "+"); result.Array[0].Array[1].Array[0].Bulk != "field" {
+		t.Errorf("expected the fields of the entry, got %v", result)
+	}
+	if result := run(client, "XDEL", key, "3-1", "9"); result.Num != 1 {
+		t.Errorf("expected 1 deleted entry, got %v", result)
+	}
+	if result := run(client, "XTRIM", key, "MINID", "4"); result.Num != 2 {
+		t.Errorf("expected 2 trimmed entries, got %v", result)
+	}
+
+	for _, args := range [][]string{
+		{"XADD", key, "MAXLEN", "2", "LIMIT", "1", "*", "f", "v"},
+		{"XADD", key, "*", "f"},
+		{"XADD", key, "1", "f", "v"},
+		{"XADD", key, "MAXLEN", "-1", "*", "f", "v"},
+		{"XRANGE", key, "x", "+"},
+		{"XTRIM", key, "SIZE", "1"},
+	} {
+		if result := run(client, args...); result.Typ != common.ERROR_TYPE {
+			t.Errorf("expected an error for %v, got %v", args, result)
+		}
+	}
+	if result := run(client, "XADD", "TestXAddAndRangeMissing", "NOMKSTREAM", "*", "f", "v"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null, got %v", result)
+	}
+}
+
+func TestXRead_Blocks(t *testing.T) {
+	client, writer := newTestClient(t), newTestClient(t)
+	run(client, "SELECT", "12")
+	run(writer, "SELECT", "12")
+	key := "TestXRead_Blocks"
+	run(writer, "XADD", key, "1", "f", "v")
+
+	result := run(client, "XREAD", "COUNT", "10", "STREAMS", key, "0")
+	if len(result.Array) != 1 || result.Array[0].Array[0].Bulk != key {
+		t.Fatalf("expected the entries of %s, got %v", key, result)
+	}
+	expectIDs(t, result.Array[0].Array[1], "1-0")
+
+	start := time.Now()
+	if result := run(client, "XREAD", "BLOCK", "50", "STREAMS", key, "$"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null once the timeout elapses, got %v", result)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Errorf("expected XREAD to block for the timeout")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		run(writer, "XADD", key, "2", "f", "v")
+	}()
+	result = run(client, "XREAD", "BLOCK", "0", "STREAMS", key, "$")
+	if len(result.Array) != 1 {
+		t.Fatalf("expected the entry added while blocked, got %v", result)
+	}
+	expectIDs(t, result.Array[0].Array[1], "2-0")
+
+	for _, args := range [][]string{
+		{"XREAD", "STREAMS", key},
+		{"XREAD", "STREAMS", key, ">"},
+		{"XREAD", "BLOCK", "-1", "STREAMS", key, "0"},
+		{"XREAD", "NOACK", "STREAMS", key, "0"},
+	} {
+		if result := run(client, args...); result.Typ != common.ERROR_TYPE {
+			t.Errorf("expected an error for %v, got %v", args, result)
+		}
+	}
+}
+
+func TestXRead_BlockEndsWithClient(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestXRead_BlockEndsWithClient"
+	SlowLog(bulkArgs("RESET"))
+	var usec int64
+	if stat, ok := commandStats.Load("XREAD"); ok {
+		usec = stat.(*commandStat).usec.Load()
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		client.Hangup()
+	}()
+	if result := run(client, "XREAD", "BLOCK", "0", "STREAMS", key, "$"); result.Typ != common.NULL_TYPE {
+		t.Errorf("expected null once the client goes away, got %v", result)
+	}
+	if result := SlowLog(bulkArgs("LEN")); result.Num != 0 {
+		t.Errorf("expected the time spent blocked to stay out of the slow log, got %v entries", result.Num)
+	}
+	stat, _ := commandStats.Load("XREAD")
+	if spent := stat.(*commandStat).usec.Load() - usec; spent >= 50000 {
+		t.Errorf("expected the time spent blocked to stay out of the command stats, got %dus", spent)
+	}
+}
+
+func TestXReadGroup_Commands(t *testing.T) {
+	client := newTestClient(t)
+	key := "TestXReadGroup_Commands"
+	if result := run(client, "XGROUP", "CREATE", key, "g", "$"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error without MKSTREAM, got %v", result)
+	}
+	if result := run(client, "XGROUP", "CREATE", key, "g", "$", "MKSTREAM"); result.Str != "OK" {
+		t.Fatalf("expected OK, got %v", result)
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		run(client, "XADD", key, id, "f", "v")
+	}
+
+	result := run(client, "XREADGROUP", "GROUP", "g", "alice", "COUNT", "2", "STREAMS", key, ">")
+	expectIDs(t, result.Array[0].Array[1], "1-0", "2-0")
+	if result := run(client, "XREADGROUP", "GROUP", "g", "alice", "STREAMS", key, "$"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error for $, got %v", result)
+	}
+
+	summary := run(client, "XPENDING", key, "g")
+	if summary.Array[0].Num != 2 || summary.Array[1].Bulk != "1-0" || summary.Array[2].Bulk != "2-0" ||
+		summary.Array[3].Array[0].Array[0].Bulk != "alice" || summary.Array[3].Array[0].Array[1].Bulk != "2" {
+		t.Errorf("unexpected XPENDING summary %v", summary)
+	}
+	pending := run(client, "XPENDING", key, "g", "IDLE", "0", "-", "+", "10", "alice")
+	if len(pending.Array) != 2 || pending.Array[0].Array[1].Bulk != "alice" || pending.Array[0].Array[3].Num != 1 {
+		t.Errorf("unexpected XPENDING entries %v", pending)
+	}
+
+	claimed := run(client, "XCLAIM", key, "g", "bob", "0", "1", "JUSTID")
+	if len(claimed.Array) != 1 || claimed.Array[0].Bulk != "1-0" {
+		t.Errorf("expected the ID of the claimed entry, got %v", claimed)
+	}
+	autoclaimed := run(client, "XAUTOCLAIM", key, "g", "bob", "0", "0", "COUNT", "10")
+	if autoclaimed.Array[0].Bulk != "0-0" || len(autoclaimed.Array[1].Array) != 2 || len(autoclaimed.Array[2].Array) != 0 {
+		t.Errorf("unexpected XAUTOCLAIM reply %v", autoclaimed)
+	}
+	if result := run(client, "XACK", key, "g", "1", "2"); result.Num != 2 {
+		t.Errorf("expected 2 acknowledged entries, got %v", result)
+	}
+
+	info := run(client, "XINFO", "STREAM", key)
+	if info.Array[0].Bulk != "length" || info.Array[1].Num != 3 || info.Array[11].Num != 1 {
+		t.Errorf("unexpected XINFO STREAM reply %v", info)
+	}
+	groups := run(client, "XINFO", "GROUPS", key)
+	if len(groups.Array) != 1 || groups.Array[0].Array[1].Bulk != "g" || groups.Array[0].Array[7].Bulk != "2-0" || groups.Array[0].Array[11].Num != 1 {
+		t.Errorf("unexpected XINFO GROUPS reply %v", groups)
+	}
+	consumers := run(client, "XINFO", "CONSUMERS", key, "g")
+	if len(consumers.Array) != 2 || consumers.Array[1].Array[1].Bulk != "bob" || consumers.Array[1].Array[3].Num != 0 {
+		t.Errorf("unexpected XINFO CONSUMERS reply %v", consumers)
+	}
+	if result := run(client, "XGROUP", "DELCONSUMER", key, "g", "alice"); result.Num != 0 {
+		t.Errorf("expected no entry pending for alice, got %v", result)
+	}
+	if result := run(client, "XGROUP", "SETID", key, "g", "0", "ENTRIESREAD", "-2"); result.Typ != common.ERROR_TYPE {
+		t.Errorf("expected an error for ENTRIESREAD -2, got %v", result)
+	}
+}
+
+func TestXReadGroup_BlockedUntilGroupDestroyed(t *testing.T) {
+	client, other := newTestClient(t), newTestClient(t)
+	key := "TestXReadGroup_BlockedUntilGroupDestroyed"
+	run(client, "XGROUP", "CREATE", key, "g", "$", "MKSTREAM")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		run(other, "XGROUP", "DESTROY", key, "g")
+	}()
+	result := run(client, "XREADGROUP", "GROUP", "g", "c", "BLOCK", "5000", "STREAMS", key, ">")
+	if result.Typ != common.ERROR_TYPE || result.Str != common.ERR_NO_GROUP {
+		t.Errorf("expected %s, got %v", common.ERR_NO_GROUP, result)
+	}
+}
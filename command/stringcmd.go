@@ -1,16 +1,22 @@
 package command
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
-	"github.com/divy-sh/animus/types/strings"
+	typestrings "github.com/divy-sh/animus/types/strings"
 )
 
 func Append(args []resp.Value) resp.Value {
 	if len(args) != 2 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	strings.Append(args[0].Bulk, args[1].Bulk)
+	typestrings.Append(args[0].Bulk, args[1].Bulk)
 	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
 }
 
@@ -19,30 +25,29 @@ func DecrBy(args []resp.Value) resp.Value {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	err := strings.DecrBy(args[0].Bulk, args[1].Bulk)
+	value, err := typestrings.DecrBy(args[0].Bulk, args[1].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
-
-	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: value}
 }
 
 func Decr(args []resp.Value) resp.Value {
 	if len(args) != 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	err := strings.Decr(args[0].Bulk)
+	value, err := typestrings.Decr(args[0].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
-	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: value}
 }
 
 func Get(args []resp.Value) resp.Value {
 	if len(args) != 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	value, err := strings.Get(args[0].Bulk)
+	value, err := typestrings.Get(args[0].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -53,29 +58,76 @@ func GetDel(args []resp.Value) resp.Value {
 	if len(args) != 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	value, err := strings.GetDel(args[0].Bulk)
+	value, err := typestrings.GetDel(args[0].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.NULL_TYPE}
 	}
 	return resp.Value{Typ: common.BULK_TYPE, Bulk: value}
 }
 
+// GetEx implements GETEX key [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|PERSIST].
+// Expiries are kept in seconds, so millisecond ones are rounded up.
 func GetEx(args []resp.Value) resp.Value {
-	if len(args) != 2 {
+	if len(args) < 1 || len(args) > 3 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	value, err := strings.GetEx(args[0].Bulk, args[1].Bulk)
+	expireAt, err := parseGetExExpiry(args[1:])
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	value, err := typestrings.GetEx(args[0].Bulk, expireAt)
 	if err != nil {
 		return resp.Value{Typ: common.NULL_TYPE}
 	}
 	return resp.Value{Typ: common.BULK_TYPE, Bulk: value}
 }
 
+// parseGetExExpiry returns the unix time in seconds the options of GETEX set the key to expire at,
+// -1 for PERSIST and 0 without any option.
+func parseGetExExpiry(args []resp.Value) (int64, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+	option := strings.ToUpper(args[0].Bulk)
+	if option == "PERSIST" {
+		if len(args) != 1 {
+			return 0, errors.New(common.ERR_SYNTAX)
+		}
+		return -1, nil
+	}
+	if len(args) != 2 {
+		return 0, errors.New(common.ERR_SYNTAX)
+	}
+	n, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return 0, errors.New(common.ERR_INVALID_INTEGER)
+	}
+	nowMillis := time.Now().UnixMilli()
+	var unit, base int64
+	switch option {
+	case "EX":
+		unit, base = 1000, nowMillis
+	case "PX":
+		unit, base = 1, nowMillis
+	case "EXAT":
+		unit = 1000
+	case "PXAT":
+		unit = 1
+	default:
+		return 0, errors.New(common.ERR_SYNTAX)
+	}
+	// Keep the expiry time in milliseconds from overflowing.
+	if n <= 0 || n > (math.MaxInt64-base)/unit {
+		return 0, errors.New(common.ERR_INVALID_EXPIRE_TIME)
+	}
+	return (base + n*unit + 999) / 1000, nil
+}
+
 func GetRange(args []resp.Value) resp.Value {
 	if len(args) != 3 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	value, err := strings.GetRange(args[0].Bulk, args[1].Bulk, args[2].Bulk)
+	value, err := typestrings.GetRange(args[0].Bulk, args[1].Bulk, args[2].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.NULL_TYPE}
 	}
@@ -87,7 +139,7 @@ func GetSet(args []resp.Value) resp.Value {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	val, err := strings.GetSet(args[0].Bulk, args[1].Bulk)
+	val, err := typestrings.GetSet(args[0].Bulk, args[1].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -99,12 +151,11 @@ func IncrBy(args []resp.Value) resp.Value {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	err := strings.IncrBy(args[0].Bulk, args[1].Bulk)
+	value, err := typestrings.IncrBy(args[0].Bulk, args[1].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
-
-	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: value}
 }
 
 func IncrByFloat(args []resp.Value) resp.Value {
@@ -112,7 +163,7 @@ func IncrByFloat(args []resp.Value) resp.Value {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	err := strings.IncrByFloat(args[0].Bulk, args[1].Bulk)
+	err := typestrings.IncrByFloat(args[0].Bulk, args[1].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -124,28 +175,82 @@ func Incr(args []resp.Value) resp.Value {
 	if len(args) != 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
-	err := strings.Incr(args[0].Bulk)
+	value, err := typestrings.Incr(args[0].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
-	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: value}
 }
 
+// LCS implements LCS key1 key2 [LEN] [IDX] [MINMATCHLEN len] [WITHMATCHLEN]. With IDX it replies
+// with the ranges of the matching substrings and the length of the longest common subsequence.
 func LCS(args []resp.Value) resp.Value {
 	if len(args) < 2 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
+	var withLen, idx, withMatchLen bool
+	var minMatchLen int64
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i].Bulk) {
+		case "LEN":
+			withLen = true
+		case "IDX":
+			idx = true
+		case "WITHMATCHLEN":
+			withMatchLen = true
+		case "MINMATCHLEN":
+			if i+1 >= len(args) {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+			}
+			n, err := strconv.ParseInt(args[i+1].Bulk, 10, 64)
+			if err != nil {
+				return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+			}
+			minMatchLen = max(n, 0)
+			i++
+		default:
+			return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_SYNTAX}
+		}
+	}
+	if withLen && idx {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_LCS_LEN_AND_IDX}
+	}
+	if idx {
+		return lcsIdx(args[0].Bulk, args[1].Bulk, minMatchLen, withMatchLen)
+	}
 	commands := []string{}
-	for _, arg := range args[2:] {
-		commands = append(commands, arg.Bulk)
+	if withLen {
+		commands = append(commands, "LEN")
 	}
-	val, err := strings.Lcs(args[0].Bulk, args[1].Bulk, commands)
+	val, err := typestrings.Lcs(args[0].Bulk, args[1].Bulk, commands)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
 	return resp.Value{Typ: common.BULK_TYPE, Bulk: val}
 }
 
+// lcsIdx replies to LCS IDX with ["matches", [[[start1, end1], [start2, end2], (len)] ...], "len", len].
+func lcsIdx(key1, key2 string, minMatchLen int64, withMatchLen bool) resp.Value {
+	matches, lcsLen, err := typestrings.LcsIdx(key1, key2, minMatchLen)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
+	}
+	values := make([]resp.Value, len(matches))
+	for i, match := range matches {
+		ranges := []resp.Value{integerArray(match.A[:]), integerArray(match.B[:])}
+		if withMatchLen {
+			ranges = append(ranges, resp.Value{Typ: common.INTEGER_TYPE, Num: match.Len})
+		}
+		values[i] = resp.Value{Typ: common.ARRAY_TYPE, Array: ranges}
+	}
+	return resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "matches"},
+		{Typ: common.ARRAY_TYPE, Array: values},
+		{Typ: common.BULK_TYPE, Bulk: "len"},
+		{Typ: common.INTEGER_TYPE, Num: lcsLen},
+	}}
+}
+
 func MGet(args []resp.Value) resp.Value {
 	if len(args) < 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
@@ -154,7 +259,7 @@ func MGet(args []resp.Value) resp.Value {
 	for _, arg := range args {
 		keys = append(keys, arg.Bulk)
 	}
-	values := strings.MGet(&keys)
+	values := typestrings.MGet(&keys)
 	response := make([]resp.Value, len(keys))
 	for i, val := range *values {
 		if val == "" {
@@ -174,16 +279,32 @@ func MSet(args []resp.Value) resp.Value {
 	for i := 0; i < len(args); i += 2 {
 		kvPairs[args[i].Bulk] = args[i+1].Bulk
 	}
-	strings.MSet(&kvPairs)
+	typestrings.MSet(&kvPairs)
 	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
 }
 
+// MSetNx implements MSETNX key value [key value ...], it replies with 1 if all the keys were set
+// and 0 if none was because one of them exists.
+func MSetNx(args []resp.Value) resp.Value {
+	if len(args) < 2 || len(args)&1 == 1 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	kvPairs := map[string]string{}
+	for i := 0; i < len(args); i += 2 {
+		kvPairs[args[i].Bulk] = args[i+1].Bulk
+	}
+	if typestrings.MSetNx(&kvPairs) {
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: 1}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: 0}
+}
+
 func Set(args []resp.Value) resp.Value {
 	if len(args) != 2 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	strings.Set(args[0].Bulk, args[1].Bulk)
+	typestrings.Set(args[0].Bulk, args[1].Bulk)
 	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
 }
 
@@ -192,31 +313,58 @@ func SetEx(args []resp.Value) resp.Value {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	err := strings.SetEx(args[0].Bulk, args[1].Bulk, args[2].Bulk)
+	err := typestrings.SetEx(args[0].Bulk, args[1].Bulk, args[2].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
 	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
 }
 
+// SetNx implements SETNX key value, it replies with 1 if the key was set and 0 if it exists.
+func SetNx(args []resp.Value) resp.Value {
+	if len(args) != 2 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	if typestrings.SetNx(args[0].Bulk, args[1].Bulk) {
+		return resp.Value{Typ: common.INTEGER_TYPE, Num: 1}
+	}
+	return resp.Value{Typ: common.INTEGER_TYPE, Num: 0}
+}
+
 func SetRange(args []resp.Value) resp.Value {
 	if len(args) != 3 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	err := strings.SetRange(args[0].Bulk, args[1].Bulk, args[2].Bulk)
+	err := typestrings.SetRange(args[0].Bulk, args[1].Bulk, args[2].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
 	return resp.Value{Typ: common.STRING_TYPE, Str: "OK"}
 }
 
+// Substr implements SUBSTR key start end, the range being clamped to the length of the string.
+func Substr(args []resp.Value) resp.Value {
+	if len(args) != 3 {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
+	}
+	start, err := strconv.ParseInt(args[1].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	end, err := strconv.ParseInt(args[2].Bulk, 10, 64)
+	if err != nil {
+		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_INVALID_INTEGER}
+	}
+	return resp.Value{Typ: common.BULK_TYPE, Bulk: typestrings.Substr(args[0].Bulk, start, end)}
+}
+
 func StrLen(args []resp.Value) resp.Value {
 	if len(args) != 1 {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: common.ERR_WRONG_ARGUMENT_COUNT}
 	}
 
-	length, err := strings.StrLen(args[0].Bulk)
+	length, err := typestrings.StrLen(args[0].Bulk)
 	if err != nil {
 		return resp.Value{Typ: common.ERROR_TYPE, Str: err.Error()}
 	}
@@ -1,7 +1,9 @@
 package command
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
@@ -31,8 +33,8 @@ func TestAppendInvalidArgumentCount(t *testing.T) {
 func TestDecr(t *testing.T) {
 	args := []resp.Value{{Typ: common.BULK_TYPE, Bulk: "counter"}}
 	result := Decr(args)
-	if result.Typ != common.STRING_TYPE || result.Str != "OK" {
-		t.Errorf("Expected OK, got %v", result)
+	if result.Typ != common.INTEGER_TYPE {
+		t.Errorf("Expected integer, got %v", result)
 	}
 }
 
@@ -62,8 +64,8 @@ func TestDecrBy(t *testing.T) {
 		{Typ: common.BULK_TYPE, Bulk: "counter"},
 		{Typ: common.BULK_TYPE, Bulk: "5"}}
 	result := DecrBy(args)
-	if result.Typ != common.STRING_TYPE || result.Str != "OK" {
-		t.Errorf("Expected OK, got %v", result)
+	if result.Typ != common.INTEGER_TYPE {
+		t.Errorf("Expected integer, got %v", result)
 	}
 }
 
@@ -150,6 +152,7 @@ func TestGetEx(t *testing.T) {
 		{Typ: common.BULK_TYPE, Bulk: "value"}})
 	args := []resp.Value{
 		{Typ: common.BULK_TYPE, Bulk: "key"},
+		{Typ: common.BULK_TYPE, Bulk: "EX"},
 		{Typ: common.BULK_TYPE, Bulk: "10"}}
 	result := GetEx(args)
 	if result.Typ != common.BULK_TYPE {
@@ -157,10 +160,62 @@ func TestGetEx(t *testing.T) {
 	}
 }
 
+func TestGetExOptions(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestGetExOptions", "value")
+
+	if result := run(client, "GETEX", "TestGetExOptions", "PX", "1500"); result.Bulk != "value" {
+		t.Fatalf("Expected value, got %v", result)
+	}
+	if result := run(client, "TTL", "TestGetExOptions"); result.Num < 2 || result.Num > 3 {
+		t.Errorf("Expected the expiry rounded up to the next second, got %v", result)
+	}
+	run(client, "GETEX", "TestGetExOptions", "PERSIST")
+	if result := run(client, "TTL", "TestGetExOptions"); result.Num != -1 {
+		t.Errorf("Expected no expiry, got %v", result)
+	}
+	run(client, "GETEX", "TestGetExOptions", "EXAT", strconv.FormatInt(time.Now().Unix()+100, 10))
+	if result := run(client, "TTL", "TestGetExOptions"); result.Num < 99 || result.Num > 100 {
+		t.Errorf("Expected a ttl of 100 seconds, got %v", result)
+	}
+	run(client, "GETEX", "TestGetExOptions")
+	if result := run(client, "TTL", "TestGetExOptions"); result.Num < 99 {
+		t.Errorf("Expected the expiry to be kept, got %v", result)
+	}
+	if result := run(client, "GETEX", "TestGetExOptions", "PXAT", "1"); result.Bulk != "value" {
+		t.Errorf("Expected value, got %v", result)
+	}
+	if result := run(client, "EXISTS", "TestGetExOptions"); result.Num != 0 {
+		t.Errorf("Expected an expiry in the past to delete the key, got %v", result)
+	}
+}
+
+func TestGetExInvalidOptions(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestGetExInvalidOptions", "value")
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"EX", "0"}, common.ERR_INVALID_EXPIRE_TIME},
+		{[]string{"PX", "-5"}, common.ERR_INVALID_EXPIRE_TIME},
+		{[]string{"EX", "9223372036854775807"}, common.ERR_INVALID_EXPIRE_TIME},
+		{[]string{"EX", "ten"}, common.ERR_INVALID_INTEGER},
+		{[]string{"EX"}, common.ERR_SYNTAX},
+		{[]string{"PERSIST", "1"}, common.ERR_SYNTAX},
+		{[]string{"KEEPTTL", "1"}, common.ERR_SYNTAX},
+	}
+	for _, tt := range tests {
+		args := append([]string{"GETEX", "TestGetExInvalidOptions"}, tt.args...)
+		if result := run(client, args...); result.Typ != common.ERROR_TYPE || result.Str != tt.expected {
+			t.Errorf("%v: expected %s, got %v", tt.args, tt.expected, result)
+		}
+	}
+}
+
 func TestGetExNonExistingKey(t *testing.T) {
-	args := []resp.Value{
-		{Typ: common.BULK_TYPE, Bulk: "non_existing"},
-		{Typ: common.BULK_TYPE, Bulk: "key"}}
+	args := []resp.Value{{Typ: common.BULK_TYPE, Bulk: "non_existing"}}
 	result := GetEx(args)
 	if result.Typ != "null" {
 		t.Errorf("Expected null, got %v", result)
@@ -168,7 +223,7 @@ func TestGetExNonExistingKey(t *testing.T) {
 }
 
 func TestGetExInvalidArgsCount(t *testing.T) {
-	args := []resp.Value{{Typ: common.BULK_TYPE, Bulk: "key"}}
+	args := []resp.Value{}
 	result := GetEx(args)
 	if result.Typ != "error" || result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
 		t.Errorf("Expected ERR wrong number of arguments for 'GetEx' command, got %v", result)
@@ -265,8 +320,8 @@ func TestSetInvalidArgsCount(t *testing.T) {
 func TestIncr(t *testing.T) {
 	args := []resp.Value{{Typ: common.BULK_TYPE, Bulk: "counter"}}
 	result := Incr(args)
-	if result.Typ != common.STRING_TYPE || result.Str != "OK" {
-		t.Errorf("Expected OK, got %v", result)
+	if result.Typ != common.INTEGER_TYPE {
+		t.Errorf("Expected integer, got %v", result)
 	}
 }
 
@@ -296,8 +351,8 @@ func TestIncrBy(t *testing.T) {
 		{Typ: common.BULK_TYPE, Bulk: "counter"},
 		{Typ: common.BULK_TYPE, Bulk: "5"}}
 	result := IncrBy(args)
-	if result.Typ != common.STRING_TYPE || result.Str != "OK" {
-		t.Errorf("Expected OK, got %v", result)
+	if result.Typ != common.INTEGER_TYPE {
+		t.Errorf("Expected integer, got %v", result)
 	}
 }
 
@@ -421,6 +476,61 @@ func TestLcsInvalidArguments(t *testing.T) {
 	}
 }
 
+func TestIncrDecrReplies(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestIncrDecrReplies", "10")
+
+	if result := run(client, "INCR", "TestIncrDecrReplies"); result.Typ != common.INTEGER_TYPE || result.Num != 11 {
+		t.Errorf("Expected 11, got %v", result)
+	}
+	if result := run(client, "INCRBY", "TestIncrDecrReplies", "9"); result.Num != 20 {
+		t.Errorf("Expected 20, got %v", result)
+	}
+	if result := run(client, "DECR", "TestIncrDecrReplies"); result.Num != 19 {
+		t.Errorf("Expected 19, got %v", result)
+	}
+	if result := run(client, "DECRBY", "TestIncrDecrReplies", "20"); result.Num != -1 {
+		t.Errorf("Expected -1, got %v", result)
+	}
+	run(client, "SET", "TestIncrDecrReplies", "9223372036854775807")
+	if result := run(client, "INCR", "TestIncrDecrReplies"); result.Str != common.ERR_INCREMENT_OVERFLOW {
+		t.Errorf("Expected %s, got %v", common.ERR_INCREMENT_OVERFLOW, result)
+	}
+}
+
+func TestLcsIdx(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "MSET", "TestLcsIdx1", "ohmytext", "TestLcsIdx2", "mynewtext")
+
+	expected := resp.Value{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+		{Typ: common.BULK_TYPE, Bulk: "matches"},
+		{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+			{Typ: common.ARRAY_TYPE, Array: []resp.Value{
+				integerArray([]int64{4, 7}),
+				integerArray([]int64{5, 8}),
+				{Typ: common.INTEGER_TYPE, Num: 4},
+			}},
+		}},
+		{Typ: common.BULK_TYPE, Bulk: "len"},
+		{Typ: common.INTEGER_TYPE, Num: 6},
+	}}
+	result := run(client, "LCS", "TestLcsIdx1", "TestLcsIdx2", "IDX", "MINMATCHLEN", "4", "WITHMATCHLEN")
+	if string(result.Marshal()) != string(expected.Marshal()) {
+		t.Errorf("Expected %q, got %q", expected.Marshal(), result.Marshal())
+	}
+
+	result = run(client, "LCS", "TestLcsIdx1", "TestLcsIdx2", "IDX")
+	if len(result.Array) != 4 || len(result.Array[1].Array) != 2 || len(result.Array[1].Array[1].Array) != 2 {
+		t.Errorf("Expected two matches without their length, got %v", result)
+	}
+	if result := run(client, "LCS", "TestLcsIdx1", "TestLcsIdx2", "IDX", "LEN"); result.Str != common.ERR_LCS_LEN_AND_IDX {
+		t.Errorf("Expected %s, got %v", common.ERR_LCS_LEN_AND_IDX, result)
+	}
+	if result := run(client, "LCS", "TestLcsIdx1", "TestLcsIdx2", "IDX", "MINMATCHLEN"); result.Str != common.ERR_SYNTAX {
+		t.Errorf("Expected %s, got %v", common.ERR_SYNTAX, result)
+	}
+}
+
 func TestMGetAndMSet(t *testing.T) {
 	MSet([]resp.Value{
 		{Typ: common.BULK_TYPE, Bulk: "key2"},
@@ -594,3 +704,50 @@ func TestSetRangeInvalidRange(t *testing.T) {
 		t.Errorf("Expected ERR wrong number of arguments for 'SetRange' command, got %v", result)
 	}
 }
+
+func TestSetNxAndMSetNx(t *testing.T) {
+	client := newTestClient(t)
+
+	if result := run(client, "SETNX", "TestSetNx", "a"); result.Num != 1 {
+		t.Errorf("Expected 1, got %v", result)
+	}
+	if result := run(client, "SETNX", "TestSetNx", "b"); result.Num != 0 {
+		t.Errorf("Expected 0, got %v", result)
+	}
+	if result := run(client, "MSETNX", "TestMSetNx", "a", "TestSetNx", "c"); result.Num != 0 {
+		t.Errorf("Expected 0, got %v", result)
+	}
+	if result := run(client, "EXISTS", "TestMSetNx"); result.Num != 0 {
+		t.Errorf("Expected MSETNX to set no key, got %v", result)
+	}
+	if result := run(client, "MSETNX", "TestMSetNx", "a", "TestMSetNx2", "b"); result.Num != 1 {
+		t.Errorf("Expected 1, got %v", result)
+	}
+	if result := run(client, "MSETNX", "TestMSetNx3"); result.Str != common.ERR_WRONG_ARGUMENT_COUNT {
+		t.Errorf("Expected %s, got %v", common.ERR_WRONG_ARGUMENT_COUNT, result)
+	}
+}
+
+func TestSubstr(t *testing.T) {
+	client := newTestClient(t)
+	run(client, "SET", "TestSubstr", "This is a string")
+
+	tests := []struct {
+		start, end, expected string
+	}{
+		{"0", "3", "This"},
+		{"-3", "-1", "ing"},
+		{"0", "-1", "This is a string"},
+		{"10", "100", "string"},
+		{"-100", "3", "This"},
+		{"5", "1", ""},
+	}
+	for _, tt := range tests {
+		if result := run(client, "SUBSTR", "TestSubstr", tt.start, tt.end); result.Typ != common.BULK_TYPE || result.Bulk != tt.expected {
+			t.Errorf("SUBSTR %s %s: expected %q, got %v", tt.start, tt.end, tt.expected, result)
+		}
+	}
+	if result := run(client, "SUBSTR", "TestSubstr", "a", "1"); result.Str != common.ERR_INVALID_INTEGER {
+		t.Errorf("Expected %s, got %v", common.ERR_INVALID_INTEGER, result)
+	}
+}
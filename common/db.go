@@ -0,0 +1,36 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+// All the logical databases share a single store, the keys of a database are told
+// apart by a "\x00<db>\x00" prefix. Keys of database 0 are stored as they are, so
+// they look the same as before databases existed, unless they start with \x00
+// themselves, then they are prefixed like the others to stay unambiguous.
+const dbKeySeparator = "\x00"
+
+// DBKey returns the name a key of database db is stored under.
+func DBKey(db int, key string) string {
+	if db == 0 && !strings.HasPrefix(key, dbKeySeparator) {
+		return key
+	}
+	return dbKeySeparator + strconv.Itoa(db) + dbKeySeparator + key
+}
+
+// SplitDBKey returns the database and the key name of a stored key.
+func SplitDBKey(key string) (int, string) {
+	if !strings.HasPrefix(key, dbKeySeparator) {
+		return 0, key
+	}
+	end := strings.Index(key[1:], dbKeySeparator)
+	if end < 0 {
+		return 0, key
+	}
+	db, err := strconv.Atoi(key[1 : end+1])
+	if err != nil {
+		return 0, key
+	}
+	return db, key[end+2:]
+}
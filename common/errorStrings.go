@@ -17,9 +17,151 @@ const (
 
 	ERR_INVALID_TIME_SECONDS = "ERR provided time is not valid seconds"
 
+	ERR_INVALID_TTL = "ERR Invalid TTL value, must be >= 0"
+
+	ERR_INVALID_EXPIRE_TIME = "ERR invalid expire time"
+
+	ERR_FIELDS_MISSING = "ERR Mandatory argument FIELDS is missing or not at the right position"
+
+	ERR_NUMFIELDS_INVALID = "ERR Parameter `numFields` should be greater than 0"
+
+	ERR_NUMFIELDS_MISMATCH = "ERR The `numfields` parameter must match the number of arguments"
+
+	ERR_NUMKEYS_INVALID = "ERR numkeys should be greater than 0"
+
+	ERR_NUMKEYS_MISMATCH = "ERR Number of keys can't be greater than number of args"
+
+	ERR_LIMIT_NEGATIVE = "ERR LIMIT can't be negative"
+
+	ERR_COUNT_NEGATIVE = "ERR COUNT can't be negative"
+
+	ERR_COUNT_NOT_POSITIVE = "ERR count should be greater than 0"
+
+	ERR_MAXLEN_NEGATIVE = "ERR MAXLEN can't be negative"
+
+	ERR_RANK_ZERO = "ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list"
+
 	ERR_OUT_OF_RANGE = "ERR value is out of range"
 
 	ERR_INDEX_OUT_OF_RANGE = "ERR index out of range"
 
 	ERR_INVALID_INTEGER = "ERR value is not an integer or out of range"
+
+	ERR_INVALID_FLOAT = "ERR value is not a valid float"
+
+	ERR_STRING_TOO_LONG = "ERR string exceeds maximum allowed size"
+
+	ERR_BIT_OFFSET = "ERR bit offset is not an integer or out of range"
+
+	ERR_BIT_VALUE = "ERR bit is not an integer or out of range"
+
+	ERR_BITFIELD_TYPE = "ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."
+
+	ERR_BITFIELD_OVERFLOW = "ERR Invalid OVERFLOW type specified"
+
+	ERR_BITFIELD_RO = "ERR BITFIELD_RO only supports the GET subcommand"
+
+	ERR_BITOP_NOT = "ERR BITOP NOT must be called with a single source key."
+
+	ERR_BITOP_DIFF = "ERR BITOP DIFF must be called with at least two source keys."
+
+	ERR_LCS_LEN_AND_IDX = "ERR If you want both the length and indexes, please just use IDX."
+
+	ERR_HASH_VALUE_NOT_INTEGER = "ERR hash value is not an integer"
+
+	ERR_HASH_VALUE_NOT_FLOAT = "ERR hash value is not a float"
+
+	ERR_ARRAY_VALUE_NOT_FLOAT = "ERR array value is not a float"
+
+	ERR_INCREMENT_OVERFLOW = "ERR increment or decrement would overflow"
+
+	ERR_INCREMENT_NAN_OR_INFINITY = "ERR increment would produce NaN or Infinity"
+
+	ERR_INVALID_CONFIG_VALUE = "ERR invalid config value"
+
+	ERR_MAX_CLIENTS = "ERR max number of clients reached"
+
+	ERR_DB_INDEX_OUT_OF_RANGE = "ERR DB index is out of range"
+
+	ERR_SAME_OBJECT = "ERR source and destination objects are the same"
+
+	ERR_SYNTAX = "ERR syntax error"
+
+	ERR_BUSY_KEY = "BUSYKEY Target key name already exists."
+
+	ERR_DUMP_PAYLOAD = "ERR DUMP payload version or checksum are wrong"
+
+	ERR_BAD_DUMP_DATA = "ERR Bad data format"
+
+	ERR_MIGRATE_IO = "IOERR error or timeout communicating with the target instance"
+
+	ERR_STREAM_ID_INVALID = "ERR Invalid stream ID specified as stream command argument"
+
+	ERR_STREAM_ID_TOO_SMALL = "ERR The ID specified in XADD is equal or smaller than the target stream top item"
+
+	ERR_STREAM_ID_ZERO = "ERR The ID specified in XADD must be greater than 0-0"
+
+	ERR_STREAM_EXHAUSTED = "ERR The stream has exhausted the last possible ID, unable to add more items"
+
+	ERR_STREAM_INTERVAL = "ERR invalid start or end ID for the interval"
+
+	ERR_STREAM_LIMIT = "ERR syntax error, LIMIT cannot be used without the special ~ option"
+
+	ERR_STREAM_UNBALANCED = "ERR Unbalanced list of streams: for each stream key an ID must be specified"
+
+	ERR_XREAD_GROUP_ID = "ERR The > ID can be specified only when calling XREADGROUP using the GROUP <group> <consumer> option"
+
+	ERR_XREADGROUP_LAST_ID = "ERR The $ ID is meaningful only for XREAD"
+
+	ERR_XGROUP_KEY = "ERR The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically"
+
+	ERR_ENTRIES_READ = "ERR value for ENTRIESREAD must be positive or -1"
+
+	ERR_BUSY_GROUP = "BUSYGROUP Consumer Group name already exists"
+
+	ERR_NO_GROUP = "NOGROUP No such key or consumer group"
+
+	ERR_TIMEOUT_NEGATIVE = "ERR timeout is negative"
+
+	ERR_HLL_WRONG_TYPE = "WRONGTYPE Key is not a valid HyperLogLog string value."
+
+	ERR_HLL_CORRUPTED = "INVALIDOBJ Corrupted HLL object detected"
+
+	ERR_HLL_NOT_SPARSE = "ERR HLL encoding is not sparse"
+
+	ERR_NX_XX = "ERR XX and NX options at the same time are not compatible"
+
+	ERR_GEO_COORDINATES = "ERR invalid longitude,latitude pair"
+
+	ERR_GEO_UNIT = "ERR unsupported unit provided. please use M, KM, FT, MI"
+
+	ERR_GEO_MEMBER = "ERR could not decode requested zset member"
+
+	ERR_GEO_FROM = "ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH"
+
+	ERR_GEO_BY = "ERR exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH"
+
+	ERR_GEO_NEGATIVE = "ERR radius, width and height cannot be negative"
+
+	ERR_GEO_ANY = "ERR the ANY argument requires COUNT argument"
+
+	ERR_GEO_STORE_WITH = "ERR GEOSEARCHSTORE is not compatible with WITHDIST, WITHHASH and WITHCOORD options"
+
+	ERR_JSON_INVALID = "ERR expected a valid JSON value"
+
+	ERR_JSON_PATH = "ERR invalid JSONPath"
+
+	ERR_JSON_PATH_MISSING = "ERR Path does not exist"
+
+	ERR_JSON_ROOT = "ERR new objects must be created at the root"
+
+	ERR_JSON_NO_KEY = "ERR could not perform this operation on a key that doesn't exist"
+
+	ERR_JSON_WRONG_TYPE = "WRONGTYPE wrong type of path value"
+
+	ERR_JSON_NOT_FINITE = "ERR result is not a finite number"
+
+	ERR_ARRAY_RANGE = "ERR range spans too many indexes, use ARSCAN to walk sparse arrays"
+
+	ERR_MIGRATE_KEYS = "ERR When using MIGRATE KEYS option, the key argument must be set to the empty string"
 )
@@ -9,69 +9,224 @@ Key Features:
     Returns PONG to test server responsiveness.
   - **COMMAND (String)**: COMMAND
     Returns metadata about all registered commands.
-  - **INFO (String)**: INFO
+  - **INFO (String)**: INFO [SECTION ...]
     Returns information and statistics about the server.
-  - **CONFIG (String)**: CONFIG
-    command to handle server configuration
+    Sections are server, clients, memory, persistence, stats, replication, cpu, commandstats and keyspace.
+    Without arguments the default sections are returned, all returns every section.
+  - **CONFIG (String)**: CONFIG GET parameter | SET parameter value | RESETSTAT
+    command to handle server configuration, RESETSTAT resets the statistics reported by INFO
+  - **CLIENT (String)**: CLIENT SETNAME name | GETNAME | ID
+    Sets or returns the name of the current connection, or returns its id.
+  - **MONITOR (String)**: MONITOR
+    Streams back every command processed by the server, with its timestamp, database and client address.
+  - **SELECT (String)**: SELECT [INDEX]
+    Selects the database used by the connection, the number of databases is set by the databases config.
+  - **SLOWLOG (String)**: SLOWLOG GET [COUNT] | LEN | RESET
+    GET returns the most recent entries of the slow log, all of them if COUNT is -1 and 10 by default.
+    LEN returns the number of entries in the slow log.
+    RESET clears the slow log.
+  - **SUBSCRIBE (String)**: SUBSCRIBE [CHANNEL ...]
+    Subscribes the connection to the given channels, it then only accepts Pub/Sub commands and PING.
+  - **UNSUBSCRIBE (String)**: UNSUBSCRIBE [CHANNEL ...]
+    Unsubscribes the connection from the given channels, or from all of them if none is given.
+  - **PSUBSCRIBE (String)**: PSUBSCRIBE [PATTERN ...]
+    Subscribes the connection to every channel matching the given glob-style patterns.
+  - **PUNSUBSCRIBE (String)**: PUNSUBSCRIBE [PATTERN ...]
+    Unsubscribes the connection from the given patterns, or from all of them if none is given.
+  - **PUBLISH (String)**: PUBLISH [CHANNEL] [MESSAGE]
+    Posts a message to a channel and returns the number of clients that received it.
+  - **PUBSUB (String)**: PUBSUB CHANNELS [PATTERN] | NUMSUB [CHANNEL ...] | NUMPAT
+    CHANNELS lists the active channels, optionally only the ones matching pattern.
+    NUMSUB returns the number of subscribers of each channel.
+    NUMPAT returns the number of subscribed patterns.
   - **ARCOUNT (String)**: ARCOUNT [KEY]
-    Returns the number of elements in the array stored at key.
+    Returns the length of the array stored at key, one past its highest index holding a value.
   - **ARDEL (String)**: ARDEL [KEY] [INDEX]
-    Deletes the element at the specified index from the array stored at key.
+    Deletes the element at the specified index from the array stored at key. The following elements keep their index.
   - **ARDELRANGE (String)**: ARDELRANGE [KEY] [START] [END]
-    Deletes elements in the specified range from the array stored at key.
+    Deletes elements in the specified range from the array stored at key. The following elements keep their index.
   - **ARGET (String)**: ARGET [KEY] [INDEX]
     Returns the element at the specified index from the array stored at key.
   - **ARGREP (String)**: ARGREP [KEY] [PATTERN]
     Returns elements from the array stored at key that match the specified pattern.
+  - **ARGETRANGE (String)**: ARGETRANGE [KEY] [START] [END]
+    Returns the elements between START and END, both inclusive, from the array stored at key.
+    The range can span at most 1048576 indexes, ARSCAN walks the values of sparse arrays.
+  - **ARSET (String)**: ARSET [KEY] [INDEX] [VALUE]
+    Sets the element at INDEX in the array stored at key, creating the array if needed.
+    Setting an index past the end grows the array, the indexes in between hold no value.
+  - **ARMSET (String)**: ARMSET [KEY] [INDEX] [VALUE] [INDEX VALUE ...]
+    Sets the elements at several indexes in the array stored at key.
+  - **ARMGET (String)**: ARMGET [KEY] [INDEX] [INDEX ...]
+    Returns the elements at several indexes in the array stored at key, null for the indexes holding no value.
+  - **ARPUSH (String)**: ARPUSH [KEY] [VALUE] [VALUE ...]
+    Appends values to the array stored at key and returns its new length.
+  - **ARINSERT (String)**: ARINSERT [KEY] [INDEX] [VALUE] [VALUE ...]
+    Inserts values before the element at INDEX in the array stored at key and returns its new length.
+  - **ARPOP (String)**: ARPOP [KEY] [COUNT]
+    Removes and returns the last element, or the last COUNT elements, of the array stored at key.
+  - **ARNEXT (String)**: ARNEXT [KEY] [CURSOR]
+    Returns the index and value of the first element at or after CURSOR holding a value, or null.
+  - **ARSCAN (String)**: ARSCAN [KEY] [CURSOR] [MATCH pattern] [COUNT count]
+    Scans COUNT elements of the array stored at key from CURSOR, skipping the indexes holding no value,
+    and returns the next cursor with the indexes and values found.
+  - **ARSORT (String)**: ARSORT [KEY] [ASC|DESC] [ALPHA] [STORE destination] [WITHINDEX]
+    Returns the values of the array stored at key sorted numerically, or as strings with ALPHA.
+    With STORE the sorted values are stored as a new array at destination and their number is returned.
+    With WITHINDEX the index of each value is returned before it.
+  - **ARSUM (String)**: ARSUM [KEY] [STRICT] [WITHINDEX]
+    Returns the sum of the numeric elements of the array stored at key. Other elements are skipped,
+    or fail the command with STRICT. With WITHINDEX the indexes of the summed elements are returned after it.
+  - **ARAVG (String)**: ARAVG [KEY] [STRICT] [WITHINDEX]
+    Returns the average of the numeric elements of the array stored at key, or null if there are none.
+    Other elements are skipped, or fail the command with STRICT.
+    With WITHINDEX the indexes of the averaged elements are returned after it.
+  - **ARMIN (String)**: ARMIN [KEY] [STRICT] [WITHINDEX]
+    Returns the smallest numeric element of the array stored at key, or null if there are none.
+    With WITHINDEX its index is returned before it.
+  - **ARMAX (String)**: ARMAX [KEY] [STRICT] [WITHINDEX]
+    Returns the largest numeric element of the array stored at key, or null if there are none.
+    With WITHINDEX its index is returned before it.
+  - **ARDISTINCT (String)**: ARDISTINCT [KEY] [WITHINDEX]
+    Returns the distinct values of the array stored at key in index order.
+    With WITHINDEX the index of the first occurrence of each value is returned before it.
+  - **ARCOUNTVAL (String)**: ARCOUNTVAL [KEY] [VALUE] [WITHINDEX]
+    Returns the number of elements of the array stored at key equal to VALUE, or with WITHINDEX their indexes.
+  - **ARSEEK (String)**: ARSEEK [KEY] [PATTERN] [FROM index] [WITHVALUE]
+    Returns the index of the first element at or after FROM of the array stored at key matching the glob PATTERN, or null.
+    With WITHVALUE the value of the element is returned after its index.
   - **APPEND (String)**: APPEND [KEY] [VALUE]
     Appends a value to a key and returns the new length of the string.
   - **DECR (String)**: DECR [KEY]
-    Decrements the integer value of a key by one.
+    Decrements the integer value of a key by one and returns the new value.
   - **DECRBY (String)**: DECRBY [KEY] [DECREMENT]
-    Decrements the integer value of a key by the given amount.
+    Decrements the integer value of a key by the given amount and returns the new value.
   - **GET (String)**: GET [KEY]
     Gets the value of a key.
   - **GETDEL (String)**: GETDEL [KEY]
     Gets the value of a key and deletes it.
-  - **GETEX (String)**: GETEX [KEY] [EXPIRATION]
-    Gets the value of a key and sets an expiration.
+  - **GETEX (String)**: GETEX [KEY] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]
+    Gets the value of a key and sets its expiration, or removes it with PERSIST.
+    Expirations are kept in seconds, millisecond ones are rounded up.
   - **GETRANGE (String)**: GETRANGE [KEY] [START] [END]
     Gets a substring of the string stored at a key.
   - **GETSET (String)**: GETSET [KEY] [VALUE]
     Gets the previous key value and then sets it to the passed value.
   - **INCR (String)**: INCR [KEY]
-    Increments the integer value of a key by one.
+    Increments the integer value of a key by one and returns the new value.
   - **INCRBY (String)**: INCRBY [KEY] [INCREMENT]
-    Increments the integer value of a key by the given amount.
+    Increments the integer value of a key by the given amount and returns the new value.
+    Fails rather than overflowing a 64 bit integer, as do INCR, DECR and DECRBY.
   - **INCRBYFLOAT (String)**: INCRBYFLOAT [KEY] [INCREMENT]
     Increments the float value of a key by the given amount.
-  - **LCS (String)**: LCS [KEY1] [KEY2] LEN
+  - **LCS (String)**: LCS [KEY1] [KEY2] [LEN] [IDX] [MINMATCHLEN len] [WITHMATCHLEN]
     Finds the Longest Common Subsequence between the value of two keys.
-    Send the optional LEN argument to get just the length
+    Send the optional LEN argument to get just the length.
+    With IDX the ranges of the matching substrings in both values are returned along with the length,
+    skipping matches shorter than MINMATCHLEN. WITHMATCHLEN adds the length of each match.
   - **MGET (String)**: MGET key [key ...]
     Returns the values for all the keys.
     Returns nil for a non-existing key.
   - **MSET (String)**: MSET key value [key1 value1 ...]
     Sets the values for all the keys value pair.
+  - **MSETNX (String)**: MSETNX key value [key1 value1 ...]
+    Sets the values for all the keys value pair, only if none of the keys exists.
+    Returns 1 if the keys were set, 0 otherwise.
   - **SET (String)**: SET [KEY] [VALUE]
     Sets the value of a key.
+  - **SETNX (String)**: SETNX [KEY] [VALUE]
+    Sets the value of a key only if it doesn't exist. Returns 1 if the key was set, 0 otherwise.
   - **SETRANGE (String)**: SETRANGE key offset value
   - **SETEX (String)**: SET [KEY] [VALUE] [EX SECONDS]
     Sets the value of a key with expiration in seconds.
+  - **SUBSTR (String)**: SUBSTR [KEY] [START] [END]
+    Gets a substring of the string stored at a key, from START to END inclusive.
+    Negative positions count from the end, and the range is clamped to the length of the string.
   - **STRLEN (String)**: STRLEN [KEY]
     Returns the length of the string value stored at key.
-  - **HSET (String)**: HSET [KEY] [FIELD] [VALUE]
-    Sets a field in the hash stored at key to a value.
+  - **SETBIT (String)**: SETBIT [KEY] [OFFSET] [VALUE]
+    Sets or clears the bit at OFFSET of the string stored at key, padding it with zero bytes as needed.
+    Returns the previous bit.
+  - **GETBIT (String)**: GETBIT [KEY] [OFFSET]
+    Returns the bit at OFFSET of the string stored at key, 0 past its end.
+  - **BITCOUNT (String)**: BITCOUNT [KEY] [START END [BYTE|BIT]]
+    Returns the number of bits set in the string stored at key, or in the range from START to END,
+    in bytes or with BIT in bits. Negative positions count from the end of the string.
+  - **BITPOS (String)**: BITPOS [KEY] [BIT] [START [END [BYTE|BIT]]]
+    Returns the position of the first bit set to BIT in the string stored at key, or in the given range, or -1.
+  - **BITOP (String)**: BITOP [AND|OR|XOR|NOT|DIFF] [DESTKEY] [KEY] [KEY ...]
+    Stores at DESTKEY the result of a bitwise operation between the strings stored at the keys and returns its length.
+    DIFF keeps the bits set in the first string and in none of the others.
+  - **BITFIELD (String)**: BITFIELD [KEY] [GET type offset] [SET type offset value] [INCRBY type offset increment] [OVERFLOW WRAP|SAT|FAIL] ...
+    Reads and writes integer fields of the string stored at key. Types are i1 to i64 and u1 to u63,
+    offsets prefixed with # count in fields of the type's width. OVERFLOW sets how the following SET and INCRBY
+    handle values out of range.
+  - **BITFIELD_RO (String)**: BITFIELD_RO [KEY] [GET type offset] ...
+    Reads integer fields of the string stored at key.
+  - **PFADD (String)**: PFADD [KEY] [ELEMENT ...]
+    Adds elements to the HyperLogLog stored at key, creating it if needed.
+    Returns 1 if the HyperLogLog was created or its estimate may have changed, 0 otherwise.
+  - **PFCOUNT (String)**: PFCOUNT [KEY] [KEY ...]
+    Returns the approximate number of distinct elements added to the HyperLogLogs stored at the keys,
+    with a standard error of 0.81%. The count of a single HyperLogLog is cached until it changes.
+  - **PFMERGE (String)**: PFMERGE [DESTKEY] [SOURCEKEY ...]
+    Stores at DESTKEY the union of the HyperLogLogs stored at DESTKEY and the source keys.
+  - **PFDEBUG (String)**: PFDEBUG [GETREG|DECODE|ENCODING|TODENSE] [KEY]
+    Inspects the HyperLogLog stored at key. GETREG returns its 16384 registers, converting it to the dense encoding,
+    DECODE describes the opcodes of a sparse HyperLogLog, ENCODING returns sparse or dense and TODENSE converts it
+    to the dense encoding, returning 1 if it was sparse.
+  - **HSET (String)**: HSET [KEY] [FIELD] [VALUE] [FIELD VALUE ...]
+    Sets one or more fields in the hash stored at key to their values. Returns the number of fields added.
+  - **HMSET (String)**: HMSET [KEY] [FIELD] [VALUE] [FIELD VALUE ...]
+    Sets one or more fields in the hash stored at key to their values, same as HSET but replies OK.
+  - **HSETNX (String)**: HSETNX [KEY] [FIELD] [VALUE]
+    Sets a field in the hash stored at key only if it doesn't exist. Returns 1 if the field was set, 0 otherwise.
+  - **HMGET (String)**: HMGET [KEY] [FIELD] [FIELD ...]
+    Gets the values of the fields in the hash stored at key, nil for the fields that don't exist.
+  - **HINCRBY (String)**: HINCRBY [KEY] [FIELD] [INCREMENT]
+    Increments the integer stored in a field of the hash by increment and returns the new value.
+    A missing field is set to the increment.
+  - **HINCRBYFLOAT (String)**: HINCRBYFLOAT [KEY] [FIELD] [INCREMENT]
+    Increments the number stored in a field of the hash by a floating point increment and returns the new value.
+    A missing field is set to the increment.
+  - **HKEYS (String)**: HKEYS [KEY]
+    Returns all the fields of the hash stored at key.
+  - **HVALS (String)**: HVALS [KEY]
+    Returns all the values of the hash stored at key.
+  - **HLEN (String)**: HLEN [KEY]
+    Returns the number of fields in the hash stored at key.
+  - **HSTRLEN (String)**: HSTRLEN [KEY] [FIELD]
+    Returns the length of the value of a field in the hash stored at key, 0 if the field doesn't exist.
+  - **HRANDFIELD (String)**: HRANDFIELD [KEY] [COUNT [WITHVALUES]]
+    Returns random fields from the hash stored at key, followed each by its value with WITHVALUES.
+    A positive count returns distinct fields, a negative count may return the same field several times.
   - **HGET (String)**: HGET [KEY] [FIELD]
     Gets the value of a field in the hash stored at key.
   - **HEXISTS (String)**: HEXISTS [KEY] [FIELD]
-    Checks if the hash and the field combination exists in the store.
-  - **HEXPIRE (String)**: HEXPIRE key seconds [NX XX GT LT]
-    Sets a timeout on hash key. After the timeout, the key gets deleted.
-    NX - Only set timeout if the key has no previous expiry.
-    XX - Only set timeout if the key has a previous expiry.
+    Checks if the hash and the field combination exists in the store. Returns 1 if the field exists, 0 otherwise.
+  - **HEXPIRE (String)**: HEXPIRE key seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+    Sets a timeout on fields of the hash stored at key. After the timeout, the fields get deleted.
+    NX - Only set timeout if the field has no previous expiry.
+    XX - Only set timeout if the field has a previous expiry.
     GT - Only set timeout if the new time is greater than the existing expiry.
     LT - Only set timeout if the new time is less than the existing expiry.
+    Returns for every field: -2 if the field doesn't exist, 0 if the condition isn't met,
+    1 if the timeout was set and 2 if the field was deleted because the time is in the past.
+  - **HPEXPIRE (String)**: HPEXPIRE key milliseconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+    Same as HEXPIRE with a timeout in milliseconds.
+  - **HEXPIREAT (String)**: HEXPIREAT key unix-time-seconds [NX | XX | GT | LT] FIELDS numfields field [field ...]
+    Same as HEXPIRE with the unix time in seconds the fields expire at.
+  - **HTTL (String)**: HTTL key FIELDS numfields field [field ...]
+    Returns the remaining time to live of fields of the hash stored at key in seconds.
+    -1 if the field has no expiry, -2 if the field doesn't exist.
+  - **HPTTL (String)**: HPTTL key FIELDS numfields field [field ...]
+    Same as HTTL in milliseconds.
+  - **HEXPIRETIME (String)**: HEXPIRETIME key FIELDS numfields field [field ...]
+    Returns the unix time in seconds fields of the hash stored at key expire at.
+    -1 if the field has no expiry, -2 if the field doesn't exist.
+  - **HPERSIST (String)**: HPERSIST key FIELDS numfields field [field ...]
+    Removes the expiry of fields of the hash stored at key.
+    Returns for every field: -2 if the field doesn't exist, -1 if it has no expiry and 1 if the expiry was removed.
   - **HDEL (String)**: HDEL [KEY] [FIELD]
     Deletes a field from the hash stored at key.
   - **HGETALL (String)**: HGETALL [KEY]
@@ -94,6 +249,21 @@ Key Features:
     Removes and returns the first element(s) of the list stored at key.
   - **LPUSH (String)**: LPUSH [KEY] [VALUE] [VALUE ...]
     Inserts one or more elements at the beginning of the list stored at key.
+  - **LPUSHX (String)**: LPUSHX [KEY] [VALUE] [VALUE ...]
+    Inserts one or more elements at the beginning of the list stored at key, only if the list exists.
+  - **RPUSHX (String)**: RPUSHX [KEY] [VALUE] [VALUE ...]
+    Inserts one or more elements at the end of the list stored at key, only if the list exists.
+  - **LPOS (String)**: LPOS [KEY] [ELEMENT] [RANK rank] [COUNT count] [MAXLEN len]
+    Returns the index of the first match of element in the list stored at key, or of the RANK-th match.
+    With COUNT an array of up to COUNT indexes is returned, all of them if COUNT is 0. MAXLEN limits the elements compared.
+  - **LREM (String)**: LREM [KEY] [COUNT] [ELEMENT]
+    Removes the first COUNT occurrences of element from the list stored at key, the last ones if COUNT is negative, all of them if it is 0.
+  - **LSET (String)**: LSET [KEY] [INDEX] [ELEMENT]
+    Sets the element at index INDEX in the list stored at key.
+  - **LTRIM (String)**: LTRIM [KEY] [START] [STOP]
+    Trims the list stored at key to the elements between START and STOP, both inclusive.
+  - **LMPOP (String)**: LMPOP numkeys key [key ...] LEFT|RIGHT [COUNT count]
+    Pops up to COUNT elements from the first non-empty list among the keys and returns the key with the elements.
   - **SADD (String)**: SADD [KEY] [MEMBER] [MEMBER ...]
     Adds one or more members to the set stored at key.
   - **SCARD (String)**: SCARD [KEY]
@@ -104,13 +274,125 @@ Key Features:
     Stores the result of the difference between the first set and all the successive sets in the destination set.
   - **SISMEMBER (String)**: SISMEMBER [KEY] [MEMBER]
     Returns if member is a member of the set stored at key.
+  - **SMISMEMBER (String)**: SMISMEMBER [KEY] [MEMBER] [MEMBER ...]
+    Returns for each member 1 if it is a member of the set stored at key, 0 otherwise.
+  - **SREM (String)**: SREM [KEY] [MEMBER] [MEMBER ...]
+    Removes one or more members from the set stored at key, the set is deleted once it is empty.
+  - **SMEMBERS (String)**: SMEMBERS [KEY]
+    Returns all the members of the set stored at key.
+  - **SINTER (String)**: SINTER [KEY] [KEY ...]
+    Returns the members of the set resulting from the intersection of all the given sets.
+  - **SINTERSTORE (String)**: SINTERSTORE [DESTINATION] [KEY] [KEY ...]
+    Stores the intersection of all the given sets in the destination set and returns its size.
+  - **SINTERCARD (String)**: SINTERCARD numkeys key [key ...] [LIMIT limit]
+    Returns the number of members in the intersection of the given sets, counting no further than LIMIT if it isn't 0.
+  - **SUNION (String)**: SUNION [KEY] [KEY ...]
+    Returns the members of the set resulting from the union of all the given sets.
+  - **SUNIONSTORE (String)**: SUNIONSTORE [DESTINATION] [KEY] [KEY ...]
+    Stores the union of all the given sets in the destination set and returns its size.
+  - **SPOP (String)**: SPOP [KEY] [COUNT]
+    Removes and returns one or COUNT random members of the set stored at key.
+  - **SRANDMEMBER (String)**: SRANDMEMBER [KEY] [COUNT]
+    Returns one or COUNT random members of the set stored at key. A negative COUNT may return the same member several times.
+  - **SMOVE (String)**: SMOVE [SOURCE] [DESTINATION] [MEMBER]
+    Moves member from the source set to the destination set.
+  - **XADD (String)**: XADD [KEY] [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id [FIELD] [VALUE] [FIELD VALUE ...]
+    Appends an entry to the stream stored at key and returns its ID. * generates the ID from the current time,
+    ms-* only its sequence number. MAXLEN and MINID trim the oldest entries, LIMIT caps how many ~ trims.
+  - **XLEN (String)**: XLEN [KEY]
+    Returns the number of entries of the stream stored at key.
+  - **XRANGE (String)**: XRANGE [KEY] [START] [END] [COUNT count]
+    Returns the entries of the stream stored at key with IDs from start to end. - and + are the lowest and highest IDs,
+    an ID prefixed with ( is excluded.
+  - **XREVRANGE (String)**: XREVRANGE [KEY] [END] [START] [COUNT count]
+    Returns the entries of the stream stored at key with IDs from end down to start.
+  - **XDEL (String)**: XDEL [KEY] [ID] [ID ...]
+    Deletes entries of the stream stored at key and returns the number deleted.
+  - **XTRIM (String)**: XTRIM [KEY] MAXLEN|MINID [=|~] threshold [LIMIT count]
+    Removes the oldest entries of the stream stored at key, keeping MAXLEN entries or the ones from MINID,
+    and returns the number removed.
+  - **XREAD (String)**: XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...]
+    Returns the entries of each stream with IDs greater than the given one, $ being the last entry of the stream.
+    BLOCK waits up to the given time, or forever with 0, for entries when there are none.
+  - **XREADGROUP (String)**: XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...]
+    Reads entries on behalf of a consumer of a group. > reads the entries never delivered to the group and adds them
+    to the consumer's pending entries unless NOACK is given, any other ID rereads the consumer's pending entries after it.
+  - **XGROUP (String)**: XGROUP CREATE key group id|$ [MKSTREAM] [ENTRIESREAD n] | SETID key group id|$ [ENTRIESREAD n] | DESTROY key group | CREATECONSUMER key group consumer | DELCONSUMER key group consumer
+    Manages the consumer groups of the stream stored at key. DELCONSUMER returns the number of entries that were pending for the consumer.
+  - **XACK (String)**: XACK [KEY] [GROUP] [ID] [ID ...]
+    Acknowledges entries pending in a consumer group and returns the number acknowledged.
+  - **XPENDING (String)**: XPENDING [KEY] [GROUP] [[IDLE min-idle-time] start end count [consumer]]
+    Returns the number of entries pending in a consumer group, their lowest and highest IDs and the count per consumer,
+    or with a range the ID, consumer, idle time and delivery count of each pending entry.
+  - **XCLAIM (String)**: XCLAIM [KEY] [GROUP] [CONSUMER] [MIN-IDLE-TIME] [ID] [ID ...] [IDLE ms] [TIME unix-time-milliseconds] [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID id]
+    Gives to consumer the entries pending in a group for at least min-idle-time milliseconds and returns them.
+  - **XAUTOCLAIM (String)**: XAUTOCLAIM [KEY] [GROUP] [CONSUMER] [MIN-IDLE-TIME] [START] [COUNT count] [JUSTID]
+    Claims up to COUNT entries pending for at least min-idle-time milliseconds from start, and returns the ID to continue
+    from, the claimed entries and the IDs of the entries deleted from the stream.
+  - **XINFO (String)**: XINFO STREAM key | GROUPS key | CONSUMERS key group
+    Describes the stream stored at key, its consumer groups or the consumers of a group.
+  - **GEOADD (String)**: GEOADD [KEY] [NX|XX] [CH] [LONGITUDE] [LATITUDE] [MEMBER] [LONGITUDE LATITUDE MEMBER ...]
+    Adds members at their positions to the geospatial index stored at key, or moves them. NX only adds new members,
+    XX only moves existing ones. Returns the number of members added, or added and moved with CH.
+  - **GEOPOS (String)**: GEOPOS [KEY] [MEMBER ...]
+    Returns the longitude and latitude of members of the geospatial index stored at key, nil for missing members.
+  - **GEODIST (String)**: GEODIST [KEY] [MEMBER1] [MEMBER2] [M|KM|FT|MI]
+    Returns the distance between two members of the geospatial index stored at key, in meters by default.
+  - **GEOHASH (String)**: GEOHASH [KEY] [MEMBER ...]
+    Returns the 11 characters geohash strings of members of the geospatial index stored at key.
+  - **GEOSEARCH (String)**: GEOSEARCH [KEY] [FROMMEMBER member|FROMLONLAT longitude latitude] [BYRADIUS radius M|KM|FT|MI|BYBOX width height M|KM|FT|MI] [ASC|DESC] [COUNT count [ANY]] [WITHCOORD] [WITHDIST] [WITHHASH]
+    Returns the members of the geospatial index stored at key within a radius or a box centered on a member or a position.
+    COUNT returns the closest members, or with ANY the first ones found. WITHDIST, WITHHASH and WITHCOORD add the
+    distance to the center, the geohash score and the position of each member.
+  - **GEOSEARCHSTORE (String)**: GEOSEARCHSTORE [DESTINATION] [SOURCE] [FROMMEMBER member|FROMLONLAT longitude latitude] [BYRADIUS radius M|KM|FT|MI|BYBOX width height M|KM|FT|MI] [ASC|DESC] [COUNT count [ANY]] [STOREDIST]
+    Stores at destination the members of the geospatial index stored at source found like GEOSEARCH does,
+    scored by their distance to the center with STOREDIST. Returns the number of members stored.
+  - **JSON.SET (String)**: JSON.SET [KEY] [PATH] [VALUE] [NX|XX]
+    Sets the values selected by path in the JSON document stored at key to the JSON value, and adds it to the objects
+    missing the member a path ending with a name selects. A new key must be set at the root path $.
+    NX only adds values, XX only replaces them. Returns OK, or nil if nothing was set.
+  - **JSON.GET (String)**: JSON.GET [KEY] [INDENT indent] [NEWLINE newline] [SPACE space] [PATH ...]
+    Returns the JSON text of the values selected by the paths in the document stored at key, the whole document
+    without path. A JSONPath starting with $ returns an array of all the values it selects, several paths return
+    an object keyed by path. INDENT, NEWLINE and SPACE format the JSON text.
+  - **JSON.MGET (String)**: JSON.MGET [KEY] [KEY ...] [PATH]
+    Returns the JSON text of the values selected by path in the documents stored at each key, nil for missing keys.
+  - **JSON.DEL (String)**: JSON.DEL [KEY] [PATH]
+    Deletes the values selected by path in the JSON document stored at key, or the key for the root path.
+    Returns the number of values deleted.
+  - **JSON.FORGET (String)**: JSON.FORGET [KEY] [PATH]
+    Deletes the values selected by path in the JSON document stored at key, same as JSON.DEL.
+  - **JSON.TYPE (String)**: JSON.TYPE [KEY] [PATH]
+    Returns the types of the values selected by path in the JSON document stored at key:
+    object, array, string, integer, number, boolean or null.
+  - **JSON.NUMINCRBY (String)**: JSON.NUMINCRBY [KEY] [PATH] [VALUE]
+    Increments the numbers selected by path in the JSON document stored at key by value. Returns the new values.
+  - **JSON.STRAPPEND (String)**: JSON.STRAPPEND [KEY] [PATH] [VALUE]
+    Appends the JSON string value to the strings selected by path in the JSON document stored at key.
+    Returns the new lengths of the strings.
+  - **JSON.ARRAPPEND (String)**: JSON.ARRAPPEND [KEY] [PATH] [VALUE] [VALUE ...]
+    Appends the JSON values to the arrays selected by path in the JSON document stored at key.
+    Returns the new lengths of the arrays.
+  - **JSON.ARRINSERT (String)**: JSON.ARRINSERT [KEY] [PATH] [INDEX] [VALUE] [VALUE ...]
+    Inserts the JSON values before index in the arrays selected by path in the JSON document stored at key,
+    counting from the end when index is negative. Returns the new lengths of the arrays.
+  - **JSON.ARRLEN (String)**: JSON.ARRLEN [KEY] [PATH]
+    Returns the lengths of the arrays selected by path in the JSON document stored at key.
+  - **JSON.ARRPOP (String)**: JSON.ARRPOP [KEY] [PATH] [INDEX]
+    Removes and returns the element at index, the last one by default, of the arrays selected by path
+    in the JSON document stored at key.
+  - **JSON.OBJKEYS (String)**: JSON.OBJKEYS [KEY] [PATH]
+    Returns the keys of the objects selected by path in the JSON document stored at key.
+  - **JSON.MERGE (String)**: JSON.MERGE [KEY] [PATH] [VALUE]
+    Merges the JSON value into the values selected by path in the JSON document stored at key as a JSON merge patch,
+    null members deleting the ones they merge into.
   - **HELP (Help)**: HELP [COMMAND]
     Provides details on how to use a command and what the command actually does.
-  - **COPY (String)**: COPY [key1] [key2]
-    Copies value(s) of key1 into key2.
-    If key2 doesn't exist, creates key2 and copies the value of key1 into key2.
+  - **COPY (String)**: COPY [key1] [key2] [DB destination-db] [REPLACE]
+    Copies value(s) of key1 into key2, along with its expiry.
+    DB copies into key2 of another database, REPLACE overwrites key2 if it exists, otherwise 0 is returned.
   - **DEL (String)**: DEL key1 [keys...]
-    Deletes all the keys passed as argument. Ignores the keys in the argument that don't exist.
+    Deletes all the keys passed as argument and returns how many of them existed.
   - **EXISTS (String)**: EXISTS key1 [keys...]
     Returns an integer denoting how many of the passed keys exist in the cache.
   - **EXPIRE (String)**: EXPIRE key seconds [NX XX GT LT]
@@ -129,8 +411,64 @@ Key Features:
     Returns the expire time of a key in unix epoch seconds.
     -1 If the key doesn't have an expiry set
     -2 If the key doesn't exist
-  - **KEYS (String)**: KEYS
-    Returns the keys that exist in the store.
+  - **KEYS (String)**: KEYS [PATTERN]
+    Returns the keys of the current database whose names match the regular expression pattern.
+  - **RENAME (String)**: RENAME key newkey
+    Renames key to newkey, keeping its expiry. An existing newkey is overwritten.
+  - **RENAMENX (String)**: RENAMENX key newkey
+    Renames key to newkey only if newkey doesn't exist. Returns 1 if key was renamed, 0 otherwise.
+  - **PERSIST (String)**: PERSIST key
+    Removes the expiry of a key. Returns 1 if the expiry was removed, 0 if the key doesn't exist or has no expiry.
+  - **TTL (String)**: TTL key
+    Returns the remaining time to live of a key in seconds.
+    -1 If the key doesn't have an expiry set
+    -2 If the key doesn't exist
+  - **TYPE (String)**: TYPE key
+    Returns the data type of the value stored at key, or none if it doesn't exist.
+  - **RANDOMKEY (String)**: RANDOMKEY
+    Returns a random key of the current database, nil if it is empty.
+  - **TOUCH (String)**: TOUCH key [key ...]
+    Marks the keys as recently used so they are evicted last. Returns the number of keys that exist.
+  - **UNLINK (String)**: UNLINK key [key ...]
+    Deletes the keys like DEL, but frees the memory of large values in the background.
+    Returns the number of keys that existed.
+  - **DUMP (String)**: DUMP key
+    Returns the value stored at key serialized in a versioned and checksummed format, to be recreated with RESTORE.
+    Returns nil if the key doesn't exist.
+  - **RESTORE (String)**: RESTORE key ttl serialized-value [REPLACE] [ABSTTL] [IDLETIME seconds] [FREQ frequency]
+    Creates a key from a value serialized with DUMP. The ttl is in milliseconds, 0 for no expiry.
+    REPLACE overwrites the key if it exists, otherwise a BUSYKEY error is returned.
+    ABSTTL makes the ttl a unix time in milliseconds.
+    IDLETIME and FREQ set the idle time and access frequency reported by OBJECT.
+  - **MIGRATE (String)**: MIGRATE host port key|"" destination-db timeout [COPY] [REPLACE] [AUTH password] [AUTH2 username password] [KEYS key [key ...]]
+    Moves keys to another instance, each key is restored on the target with RESTORE and then deleted here.
+    The timeout is in milliseconds. Up to 4 connections per target are kept for reuse for 10 seconds.
+    COPY keeps the local keys, REPLACE overwrites existing keys on the target.
+    AUTH and AUTH2 authenticate on Redis targets only, an animus target rejects them.
+    KEYS migrates several keys at once, the key argument must be an empty string then.
+    Returns OK, or NOKEY if none of the keys exist.
+  - **OBJECT (String)**: OBJECT ENCODING|IDLETIME|FREQ|REFCOUNT key
+    ENCODING returns the internal representation of the value stored at key: int for strings holding
+    a 64-bit integer, embstr or raw for other strings, listpack or deque for lists, listpack or hashtable for hashes, intset or hashtable for sets,
+    skiplist for sorted sets.
+    IDLETIME returns the seconds since the key was last read or written.
+    FREQ returns the logarithmic access frequency counter of the key.
+    REFCOUNT returns the number of references to the value, always 1.
+  - **MEMORY (String)**: MEMORY USAGE key [SAMPLES count] | STATS | DOCTOR
+    USAGE estimates the bytes used by a key and its value, from count elements of a container (5 by default, 0 for all).
+    STATS reports the memory used by the dataset versus the server overhead.
+    DOCTOR describes the memory issues found, if any.
+  - **MOVE (String)**: MOVE [KEY] [DB]
+    Moves a key of the current database to the given database, keeping its expiry.
+    Returns 1 if the key was moved, 0 if it doesn't exist or the target database already has it.
+  - **SWAPDB (String)**: SWAPDB [INDEX1] [INDEX2]
+    Swaps the contents of two databases.
+  - **FLUSHDB (String)**: FLUSHDB [ASYNC|SYNC]
+    Deletes all the keys of the current database.
+  - **FLUSHALL (String)**: FLUSHALL [ASYNC|SYNC]
+    Deletes all the keys of every database.
+  - **DBSIZE (String)**: DBSIZE
+    Returns the number of keys in the current database.
 
 Roadmap:
   - Advanced data structures (Sets, Sorted Sets)
@@ -10,7 +10,7 @@ def extract_commands_and_docs(file_path):
     with open(file_path, 'r') as f:
         content = f.read()
 
-    register_command_pattern = re.compile(r'RegisterCommand\("([^"]+)",\s*([^,]+),\s*`([^`]+)`,.*\)')
+    register_command_pattern = re.compile(r'Register(?:Client)?Command\("([^"]+)",\s*([^,]+),\s*`([^`]+)`,.*\)')
     matches = register_command_pattern.findall(content)
 
     for command, func_name, doc in matches:
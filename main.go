@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
 	"log"
 	"net"
+	"os"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/divy-sh/animus/command"
 	"github.com/divy-sh/animus/common"
@@ -12,9 +17,63 @@ import (
 )
 
 func main() {
+	if err := configure(os.Args[1:]); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
 	Handle()
 }
 
+// configure applies the startup configuration given on the command line the way
+// redis-server takes it: an optional config file, then --parameter value pairs overriding it.
+func configure(args []string) error {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "--") {
+		if err := loadConfigFile(args[0]); err != nil {
+			return err
+		}
+		args = args[1:]
+	}
+	for ; len(args) > 0; args = args[2:] {
+		param, ok := strings.CutPrefix(args[0], "--")
+		if !ok || len(args) < 2 {
+			return fmt.Errorf("expected --parameter value, got %q", args[0])
+		}
+		if err := command.SetStartupConfig(param, args[1]); err != nil {
+			return fmt.Errorf("%s: %v", param, err)
+		}
+	}
+	return nil
+}
+
+// loadConfigFile applies a config file holding a parameter and its value per line.
+// Blank lines and lines starting with # are skipped, a value may be double quoted.
+func loadConfigFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		param, value := text, ""
+		if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+			param, value = text[:i], strings.TrimSpace(text[i:])
+		}
+		if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				return fmt.Errorf("%s:%d: invalid quoted value", path, line)
+			}
+		}
+		if err := command.SetStartupConfig(param, value); err != nil {
+			return fmt.Errorf("%s:%d: %s: %v", path, line, param, err)
+		}
+	}
+	return scanner.Err()
+}
+
 // retry executes a function up to maxRetries times with a delay between attempts
 func retry(maxRetries int, delay time.Duration, fn func() error) error {
 	var err error
@@ -30,14 +89,15 @@ func retry(maxRetries int, delay time.Duration, fn func() error) error {
 }
 
 func Handle() {
-	log.Print("Listening on port :6379")
+	addr := ":" + command.GetConfig("port")
+	log.Print("Listening on port ", addr)
 
 	var l net.Listener
 
 	// Retry listener creation
 	err := retry(5, 2*time.Second, func() error {
 		var err error
-		l, err = net.Listen("tcp", ":6379")
+		l, err = net.Listen("tcp", addr)
 		return err
 	})
 
@@ -71,32 +131,61 @@ func Handle() {
 }
 
 func handleRequests(conn net.Conn) {
-	reader := resp.NewReader(conn)
-	writer := resp.NewWriter(conn)
-	for {
-		value, err := reader.Read()
-		if err != nil {
-			// log.Print("Error reading request:", err)
-			return
-		}
+	client := command.NewClient(conn)
+	defer client.Close()
+	if !command.AdmitClient(client) {
+		return
+	}
+	for value := range readRequests(conn, client) {
 		if value.Typ != "array" || len(value.Array) == 0 {
 			log.Print("Invalid request, expected array")
-			writer.Write(resp.Value{Typ: common.STRING_TYPE, Str: "Invalid request"})
+			if client.Write(resp.Value{Typ: common.STRING_TYPE, Str: "Invalid request"}) != nil {
+				return
+			}
 			continue
 		}
 		cmd := strings.ToUpper(value.Array[0].Bulk)
 		args := value.Array[1:]
 		if cmd == "QUIT" {
-			writer.Write(resp.Value{Typ: common.STRING_TYPE, Str: "OK"})
+			client.Write(resp.Value{Typ: common.STRING_TYPE, Str: "OK"})
 			return
 		}
 		handler, ok := command.Handlers[cmd]
 		if !ok {
 			// log.Print("Invalid command: ", cmd)
-			writer.Write(resp.Value{Typ: common.STRING_TYPE, Str: "Invalid command"})
+			if client.Write(resp.Value{Typ: common.STRING_TYPE, Str: "Invalid command"}) != nil {
+				return
+			}
 			continue
 		}
-		result := handler.Func(args)
-		writer.Write(result)
+		result := command.Execute(client, cmd, handler, args)
+		if client.Write(result) != nil {
+			// The client was disconnected, most likely for exceeding its output buffer limit.
+			return
+		}
 	}
 }
+
+// readRequests reads the requests of a client in the background, so that a client which
+// disconnects while one of its commands is blocked is noticed and the command woken up.
+func readRequests(conn net.Conn, client *command.Client) <-chan resp.Value {
+	requests := make(chan resp.Value)
+	go func() {
+		defer close(requests)
+		reader := resp.NewReader(conn)
+		for {
+			value, err := reader.Read()
+			if err != nil {
+				// log.Print("Error reading request:", err)
+				client.Hangup()
+				return
+			}
+			select {
+			case requests <- value:
+			case <-client.Done():
+				return
+			}
+		}
+	}()
+	return requests
+}
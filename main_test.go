@@ -2,10 +2,16 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/divy-sh/animus/command"
 	"github.com/divy-sh/animus/resp"
 )
 
@@ -121,9 +127,79 @@ func Test_HandleRequests_EmptyCommand(t *testing.T) {
 	}
 }
 
+func TestHandleRequests_DisconnectWhileBlocked(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		handleRequests(server)
+		close(done)
+	}()
+
+	writer := resp.NewWriter(client)
+	writer.Write(resp.Value{
+		Typ: "array",
+		Array: []resp.Value{
+			{Typ: "bulk", Bulk: "XREAD"},
+			{Typ: "bulk", Bulk: "BLOCK"},
+			{Typ: "bulk", Bulk: "0"},
+			{Typ: "bulk", Bulk: "STREAMS"},
+			{Typ: "bulk", Bulk: "TestHandleRequests_DisconnectWhileBlocked"},
+			{Typ: "bulk", Bulk: "$"},
+		},
+	})
+	time.Sleep(20 * time.Millisecond)
+	client.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the blocked XREAD to end once the client disconnected")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() {
+		command.SetStartupConfig("port", "6379")
+		command.SetStartupConfig("databases", "16")
+		command.SetStartupConfig("slowlog-max-len", "128")
+		command.SetStartupConfig("notify-keyspace-events", "")
+	})
+	path := filepath.Join(t.TempDir(), "animus.conf")
+	if err := os.WriteFile(path, []byte("# animus config\n\ndatabases 4\nslowlog-max-len 10\nnotify-keyspace-events \"Kg\"\n"), 0o644); err != nil {
+		t.Fatalf("Expected no error writing the config file, got %v", err)
+	}
+
+	if err := configure([]string{path, "--slowlog-max-len", "20", "--port", "6380"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for param, expected := range map[string]string{"port": "6380", "databases": "4", "slowlog-max-len": "20", "notify-keyspace-events": "Kg"} {
+		if value := command.GetConfig(param); value != expected {
+			t.Errorf("Expected %s to be %q, got %q", param, expected, value)
+		}
+	}
+
+	for _, args := range [][]string{
+		{filepath.Join(t.TempDir(), "missing.conf")},
+		{"--databases"},
+		{"--databases", "0"},
+		{"--port", "70000"},
+		{"--unknown", "1"},
+		{path, "databases", "8"},
+	} {
+		if err := configure(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+	if value := command.GetConfig("port"); value != "6380" {
+		t.Errorf("Expected the port to be kept, got %q", value)
+	}
+}
+
 func TestHandle(t *testing.T) {
 	// Run the server in a goroutine
-	go main()
+	go Handle()
 	if err := waitForServer("127.0.0.1:6379", time.Second); err != nil {
 		t.Fatalf("server never started: %v", err)
 	}
@@ -180,3 +256,144 @@ func waitForServer(addr string, timeout time.Duration) error {
 	}
 	return fmt.Errorf("server did not start on time")
 }
+
+// targetPortEnv is set for the copies of the test binary started by startTarget,
+// which run a server on the port it holds instead of the tests.
+const targetPortEnv = "ANIMUS_TEST_TARGET_PORT"
+
+func TestMain(m *testing.M) {
+	if port := os.Getenv(targetPortEnv); port != "" {
+		if err := configure([]string{"--port", port}); err != nil {
+			log.Fatal(err)
+		}
+		Handle()
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// startTarget runs a second server, with a store of its own, as a MIGRATE target.
+// It is a copy of the test binary running on a free local port.
+func startTarget(t *testing.T) (string, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not find a free port: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), targetPortEnv+"="+port)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Could not start the target server: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	if err := waitForServer(net.JoinHostPort(host, port), 5*time.Second); err != nil {
+		t.Fatalf("target server never started: %v", err)
+	}
+	return host, port
+}
+
+// connect opens a connection to the server listening at host and port.
+func connect(t *testing.T, host, port string) (*resp.Writer, *resp.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		t.Fatalf("Could not connect to %s:%s: %v", host, port, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return resp.NewWriter(conn), resp.NewReader(conn)
+}
+
+func call(t *testing.T, writer *resp.Writer, reader *resp.Reader, args ...string) resp.Value {
+	t.Helper()
+	values := make([]resp.Value, len(args))
+	for i, arg := range args {
+		values[i] = resp.Value{Typ: "bulk", Bulk: arg}
+	}
+	writer.Write(resp.Value{Typ: "array", Array: values})
+	reply, err := reader.ReadReply()
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return reply
+}
+
+func TestMigrate(t *testing.T) {
+	host, port := startTarget(t)
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go handleRequests(server)
+	writer := resp.NewWriter(client)
+	reader := resp.NewReader(client)
+
+	call(t, writer, reader, "SET", "TestMigrate", "value")
+	call(t, writer, reader, "RPUSH", "TestMigrateList", "a", "b")
+	call(t, writer, reader, "EXPIRE", "TestMigrateList", "100")
+	if reply := call(t, writer, reader, "MIGRATE", host, port, "TestMigrate", "12", "1000"); reply.Str != "OK" {
+		t.Fatalf("Expected OK, got %v", reply)
+	}
+	if reply := call(t, writer, reader, "EXISTS", "TestMigrate"); reply.Num != 0 {
+		t.Errorf("Expected the key to be deleted locally")
+	}
+	reply := call(t, writer, reader, "MIGRATE", host, port, "", "12", "1000", "COPY", "KEYS", "TestMigrateList", "TestMigrateMissing")
+	if reply.Str != "OK" {
+		t.Fatalf("Expected OK, got %v", reply)
+	}
+	if reply := call(t, writer, reader, "EXISTS", "TestMigrateList"); reply.Num != 1 {
+		t.Errorf("Expected COPY to keep the key locally")
+	}
+
+	targetWriter, targetReader := connect(t, host, port)
+	if reply := call(t, targetWriter, targetReader, "EXISTS", "TestMigrate"); reply.Num != 0 {
+		t.Errorf("Expected the key to be kept out of the default database of the target")
+	}
+	call(t, targetWriter, targetReader, "SELECT", "12")
+	if reply := call(t, targetWriter, targetReader, "GET", "TestMigrate"); reply.Bulk != "value" {
+		t.Errorf("Expected the key on the target, got %v", reply)
+	}
+	if reply := call(t, targetWriter, targetReader, "LRANGE", "TestMigrateList", "0", "-1"); len(reply.Array) != 2 {
+		t.Errorf("Expected the list on the target, got %v", reply)
+	}
+	if reply := call(t, targetWriter, targetReader, "TTL", "TestMigrateList"); reply.Num < 99 {
+		t.Errorf("Expected the expiry to be migrated, got %v", reply)
+	}
+
+	reply = call(t, writer, reader, "MIGRATE", host, port, "TestMigrateList", "12", "1000")
+	if reply.Typ != "error" || !strings.Contains(reply.Str, "BUSYKEY") {
+		t.Errorf("Expected a BUSYKEY error, got %v", reply)
+	}
+	if reply := call(t, writer, reader, "MIGRATE", host, port, "TestMigrateList", "12", "1000", "REPLACE"); reply.Str != "OK" {
+		t.Errorf("Expected OK, got %v", reply)
+	}
+	if reply := call(t, writer, reader, "MIGRATE", host, port, "TestMigrateList", "12", "1000"); reply.Str != "NOKEY" {
+		t.Errorf("Expected NOKEY, got %v", reply)
+	}
+}
+
+func TestMigrate_UnreachableTarget(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go handleRequests(server)
+	writer := resp.NewWriter(client)
+	reader := resp.NewReader(client)
+
+	call(t, writer, reader, "SET", "TestMigrate_UnreachableTarget", "value")
+	reply := call(t, writer, reader, "MIGRATE", "127.0.0.1", "1", "TestMigrate_UnreachableTarget", "0", "100")
+	if reply.Typ != "error" || !strings.HasPrefix(reply.Str, "IOERR") {
+		t.Errorf("Expected an IOERR error, got %v", reply)
+	}
+	if reply := call(t, writer, reader, "EXISTS", "TestMigrate_UnreachableTarget"); reply.Num != 1 {
+		t.Errorf("Expected the key to be kept")
+	}
+	reply = call(t, writer, reader, "MIGRATE", "127.0.0.1", "1", "TestMigrate_UnreachableTarget", "0", "100", "KEYS", "a")
+	if reply.Typ != "error" {
+		t.Errorf("Expected an error for KEYS with a key, got %v", reply)
+	}
+}
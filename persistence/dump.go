@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc64"
+	"io"
+
+	"github.com/divy-sh/animus/common"
+)
+
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+// A dump is the encoded value followed by the format version as 2 bytes and a CRC64
+// checksum of everything before it as 8 bytes, both little endian.
+const dumpFooterSize = 2 + 8
+
+// Dump serializes a single stored value, as returned by the DUMP command.
+func Dump(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := NewEncoder(&buf)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	payload := binary.LittleEndian.AppendUint16(buf.Bytes(), FormatVersion)
+	return binary.LittleEndian.AppendUint64(payload, crc64.Checksum(payload, crcTable)), nil
+}
+
+// Load deserializes a value serialized by Dump. It fails if the checksum doesn't match
+// or the payload was written by another version of the format.
+func Load(payload []byte) (any, error) {
+	if len(payload) < dumpFooterSize {
+		return nil, errors.New(common.ERR_DUMP_PAYLOAD)
+	}
+	body, footer := payload[:len(payload)-8], payload[len(payload)-8:]
+	if crc64.Checksum(body, crcTable) != binary.LittleEndian.Uint64(footer) {
+		return nil, errors.New(common.ERR_DUMP_PAYLOAD)
+	}
+	version := binary.LittleEndian.Uint16(body[len(body)-2:])
+	if version != FormatVersion {
+		return nil, errors.New(common.ERR_DUMP_PAYLOAD)
+	}
+	decoder := NewDecoder(bytes.NewReader(body[:len(body)-2]))
+	value, err := decoder.Decode()
+	if err != nil {
+		return nil, errors.New(common.ERR_BAD_DUMP_DATA)
+	}
+	// The value has to take up the whole payload.
+	if _, err := decoder.r.ReadByte(); err != io.EOF {
+		return nil, errors.New(common.ERR_BAD_DUMP_DATA)
+	}
+	return value, nil
+}
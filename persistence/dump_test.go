@@ -0,0 +1,144 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"hash/crc64"
+	"reflect"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/types/arrays"
+	"github.com/divy-sh/animus/types/hashes"
+	"github.com/divy-sh/animus/types/json"
+	"github.com/divy-sh/animus/types/lists"
+	"github.com/divy-sh/animus/types/sets"
+	"github.com/divy-sh/animus/types/streams"
+	"github.com/divy-sh/animus/types/zsets"
+)
+
+// zsetPairs lists the members and scores of a sorted set, whose skiplist levels are random.
+func zsetPairs(z *zsets.ZSet) []any {
+	pairs := []any{}
+	z.Each(func(member string, score float64) bool {
+		pairs = append(pairs, member, score)
+		return true
+	})
+	return pairs
+}
+
+func TestDumpAndLoad(t *testing.T) {
+	list := lists.NewList()
+	for _, element := range []string{"a", "", "c", "d", "e"} {
+		list.PushBack(element)
+	}
+	intset := sets.NewSet()
+	for _, member := range []string{"3", "1", "-2"} {
+		intset.Add(member)
+	}
+	set := sets.NewSet()
+	set.Add("a")
+	set.Add("b")
+	hash := hashes.NewHash(2)
+	hash.Set("field", "value")
+	hash.Set("empty", "")
+	expiring := hash.Clone()
+	expiring.SetExpireAt("field", 1893456000000)
+	array := arrays.FromSlice([]any{"a", nil, int64(-42), 3.5, true, []any{"nested"}})
+	array.Set(1_000_000_000, "far")
+	stream := streams.NewStream()
+	stream.Append(streams.Entry{ID: streams.ID{Ms: 1, Seq: 0}, Fields: []string{"field", "value"}})
+	stream.Append(streams.Entry{ID: streams.ID{Ms: 3, Seq: 2}, Fields: []string{"a", "", "b", "c"}})
+	stream.LastID, stream.MaxDeletedID, stream.EntriesAdded = streams.ID{Ms: 4, Seq: 0}, streams.ID{Ms: 4, Seq: 0}, 3
+	group := streams.NewGroup(streams.ID{Ms: 3, Seq: 2}, -1)
+	group.AddPending(streams.ID{Ms: 1, Seq: 0}, &streams.PendingEntry{Consumer: "alice", DeliveredAt: 1700000000000, Deliveries: 2})
+	group.Consumers["alice"] = &streams.Consumer{SeenAt: 1700000000000, ActiveAt: -1}
+	stream.SetGroup("group", group)
+	stream.SetGroup("empty", streams.NewGroup(streams.ID{}, 0))
+	zset := zsets.NewZSet()
+	zset.Add("b", 3479447370796909)
+	zset.Add("a", -1.5)
+	zset.Add("", 0)
+	document, _ := json.NewDocument(`{"b":[1,2.0,"x\n",null,true],"a":{},"":-1.5e300}`)
+	values := []any{
+		"",
+		"hello world",
+		list,
+		hash,
+		expiring,
+		intset,
+		set,
+		array,
+		stream,
+		streams.NewStream(),
+		zset,
+		document,
+	}
+	for _, value := range values {
+		payload, err := Dump(value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		loaded, err := Load(payload)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if l, ok := value.(*lists.List); ok {
+			value, loaded = l.ToSlice(), loaded.(*lists.List).ToSlice()
+		}
+		if z, ok := value.(*zsets.ZSet); ok {
+			value, loaded = zsetPairs(z), zsetPairs(loaded.(*zsets.ZSet))
+		}
+		if !reflect.DeepEqual(loaded, value) {
+			t.Errorf("expected %v, got %v", value, loaded)
+		}
+	}
+}
+
+func TestLoad_RejectsCorruptedPayloads(t *testing.T) {
+	payload, _ := Dump("value")
+	corrupted := append([]byte{}, payload...)
+	corrupted[1] ^= 0xff
+	if _, err := Load(corrupted); err == nil || err.Error() != common.ERR_DUMP_PAYLOAD {
+		t.Errorf("expected %s, got %v", common.ERR_DUMP_PAYLOAD, err)
+	}
+	if _, err := Load(payload[:5]); err == nil {
+		t.Errorf("expected an error for a truncated payload")
+	}
+
+	newer := append([]byte{}, payload[:len(payload)-dumpFooterSize]...)
+	newer = append(newer, 0xff, 0xff)
+	newer = append(newer, make([]byte, 8)...)
+	if _, err := Load(newer); err == nil {
+		t.Errorf("expected an error for a newer format version")
+	}
+
+	older := append([]byte{}, payload[:len(payload)-dumpFooterSize]...)
+	older = append(older, 0, 0)
+	older = binary.LittleEndian.AppendUint64(older, crc64.Checksum(older, crcTable))
+	if _, err := Load(older); err == nil || err.Error() != common.ERR_DUMP_PAYLOAD {
+		t.Errorf("expected %s for format version 0, got %v", common.ERR_DUMP_PAYLOAD, err)
+	}
+}
+
+func TestLoad_RejectsPendingEntriesWithoutConsumer(t *testing.T) {
+	stream := streams.NewStream()
+	stream.Append(streams.Entry{ID: streams.ID{Ms: 1, Seq: 0}, Fields: []string{"field", "value"}})
+	stream.LastID, stream.EntriesAdded = streams.ID{Ms: 1, Seq: 0}, 1
+	group := streams.NewGroup(streams.ID{Ms: 1, Seq: 0}, 1)
+	group.AddPending(streams.ID{Ms: 1, Seq: 0}, &streams.PendingEntry{Consumer: "bob", DeliveredAt: 1700000000000, Deliveries: 1})
+	group.Consumers["alice"] = &streams.Consumer{SeenAt: 1700000000000, ActiveAt: -1}
+	stream.SetGroup("group", group)
+	payload, err := Dump(stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Load(payload); err == nil || err.Error() != common.ERR_BAD_DUMP_DATA {
+		t.Errorf("expected %s, got %v", common.ERR_BAD_DUMP_DATA, err)
+	}
+}
+
+func TestDump_UnsupportedType(t *testing.T) {
+	if _, err := Dump(struct{}{}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
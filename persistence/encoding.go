@@ -0,0 +1,522 @@
+// Package persistence implements the binary format single values are serialized to by DUMP
+// and read back from by RESTORE.
+package persistence
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/divy-sh/animus/types/arrays"
+	"github.com/divy-sh/animus/types/hashes"
+	"github.com/divy-sh/animus/types/json"
+	"github.com/divy-sh/animus/types/lists"
+	"github.com/divy-sh/animus/types/sets"
+	"github.com/divy-sh/animus/types/streams"
+	"github.com/divy-sh/animus/types/zsets"
+)
+
+// FormatVersion is the version of the value encoding. Readers only accept their own version.
+const FormatVersion uint16 = 1
+
+// Type tags written before every encoded value.
+const (
+	typeString byte = iota
+	typeList
+	typeHash
+	typeSet
+	// A slice held as an element of an array, stored arrays are written as typeSparseArray.
+	typeArray
+	// Array elements are not limited to strings.
+	typeNil
+	typeInt
+	typeFloat
+	typeBool
+	// Every field of the hash is followed by its expiry in unix milliseconds, 0 for none.
+	typeHashWithExpiry
+	// Only the indexes holding a value are written, each followed by its value.
+	typeSparseArray
+	// The entries of the stream are followed by its consumer groups.
+	typeStream
+	// Every member of the sorted set is followed by its score, in ascending order.
+	typeZSet
+	// The document is written as its compact JSON text.
+	typeJSON
+)
+
+var errCorrupted = errors.New("corrupted value encoding")
+
+// Encoder writes encoded values to a stream.
+type Encoder struct {
+	w *bufio.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes a stored value. Flush must be called once all the values are written.
+func (e *Encoder) Encode(value any) error {
+	switch v := value.(type) {
+	case string:
+		e.w.WriteByte(typeString)
+		e.writeString(v)
+	case *lists.List:
+		e.w.WriteByte(typeList)
+		e.writeLength(v.Len())
+		for _, element := range v.ToSlice() {
+			e.writeString(element)
+		}
+	case *hashes.Hash:
+		if !v.HasFieldExpiry() {
+			e.w.WriteByte(typeHash)
+		} else {
+			e.w.WriteByte(typeHashWithExpiry)
+		}
+		e.writeLength(v.Len())
+		v.Each(func(field, val string) bool {
+			e.writeString(field)
+			e.writeString(val)
+			if v.HasFieldExpiry() {
+				at, _ := v.ExpireAt(field)
+				e.w.Write(binary.AppendUvarint(nil, uint64(at)))
+			}
+			return true
+		})
+	case *sets.Set:
+		e.w.WriteByte(typeSet)
+		e.writeLength(v.Len())
+		v.Each(func(member string) bool {
+			e.writeString(member)
+			return true
+		})
+	case *arrays.Array:
+		e.w.WriteByte(typeSparseArray)
+		e.writeLength(int(v.Count()))
+		var err error
+		v.Each(0, func(index int64, element any) bool {
+			e.w.Write(binary.AppendUvarint(nil, uint64(index)))
+			err = e.Encode(element)
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+	case *streams.Stream:
+		e.w.WriteByte(typeStream)
+		e.encodeStream(v)
+	case *zsets.ZSet:
+		e.w.WriteByte(typeZSet)
+		e.writeLength(v.Len())
+		v.Each(func(member string, score float64) bool {
+			e.writeString(member)
+			e.w.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(score)))
+			return true
+		})
+	case *json.Document:
+		e.w.WriteByte(typeJSON)
+		e.writeString(v.String())
+	case []any:
+		e.w.WriteByte(typeArray)
+		e.writeLength(len(v))
+		for _, element := range v {
+			if err := e.Encode(element); err != nil {
+				return err
+			}
+		}
+	case nil:
+		e.w.WriteByte(typeNil)
+	case int64:
+		e.w.WriteByte(typeInt)
+		e.w.Write(binary.AppendVarint(nil, v))
+	case float64:
+		e.w.WriteByte(typeFloat)
+		e.w.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)))
+	case bool:
+		e.w.WriteByte(typeBool)
+		if v {
+			e.w.WriteByte(1)
+		} else {
+			e.w.WriteByte(0)
+		}
+	default:
+		return fmt.Errorf("cannot encode value of type %T", value)
+	}
+	return nil
+}
+
+// encodeStream writes the counters of a stream, its entries and its consumer groups
+// along with their pending entries and consumers, all in ascending order.
+func (e *Encoder) encodeStream(s *streams.Stream) {
+	e.writeID(s.LastID)
+	e.writeID(s.MaxDeletedID)
+	e.w.Write(binary.AppendVarint(nil, s.EntriesAdded))
+	e.writeLength(s.Len())
+	s.Each(func(entry streams.Entry) bool {
+		e.writeID(entry.ID)
+		e.writeLength(len(entry.Fields))
+		for _, field := range entry.Fields {
+			e.writeString(field)
+		}
+		return true
+	})
+	names := s.GroupNames()
+	e.writeLength(len(names))
+	for _, name := range names {
+		g, _ := s.Group(name)
+		e.writeString(name)
+		e.writeID(g.LastID)
+		e.w.Write(binary.AppendVarint(nil, g.EntriesRead))
+		e.writeLength(g.PendingLen())
+		g.EachPending(streams.ID{}, func(id streams.ID, p *streams.PendingEntry) bool {
+			e.writeID(id)
+			e.writeString(p.Consumer)
+			e.w.Write(binary.AppendVarint(nil, p.DeliveredAt))
+			e.w.Write(binary.AppendVarint(nil, p.Deliveries))
+			return true
+		})
+		e.writeLength(len(g.Consumers))
+		for _, consumer := range slices.Sorted(maps.Keys(g.Consumers)) {
+			c := g.Consumers[consumer]
+			e.writeString(consumer)
+			e.w.Write(binary.AppendVarint(nil, c.SeenAt))
+			e.w.Write(binary.AppendVarint(nil, c.ActiveAt))
+		}
+	}
+}
+
+func (e *Encoder) writeID(id streams.ID) {
+	e.w.Write(binary.AppendUvarint(nil, id.Ms))
+	e.w.Write(binary.AppendUvarint(nil, id.Seq))
+}
+
+// Flush writes the buffered data to the underlying writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *Encoder) writeLength(n int) {
+	e.w.Write(binary.AppendUvarint(nil, uint64(n)))
+}
+
+func (e *Encoder) writeString(s string) {
+	e.writeLength(len(s))
+	e.w.WriteString(s)
+}
+
+// Decoder reads encoded values from a stream.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next value.
+func (d *Decoder) Decode() (any, error) {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case typeString:
+		return d.readString()
+	case typeList:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		list := lists.NewList()
+		for range n {
+			element, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			list.PushBack(element)
+		}
+		return list, nil
+	case typeHash, typeHashWithExpiry:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		hash := hashes.NewHash(preallocate(n))
+		for range n {
+			field, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			val, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(field, val)
+			if typ == typeHashWithExpiry {
+				at, err := binary.ReadUvarint(d.r)
+				if err != nil {
+					return nil, err
+				}
+				if at > 0 {
+					hash.SetExpireAt(field, int64(at))
+				}
+			}
+		}
+		return hash, nil
+	case typeSet:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		set := sets.NewSet()
+		for range n {
+			member, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			set.Add(member)
+		}
+		return set, nil
+	case typeArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		array := make([]any, 0, preallocate(n))
+		for range n {
+			element, err := d.Decode()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, element)
+		}
+		return array, nil
+	case typeSparseArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		array := arrays.NewArray()
+		for range n {
+			index, err := binary.ReadUvarint(d.r)
+			if err != nil {
+				return nil, err
+			}
+			if index > arrays.MaxIndex {
+				return nil, errCorrupted
+			}
+			element, err := d.Decode()
+			if err != nil {
+				return nil, err
+			}
+			array.Set(int64(index), element)
+		}
+		return array, nil
+	case typeStream:
+		return d.decodeStream()
+	case typeZSet:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		z := zsets.NewZSet()
+		for range n {
+			member, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			var bits [8]byte
+			if _, err := io.ReadFull(d.r, bits[:]); err != nil {
+				return nil, err
+			}
+			score := math.Float64frombits(binary.LittleEndian.Uint64(bits[:]))
+			if math.IsNaN(score) {
+				return nil, errCorrupted
+			}
+			z.Add(member, score)
+		}
+		return z, nil
+	case typeJSON:
+		text, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+		document, err := json.NewDocument(text)
+		if err != nil {
+			return nil, errCorrupted
+		}
+		return document, nil
+	case typeNil:
+		return nil, nil
+	case typeInt:
+		return binary.ReadVarint(d.r)
+	case typeFloat:
+		var bits [8]byte
+		if _, err := io.ReadFull(d.r, bits[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(bits[:])), nil
+	case typeBool:
+		b, err := d.r.ReadByte()
+		return b == 1, err
+	default:
+		return nil, errCorrupted
+	}
+}
+
+func (d *Decoder) decodeStream() (*streams.Stream, error) {
+	s := streams.NewStream()
+	var err error
+	if s.LastID, err = d.readID(); err != nil {
+		return nil, err
+	}
+	if s.MaxDeletedID, err = d.readID(); err != nil {
+		return nil, err
+	}
+	if s.EntriesAdded, err = binary.ReadVarint(d.r); err != nil {
+		return nil, err
+	}
+	n, err := d.readLength()
+	if err != nil {
+		return nil, err
+	}
+	previous := streams.ID{}
+	for i := range n {
+		id, err := d.readID()
+		if err != nil {
+			return nil, err
+		}
+		// The entries have to be in ascending order and not past the last ID of the stream.
+		if (i > 0 && id.Compare(previous) <= 0) || id.Compare(s.LastID) > 0 {
+			return nil, errCorrupted
+		}
+		previous = id
+		fieldCount, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		fields := make([]string, 0, preallocate(fieldCount))
+		for range fieldCount {
+			field, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+		s.Append(streams.Entry{ID: id, Fields: fields})
+	}
+	groupCount, err := d.readLength()
+	if err != nil {
+		return nil, err
+	}
+	for range groupCount {
+		name, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+		lastID, err := d.readID()
+		if err != nil {
+			return nil, err
+		}
+		entriesRead, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return nil, err
+		}
+		g := streams.NewGroup(lastID, entriesRead)
+		pendingCount, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		for range pendingCount {
+			id, err := d.readID()
+			if err != nil {
+				return nil, err
+			}
+			p := &streams.PendingEntry{}
+			if p.Consumer, err = d.readString(); err != nil {
+				return nil, err
+			}
+			if p.DeliveredAt, err = binary.ReadVarint(d.r); err != nil {
+				return nil, err
+			}
+			if p.Deliveries, err = binary.ReadVarint(d.r); err != nil {
+				return nil, err
+			}
+			g.AddPending(id, p)
+		}
+		consumerCount, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		for range consumerCount {
+			consumer, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			c := &streams.Consumer{}
+			if c.SeenAt, err = binary.ReadVarint(d.r); err != nil {
+				return nil, err
+			}
+			if c.ActiveAt, err = binary.ReadVarint(d.r); err != nil {
+				return nil, err
+			}
+			g.Consumers[consumer] = c
+		}
+		// Every pending entry has to be owned by a consumer of the group.
+		owned := true
+		g.EachPending(streams.ID{}, func(_ streams.ID, p *streams.PendingEntry) bool {
+			_, owned = g.Consumers[p.Consumer]
+			return owned
+		})
+		if !owned {
+			return nil, errCorrupted
+		}
+		s.SetGroup(name, g)
+	}
+	return s, nil
+}
+
+func (d *Decoder) readID() (streams.ID, error) {
+	ms, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return streams.ID{}, err
+	}
+	seq, err := binary.ReadUvarint(d.r)
+	return streams.ID{Ms: ms, Seq: seq}, err
+}
+
+func (d *Decoder) readLength() (int, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt32 {
+		return 0, errCorrupted
+	}
+	return int(n), nil
+}
+
+// preallocate bounds the capacity allocated up front for a length read from the input,
+// so a corrupted length fails when the input runs out rather than by exhausting memory.
+func preallocate(n int) int {
+	return min(n, 1024)
+}
+
+func (d *Decoder) readString() (string, error) {
+	n, err := d.readLength()
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.Grow(preallocate(n))
+	if _, err := io.CopyN(&sb, d.r, int64(n)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,75 @@
+package pubsub
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/divy-sh/animus/common"
+)
+
+// Keyspace event classes, each one enabled by its letter in notify-keyspace-events.
+const (
+	NOTIFY_KEYSPACE = 1 << iota // K
+	NOTIFY_KEYEVENT             // E
+	NOTIFY_GENERIC              // g
+	NOTIFY_STRING               // $
+	NOTIFY_LIST                 // l
+	NOTIFY_SET                  // s
+	NOTIFY_HASH                 // h
+	NOTIFY_ZSET                 // z
+	NOTIFY_EXPIRED              // x
+	NOTIFY_EVICTED              // e
+	NOTIFY_STREAM               // t
+	NOTIFY_KEY_MISS             // m
+	NOTIFY_NEW                  // n
+	NOTIFY_ARRAY                // a
+	NOTIFY_JSON                 // d
+)
+
+// NOTIFY_ALL is the A alias, it leaves out the key miss and new key events like Redis does.
+const NOTIFY_ALL = NOTIFY_GENERIC | NOTIFY_STRING | NOTIFY_LIST | NOTIFY_SET | NOTIFY_HASH |
+	NOTIFY_ZSET | NOTIFY_EXPIRED | NOTIFY_EVICTED | NOTIFY_STREAM | NOTIFY_ARRAY | NOTIFY_JSON
+
+var notifyFlags atomic.Int64
+
+var notifyLetters = map[rune]int64{
+	'K': NOTIFY_KEYSPACE, 'E': NOTIFY_KEYEVENT, 'g': NOTIFY_GENERIC, '$': NOTIFY_STRING,
+	'l': NOTIFY_LIST, 's': NOTIFY_SET, 'h': NOTIFY_HASH, 'z': NOTIFY_ZSET, 'x': NOTIFY_EXPIRED,
+	'e': NOTIFY_EVICTED, 't': NOTIFY_STREAM, 'm': NOTIFY_KEY_MISS, 'n': NOTIFY_NEW,
+	'a': NOTIFY_ARRAY, 'd': NOTIFY_JSON, 'A': NOTIFY_ALL,
+}
+
+// SetNotifyKeyspaceEvents parses the notify-keyspace-events config value, e.g. "KEA" or "Kx".
+// Notifications are only sent when K or E is given along with at least one event class.
+func SetNotifyKeyspaceEvents(value string) error {
+	var flags int64
+	for _, letter := range value {
+		flag, ok := notifyLetters[letter]
+		if !ok {
+			return errors.New(common.ERR_INVALID_CONFIG_VALUE)
+		}
+		flags |= flag
+	}
+	if flags&(NOTIFY_KEYSPACE|NOTIFY_KEYEVENT) == 0 {
+		flags = 0
+	}
+	notifyFlags.Store(flags)
+	return nil
+}
+
+// NotifyKeyspaceEvent publishes event on the stored key to the __keyspace@<db>__:<key> and
+// __keyevent@<db>__:<event> channels, if the event class is enabled in notify-keyspace-events.
+func NotifyKeyspaceEvent(class int64, event, key string) {
+	flags := notifyFlags.Load()
+	if flags&class == 0 {
+		return
+	}
+	db, name := common.SplitDBKey(key)
+	if flags&NOTIFY_KEYSPACE != 0 {
+		Publish("__keyspace@"+strconv.Itoa(db)+"__:"+name, event)
+	}
+	if flags&NOTIFY_KEYEVENT != 0 {
+		Publish("__keyevent@"+strconv.Itoa(db)+"__:"+event, name)
+	}
+}
@@ -0,0 +1,53 @@
+package pubsub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotifyKeyspaceEvent(t *testing.T) {
+	defer SetNotifyKeyspaceEvents("")
+	subscriber := &recorder{}
+	Subscribe(subscriber, "__keyspace@0__:TestNotify")
+	Subscribe(subscriber, "__keyevent@0__:set")
+	defer Unsubscribe(subscriber, "__keyspace@0__:TestNotify")
+	defer Unsubscribe(subscriber, "__keyevent@0__:set")
+
+	NotifyKeyspaceEvent(NOTIFY_STRING, "set", "TestNotify")
+	if got := subscriber.received(); len(got) != 0 {
+		t.Fatalf("expected notifications to be disabled by default, got %v", got)
+	}
+
+	if err := SetNotifyKeyspaceEvents("K$"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	NotifyKeyspaceEvent(NOTIFY_STRING, "set", "TestNotify")
+	NotifyKeyspaceEvent(NOTIFY_LIST, "lpush", "TestNotify")
+	if got := subscriber.received(); !reflect.DeepEqual(got, []string{"__keyspace@0__:TestNotify set"}) {
+		t.Errorf("unexpected notifications %v", got)
+	}
+
+	if err := SetNotifyKeyspaceEvents("EA"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	NotifyKeyspaceEvent(NOTIFY_STRING, "set", "TestNotify")
+	got := subscriber.received()
+	if got[len(got)-1] != "__keyevent@0__:set TestNotify" {
+		t.Errorf("unexpected notifications %v", got)
+	}
+}
+
+func TestSetNotifyKeyspaceEvents(t *testing.T) {
+	defer SetNotifyKeyspaceEvents("")
+	if err := SetNotifyKeyspaceEvents("KEq"); err == nil {
+		t.Errorf("expected an error for an unknown class")
+	}
+	SetNotifyKeyspaceEvents("A")
+	if notifyFlags.Load() != 0 {
+		t.Errorf("expected notifications to stay off without K or E")
+	}
+	SetNotifyKeyspaceEvents("KEA")
+	if flags := notifyFlags.Load(); flags&NOTIFY_KEY_MISS != 0 || flags&NOTIFY_EXPIRED == 0 {
+		t.Errorf("unexpected flags %b", flags)
+	}
+}
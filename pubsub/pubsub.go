@@ -0,0 +1,138 @@
+package pubsub
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/divy-sh/animus/common"
+)
+
+// Subscriber receives the messages published on the channels and patterns it subscribed to.
+type Subscriber interface {
+	Message(channel, message string)
+	PMessage(pattern, channel, message string)
+}
+
+type registry struct {
+	mutex    sync.RWMutex
+	channels map[string]map[Subscriber]struct{}
+	patterns map[string]map[Subscriber]struct{}
+}
+
+var subscriptions = &registry{
+	channels: map[string]map[Subscriber]struct{}{},
+	patterns: map[string]map[Subscriber]struct{}{},
+}
+
+// Subscribe subscribes s to channel and reports whether it wasn't subscribed already.
+func Subscribe(s Subscriber, channel string) bool {
+	return subscriptions.add(subscriptions.channels, s, channel)
+}
+
+// Unsubscribe unsubscribes s from channel and reports whether it was subscribed.
+func Unsubscribe(s Subscriber, channel string) bool {
+	return subscriptions.remove(subscriptions.channels, s, channel)
+}
+
+// PSubscribe subscribes s to every channel matching the glob-style pattern.
+func PSubscribe(s Subscriber, pattern string) bool {
+	return subscriptions.add(subscriptions.patterns, s, pattern)
+}
+
+// PUnsubscribe unsubscribes s from pattern and reports whether it was subscribed.
+func PUnsubscribe(s Subscriber, pattern string) bool {
+	return subscriptions.remove(subscriptions.patterns, s, pattern)
+}
+
+// Publish sends message to the subscribers of channel and of the patterns matching it.
+// It returns the number of subscribers that received the message.
+func Publish(channel, message string) int64 {
+	type delivery struct {
+		subscriber Subscriber
+		pattern    string
+	}
+	subscriptions.mutex.RLock()
+	deliveries := []delivery{}
+	for s := range subscriptions.channels[channel] {
+		deliveries = append(deliveries, delivery{s, ""})
+	}
+	for pattern, subscribers := range subscriptions.patterns {
+		if !common.MatchPattern(channel, pattern) {
+			continue
+		}
+		for s := range subscribers {
+			deliveries = append(deliveries, delivery{s, pattern})
+		}
+	}
+	subscriptions.mutex.RUnlock()
+
+	// Deliver outside of the lock so subscribers can unsubscribe while handling a message.
+	for _, d := range deliveries {
+		if d.pattern == "" {
+			d.subscriber.Message(channel, message)
+		} else {
+			d.subscriber.PMessage(d.pattern, channel, message)
+		}
+	}
+	return int64(len(deliveries))
+}
+
+// Channels returns the active channels matching pattern, or all of them if pattern is empty.
+func Channels(pattern string) []string {
+	subscriptions.mutex.RLock()
+	defer subscriptions.mutex.RUnlock()
+	channels := []string{}
+	for channel := range subscriptions.channels {
+		if pattern == "" || common.MatchPattern(channel, pattern) {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// NumSub returns the number of subscribers of channel.
+func NumSub(channel string) int64 {
+	subscriptions.mutex.RLock()
+	defer subscriptions.mutex.RUnlock()
+	return int64(len(subscriptions.channels[channel]))
+}
+
+// NumPat returns the number of patterns subscribed to.
+func NumPat() int64 {
+	subscriptions.mutex.RLock()
+	defer subscriptions.mutex.RUnlock()
+	return int64(len(subscriptions.patterns))
+}
+
+func (r *registry) add(index map[string]map[Subscriber]struct{}, s Subscriber, name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	subscribers, ok := index[name]
+	if !ok {
+		subscribers = map[Subscriber]struct{}{}
+		index[name] = subscribers
+	}
+	if _, exists := subscribers[s]; exists {
+		return false
+	}
+	subscribers[s] = struct{}{}
+	return true
+}
+
+func (r *registry) remove(index map[string]map[Subscriber]struct{}, s Subscriber, name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	subscribers, ok := index[name]
+	if !ok {
+		return false
+	}
+	if _, exists := subscribers[s]; !exists {
+		return false
+	}
+	delete(subscribers, s)
+	if len(subscribers) == 0 {
+		delete(index, name)
+	}
+	return true
+}
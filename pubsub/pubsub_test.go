@@ -0,0 +1,88 @@
+package pubsub
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recorder struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (r *recorder) Message(channel, message string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.messages = append(r.messages, channel+" "+message)
+}
+
+func (r *recorder) PMessage(pattern, channel, message string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.messages = append(r.messages, pattern+" "+channel+" "+message)
+}
+
+func (r *recorder) received() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string{}, r.messages...)
+}
+
+func TestPublish(t *testing.T) {
+	subscriber := &recorder{}
+	if !Subscribe(subscriber, "TestPublish") {
+		t.Fatalf("expected a new subscription")
+	}
+	if Subscribe(subscriber, "TestPublish") {
+		t.Errorf("expected the subscription to exist already")
+	}
+	defer Unsubscribe(subscriber, "TestPublish")
+
+	if n := Publish("TestPublish", "hello"); n != 1 {
+		t.Errorf("expected 1 receiver, got %d", n)
+	}
+	if n := Publish("TestPublishOther", "hello"); n != 0 {
+		t.Errorf("expected 0 receivers, got %d", n)
+	}
+	if got := subscriber.received(); !reflect.DeepEqual(got, []string{"TestPublish hello"}) {
+		t.Errorf("unexpected messages %v", got)
+	}
+}
+
+func TestPSubscribe(t *testing.T) {
+	subscriber := &recorder{}
+	PSubscribe(subscriber, "TestPSubscribe.*")
+	Publish("TestPSubscribe.a", "1")
+	Publish("TestPSubscribe", "2")
+	if NumPat() < 1 {
+		t.Errorf("expected the pattern to be counted")
+	}
+	if !PUnsubscribe(subscriber, "TestPSubscribe.*") {
+		t.Errorf("expected the pattern to be removed")
+	}
+	Publish("TestPSubscribe.b", "3")
+	if got := subscriber.received(); !reflect.DeepEqual(got, []string{"TestPSubscribe.* TestPSubscribe.a 1"}) {
+		t.Errorf("unexpected messages %v", got)
+	}
+}
+
+func TestChannelsAndNumSub(t *testing.T) {
+	first, second := &recorder{}, &recorder{}
+	Subscribe(first, "TestChannels.a")
+	Subscribe(second, "TestChannels.a")
+	Subscribe(second, "TestChannels.b")
+	defer Unsubscribe(first, "TestChannels.a")
+	defer Unsubscribe(second, "TestChannels.a")
+
+	if n := NumSub("TestChannels.a"); n != 2 {
+		t.Errorf("expected 2 subscribers, got %d", n)
+	}
+	if got := Channels("TestChannels.*"); !reflect.DeepEqual(got, []string{"TestChannels.a", "TestChannels.b"}) {
+		t.Errorf("unexpected channels %v", got)
+	}
+	Unsubscribe(second, "TestChannels.b")
+	if got := Channels("TestChannels.*"); !reflect.DeepEqual(got, []string{"TestChannels.a"}) {
+		t.Errorf("expected channels without subscribers to be dropped, got %v", got)
+	}
+}
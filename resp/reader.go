@@ -36,6 +36,59 @@ func (r *Reader) Read() (Value, error) {
 	}
 }
 
+// ReadReply reads a reply sent by a server, which unlike a request can be of any type.
+func (r *Reader) ReadReply() (Value, error) {
+	firstByte, err := r.reader.ReadByte()
+	if err != nil {
+		return Value{}, err
+	}
+	switch firstByte {
+	case STRING, ERROR:
+		line, _, err := r.readLine()
+		if err != nil {
+			return Value{}, err
+		}
+		if firstByte == ERROR {
+			return Value{Typ: common.ERROR_TYPE, Str: string(line)}, nil
+		}
+		return Value{Typ: common.STRING_TYPE, Str: string(line)}, nil
+	case INTEGER:
+		n, err := r.readInt()
+		return Value{Typ: common.INTEGER_TYPE, Num: int64(n)}, err
+	case BULK:
+		len, err := r.readInt()
+		if err != nil {
+			return Value{}, err
+		}
+		if len < 0 {
+			return Value{Typ: common.NULL_TYPE}, nil
+		}
+		bulk := make([]byte, len)
+		if _, err := io.ReadFull(r.reader, bulk); err != nil {
+			return Value{}, err
+		}
+		r.readLine()
+		return Value{Typ: common.BULK_TYPE, Bulk: string(bulk)}, nil
+	case ARRAY:
+		len, err := r.readInt()
+		if err != nil {
+			return Value{}, err
+		}
+		if len < 0 {
+			return Value{Typ: common.NULL_TYPE}, nil
+		}
+		v := Value{Typ: common.ARRAY_TYPE, Array: make([]Value, len)}
+		for i := range len {
+			if v.Array[i], err = r.ReadReply(); err != nil {
+				return v, err
+			}
+		}
+		return v, nil
+	default:
+		return Value{}, errors.New("ERR unknown reply type " + strconv.Quote(string(firstByte)))
+	}
+}
+
 func (r *Reader) readArray() (Value, error) {
 	len, err := r.readInt()
 	if err != nil {
@@ -61,7 +114,10 @@ func (r *Reader) readBulk() (Value, error) {
 		return Value{}, err
 	}
 	bulk := make([]byte, len)
-	r.reader.Read(bulk)
+	// A single Read stops at the end of the buffered data, large bulks take several.
+	if _, err := io.ReadFull(r.reader, bulk); err != nil {
+		return Value{}, err
+	}
 	r.readLine()
 	return Value{
 		Typ:  common.BULK_TYPE,
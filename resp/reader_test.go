@@ -3,7 +3,9 @@ package resp_test
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/divy-sh/animus/common"
 	"github.com/divy-sh/animus/resp"
@@ -118,3 +120,37 @@ func TestReadIntNoValue(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 }
+
+func TestReadBulkString_LargerThanBuffer(t *testing.T) {
+	payload := strings.Repeat("x", 10000)
+	input := "$10000\r\n" + payload + "\r\n"
+	r := resp.NewReader(iotest.HalfReader(bytes.NewBufferString(input)))
+	val, err := r.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if val.Bulk != payload {
+		t.Errorf("Expected the whole bulk, got %d bytes", len(val.Bulk))
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	input := "+OK\r\n-ERR failed\r\n:42\r\n$-1\r\n*2\r\n$5\r\nhello\r\n:1\r\n"
+	r := resp.NewReader(bytes.NewBufferString(input))
+	expected := []resp.Value{
+		{Typ: common.STRING_TYPE, Str: "OK"},
+		{Typ: common.ERROR_TYPE, Str: "ERR failed"},
+		{Typ: common.INTEGER_TYPE, Num: 42},
+		{Typ: common.NULL_TYPE},
+		{Typ: common.ARRAY_TYPE, Array: []resp.Value{{Typ: common.BULK_TYPE, Bulk: "hello"}, {Typ: common.INTEGER_TYPE, Num: 1}}},
+	}
+	for _, want := range expected {
+		val, err := r.ReadReply()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(val, want) {
+			t.Errorf("Expected %v, got %v", want, val)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"math/rand"
+	"time"
+)
+
+// The access frequency of a key is a logarithmic counter, like the Redis LFU counter.
+// It starts at lfuInitVal so new keys aren't the first ones considered cold, grows more
+// slowly the higher it gets and decays by one for every lfuDecayTime the key isn't accessed.
+const (
+	lfuInitVal   = 5
+	lfuLogFactor = 10
+	lfuMaxVal    = 255
+	lfuDecayTime = time.Minute
+)
+
+// IdleTime returns the time since the value was last read or written.
+func (v *Value) IdleTime() time.Duration {
+	return time.Since(time.UnixMilli(v.lastAccess.Load()))
+}
+
+// Freq returns the logarithmic access frequency counter of the value.
+func (v *Value) Freq() int64 {
+	return v.decayedFreq(time.Now())
+}
+
+// SetAccess sets the access metadata of the value, as if it had last been accessed idle ago
+// with the given frequency counter.
+func (v *Value) SetAccess(idle time.Duration, freq int64) {
+	v.lastAccess.Store(time.Now().Add(-idle).UnixMilli())
+	v.freq.Store(min(max(freq, 0), lfuMaxVal))
+}
+
+func (v *Value) decayedFreq(now time.Time) int64 {
+	freq := v.freq.Load()
+	periods := int64(now.Sub(time.UnixMilli(v.lastAccess.Load())) / lfuDecayTime)
+	return max(freq-periods, 0)
+}
+
+// touch records an access to the value.
+func (v *Value) touch() {
+	now := time.Now()
+	freq := v.decayedFreq(now)
+	if freq < lfuMaxVal {
+		base := max(freq-lfuInitVal, 0)
+		if rand.Float64() < 1.0/float64(base*lfuLogFactor+1) {
+			freq++
+		}
+	}
+	v.freq.Store(freq)
+	v.lastAccess.Store(now.UnixMilli())
+}
+
+// newValue creates a value accessed right now. It inherits the access frequency of the
+// value it replaces, so overwriting a hot key doesn't make it look cold.
+func newValue(val any, ttl int64, previous *Value) *Value {
+	value := &Value{Val: val, TTL: ttl}
+	freq := int64(lfuInitVal)
+	if previous != nil {
+		freq = max(previous.Freq(), freq)
+	}
+	value.freq.Store(freq)
+	value.lastAccess.Store(time.Now().UnixMilli())
+	return value
+}
@@ -0,0 +1,84 @@
+package store
+
+import "sync"
+
+// FieldExpirer is implemented by values whose elements can expire on their own, like the
+// fields of a hash. The expiry cleaner keeps track of the keys holding such values and
+// passes them to the handler set with SetFieldExpiryHandler.
+type FieldExpirer interface {
+	HasFieldExpiry() bool
+}
+
+var fieldExpiry = struct {
+	sync.Mutex
+	keys    map[any]struct{}
+	handler func(key any)
+}{keys: map[any]struct{}{}}
+
+// SetFieldExpiryHandler sets the function the expiry cleaner calls with the tracked keys.
+// It must remove the expired elements of the key's value, and call UntrackFieldExpiry
+// once the key no longer holds a value with expiring elements.
+func SetFieldExpiryHandler(handler func(key any)) {
+	fieldExpiry.Lock()
+	defer fieldExpiry.Unlock()
+	fieldExpiry.handler = handler
+}
+
+// TrackFieldExpiry makes the expiry cleaner look at a key whose value has expiring elements.
+func TrackFieldExpiry(key any) {
+	fieldExpiry.Lock()
+	defer fieldExpiry.Unlock()
+	fieldExpiry.keys[key] = struct{}{}
+}
+
+// UntrackFieldExpiry stops the expiry cleaner from looking at a key.
+func UntrackFieldExpiry(key any) {
+	fieldExpiry.Lock()
+	defer fieldExpiry.Unlock()
+	delete(fieldExpiry.keys, key)
+}
+
+// trackValue tracks key if the value stored at it has expiring elements.
+func trackValue(key, val any) {
+	if expirer, ok := val.(FieldExpirer); ok && expirer.HasFieldExpiry() {
+		TrackFieldExpiry(key)
+	}
+}
+
+// renameTracked moves the tracking of renamed keys to their new names. All the old names
+// are removed first, so keys can trade places.
+func renameTracked(renames map[any]any) {
+	fieldExpiry.Lock()
+	defer fieldExpiry.Unlock()
+	tracked := []any{}
+	for key, newKey := range renames {
+		if _, ok := fieldExpiry.keys[key]; ok {
+			delete(fieldExpiry.keys, key)
+			tracked = append(tracked, newKey)
+		}
+	}
+	for _, key := range tracked {
+		fieldExpiry.keys[key] = struct{}{}
+	}
+}
+
+// cleanExpiredFields passes a sample of the tracked keys to the field expiry handler.
+func cleanExpiredFields() {
+	const sampleSize = 20
+	fieldExpiry.Lock()
+	handler := fieldExpiry.handler
+	keys := make([]any, 0, sampleSize)
+	for key := range fieldExpiry.keys {
+		if len(keys) == sampleSize {
+			break
+		}
+		keys = append(keys, key)
+	}
+	fieldExpiry.Unlock()
+	if handler == nil {
+		return
+	}
+	for _, key := range keys {
+		handler(key)
+	}
+}
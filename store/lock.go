@@ -1,7 +1,7 @@
 package store
 
 import (
-	"fmt"
+	"slices"
 	"sort"
 	"sync"
 )
@@ -59,11 +59,11 @@ func UnlockKeys(keys ...string) {
 	}
 }
 
+// sortKeys returns the keys sorted and without duplicates, so a key given twice, as in
+// RENAME key key, isn't locked twice.
 func sortKeys(keys []string) []string {
 	sortedKeys := make([]string, len(keys))
 	copy(sortedKeys, keys)
-	sort.Slice(sortedKeys, func(i, j int) bool {
-		return fmt.Sprintf("%v", sortedKeys[i]) < fmt.Sprintf("%v", sortedKeys[j])
-	})
-	return sortedKeys
+	sort.Strings(sortedKeys)
+	return slices.Compact(sortedKeys)
 }
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+)
+
+// Counters describing the keyspace activity, reported by INFO.
+var (
+	keyspaceHits   atomic.Int64
+	keyspaceMisses atomic.Int64
+	expiredKeys    atomic.Int64
+	evictedKeys    atomic.Int64
+)
+
+type Stats struct {
+	KeyspaceHits   int64
+	KeyspaceMisses int64
+	ExpiredKeys    int64
+	EvictedKeys    int64
+}
+
+type KeyspaceInfo struct {
+	Keys    int64
+	Expires int64
+	// AvgTTL is the average remaining time to live of the keys with an expiry, in milliseconds.
+	AvgTTL int64
+}
+
+func GetStats() Stats {
+	return Stats{
+		KeyspaceHits:   keyspaceHits.Load(),
+		KeyspaceMisses: keyspaceMisses.Load(),
+		ExpiredKeys:    expiredKeys.Load(),
+		EvictedKeys:    evictedKeys.Load(),
+	}
+}
+
+func ResetStats() {
+	keyspaceHits.Store(0)
+	keyspaceMisses.Store(0)
+	expiredKeys.Store(0)
+	evictedKeys.Store(0)
+}
+
+// GetKeyspaceInfo returns the keyspace statistics of every database holding keys.
+func GetKeyspaceInfo() map[int]KeyspaceInfo {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	infos := map[int]KeyspaceInfo{}
+	ttlSums := map[int]int64{}
+	now := time.Now().Unix()
+	for _, key := range keys() {
+		val, ok := peek(key)
+		if !ok {
+			continue
+		}
+		db, _ := common.SplitDBKey(keyName(key))
+		info := infos[db]
+		info.Keys++
+		if ttl := val.(*Value).TTL; ttl > -1 {
+			info.Expires++
+			if ttl > now {
+				ttlSums[db] += (ttl - now) * 1000
+			}
+		}
+		infos[db] = info
+	}
+	for db, info := range infos {
+		if info.Expires > 0 {
+			info.AvgTTL = ttlSums[db] / info.Expires
+			infos[db] = info
+		}
+	}
+	return infos
+}
@@ -1,14 +1,22 @@
 package store
 
 import (
+	"fmt"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
 	lru "github.com/hashicorp/golang-lru/v2"
 )
 
 type Store struct {
-	LRUCache    *lru.Cache[any, any]
+	// dbs holds the keys of each logical database in a cache of its own, so the keys of a
+	// busy database don't evict the keys of the others. A database gets its cache with its first key.
+	dbs         map[int]*lru.Cache[any, any]
 	stopCleaner chan struct{}
 	mutex       sync.RWMutex
 	isRunning   bool
@@ -17,16 +25,21 @@ type Store struct {
 type Value struct {
 	Val any
 	TTL int64
+	// lastAccess (unix milliseconds) and freq are the access metadata reported by OBJECT.
+	lastAccess atomic.Int64
+	freq       atomic.Int64
 }
 
+// capacity is the number of keys a database keeps before its least recently used ones get evicted.
+const capacity = 100000
+
 var (
 	store *Store
 )
 
 func init() {
-	cache, _ := lru.New[any, any](100000)
 	store = &Store{
-		LRUCache:    cache,
+		dbs:         map[int]*lru.Cache[any, any]{},
 		stopCleaner: make(chan struct{}),
 		isRunning:   false,
 	}
@@ -36,18 +49,24 @@ func init() {
 
 func Get[K comparable, V any](key K) (V, bool) {
 	store.mutex.RLock()
-	val, ok := store.LRUCache.Get(key)
+	val, ok := get(key)
 	store.mutex.RUnlock()
 	if !ok {
+		miss(key)
 		var zero V
 		return zero, false
 	}
 	value := val.(*Value)
 	if value.TTL > -1 && value.TTL <= time.Now().Unix() {
 		Delete(key)
+		expiredKeys.Add(1)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_EXPIRED, "expired", keyName(key))
+		miss(key)
 		var zero V
 		return zero, false
 	}
+	keyspaceHits.Add(1)
+	value.touch()
 	if typedVal, ok := value.Val.(V); ok {
 		return typedVal, true
 	}
@@ -57,18 +76,24 @@ func Get[K comparable, V any](key K) (V, bool) {
 
 func GetWithTTL[K comparable, V any](key K) (V, int64, bool) {
 	store.mutex.RLock()
-	val, ok := store.LRUCache.Get(key)
+	val, ok := get(key)
 	store.mutex.RUnlock()
 	if !ok {
+		miss(key)
 		var zero V
 		return zero, -1, false
 	}
 	value := val.(*Value)
 	if value.TTL > -1 && value.TTL <= time.Now().Unix() {
 		Delete(key)
+		expiredKeys.Add(1)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_EXPIRED, "expired", keyName(key))
+		miss(key)
 		var zero V
 		return zero, -1, false
 	}
+	keyspaceHits.Add(1)
+	value.touch()
 	if typedVal, ok := value.Val.(V); ok {
 		return typedVal, value.TTL, true
 	}
@@ -78,33 +103,288 @@ func GetWithTTL[K comparable, V any](key K) (V, int64, bool) {
 
 func Set[K comparable, V any](key K, value V) {
 	store.mutex.Lock()
-	defer store.mutex.Unlock()
-	store.LRUCache.Add(key, &Value{value, -1})
+	events := add(key, value, -1)
+	store.mutex.Unlock()
+	publish(events)
 }
 
 func SetWithTTL[K comparable, V any](key K, value V, ttl int64) {
 	store.mutex.Lock()
-	defer store.mutex.Unlock()
-	store.LRUCache.Add(key, &Value{value, ttl + time.Now().Unix()})
+	events := add(key, value, ttl+time.Now().Unix())
+	store.mutex.Unlock()
+	publish(events)
 }
 
 func SetWithTTLAsUnixTimeStamp[K comparable, V any](key K, value V, ttl int64) {
 	store.mutex.Lock()
-	defer store.mutex.Unlock()
-	store.LRUCache.Add(key, &Value{value, ttl})
+	events := add(key, value, ttl)
+	store.mutex.Unlock()
+	publish(events)
+}
+
+// Delete removes a key and reports whether it held a live value.
+func Delete[K comparable](key K) bool {
+	_, ok := Take(key)
+	return ok
 }
 
-func Delete[K comparable](key K) {
+// Take removes a key and returns its value, if it was live.
+func Take[K comparable](key K) (any, bool) {
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
-	store.LRUCache.Remove(key)
+	val, ok := peek(key)
+	remove(key)
+	if !ok {
+		return nil, false
+	}
+	value := val.(*Value)
+	if value.TTL > -1 && value.TTL <= time.Now().Unix() {
+		return nil, false
+	}
+	return value.Val, true
+}
+
+// Inspect returns the stored value of a live key without counting it as an access.
+func Inspect[K comparable](key K) (*Value, bool) {
+	store.mutex.RLock()
+	val, ok := peek(key)
+	store.mutex.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	value := val.(*Value)
+	if value.TTL > -1 && value.TTL <= time.Now().Unix() {
+		return nil, false
+	}
+	return value, true
+}
+
+// Scan calls fn for every live key without updating its LRU recency.
+// fn must not call back into the store.
+func Scan(fn func(key any, value *Value)) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	now := time.Now().Unix()
+	for _, key := range keys() {
+		val, ok := peek(key)
+		if !ok {
+			continue
+		}
+		value := val.(*Value)
+		if value.TTL > -1 && value.TTL <= now {
+			continue
+		}
+		fn(key, value)
+	}
+}
+
+// Flush deletes the keys for which match returns true, or every key if match is nil.
+// It returns the number of keys deleted.
+func Flush(match func(key any) bool) int64 {
+	locked := lockMatching(func(key any) []string {
+		if match != nil && !match(key) {
+			return nil
+		}
+		return []string{keyName(key)}
+	})
+	defer UnlockKeys(locked...)
+	defer store.mutex.Unlock()
+	if match == nil {
+		n := length()
+		clear(store.dbs)
+		return int64(n)
+	}
+	var n int64
+	for _, key := range keys() {
+		if match(key) {
+			remove(key)
+			n++
+		}
+	}
+	return n
+}
+
+// RenameKeys atomically moves the values of every key for which rename returns a new name.
+// The values keep their time to live. All the keys are removed before the new ones are added,
+// so keys can trade places without being overwritten.
+func RenameKeys(rename func(key any) (any, bool)) {
+	locked := lockMatching(func(key any) []string {
+		newKey, ok := rename(key)
+		if !ok {
+			return nil
+		}
+		return []string{keyName(key), keyName(newKey)}
+	})
+	defer UnlockKeys(locked...)
+	defer store.mutex.Unlock()
+	type entry struct {
+		key   any
+		value any
+	}
+	moved := []entry{}
+	renames := map[any]any{}
+	for _, key := range keys() {
+		newKey, ok := rename(key)
+		if !ok {
+			continue
+		}
+		value, _ := peek(key)
+		remove(key)
+		moved = append(moved, entry{newKey, value})
+		renames[key] = newKey
+	}
+	for _, e := range moved {
+		cacheFor(e.key).Add(e.key, e.value)
+	}
+	renameTracked(renames)
+}
+
+// lockMatching takes the locks of the keys named by names for the keys in the store, then
+// store.mutex. Commands take their key locks before store.mutex, so the keys can't be locked
+// while holding it: the keys are locked first, and locked again along with the keys added in
+// the meantime until no key was added. The caller has to release store.mutex, then the
+// returned keys.
+func lockMatching(names func(key any) []string) []string {
+	var locked []string
+	for {
+		LockKeys(locked...)
+		store.mutex.Lock()
+		wanted := []string{}
+		for _, key := range keys() {
+			for _, name := range names(key) {
+				if _, ok := slices.BinarySearch(locked, name); !ok {
+					wanted = append(wanted, name)
+				}
+			}
+		}
+		if len(wanted) == 0 {
+			return locked
+		}
+		store.mutex.Unlock()
+		UnlockKeys(locked...)
+		locked = sortKeys(append(locked, wanted...))
+	}
 }
 
 func GetKeys[K comparable]() *[]K {
-	keys := store.LRUCache.Keys()
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
 	kKeys := []K{}
-	for _, key := range keys {
+	for _, key := range keys() {
 		kKeys = append(kKeys, key.(K))
 	}
 	return &kKeys
 }
+
+// cache returns the cache of the database of a key, nil if the database holds no keys yet.
+// The caller must hold store.mutex.
+func cache(key any) *lru.Cache[any, any] {
+	db, _ := common.SplitDBKey(keyName(key))
+	return store.dbs[db]
+}
+
+// cacheFor returns the cache of the database of a key, creating it if needed.
+// The caller must hold store.mutex for writing.
+func cacheFor(key any) *lru.Cache[any, any] {
+	db, _ := common.SplitDBKey(keyName(key))
+	c, ok := store.dbs[db]
+	if !ok {
+		c, _ = lru.New[any, any](capacity)
+		store.dbs[db] = c
+	}
+	return c
+}
+
+// get returns the stored value of a key, counting it as the most recently used.
+// The caller must hold store.mutex.
+func get(key any) (any, bool) {
+	if c := cache(key); c != nil {
+		return c.Get(key)
+	}
+	return nil, false
+}
+
+// peek returns the stored value of a key without updating its recency.
+// The caller must hold store.mutex.
+func peek(key any) (any, bool) {
+	if c := cache(key); c != nil {
+		return c.Peek(key)
+	}
+	return nil, false
+}
+
+// remove deletes a key. The caller must hold store.mutex for writing.
+func remove(key any) {
+	if c := cache(key); c != nil {
+		c.Remove(key)
+	}
+}
+
+// keys returns the keys of every database, database by database.
+// The caller must hold store.mutex.
+func keys() []any {
+	all := []any{}
+	for _, db := range slices.Sorted(maps.Keys(store.dbs)) {
+		all = append(all, store.dbs[db].Keys()...)
+	}
+	return all
+}
+
+// length returns the number of keys of every database. The caller must hold store.mutex.
+func length() int {
+	n := 0
+	for _, c := range store.dbs {
+		n += c.Len()
+	}
+	return n
+}
+
+// keyspaceEvent is a keyspace notification held back until store.mutex is released, so
+// subscribers aren't written to while every other access to the store waits.
+type keyspaceEvent struct {
+	class int64
+	event string
+	key   string
+}
+
+func publish(events []keyspaceEvent) {
+	for _, e := range events {
+		pubsub.NotifyKeyspaceEvent(e.class, e.event, e.key)
+	}
+}
+
+// add stores a value, counting the key evicted by the cache of its database to make room for it.
+// It returns the events to publish once the caller, which must hold store.mutex, releases it.
+func add(key any, val any, ttl int64) []keyspaceEvent {
+	var events []keyspaceEvent
+	c := cacheFor(key)
+	var oldest any
+	var previous *Value
+	if old, ok := c.Peek(key); ok {
+		previous = old.(*Value)
+	} else if c.Len() >= capacity {
+		oldest, _, _ = c.GetOldest()
+	}
+	trackValue(key, val)
+	if c.Add(key, newValue(val, ttl, previous)) {
+		evictedKeys.Add(1)
+		events = append(events, keyspaceEvent{pubsub.NOTIFY_EVICTED, "evicted", keyName(oldest)})
+	}
+	if previous == nil || (previous.TTL > -1 && previous.TTL <= time.Now().Unix()) {
+		events = append(events, keyspaceEvent{pubsub.NOTIFY_NEW, "new", keyName(key)})
+	}
+	return events
+}
+
+// miss counts a lookup of a key that holds no live value.
+func miss(key any) {
+	keyspaceMisses.Add(1)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_KEY_MISS, "keymiss", keyName(key))
+}
+
+func keyName(key any) string {
+	if name, ok := key.(string); ok {
+		return name
+	}
+	return fmt.Sprint(key)
+}
@@ -3,6 +3,8 @@ package store
 import (
 	"math/rand"
 	"time"
+
+	"github.com/divy-sh/animus/pubsub"
 )
 
 func StartExpiryCleaner() {
@@ -13,7 +15,7 @@ func StartExpiryCleaner() {
 	}
 	store.isRunning = true
 	store.stopCleaner = make(chan struct{})
-	go expiryCleanerLoop(store)
+	go expiryCleanerLoop(store.stopCleaner)
 }
 
 func StopExpiryCleaner() {
@@ -26,14 +28,17 @@ func StopExpiryCleaner() {
 	close(store.stopCleaner)
 }
 
-func expiryCleanerLoop(store *Store) {
+// expiryCleanerLoop cleans expired keys and fields until stop is closed. The channel is
+// passed in rather than read from the store, which a restart of the cleaner replaces.
+func expiryCleanerLoop(stop <-chan struct{}) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
 			cleanExpiredKeys()
-		case <-store.stopCleaner:
+			cleanExpiredFields()
+		case <-stop:
 			return
 		}
 	}
@@ -47,7 +52,7 @@ func cleanExpiredKeys() {
 	)
 	now := time.Now().Unix()
 	store.mutex.RLock()
-	allKeys := store.LRUCache.Keys()
+	allKeys := keys()
 	store.mutex.RUnlock()
 	for iteration := 0; iteration < maxIterations; iteration++ {
 		keysToCheck := sampleRandomKeys(allKeys, sampleSize)
@@ -58,7 +63,7 @@ func cleanExpiredKeys() {
 		expiredCount := 0
 		for _, key := range keysToCheck {
 			store.mutex.RLock()
-			val, ok := store.LRUCache.Get(key)
+			val, ok := get(key)
 			store.mutex.RUnlock()
 			if !ok {
 				continue
@@ -66,8 +71,10 @@ func cleanExpiredKeys() {
 			value := val.(*Value)
 			if value.TTL > -1 && value.TTL < now {
 				expiredCount++
+				expiredKeys.Add(1)
+				pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_EXPIRED, "expired", keyName(key))
 				store.mutex.Lock()
-				store.LRUCache.Remove(key)
+				remove(key)
 				store.mutex.Unlock()
 			}
 		}
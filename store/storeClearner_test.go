@@ -8,9 +8,7 @@ import (
 
 func TestExpiryCleaner(t *testing.T) {
 	// Clear store before testing
-	store.mutex.Lock()
-	store.LRUCache.Purge()
-	store.mutex.Unlock()
+	Flush(nil)
 
 	// Set values: 2 active, 3 expired
 	SetWithTTL("active1", "value1", 60)   // TTL in 60 seconds
@@ -44,9 +42,7 @@ func TestExpiryCleaner(t *testing.T) {
 
 func TestExpiryCleanerAutoWithSampling(t *testing.T) {
 	// Clear store before testing
-	store.mutex.Lock()
-	store.LRUCache.Purge()
-	store.mutex.Unlock()
+	Flush(nil)
 
 	for i := 0; i < 100; i++ {
 		SetWithTTL(fmt.Sprintf("active%d", i), "value", int64(i%2))
@@ -54,22 +50,20 @@ func TestExpiryCleanerAutoWithSampling(t *testing.T) {
 
 	time.Sleep(2 * time.Second)
 	cleanExpiredKeys()
-	if store.LRUCache.Len() > 0 {
+	if length() > 0 {
 		t.Errorf("cleanExpiredKeys should've cleared the expired keys")
 	}
 }
 
 func TestExpiryCleanerAutoWithEmptyStore(t *testing.T) {
 	// Clear store before testing
-	store.mutex.Lock()
-	store.LRUCache.Purge()
-	store.mutex.Unlock()
+	Flush(nil)
 
-	if store.LRUCache.Len() > 0 {
+	if length() > 0 {
 		t.Errorf("store should be empty before testing TestExpiryCleanerAutoWithEmptyStore")
 	}
 	cleanExpiredKeys()
-	if store.LRUCache.Len() > 0 {
+	if length() > 0 {
 		t.Errorf("cleanExpiredKeys should've cleared the expired keys")
 	}
 }
@@ -1,8 +1,12 @@
 package store
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/divy-sh/animus/common"
 )
 
 func TestExpiryCleanerRemovesExpiredKeys(t *testing.T) {
@@ -135,3 +139,158 @@ func TestGetKeys(t *testing.T) {
 		t.Errorf("expected atleast 1 key but got no keys")
 	}
 }
+
+func TestStats(t *testing.T) {
+	StopExpiryCleaner()
+	defer StartExpiryCleaner()
+	ResetStats()
+	Set("TestStatsKey", "value")
+	Get[string, string]("TestStatsKey")
+	Get[string, string]("TestStatsMissingKey")
+	SetWithTTL("TestStatsExpiredKey", "value", -1)
+	Get[string, string]("TestStatsExpiredKey")
+
+	stats := GetStats()
+	if stats.KeyspaceHits != 1 || stats.KeyspaceMisses != 2 || stats.ExpiredKeys != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	ResetStats()
+	if GetStats() != (Stats{}) {
+		t.Errorf("expected stats to be reset")
+	}
+}
+
+func TestGetKeyspaceInfo(t *testing.T) {
+	Set("TestGetKeyspaceInfo", "value")
+	SetWithTTL("TestGetKeyspaceInfoTTL", "value", 100)
+	SetWithTTL(common.DBKey(1, "TestGetKeyspaceInfo"), "value", 100)
+	infos := GetKeyspaceInfo()
+	info := infos[0]
+	if info.Keys < 2 || info.Expires < 1 || info.AvgTTL <= 0 {
+		t.Errorf("unexpected keyspace info %+v", info)
+	}
+	if infos[1].Keys != 1 || infos[1].Expires != 1 {
+		t.Errorf("unexpected keyspace info for db 1 %+v", infos[1])
+	}
+}
+
+func TestAccessMetadata(t *testing.T) {
+	Set("TestAccessMetadata", "value")
+	value, ok := Inspect("TestAccessMetadata")
+	if !ok {
+		t.Fatal("expected the key to exist")
+	}
+	if freq := value.Freq(); freq != lfuInitVal {
+		t.Errorf("expected a new key to start at %d, got %d", lfuInitVal, freq)
+	}
+
+	value.SetAccess(90*time.Second, 20)
+	if idle := value.IdleTime(); idle < 90*time.Second {
+		t.Errorf("expected 90s of idle time, got %v", idle)
+	}
+	if freq := value.Freq(); freq != 19 {
+		t.Errorf("expected the counter to decay by one per minute, got %d", freq)
+	}
+	Inspect("TestAccessMetadata")
+	if idle := value.IdleTime(); idle < 90*time.Second {
+		t.Errorf("expected Inspect not to count as an access, got %v", idle)
+	}
+	Get[string, string]("TestAccessMetadata")
+	if idle := value.IdleTime(); idle >= time.Second {
+		t.Errorf("expected Get to reset the idle time, got %v", idle)
+	}
+
+	Set("TestAccessMetadata", "other")
+	value, _ = Inspect("TestAccessMetadata")
+	if freq := value.Freq(); freq < 19 {
+		t.Errorf("expected an overwrite to keep the counter, got %d", freq)
+	}
+}
+
+type expiringValue struct{ expiring bool }
+
+func (v *expiringValue) HasFieldExpiry() bool { return v.expiring }
+
+func TestFieldExpiryTracking(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[any]int{}
+	SetFieldExpiryHandler(func(key any) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[key]++
+		UntrackFieldExpiry(key)
+	})
+	defer SetFieldExpiryHandler(nil)
+
+	Set("field-expiry-a", &expiringValue{expiring: true})
+	Set("field-expiry-b", &expiringValue{})
+	RenameKeys(func(key any) (any, bool) { return "field-expiry-c", key == "field-expiry-a" })
+	cleanExpiredFields()
+	cleanExpiredFields()
+	mu.Lock()
+	defer mu.Unlock()
+	if seen["field-expiry-c"] != 1 || seen["field-expiry-a"] != 0 || seen["field-expiry-b"] != 0 {
+		t.Errorf("expected only the renamed key to be handled once, got %v", seen)
+	}
+}
+
+func TestEvictionIsPerDatabase(t *testing.T) {
+	Set("TestEvictionIsPerDatabase", "value")
+	for i := range capacity + 1 {
+		Set(common.DBKey(2, strconv.Itoa(i)), "value")
+	}
+	defer Flush(func(key any) bool {
+		db, _ := common.SplitDBKey(key.(string))
+		return db == 2
+	})
+	if _, ok := Get[string, string]("TestEvictionIsPerDatabase"); !ok {
+		t.Errorf("expected a full database not to evict the keys of another one")
+	}
+	if _, ok := Get[string, string](common.DBKey(2, "0")); ok {
+		t.Errorf("expected the oldest key of the full database to be evicted")
+	}
+}
+
+func TestFlushWaitsForKeyLocks(t *testing.T) {
+	key := "TestFlushWaitsForKeyLocks"
+	Set(key, "value")
+	LockKeys(key)
+	flushed := make(chan struct{})
+	go func() {
+		Flush(func(k any) bool { return k == key })
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+		t.Fatalf("expected Flush to wait for the key lock")
+	case <-time.After(20 * time.Millisecond):
+	}
+	Set(key, "changed")
+	UnlockKeys(key)
+	<-flushed
+	if _, ok := Get[string, string](key); ok {
+		t.Errorf("expected the key to be flushed")
+	}
+}
+
+func TestRenameKeysWaitsForDestinationLocks(t *testing.T) {
+	source, destination := "TestRenameKeysSource", "TestRenameKeysDestination"
+	Set(source, "value")
+	LockKeys(destination)
+	renamed := make(chan struct{})
+	go func() {
+		RenameKeys(func(k any) (any, bool) { return destination, k == source })
+		close(renamed)
+	}()
+	select {
+	case <-renamed:
+		t.Fatalf("expected RenameKeys to wait for the lock of the destination key")
+	case <-time.After(20 * time.Millisecond):
+	}
+	UnlockKeys(destination)
+	<-renamed
+	if val, ok := Get[string, string](destination); !ok || val != "value" {
+		t.Errorf("expected the key to be renamed, got %q", val)
+	}
+	Delete(destination)
+}
@@ -0,0 +1,276 @@
+package arrays
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+// Element is a value of an array along with its index.
+type Element struct {
+	Index int64
+	Value any
+}
+
+// number returns the numeric value of an array element, false if it isn't a finite number.
+func number(value any) (float64, bool) {
+	n, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+// numbers returns the numeric elements of an array in index order. Non-numeric elements are
+// skipped, or fail with an error if strict is set. The caller must hold a lock on the key.
+func numbers(arr *Array, strict bool) ([]Element, []float64, error) {
+	var elements []Element
+	var values []float64
+	var err error
+	arr.Each(0, func(index int64, value any) bool {
+		n, ok := number(value)
+		if !ok {
+			if strict {
+				err = errors.New(common.ERR_ARRAY_VALUE_NOT_FLOAT)
+				return false
+			}
+			return true
+		}
+		elements = append(elements, Element{index, value})
+		values = append(values, n)
+		return true
+	})
+	return elements, values, err
+}
+
+// sorted returns the elements of an array sorted by value, numerically unless alpha is set.
+// Equal values keep their index order. The caller must hold a lock on the key.
+func sorted(arr *Array, desc, alpha bool) ([]Element, error) {
+	var elements []Element
+	var compare func(a, b Element) int
+	if alpha {
+		arr.Each(0, func(index int64, value any) bool {
+			elements = append(elements, Element{index, value})
+			return true
+		})
+		compare = func(a, b Element) int {
+			return strings.Compare(fmt.Sprint(a.Value), fmt.Sprint(b.Value))
+		}
+	} else {
+		numeric, values, err := numbers(arr, true)
+		if err != nil {
+			return nil, err
+		}
+		keys := make(map[int64]float64, len(numeric))
+		for i, element := range numeric {
+			keys[element.Index] = values[i]
+		}
+		elements = numeric
+		compare = func(a, b Element) int {
+			return cmp.Compare(keys[a.Index], keys[b.Index])
+		}
+	}
+	if desc {
+		slices.SortStableFunc(elements, func(a, b Element) int { return compare(b, a) })
+	} else {
+		slices.SortStableFunc(elements, compare)
+	}
+	return elements, nil
+}
+
+// ArSort returns the elements of an array sorted by value, numerically unless alpha is set,
+// in which case the values are compared as strings. Numeric sorting fails if a value isn't a number.
+func ArSort(key string, desc, alpha bool) ([]Element, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
+	}
+	return sorted(arr, desc, alpha)
+}
+
+// ArSortStore stores the sorted values of an array at destKey, as a new array without holes,
+// and returns their number. An empty result deletes destKey.
+func ArSortStore(key, destKey string, desc, alpha bool) (int64, error) {
+	store.LockKeys(key, destKey)
+	defer store.UnlockKeys(key, destKey)
+
+	arr, err := get(key)
+	if err != nil {
+		return 0, err
+	}
+	elements, err := sorted(arr, desc, alpha)
+	if err != nil {
+		return 0, err
+	}
+	if len(elements) == 0 {
+		if store.Delete(destKey) {
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", destKey)
+		}
+		return 0, nil
+	}
+	result := NewArray()
+	for i, element := range elements {
+		result.Set(int64(i), element.Value)
+	}
+	store.Set(destKey, result)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "arsortstore", destKey)
+	return result.Len(), nil
+}
+
+// ArSum returns the sum of the numeric elements of an array and their indexes. Non-numeric
+// elements are skipped, or fail with an error if strict is set.
+func ArSum(key string, strict bool) (float64, []int64, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	elements, values, err := numbers(arr, strict)
+	if err != nil {
+		return 0, nil, err
+	}
+	return sum(values), indexes(elements), nil
+}
+
+// ArAvg returns the average of the numeric elements of an array and their indexes, no indexes
+// if it has none. Non-numeric elements are skipped, or fail with an error if strict is set.
+func ArAvg(key string, strict bool) (float64, []int64, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	elements, values, err := numbers(arr, strict)
+	if err != nil || len(values) == 0 {
+		return 0, nil, err
+	}
+	return sum(values) / float64(len(values)), indexes(elements), nil
+}
+
+func sum(values []float64) float64 {
+	var sum float64
+	for _, n := range values {
+		sum += n
+	}
+	return sum
+}
+
+// indexes returns the indexes of elements.
+func indexes(elements []Element) []int64 {
+	indexes := make([]int64, len(elements))
+	for i, element := range elements {
+		indexes[i] = element.Index
+	}
+	return indexes
+}
+
+// ArMin returns the smallest numeric element of an array, the first one if several are equal,
+// and false if it has none. Non-numeric elements are skipped, or fail with an error if strict is set.
+func ArMin(key string, strict bool) (Element, bool, error) {
+	return extreme(key, strict, -1)
+}
+
+// ArMax returns the largest numeric element of an array, the first one if several are equal,
+// and false if it has none. Non-numeric elements are skipped, or fail with an error if strict is set.
+func ArMax(key string, strict bool) (Element, bool, error) {
+	return extreme(key, strict, 1)
+}
+
+// extreme returns the numeric element of an array that compares to all the others as sign.
+func extreme(key string, strict bool, sign int) (Element, bool, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return Element{}, false, err
+	}
+	elements, values, err := numbers(arr, strict)
+	if err != nil || len(elements) == 0 {
+		return Element{}, false, err
+	}
+	best := 0
+	for i := 1; i < len(values); i++ {
+		if cmp.Compare(values[i], values[best]) == sign {
+			best = i
+		}
+	}
+	return elements[best], true, nil
+}
+
+// ArDistinct returns the distinct values of an array in index order, each with the index
+// of its first occurrence.
+func ArDistinct(key string) ([]Element, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	elements := []Element{}
+	arr.Each(0, func(index int64, value any) bool {
+		if str := fmt.Sprint(value); !seen[str] {
+			seen[str] = true
+			elements = append(elements, Element{index, value})
+		}
+		return true
+	})
+	return elements, nil
+}
+
+// ArCountVal returns the indexes of the elements of an array equal to value.
+func ArCountVal(key string, value string) ([]int64, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
+	}
+	indexes := []int64{}
+	arr.Each(0, func(index int64, v any) bool {
+		if fmt.Sprint(v) == value {
+			indexes = append(indexes, index)
+		}
+		return true
+	})
+	return indexes, nil
+}
+
+// ArSeek returns the first element at or after from whose value matches pattern, false if there is none.
+func ArSeek(key string, pattern string, from int64) (Element, bool, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return Element{}, false, err
+	}
+	var found Element
+	ok := false
+	arr.Each(from, func(index int64, value any) bool {
+		if common.MatchPattern(fmt.Sprint(value), pattern) {
+			found, ok = Element{index, value}, true
+			return false
+		}
+		return true
+	})
+	return found, ok, nil
+}
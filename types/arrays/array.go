@@ -0,0 +1,352 @@
+package arrays
+
+import (
+	"errors"
+	"math"
+
+	"github.com/divy-sh/animus/common"
+)
+
+// PageSize is the number of consecutive indexes held by a leaf of an array, and the number
+// of children of its other nodes.
+const PageSize = 64
+
+const pageBits = 6
+
+// MaxIndex is the highest index an array can hold, so that its length fits an int64.
+const MaxIndex = math.MaxInt64 - 1
+
+// MaxRange is the number of indexes a range of an array may span, holes included.
+const MaxRange = 1 << 20
+
+// node is a node of the radix tree holding the values of an array. Leaves hold values while
+// the other nodes hold children, each covering PageSize times fewer indexes than its parent.
+type node struct {
+	children []*node
+	values   []any
+	// count is the number of children or values that are set.
+	count int
+}
+
+func newNode(leaf bool) *node {
+	if leaf {
+		return &node{values: make([]any, PageSize)}
+	}
+	return &node{children: make([]*node, PageSize)}
+}
+
+// Array is the value stored for an array. Its values are held in a radix tree whose nodes only
+// exist for the ranges of indexes holding values, so setting a large index costs a few nodes
+// rather than a slice of that length. Indexes holding no value read as nil.
+//
+// The height of the tree grows with the highest index set, so reading or setting an index
+// walks O(log n) nodes and walking the values in order skips the ranges holding none.
+type Array struct {
+	root *node
+	// height is the number of levels above the leaves, 0 when the root is a leaf.
+	height int
+	// length is one past the highest index holding a value.
+	length int64
+	// count is the number of indexes holding a value.
+	count int64
+	// leaves is the number of leaves in the tree.
+	leaves int
+}
+
+func NewArray() *Array {
+	return &Array{}
+}
+
+// FromSlice returns an array holding values at their index in the slice. Nil values are holes.
+func FromSlice(values []any) *Array {
+	arr := NewArray()
+	for i, v := range values {
+		arr.Set(int64(i), v)
+	}
+	return arr
+}
+
+// Len returns one past the highest index holding a value, 0 for an empty array.
+func (a *Array) Len() int64 {
+	return a.length
+}
+
+// Count returns the number of indexes holding a value.
+func (a *Array) Count() int64 {
+	return a.count
+}
+
+// Pages returns the number of leaves allocated, for memory accounting.
+func (a *Array) Pages() int {
+	return a.leaves
+}
+
+// slot returns the position in a node at height h of the child or value covering index.
+func slot(index int64, h int) int {
+	return int(index>>(pageBits*h)) & (PageSize - 1)
+}
+
+// covers reports whether a tree of height h has room for index.
+func covers(index int64, h int) bool {
+	return pageBits*(h+1) >= 63 || index>>(pageBits*(h+1)) == 0
+}
+
+// Get returns the value at index, nil if it holds none.
+func (a *Array) Get(index int64) any {
+	if a.root == nil || index < 0 || !covers(index, a.height) {
+		return nil
+	}
+	n := a.root
+	for h := a.height; h > 0; h-- {
+		if n = n.children[slot(index, h)]; n == nil {
+			return nil
+		}
+	}
+	return n.values[slot(index, 0)]
+}
+
+// Set sets the value at an index from 0 to MaxIndex. Setting nil deletes the value.
+func (a *Array) Set(index int64, value any) {
+	if value == nil {
+		a.Delete(index)
+		return
+	}
+	if a.root == nil {
+		a.root, a.height = newNode(true), 0
+		a.leaves++
+	}
+	for !covers(index, a.height) {
+		root := newNode(false)
+		root.children[0], root.count = a.root, 1
+		a.root = root
+		a.height++
+	}
+	n := a.root
+	for h := a.height; h > 0; h-- {
+		i := slot(index, h)
+		if n.children[i] == nil {
+			n.children[i] = newNode(h == 1)
+			n.count++
+			if h == 1 {
+				a.leaves++
+			}
+		}
+		n = n.children[i]
+	}
+	i := slot(index, 0)
+	if n.values[i] == nil {
+		n.count++
+		a.count++
+	}
+	n.values[i] = value
+	a.length = max(a.length, index+1)
+}
+
+// Delete removes the value at index without moving the following ones, and reports whether
+// there was one.
+func (a *Array) Delete(index int64) bool {
+	if a.root == nil || index < 0 || !covers(index, a.height) {
+		return false
+	}
+	path := make([]*node, a.height+1)
+	n := a.root
+	for h := a.height; h > 0; h-- {
+		path[h] = n
+		if n = n.children[slot(index, h)]; n == nil {
+			return false
+		}
+	}
+	i := slot(index, 0)
+	if n.values[i] == nil {
+		return false
+	}
+	n.values[i] = nil
+	n.count--
+	a.count--
+	// Drop the nodes left empty, from the leaf up.
+	for h := 0; h < a.height && n.count == 0; h++ {
+		if h == 0 {
+			a.leaves--
+		}
+		n = path[h+1]
+		n.children[slot(index, h+1)] = nil
+		n.count--
+	}
+	a.shrink()
+	if index == a.length-1 {
+		a.length = a.last() + 1
+	}
+	return true
+}
+
+// shrink lowers the tree while its root only holds the first child.
+func (a *Array) shrink() {
+	if a.root.count == 0 {
+		a.root, a.height, a.leaves = nil, 0, 0
+		return
+	}
+	for a.height > 0 && a.root.count == 1 && a.root.children[0] != nil {
+		a.root = a.root.children[0]
+		a.height--
+	}
+}
+
+// DeleteRange removes the values from start to end, both inclusive, without moving the
+// following ones. It returns the number of values removed.
+func (a *Array) DeleteRange(start, end int64) int64 {
+	var indexes []int64
+	a.Each(start, func(index int64, _ any) bool {
+		if index > end {
+			return false
+		}
+		indexes = append(indexes, index)
+		return true
+	})
+	for _, index := range indexes {
+		a.Delete(index)
+	}
+	return int64(len(indexes))
+}
+
+// last returns the highest index holding a value, -1 for an empty array.
+func (a *Array) last() int64 {
+	if a.root == nil {
+		return -1
+	}
+	var index int64
+	n := a.root
+	for h := a.height; h >= 0; h-- {
+		for i := PageSize - 1; i >= 0; i-- {
+			if (h > 0 && n.children[i] != nil) || (h == 0 && n.values[i] != nil) {
+				index |= int64(i) << (pageBits * h)
+				if h > 0 {
+					n = n.children[i]
+				}
+				break
+			}
+		}
+	}
+	return index
+}
+
+// Range returns the values from start to end, both inclusive, nil for the indexes holding none.
+// The range must span at most MaxRange indexes.
+func (a *Array) Range(start, end int64) []any {
+	values := make([]any, end-start+1)
+	a.Each(start, func(index int64, value any) bool {
+		if index > end {
+			return false
+		}
+		values[index-start] = value
+		return true
+	})
+	return values
+}
+
+// Next returns the first index at or after from holding a value, and the value.
+func (a *Array) Next(from int64) (int64, any, bool) {
+	var found int64
+	var value any
+	ok := false
+	a.Each(from, func(index int64, v any) bool {
+		found, value, ok = index, v, true
+		return false
+	})
+	return found, value, ok
+}
+
+// Each calls fn with the indexes at or after from holding a value, in ascending order,
+// until fn returns false.
+func (a *Array) Each(from int64, fn func(index int64, value any) bool) {
+	if a.root == nil || !covers(max(from, 0), a.height) {
+		return
+	}
+	a.root.each(a.height, 0, max(from, 0), fn)
+}
+
+// each walks the values of a node at height h whose first index is base. It returns false
+// once fn does.
+func (n *node) each(h int, base, from int64, fn func(index int64, value any) bool) bool {
+	first := 0
+	if from > base {
+		first = slot(from, h)
+	}
+	for i := first; i < PageSize; i++ {
+		index := base | int64(i)<<(pageBits*h)
+		if h == 0 {
+			if n.values[i] != nil && !fn(index, n.values[i]) {
+				return false
+			}
+		} else if n.children[i] != nil && !n.children[i].each(h-1, index, from, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Insert inserts values before index, moving the values at or after it. Its cost is
+// proportional to the number of values moved. Nothing is inserted if a value would be moved
+// past MaxIndex.
+func (a *Array) Insert(index int64, values ...any) error {
+	if max(a.length, index) > MaxIndex+1-int64(len(values)) {
+		return errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
+	}
+	type entry struct {
+		index int64
+		value any
+	}
+	moved := []entry{}
+	a.Each(index, func(i int64, v any) bool {
+		moved = append(moved, entry{i, v})
+		return true
+	})
+	a.DeleteRange(index, a.length-1)
+	for i, v := range values {
+		a.Set(index+int64(i), v)
+	}
+	for _, e := range moved {
+		a.Set(e.index+int64(len(values)), e.value)
+	}
+	return nil
+}
+
+// Pop removes and returns up to count values from the end of the array, the last one first.
+func (a *Array) Pop(count int64) []any {
+	popped := make([]any, 0, min(count, a.count))
+	for int64(len(popped)) < count && a.length > 0 {
+		index := a.length - 1
+		popped = append(popped, a.Get(index))
+		a.Delete(index)
+	}
+	return popped
+}
+
+// Clone returns a copy of the array.
+func (a *Array) Clone() *Array {
+	clone := *a
+	if a.root != nil {
+		clone.root = a.root.clone()
+	}
+	return &clone
+}
+
+func (n *node) clone() *node {
+	copied := &node{count: n.count}
+	if n.values != nil {
+		copied.values = make([]any, PageSize)
+		copy(copied.values, n.values)
+		return copied
+	}
+	copied.children = make([]*node, PageSize)
+	for i, child := range n.children {
+		if child != nil {
+			copied.children[i] = child.clone()
+		}
+	}
+	return copied
+}
+
+// Clear removes every value.
+func (a *Array) Clear() {
+	*a = Array{}
+}
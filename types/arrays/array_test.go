@@ -0,0 +1,136 @@
+package arrays_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/divy-sh/animus/types/arrays"
+)
+
+func TestArray_LargeIndexes(t *testing.T) {
+	arr := arrays.NewArray()
+	arr.Set(3, "a")
+	arr.Set(1_000_000_000_000, "b")
+
+	if arr.Len() != 1_000_000_000_001 || arr.Count() != 2 || arr.Pages() != 2 {
+		t.Fatalf("Expected length 1000000000001, 2 values in 2 pages, got %d %d %d", arr.Len(), arr.Count(), arr.Pages())
+	}
+	if arr.Get(1_000_000_000_000) != "b" || arr.Get(500) != nil {
+		t.Fatalf("Unexpected values %v %v", arr.Get(1_000_000_000_000), arr.Get(500))
+	}
+	index, value, ok := arr.Next(4)
+	if !ok || index != 1_000_000_000_000 || value != "b" {
+		t.Fatalf("Expected the next value at 1000000000000, got %d %v %v", index, value, ok)
+	}
+	if values := arr.Range(999_999_999_998, 1_000_000_000_000); !reflect.DeepEqual(values, []any{nil, nil, "b"}) {
+		t.Fatalf("Expected [<nil> <nil> b], got %v", values)
+	}
+
+	arr.Delete(1_000_000_000_000)
+	if arr.Len() != 4 || arr.Pages() != 1 {
+		t.Fatalf("Expected length 4 in 1 page, got %d %d", arr.Len(), arr.Pages())
+	}
+}
+
+func TestArray_DeleteKeepsIndexes(t *testing.T) {
+	arr := arrays.FromSlice([]any{"a", "b", "c", "d"})
+	if !arr.Delete(1) || arr.Delete(1) {
+		t.Fatalf("Expected only the first delete to remove a value")
+	}
+	if values := arr.Range(0, 3); !reflect.DeepEqual(values, []any{"a", nil, "c", "d"}) {
+		t.Fatalf("Expected [a <nil> c d], got %v", values)
+	}
+
+	if removed := arr.DeleteRange(2, 3); removed != 2 || arr.Len() != 1 || arr.Count() != 1 {
+		t.Fatalf("Expected 2 values removed leaving length 1, got %d %d %d", removed, arr.Len(), arr.Count())
+	}
+}
+
+func TestArray_DeleteRangeAcrossPages(t *testing.T) {
+	arr := arrays.NewArray()
+	for i := range int64(5 * arrays.PageSize) {
+		arr.Set(i, i)
+	}
+	removed := arr.DeleteRange(arrays.PageSize/2, 4*arrays.PageSize-1)
+	if removed != 4*arrays.PageSize-arrays.PageSize/2 || arr.Pages() != 2 {
+		t.Fatalf("Unexpected removal of %d values leaving %d pages", removed, arr.Pages())
+	}
+	if arr.Len() != 5*arrays.PageSize || arr.Get(4*arrays.PageSize) != int64(4*arrays.PageSize) {
+		t.Fatalf("Expected the values after the range to keep their index")
+	}
+}
+
+func TestArray_InsertAndPop(t *testing.T) {
+	arr := arrays.FromSlice([]any{"a", nil, "d"})
+	arr.Insert(1, "b", "c")
+	if values := arr.Range(0, arr.Len()-1); !reflect.DeepEqual(values, []any{"a", "b", "c", nil, "d"}) {
+		t.Fatalf("Expected [a b c <nil> d], got %v", values)
+	}
+	if popped := arr.Pop(2); !reflect.DeepEqual(popped, []any{"d", "c"}) {
+		t.Fatalf("Expected [d c], got %v", popped)
+	}
+	if arr.Len() != 2 {
+		t.Fatalf("Expected length 2, got %d", arr.Len())
+	}
+}
+
+func TestArray_CloneIsIndependent(t *testing.T) {
+	arr := arrays.FromSlice([]any{"a", "b"})
+	clone := arr.Clone()
+	clone.Set(0, "z")
+	clone.Delete(1)
+	if arr.Get(0) != "a" || arr.Get(1) != "b" || arr.Count() != 2 {
+		t.Fatalf("Expected the original array to be unchanged, got %v", arr.Range(0, arr.Len()-1))
+	}
+}
+
+func BenchmarkArray_SetRandom(b *testing.B) {
+	arr := arrays.NewArray()
+	for i := 0; i < b.N; i++ {
+		arr.Set(rand.Int63n(1_000_000), "value")
+	}
+}
+
+func BenchmarkArray_GetRandom(b *testing.B) {
+	arr := arrays.NewArray()
+	for i := range int64(100_000) {
+		arr.Set(i*10, "value")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		arr.Get(rand.Int63n(1_000_000))
+	}
+}
+
+func BenchmarkArray_SetLargeIndex(b *testing.B) {
+	arr := arrays.NewArray()
+	for i := 0; i < b.N; i++ {
+		arr.Set(rand.Int63n(1<<62), "value")
+	}
+}
+
+func BenchmarkArray_Range(b *testing.B) {
+	arr := arrays.NewArray()
+	for i := range int64(100_000) {
+		arr.Set(i, "value")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := rand.Int63n(99_000)
+		arr.Range(start, start+999)
+	}
+}
+
+func BenchmarkArray_Delete(b *testing.B) {
+	arr := arrays.NewArray()
+	for i := range int64(100_000) {
+		arr.Set(i, "value")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index := rand.Int63n(100_000)
+		arr.Delete(index)
+		arr.Set(index, "value")
+	}
+}
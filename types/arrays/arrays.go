@@ -2,202 +2,282 @@ package arrays
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
 	"github.com/divy-sh/animus/store"
 )
 
+func get(key string) (*Array, error) {
+	arr, ok := store.Get[string, *Array](key)
+	if !ok {
+		return nil, errors.New(common.ERR_ARRAY_NOT_FOUND)
+	}
+	return arr, nil
+}
+
+func getOrCreate(key string) *Array {
+	arr, ok := store.Get[string, *Array](key)
+	if !ok {
+		arr = NewArray()
+		store.Set(key, arr)
+	}
+	return arr
+}
+
+// deleteIfEmpty removes an array that holds no values, arrays are never stored empty.
+// The caller must hold the write lock of the key.
+func deleteIfEmpty(key string, arr *Array) {
+	if arr.Count() == 0 {
+		store.Delete(key)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+	}
+}
+
 func ArCount(key string) (int64, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	arr, ok := store.Get[string, []any](key)
-	if !ok {
-		return 0, errors.New(common.ERR_ARRAY_NOT_FOUND)
+	arr, err := get(key)
+	if err != nil {
+		return 0, err
 	}
 
-	return int64(len(arr)), nil
+	return arr.Len(), nil
 }
 
+// ArDel removes the element at index. The following elements keep their index.
 func ArDel(key string, index int64) error {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	arr, ok := store.Get[string, []any](key)
-	if !ok {
-		return errors.New(common.ERR_ARRAY_NOT_FOUND)
+	arr, err := get(key)
+	if err != nil {
+		return err
 	}
 
-	if index < 0 || index >= int64(len(arr)) {
+	if index < 0 || index >= arr.Len() {
 		return errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
 	}
 
-	arr = append(arr[:index], arr[index+1:]...)
-	store.Set(key, arr)
+	arr.Delete(index)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "ardel", key)
+	deleteIfEmpty(key, arr)
 
 	return nil
 }
 
+// ArDelRange removes the elements from start to end, both inclusive. The following elements keep their index.
 func ArDelRange(key string, start, end int64) error {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	arr, ok := store.Get[string, []any](key)
-	if !ok {
-		return errors.New(common.ERR_ARRAY_NOT_FOUND)
+	arr, err := get(key)
+	if err != nil {
+		return err
 	}
 
-	if start < 0 || end >= int64(len(arr)) || start > end {
+	if start < 0 || end >= arr.Len() || start > end {
 		return errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
 	}
 
-	arr = append(arr[:start], arr[end+1:]...)
-	store.Set(key, arr)
+	arr.DeleteRange(start, end)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "ardelrange", key)
+	deleteIfEmpty(key, arr)
 
 	return nil
 }
 
 func ArGet(key string, index int64) (any, error) {
-	store.LockKeys(key)
-	defer store.UnlockKeys(key)
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
 
-	arr, ok := store.Get[string, []any](key)
-	if !ok {
-		return nil, errors.New(common.ERR_ARRAY_NOT_FOUND)
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
 	}
 
-	if index < 0 || index >= int64(len(arr)) {
+	if index < 0 || index >= arr.Len() {
 		return nil, errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
 	}
 
-	return arr[index], nil
+	return arr.Get(index), nil
 }
 
 func ArGetRange(key string, start, end int64) ([]any, error) {
-	store.LockKeys(key)
-	defer store.UnlockKeys(key)
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
 
-	arr, ok := store.Get[string, []any](key)
-	if !ok {
-		return nil, errors.New(common.ERR_ARRAY_NOT_FOUND)
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
 	}
 
-	if start < 0 || end >= int64(len(arr)) || start > end {
+	if start < 0 || end >= arr.Len() || start > end {
 		return nil, errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
 	}
+	if end-start >= MaxRange {
+		return nil, errors.New(common.ERR_ARRAY_RANGE)
+	}
 
-	return arr[start : end+1], nil
+	return arr.Range(start, end), nil
 }
 
 func ArGrep(key string, pattern string) ([]any, error) {
-	store.LockKeys(key)
-	defer store.UnlockKeys(key)
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
 
-	arr, ok := store.Get[string, []any](key)
-	if !ok {
-		return nil, errors.New(common.ERR_ARRAY_NOT_FOUND)
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
 	}
 
 	var result []any
-	for _, v := range arr {
-		str, ok := v.(string)
-		if !ok {
-			continue
-		}
-		if matchPattern(str, pattern) {
+	arr.Each(0, func(_ int64, v any) bool {
+		if str, ok := v.(string); ok && common.MatchPattern(str, pattern) {
 			result = append(result, str)
 		}
-	}
+		return true
+	})
 
 	return result, nil
 }
 
-// Private helper functions
+// ArSet sets the element at index, creating the array if needed. Setting an index past the
+// end of the array grows it, the indexes in between hold no value.
+func ArSet(key string, index int64, value any) error {
+	if index < 0 || index > MaxIndex {
+		return errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
+	}
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
 
-func matchPattern(str, pattern string) bool {
-	return matchPatternRecursive([]rune(str), []rune(pattern), 0, 0)
+	getOrCreate(key).Set(index, value)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "arset", key)
+	return nil
 }
 
-func matchPatternRecursive(str, pattern []rune, strIndex, patternIndex int) bool {
-	if patternIndex == len(pattern) {
-		return strIndex == len(str)
+// ArMSet sets the elements at several indexes, given as index value pairs.
+func ArMSet(key string, indexes []int64, values []any) error {
+	for _, index := range indexes {
+		if index < 0 || index > MaxIndex {
+			return errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
+		}
 	}
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
 
-	char := pattern[patternIndex]
-	switch char {
-	case '*':
-		if patternIndex+1 == len(pattern) {
-			return true
-		}
-		return matchPatternRecursive(str, pattern, strIndex, patternIndex+1) ||
-			(strIndex < len(str) && matchPatternRecursive(str, pattern, strIndex+1, patternIndex))
-	case '?':
-		return strIndex < len(str) && matchPatternRecursive(str, pattern, strIndex+1, patternIndex+1)
-	case '\\':
-		if patternIndex+1 >= len(pattern) {
-			return strIndex < len(str) && str[strIndex] == '\\'
-		}
-		if strIndex < len(str) && str[strIndex] == pattern[patternIndex+1] {
-			return matchPatternRecursive(str, pattern, strIndex+1, patternIndex+2)
-		}
-		return false
-	case '[':
-		matched, nextIndex, ok := matchClass(str, pattern, strIndex, patternIndex)
-		if !ok {
-			return false
+	arr := getOrCreate(key)
+	for i, index := range indexes {
+		arr.Set(index, values[i])
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "armset", key)
+	return nil
+}
+
+// ArMGet returns the elements at several indexes, nil for the indexes out of bounds or holding no value.
+func ArMGet(key string, indexes []int64) ([]any, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(indexes))
+	for i, index := range indexes {
+		if index >= 0 {
+			values[i] = arr.Get(index)
 		}
-		return matched && matchPatternRecursive(str, pattern, strIndex+1, nextIndex)
-	default:
-		return strIndex < len(str) && str[strIndex] == char && matchPatternRecursive(str, pattern, strIndex+1, patternIndex+1)
 	}
+	return values, nil
 }
 
-func matchClass(str, pattern []rune, strIndex, patternIndex int) (bool, int, bool) {
-	if strIndex >= len(str) {
-		return false, patternIndex, false
+// ArPush appends values to the array, creating it if needed, and returns its new length.
+func ArPush(key string, values []any) (int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	arr := getOrCreate(key)
+	if err := arr.Insert(arr.Len(), values...); err != nil {
+		return 0, err
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "arpush", key)
+	return arr.Len(), nil
+}
 
-	end := patternIndex + 1
-	for end < len(pattern) && pattern[end] != ']' {
-		end++
+// ArInsert inserts values before the element at index and returns the new length of the array.
+// An index equal to the length of the array appends the values.
+func ArInsert(key string, index int64, values []any) (int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	arr, ok := store.Get[string, *Array](key)
+	if index < 0 || index > MaxIndex || (ok && index > arr.Len()) || (!ok && index > 0) {
+		return 0, errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
 	}
-	if end >= len(pattern) {
-		return false, patternIndex, false
+	arr = getOrCreate(key)
+	if err := arr.Insert(index, values...); err != nil {
+		return 0, err
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "arinsert", key)
+	return arr.Len(), nil
+}
 
-	negated := false
-	start := patternIndex + 1
-	if start < end && (pattern[start] == '^' || pattern[start] == '!') {
-		negated = true
-		start++
-	}
+// ArPop removes and returns up to count elements from the end of the array, the last one first.
+func ArPop(key string, count int64) ([]any, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
 
-	matched := false
-	for i := start; i < end; {
-		if pattern[i] == '\\' && i+1 < end {
-			if str[strIndex] == pattern[i+1] {
-				matched = true
-			}
-			i += 2
-			continue
-		}
+	arr, err := get(key)
+	if err != nil {
+		return nil, err
+	}
+	popped := arr.Pop(count)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ARRAY, "arpop", key)
+	deleteIfEmpty(key, arr)
+	return popped, nil
+}
 
-		if i+2 < end && pattern[i+1] == '-' {
-			if pattern[i] <= str[strIndex] && str[strIndex] <= pattern[i+2] {
-				matched = true
-			}
-			i += 3
-			continue
-		}
+// ArNext returns the first index at or after cursor that holds a value, and the value.
+// It returns false if there is none.
+func ArNext(key string, cursor int64) (int64, any, bool, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
 
-		if str[strIndex] == pattern[i] {
-			matched = true
-		}
-		i++
+	arr, err := get(key)
+	if err != nil {
+		return 0, nil, false, err
 	}
+	index, value, ok := arr.Next(cursor)
+	return index, value, ok, nil
+}
 
-	if negated {
-		matched = !matched
+// ArScan looks at up to count elements from cursor, skipping the indexes holding no value, and
+// returns the indexes and values of the ones matching pattern, or all of them if pattern is empty.
+// The returned cursor is the index to continue from, 0 once the end of the array is reached.
+func ArScan(key string, cursor int64, count int64, pattern string) (int64, []int64, []any, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	arr, err := get(key)
+	if err != nil {
+		return 0, nil, nil, err
 	}
-	return matched, end + 1, true
+	indexes, values := []int64{}, []any{}
+	var seen, next int64
+	arr.Each(cursor, func(index int64, value any) bool {
+		if seen == count {
+			next = index
+			return false
+		}
+		seen++
+		if pattern == "" || common.MatchPattern(fmt.Sprint(value), pattern) {
+			indexes = append(indexes, index)
+			values = append(values, value)
+		}
+		return true
+	})
+	return next, indexes, values, nil
 }
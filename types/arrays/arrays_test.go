@@ -1,7 +1,9 @@
 package arrays_test
 
 import (
+	"math"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/divy-sh/animus/common"
@@ -12,7 +14,7 @@ import (
 func TestArGet(t *testing.T) {
 	t.Run("returns the value at a valid index", func(t *testing.T) {
 		key := "testArrayGetType"
-		store.Set(key, []any{1, 2, 3, 4, 5})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4, 5}))
 
 		value, err := arrays.ArGet(key, 2)
 		if err != nil {
@@ -35,7 +37,7 @@ func TestArGet(t *testing.T) {
 
 	t.Run("returns an error for an out-of-bounds index", func(t *testing.T) {
 		key := "outOfBoundsArrayType"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		_, err := arrays.ArGet(key, 5)
 		if err == nil {
@@ -50,7 +52,7 @@ func TestArGet(t *testing.T) {
 func TestArCount(t *testing.T) {
 	t.Run("returns the length of an existing array", func(t *testing.T) {
 		key := "testArrayCountType"
-		store.Set(key, []any{1, 2, 3, 4})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4}))
 
 		count, err := arrays.ArCount(key)
 		if err != nil {
@@ -75,19 +77,19 @@ func TestArCount(t *testing.T) {
 func TestArDel(t *testing.T) {
 	t.Run("removes the value at a valid index", func(t *testing.T) {
 		key := "testArrayDelType"
-		store.Set(key, []any{1, 2, 3, 4})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4}))
 
 		err := arrays.ArDel(key, 1)
 		if err != nil {
 			t.Fatalf("Expected no error, got %v", err)
 		}
 
-		updated, ok := store.Get[string, []any](key)
+		updated, ok := store.Get[string, *arrays.Array](key)
 		if !ok {
 			t.Fatalf("Expected array to remain stored")
 		}
-		if !reflect.DeepEqual(updated, []any{1, 3, 4}) {
-			t.Fatalf("Expected array to become [1 3 4], got %v", updated)
+		if values := updated.Range(0, updated.Len()-1); !reflect.DeepEqual(values, []any{1, nil, 3, 4}) {
+			t.Fatalf("Expected array to become [1 <nil> 3 4], got %v", values)
 		}
 	})
 
@@ -103,7 +105,7 @@ func TestArDel(t *testing.T) {
 
 	t.Run("returns an error for an out-of-bounds index", func(t *testing.T) {
 		key := "testArrayDelOutOfBounds"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		err := arrays.ArDel(key, 5)
 		if err == nil {
@@ -118,19 +120,19 @@ func TestArDel(t *testing.T) {
 func TestArDelRange(t *testing.T) {
 	t.Run("removes the values in a valid range", func(t *testing.T) {
 		key := "testArrayDelRangeType"
-		store.Set(key, []any{10, 20, 30, 40, 50})
+		store.Set(key, arrays.FromSlice([]any{10, 20, 30, 40, 50}))
 
 		err := arrays.ArDelRange(key, 1, 3)
 		if err != nil {
 			t.Fatalf("Expected no error, got %v", err)
 		}
 
-		updated, ok := store.Get[string, []any](key)
+		updated, ok := store.Get[string, *arrays.Array](key)
 		if !ok {
 			t.Fatalf("Expected array to remain stored")
 		}
-		if !reflect.DeepEqual(updated, []any{10, 50}) {
-			t.Fatalf("Expected array to become [10 50], got %v", updated)
+		if values := updated.Range(0, updated.Len()-1); !reflect.DeepEqual(values, []any{10, nil, nil, nil, 50}) {
+			t.Fatalf("Expected array to become [10 <nil> <nil> <nil> 50], got %v", values)
 		}
 	})
 
@@ -146,7 +148,7 @@ func TestArDelRange(t *testing.T) {
 
 	t.Run("returns an error for an invalid range", func(t *testing.T) {
 		key := "testArrayDelRangeOutOfBounds"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		err := arrays.ArDelRange(key, 1, 5)
 		if err == nil {
@@ -161,7 +163,7 @@ func TestArDelRange(t *testing.T) {
 func TestArGetRange(t *testing.T) {
 	t.Run("returns the values in a valid range", func(t *testing.T) {
 		key := "testArrayGetRangeType"
-		store.Set(key, []any{1, 2, 3, 4, 5})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3, 4, 5}))
 
 		values, err := arrays.ArGetRange(key, 1, 3)
 		if err != nil {
@@ -184,7 +186,7 @@ func TestArGetRange(t *testing.T) {
 
 	t.Run("returns an error for an invalid range", func(t *testing.T) {
 		key := "testArrayGetRangeOutOfBounds"
-		store.Set(key, []any{1, 2, 3})
+		store.Set(key, arrays.FromSlice([]any{1, 2, 3}))
 
 		_, err := arrays.ArGetRange(key, 1, 5)
 		if err == nil {
@@ -199,7 +201,7 @@ func TestArGetRange(t *testing.T) {
 func TestArGrep(t *testing.T) {
 	t.Run("matches array values with asterisk wildcard", func(t *testing.T) {
 		key := "testArrayGrepAsterisk"
-		store.Set(key, []any{"hello", "world", "hallo", "hi", "help"})
+		store.Set(key, arrays.FromSlice([]any{"hello", "world", "hallo", "hi", "help"}))
 
 		values, err := arrays.ArGrep(key, "h*")
 		if err != nil {
@@ -212,7 +214,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with asterisk wildcard in middle", func(t *testing.T) {
 		key := "testArrayGrepAsteriskMiddle"
-		store.Set(key, []any{"cat", "car", "card", "dog", "cast"})
+		store.Set(key, arrays.FromSlice([]any{"cat", "car", "card", "dog", "cast"}))
 
 		values, err := arrays.ArGrep(key, "ca*")
 		if err != nil {
@@ -225,7 +227,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with question mark wildcard", func(t *testing.T) {
 		key := "testArrayGrepQuestion"
-		store.Set(key, []any{"cat", "cot", "cut", "dog", "ca"})
+		store.Set(key, arrays.FromSlice([]any{"cat", "cot", "cut", "dog", "ca"}))
 
 		values, err := arrays.ArGrep(key, "c?t")
 		if err != nil {
@@ -238,7 +240,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with multiple question marks", func(t *testing.T) {
 		key := "testArrayGrepMultipleQuestion"
-		store.Set(key, []any{"ab", "abc", "abcd", "a", "acd"})
+		store.Set(key, arrays.FromSlice([]any{"ab", "abc", "abcd", "a", "acd"}))
 
 		values, err := arrays.ArGrep(key, "a??")
 		if err != nil {
@@ -251,7 +253,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with character class", func(t *testing.T) {
 		key := "testArrayGrepCharClass"
-		store.Set(key, []any{"cat", "cot", "cut", "dog", "cbt"})
+		store.Set(key, arrays.FromSlice([]any{"cat", "cot", "cut", "dog", "cbt"}))
 
 		values, err := arrays.ArGrep(key, "c[ao]t")
 		if err != nil {
@@ -264,7 +266,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with character range in class", func(t *testing.T) {
 		key := "testArrayGrepCharRange"
-		store.Set(key, []any{"cat", "cot", "cut", "cet", "dog"})
+		store.Set(key, arrays.FromSlice([]any{"cat", "cot", "cut", "cet", "dog"}))
 
 		values, err := arrays.ArGrep(key, "c[a-o]t")
 		if err != nil {
@@ -277,7 +279,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with negated character class", func(t *testing.T) {
 		key := "testArrayGrepNegatedClass"
-		store.Set(key, []any{"cat", "cot", "cut", "c1t", "c#t"})
+		store.Set(key, arrays.FromSlice([]any{"cat", "cot", "cut", "c1t", "c#t"}))
 
 		values, err := arrays.ArGrep(key, "c[^ao]t")
 		if err != nil {
@@ -290,7 +292,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with escaped asterisk", func(t *testing.T) {
 		key := "testArrayGrepEscapedAsterisk"
-		store.Set(key, []any{"a*b", "aab", "abb", "a*c"})
+		store.Set(key, arrays.FromSlice([]any{"a*b", "aab", "abb", "a*c"}))
 
 		values, err := arrays.ArGrep(key, "a\\*b")
 		if err != nil {
@@ -303,7 +305,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches array values with escaped question mark", func(t *testing.T) {
 		key := "testArrayGrepEscapedQuestion"
-		store.Set(key, []any{"a?b", "aab", "abb", "a?c"})
+		store.Set(key, arrays.FromSlice([]any{"a?b", "aab", "abb", "a?c"}))
 
 		values, err := arrays.ArGrep(key, "a\\?b")
 		if err != nil {
@@ -316,7 +318,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches with complex pattern combining wildcards and classes", func(t *testing.T) {
 		key := "testArrayGrepComplex"
-		store.Set(key, []any{"test1.go", "test2.go", "hello.go", "test_a.go", "testing.py"})
+		store.Set(key, arrays.FromSlice([]any{"test1.go", "test2.go", "hello.go", "test_a.go", "testing.py"}))
 
 		values, err := arrays.ArGrep(key, "test?.go")
 		if err != nil {
@@ -329,7 +331,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("returns empty result when no matches found", func(t *testing.T) {
 		key := "testArrayGrepNoMatch"
-		store.Set(key, []any{"hello", "world", "foo"})
+		store.Set(key, arrays.FromSlice([]any{"hello", "world", "foo"}))
 
 		values, err := arrays.ArGrep(key, "z*")
 		if err != nil {
@@ -342,7 +344,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("returns empty result for empty array", func(t *testing.T) {
 		key := "testArrayGrepEmpty"
-		store.Set(key, []any{})
+		store.Set(key, arrays.NewArray())
 
 		values, err := arrays.ArGrep(key, "h*")
 		if err != nil {
@@ -355,7 +357,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("skips non-string elements in array", func(t *testing.T) {
 		key := "testArrayGrepMixedTypes"
-		store.Set(key, []any{"hello", 42, "hallo", 3.14, "hi", true})
+		store.Set(key, arrays.FromSlice([]any{"hello", 42, "hallo", 3.14, "hi", true}))
 
 		values, err := arrays.ArGrep(key, "h*")
 		if err != nil {
@@ -378,7 +380,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches exact strings", func(t *testing.T) {
 		key := "testArrayGrepExact"
-		store.Set(key, []any{"exact", "exacto", "exact match", "notexact"})
+		store.Set(key, arrays.FromSlice([]any{"exact", "exacto", "exact match", "notexact"}))
 
 		values, err := arrays.ArGrep(key, "exact")
 		if err != nil {
@@ -391,7 +393,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches with asterisk at end", func(t *testing.T) {
 		key := "testArrayGrepAsteriskEnd"
-		store.Set(key, []any{"testing", "test", "tested", "tasting", "tea"})
+		store.Set(key, arrays.FromSlice([]any{"testing", "test", "tested", "tasting", "tea"}))
 
 		values, err := arrays.ArGrep(key, "test*")
 		if err != nil {
@@ -404,7 +406,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches with asterisk at start", func(t *testing.T) {
 		key := "testArrayGrepAsteriskStart"
-		store.Set(key, []any{"testing", "parsing", "working", "coding"})
+		store.Set(key, arrays.FromSlice([]any{"testing", "parsing", "working", "coding"}))
 
 		values, err := arrays.ArGrep(key, "*ing")
 		if err != nil {
@@ -417,7 +419,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches with multiple asterisks", func(t *testing.T) {
 		key := "testArrayGrepMultipleAsterisk"
-		store.Set(key, []any{"a1b2c", "a1b", "ac", "a1c", "axbxc"})
+		store.Set(key, arrays.FromSlice([]any{"a1b2c", "a1b", "ac", "a1c", "axbxc"}))
 
 		values, err := arrays.ArGrep(key, "a*b*c")
 		if err != nil {
@@ -430,7 +432,7 @@ func TestArGrep(t *testing.T) {
 
 	t.Run("matches with negated character class using exclamation", func(t *testing.T) {
 		key := "testArrayGrepNegatedExclamation"
-		store.Set(key, []any{"cat", "cot", "cut", "c1t", "c#t"})
+		store.Set(key, arrays.FromSlice([]any{"cat", "cot", "cut", "c1t", "c#t"}))
 
 		values, err := arrays.ArGrep(key, "c[!ao]t")
 		if err != nil {
@@ -450,3 +452,199 @@ func contains(slice []any, value string) bool {
 	}
 	return false
 }
+
+func TestArSet(t *testing.T) {
+	t.Run("creates the array and grows it with holes", func(t *testing.T) {
+		key := "testArraySet"
+		if err := arrays.ArSet(key, 3, "d"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := arrays.ArSet(key, 0, "a"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		values, _ := arrays.ArGetRange(key, 0, 3)
+		if !reflect.DeepEqual(values, []any{"a", nil, nil, "d"}) {
+			t.Fatalf("Expected values [a <nil> <nil> d], got %v", values)
+		}
+	})
+
+	t.Run("returns an error for a negative index", func(t *testing.T) {
+		err := arrays.ArSet("testArraySetNegative", -1, "a")
+		if err == nil || err.Error() != common.ERR_INDEX_OUT_OF_BOUNDS {
+			t.Fatalf("Expected error message '%s', got '%v'", common.ERR_INDEX_OUT_OF_BOUNDS, err)
+		}
+	})
+
+	t.Run("returns an error for an index the length can't follow", func(t *testing.T) {
+		key := "testArraySetMaxIndex"
+		err := arrays.ArSet(key, math.MaxInt64, "a")
+		if err == nil || err.Error() != common.ERR_INDEX_OUT_OF_BOUNDS {
+			t.Fatalf("Expected error message '%s', got '%v'", common.ERR_INDEX_OUT_OF_BOUNDS, err)
+		}
+		if err := arrays.ArMSet(key, []int64{0, math.MaxInt64}, []any{"a", "b"}); err == nil || err.Error() != common.ERR_INDEX_OUT_OF_BOUNDS {
+			t.Fatalf("Expected error message '%s', got '%v'", common.ERR_INDEX_OUT_OF_BOUNDS, err)
+		}
+		if err := arrays.ArSet(key, arrays.MaxIndex, "a"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if length, _ := arrays.ArCount(key); length != math.MaxInt64 {
+			t.Fatalf("Expected length %d, got %d", int64(math.MaxInt64), length)
+		}
+		if _, err := arrays.ArPush(key, []any{"b"}); err == nil || err.Error() != common.ERR_INDEX_OUT_OF_BOUNDS {
+			t.Fatalf("Expected error message '%s', got '%v'", common.ERR_INDEX_OUT_OF_BOUNDS, err)
+		}
+		if _, err := arrays.ArInsert(key, 0, []any{"b"}); err == nil || err.Error() != common.ERR_INDEX_OUT_OF_BOUNDS {
+			t.Fatalf("Expected error message '%s', got '%v'", common.ERR_INDEX_OUT_OF_BOUNDS, err)
+		}
+	})
+
+	t.Run("keeps the expiry of the array", func(t *testing.T) {
+		key := "testArraySetKeepsTTL"
+		store.SetWithTTL(key, arrays.FromSlice([]any{"a"}), 100)
+		arrays.ArSet(key, 1, "b")
+		if _, ttl, _ := store.GetWithTTL[string, *arrays.Array](key); ttl == -1 {
+			t.Fatalf("Expected the array to keep its expiry")
+		}
+	})
+}
+
+func TestArMSetAndArMGet(t *testing.T) {
+	key := "testArrayMSet"
+	if err := arrays.ArMSet(key, []int64{0, 2}, []any{"a", "c"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	values, err := arrays.ArMGet(key, []int64{2, 1, 0, 10, -1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(values, []any{"c", nil, "a", nil, nil}) {
+		t.Fatalf("Expected values [c <nil> a <nil> <nil>], got %v", values)
+	}
+	if _, err := arrays.ArMGet("missingMGetArray", []int64{0}); err == nil || err.Error() != common.ERR_ARRAY_NOT_FOUND {
+		t.Fatalf("Expected error message '%s', got '%v'", common.ERR_ARRAY_NOT_FOUND, err)
+	}
+}
+
+func TestArPushInsertPop(t *testing.T) {
+	key := "testArrayPushInsertPop"
+	if length, err := arrays.ArPush(key, []any{"a", "d"}); err != nil || length != 2 {
+		t.Fatalf("Expected length 2, got %d (%v)", length, err)
+	}
+	if length, err := arrays.ArInsert(key, 1, []any{"b", "c"}); err != nil || length != 4 {
+		t.Fatalf("Expected length 4, got %d (%v)", length, err)
+	}
+	if _, err := arrays.ArInsert(key, 5, []any{"x"}); err == nil || err.Error() != common.ERR_INDEX_OUT_OF_BOUNDS {
+		t.Fatalf("Expected error message '%s', got '%v'", common.ERR_INDEX_OUT_OF_BOUNDS, err)
+	}
+	popped, err := arrays.ArPop(key, 3)
+	if err != nil || !reflect.DeepEqual(popped, []any{"d", "c", "b"}) {
+		t.Fatalf("Expected values [d c b], got %v (%v)", popped, err)
+	}
+	arrays.ArPop(key, 5)
+	if _, err := arrays.ArCount(key); err == nil {
+		t.Fatalf("Expected the empty array to be deleted")
+	}
+}
+
+func TestArNextAndArScan(t *testing.T) {
+	key := "testArrayScan"
+	arrays.ArMSet(key, []int64{1, 4, 5, 9}, []any{"one", "four", "five", "nine"})
+
+	index, value, ok, err := arrays.ArNext(key, 2)
+	if err != nil || !ok || index != 4 || value != "four" {
+		t.Fatalf("Expected index 4 and value four, got %d %v %v (%v)", index, value, ok, err)
+	}
+	if _, _, ok, _ := arrays.ArNext(key, 10); ok {
+		t.Fatalf("Expected no element past the end")
+	}
+
+	cursor, indexes, values, err := arrays.ArScan(key, 0, 2, "")
+	if err != nil || cursor != 5 || !reflect.DeepEqual(indexes, []int64{1, 4}) || !reflect.DeepEqual(values, []any{"one", "four"}) {
+		t.Fatalf("Unexpected scan: %d %v %v (%v)", cursor, indexes, values, err)
+	}
+	cursor, indexes, _, _ = arrays.ArScan(key, cursor, 100, "f*")
+	if cursor != 0 || !reflect.DeepEqual(indexes, []int64{5}) {
+		t.Fatalf("Unexpected scan: %d %v", cursor, indexes)
+	}
+}
+
+func TestArSort(t *testing.T) {
+	key := "testArraySort"
+	arrays.ArMSet(key, []int64{0, 2, 3, 5}, []any{"10", "9", "-1.5", "9"})
+
+	elements, err := arrays.ArSort(key, false, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []arrays.Element{{3, "-1.5"}, {2, "9"}, {5, "9"}, {0, "10"}}
+	if !reflect.DeepEqual(elements, expected) {
+		t.Fatalf("Expected %v, got %v", expected, elements)
+	}
+	elements, _ = arrays.ArSort(key, true, true)
+	expected = []arrays.Element{{2, "9"}, {5, "9"}, {0, "10"}, {3, "-1.5"}}
+	if !reflect.DeepEqual(elements, expected) {
+		t.Fatalf("Expected %v, got %v", expected, elements)
+	}
+
+	count, err := arrays.ArSortStore(key, "testArraySortDest", true, false)
+	if err != nil || count != 4 {
+		t.Fatalf("Expected 4 values stored, got %d (%v)", count, err)
+	}
+	if values, _ := arrays.ArGetRange("testArraySortDest", 0, 3); !reflect.DeepEqual(values, []any{"10", "9", "9", "-1.5"}) {
+		t.Fatalf("Expected [10 9 9 -1.5], got %v", values)
+	}
+
+	arrays.ArPush(key, []any{"ten"})
+	if _, err := arrays.ArSort(key, false, false); err == nil || err.Error() != common.ERR_ARRAY_VALUE_NOT_FLOAT {
+		t.Fatalf("Expected error message '%s', got '%v'", common.ERR_ARRAY_VALUE_NOT_FLOAT, err)
+	}
+}
+
+func TestArAggregates(t *testing.T) {
+	key := "testArrayAggregates"
+	arrays.ArMSet(key, []int64{0, 1, 4, 6}, []any{"3", "x", "-2", "7.5"})
+
+	if sum, indexes, err := arrays.ArSum(key, false); err != nil || sum != 8.5 || !slices.Equal(indexes, []int64{0, 4, 6}) {
+		t.Fatalf("Expected sum 8.5 of [0 4 6], got %v of %v (%v)", sum, indexes, err)
+	}
+	if _, _, err := arrays.ArSum(key, true); err == nil || err.Error() != common.ERR_ARRAY_VALUE_NOT_FLOAT {
+		t.Fatalf("Expected error message '%s', got '%v'", common.ERR_ARRAY_VALUE_NOT_FLOAT, err)
+	}
+	if avg, indexes, err := arrays.ArAvg(key, false); err != nil || avg != 8.5/3 || !slices.Equal(indexes, []int64{0, 4, 6}) {
+		t.Fatalf("Expected average %v of [0 4 6], got %v of %v (%v)", 8.5/3, avg, indexes, err)
+	}
+	if element, ok, _ := arrays.ArMin(key, false); !ok || element != (arrays.Element{Index: 4, Value: "-2"}) {
+		t.Fatalf("Expected the minimum -2 at 4, got %v", element)
+	}
+	if element, ok, _ := arrays.ArMax(key, false); !ok || element != (arrays.Element{Index: 6, Value: "7.5"}) {
+		t.Fatalf("Expected the maximum 7.5 at 6, got %v", element)
+	}
+
+	arrays.ArSet("testArrayNoNumbers", 0, "x")
+	if _, indexes, err := arrays.ArAvg("testArrayNoNumbers", false); err != nil || len(indexes) != 0 {
+		t.Fatalf("Expected no average, got one of %v (%v)", indexes, err)
+	}
+}
+
+func TestArDistinctCountValAndSeek(t *testing.T) {
+	key := "testArrayDistinct"
+	arrays.ArPush(key, []any{"a", "b", "a", "c", "b", "a"})
+
+	elements, err := arrays.ArDistinct(key)
+	expected := []arrays.Element{{0, "a"}, {1, "b"}, {3, "c"}}
+	if err != nil || !reflect.DeepEqual(elements, expected) {
+		t.Fatalf("Expected %v, got %v (%v)", expected, elements, err)
+	}
+	if indexes, err := arrays.ArCountVal(key, "a"); err != nil || !reflect.DeepEqual(indexes, []int64{0, 2, 5}) {
+		t.Fatalf("Expected indexes [0 2 5], got %v (%v)", indexes, err)
+	}
+	if element, ok, _ := arrays.ArSeek(key, "[bc]", 2); !ok || element.Index != 3 {
+		t.Fatalf("Expected index 3, got %v %v", element, ok)
+	}
+	if _, ok, _ := arrays.ArSeek(key, "z*", 0); ok {
+		t.Fatalf("Expected no match")
+	}
+	if _, _, err := arrays.ArSeek("missingSeekArray", "*", 0); err == nil || err.Error() != common.ERR_ARRAY_NOT_FOUND {
+		t.Fatalf("Expected error message '%s', got '%v'", common.ERR_ARRAY_NOT_FOUND, err)
+	}
+}
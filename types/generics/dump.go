@@ -0,0 +1,103 @@
+package generics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/persistence"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/strings"
+)
+
+// Dump serializes the value stored at key, it returns false if the key doesn't exist.
+func Dump(key string) ([]byte, bool, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	value, ok := store.Get[string, any](key)
+	if !ok {
+		return nil, false, nil
+	}
+	payload, err := persistence.Dump(value)
+	return payload, err == nil, err
+}
+
+// RestoreOptions are the options of RESTORE.
+type RestoreOptions struct {
+	// Replace overwrites the key if it exists already.
+	Replace bool
+	// ExpireAt is the unix time in seconds the key expires at, -1 for no expiry.
+	ExpireAt int64
+	// IdleTime and Freq set the access metadata of the key, unless they are negative.
+	IdleTime time.Duration
+	Freq     int64
+}
+
+// Restore stores a value serialized by Dump at key. A key whose expiry is in the past
+// is not created, but still replaces the existing key.
+func Restore(key string, payload []byte, options RestoreOptions) error {
+	value, err := persistence.Load(payload)
+	if err != nil {
+		return err
+	}
+	if s, ok := value.(string); ok {
+		value = strings.Encode(s)
+	}
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+	_, exists := store.Inspect(key)
+	if exists && !options.Replace {
+		return errors.New(common.ERR_BUSY_KEY)
+	}
+	if options.ExpireAt > -1 && options.ExpireAt <= time.Now().Unix() {
+		if exists {
+			store.Delete(key)
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+		}
+		return nil
+	}
+	store.SetWithTTLAsUnixTimeStamp(key, value, options.ExpireAt)
+	if options.IdleTime >= 0 || options.Freq >= 0 {
+		stored, _ := store.Inspect(key)
+		idle, freq := options.IdleTime, options.Freq
+		if idle < 0 {
+			idle = stored.IdleTime()
+		}
+		if freq < 0 {
+			freq = stored.Freq()
+		}
+		stored.SetAccess(idle, freq)
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "restore", key)
+	return nil
+}
+
+// Transfer serializes the value stored at key and hands it to send, with its remaining
+// time to live in milliseconds or 0 if it has none. The key stays locked until send
+// returns and is deleted if send succeeds, unless keep is set. It returns false if the
+// key doesn't exist.
+func Transfer(key string, keep bool, send func(payload []byte, ttl int64) error) (bool, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+	value, expireAt, ok := store.GetWithTTL[string, any](key)
+	if !ok {
+		return false, nil
+	}
+	payload, err := persistence.Dump(value)
+	if err != nil {
+		return true, err
+	}
+	var ttl int64
+	if expireAt > -1 {
+		ttl = max(expireAt*1000-time.Now().UnixMilli(), 1)
+	}
+	if err := send(payload, ttl); err != nil {
+		return true, err
+	}
+	if !keep {
+		store.Delete(key)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+	}
+	return true, nil
+}
@@ -2,32 +2,246 @@ package generics
 
 import (
 	"errors"
+	"math/rand"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
 	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/arrays"
+	"github.com/divy-sh/animus/types/hashes"
+	"github.com/divy-sh/animus/types/json"
+	"github.com/divy-sh/animus/types/lists"
+	"github.com/divy-sh/animus/types/sets"
+	"github.com/divy-sh/animus/types/streams"
+	"github.com/divy-sh/animus/types/zsets"
 )
 
-func Copy(source, destination string) (int64, error) {
+// Copy copies the value of source, along with its time to live, to destination.
+// It returns 0 if destination exists and replace isn't set.
+func Copy(source, destination string, replace bool) (int64, error) {
 	store.LockKeys(source, destination)
 	defer store.UnlockKeys(source, destination)
-	value, ok := store.Get[any, any](source)
+	value, ttl, ok := store.GetWithTTL[string, any](source)
 	if !ok {
 		return 0, errors.New(common.ERR_SOURCE_KEY_NOT_FOUND)
 	}
-	store.Set(destination, value)
+	if _, exists := store.Get[string, any](destination); exists && !replace {
+		return 0, nil
+	}
+	store.SetWithTTLAsUnixTimeStamp(destination, deepCopy(value), ttl)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "copy_to", destination)
 	return 1, nil
 }
 
-func Delete(keys *[]string) {
+// deepCopy copies a stored value so the copy can be modified independently of the original.
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case *lists.List:
+		return v.Clone()
+	case *hashes.Hash:
+		return v.Clone()
+	case *sets.Set:
+		return v.Clone()
+	case *arrays.Array:
+		return v.Clone()
+	case *streams.Stream:
+		return v.Clone()
+	case *zsets.ZSet:
+		return v.Clone()
+	case *json.Document:
+		return v.Clone()
+	default:
+		return value
+	}
+}
+
+// Delete deletes keys and returns the number of keys that existed.
+func Delete(keys *[]string) int64 {
+	store.LockKeys(*keys...)
+	defer store.UnlockKeys(*keys...)
+	var deleted int64
+	for _, key := range *keys {
+		if store.Delete(key) {
+			deleted++
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+		}
+	}
+	return deleted
+}
+
+// lazyfreeThreshold is the number of elements above which UNLINK frees a value in the background.
+const lazyfreeThreshold = 64
+
+var lazyfreePendingObjects atomic.Int64
+
+// Unlink deletes keys like Delete, but the elements of large values are released on a
+// background goroutine so the caller doesn't wait for them. It returns the number of keys that existed.
+func Unlink(keys *[]string) int64 {
 	store.LockKeys(*keys...)
 	defer store.UnlockKeys(*keys...)
+	var unlinked int64
 	for _, key := range *keys {
-		store.Delete(key)
+		value, ok := store.Take(key)
+		if !ok {
+			continue
+		}
+		unlinked++
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+		if valueLength(value) > lazyfreeThreshold {
+			lazyfreePendingObjects.Add(1)
+			go func() {
+				defer lazyfreePendingObjects.Add(-1)
+				freeValue(value)
+			}()
+		}
 	}
+	return unlinked
+}
+
+// LazyfreePendingObjects returns the number of unlinked values still being freed.
+func LazyfreePendingObjects() int64 {
+	return lazyfreePendingObjects.Load()
+}
+
+func valueLength(value any) int {
+	switch v := value.(type) {
+	case *lists.List:
+		return v.Len()
+	case *hashes.Hash:
+		return v.Len()
+	case *sets.Set:
+		return v.Len()
+	case *arrays.Array:
+		return int(v.Count())
+	case *streams.Stream:
+		return v.Len()
+	case *zsets.ZSet:
+		return v.Len()
+	case *json.Document:
+		return v.Len()
+	default:
+		return 1
+	}
+}
+
+// freeValue drops the references a value holds to its elements.
+func freeValue(value any) {
+	switch v := value.(type) {
+	case *lists.List:
+		v.Clear()
+	case *hashes.Hash:
+		v.Clear()
+	case *sets.Set:
+		v.Clear()
+	case *arrays.Array:
+		v.Clear()
+	case *streams.Stream:
+		v.Clear()
+	case *zsets.ZSet:
+		v.Clear()
+	case *json.Document:
+		v.Clear()
+	}
+}
+
+// Rename renames source to destination, keeping its time to live and overwriting destination.
+// With nx set nothing is renamed if destination exists and 0 is returned.
+func Rename(source, destination string, nx bool) (int64, error) {
+	store.LockKeys(source, destination)
+	defer store.UnlockKeys(source, destination)
+	value, ttl, ok := store.GetWithTTL[string, any](source)
+	if !ok {
+		return 0, errors.New(common.ERR_KEY_NOT_FOUND)
+	}
+	if source == destination {
+		if nx {
+			return 0, nil
+		}
+		return 1, nil
+	}
+	if _, exists := store.Get[string, any](destination); exists && nx {
+		return 0, nil
+	}
+	store.Delete(source)
+	store.SetWithTTLAsUnixTimeStamp(destination, value, ttl)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "rename_from", source)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "rename_to", destination)
+	return 1, nil
+}
+
+// Persist removes the time to live of a key, it returns 0 if the key doesn't exist or has no expiry.
+func Persist(key string) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+	value, ttl, ok := store.GetWithTTL[string, any](key)
+	if !ok || ttl == -1 {
+		return 0
+	}
+	store.Set(key, value)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "persist", key)
+	return 1
+}
+
+// TTL returns the remaining time to live of a key in seconds,
+// -1 if it has no expiry and -2 if it doesn't exist.
+func TTL(key string) int64 {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	_, ttl, ok := store.GetWithTTL[string, any](key)
+	if !ok {
+		return -2
+	}
+	if ttl == -1 {
+		return -1
+	}
+	return max(ttl-time.Now().Unix(), 0)
+}
+
+// Type returns the name of the data type stored at key, none if it doesn't exist.
+func Type(key string) string {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	value, ok := store.Get[string, any](key)
+	if !ok {
+		return "none"
+	}
+	return TypeOf(value)
+}
+
+// RandomKey returns a random key of database db, or false if the database is empty.
+func RandomKey(db int) (string, bool) {
+	var key string
+	var seen int64
+	// Reservoir sampling picks each key with the same probability in a single pass.
+	store.Scan(func(k any, _ *store.Value) {
+		keyDB, name := common.SplitDBKey(k.(string))
+		if keyDB != db {
+			return
+		}
+		seen++
+		if rand.Int63n(seen) == 0 {
+			key = name
+		}
+	})
+	return key, seen > 0
+}
+
+// Touch refreshes the LRU recency of keys and returns the number of keys that exist.
+func Touch(keys *[]string) int64 {
+	store.RLockKeys(*keys...)
+	defer store.RUnlockKeys(*keys...)
+	var touched int64
+	for _, key := range *keys {
+		if _, ok := store.Get[string, any](key); ok {
+			touched++
+		}
+	}
+	return touched
 }
 
 func Exists(keys *[]string) int64 {
@@ -64,6 +278,7 @@ func Expire(key, seconds, flag string) error {
 		return errors.New(common.ERR_EXPIRY_TYPE)
 	}
 	store.SetWithTTL(key, val, secs)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "expire", key)
 	return nil
 }
 
@@ -88,20 +303,22 @@ func ExpireAt(key, unixTimeInSeconds, flag string) error {
 		return errors.New(common.ERR_EXPIRY_TYPE)
 	}
 	store.SetWithTTLAsUnixTimeStamp(key, val, unixTimeStamp)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "expire", key)
 	return nil
 }
 
 func ExpireTime(key string) (int64, error) {
 	store.RLockKeys(key)
 	defer store.RUnlockKeys(key)
-	_, ttl, exists := store.GetWithTTL[string, string](key)
+	_, ttl, exists := store.GetWithTTL[string, any](key)
 	if !exists {
 		return -2, errors.New(common.ERR_SOURCE_KEY_NOT_FOUND)
 	}
 	return ttl, nil
 }
 
-func Keys(pattern string) (*[]string, error) {
+// Keys returns the keys of database db whose names match the regular expression pattern.
+func Keys(db int, pattern string) (*[]string, error) {
 	re, err := regexp.Compile(pattern)
 	if err != nil {
 		return nil, errors.New(common.ERR_INVALID_REGEX)
@@ -109,9 +326,104 @@ func Keys(pattern string) (*[]string, error) {
 	allKeys := store.GetKeys[string]()
 	matchedKeys := []string{}
 	for _, key := range *allKeys {
-		if re.MatchString(key) {
-			matchedKeys = append(matchedKeys, key)
+		keyDB, name := common.SplitDBKey(key)
+		if keyDB == db && re.MatchString(name) {
+			matchedKeys = append(matchedKeys, name)
 		}
 	}
 	return &matchedKeys, nil
 }
+
+// Move moves a key to another database, given the names both keys are stored under.
+// The key keeps its time to live, nothing is moved if the destination key already exists.
+func Move(source, destination string) int64 {
+	store.LockKeys(source, destination)
+	defer store.UnlockKeys(source, destination)
+	if _, exists := store.Get[string, any](destination); exists {
+		return 0
+	}
+	value, ttl, ok := store.GetWithTTL[string, any](source)
+	if !ok {
+		return 0
+	}
+	store.SetWithTTLAsUnixTimeStamp(destination, value, ttl)
+	store.Delete(source)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "move_from", source)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "move_to", destination)
+	return 1
+}
+
+// DBSize returns the number of keys in database db.
+func DBSize(db int) int64 {
+	var size int64
+	store.Scan(func(key any, _ *store.Value) {
+		if keyDB, _ := common.SplitDBKey(key.(string)); keyDB == db {
+			size++
+		}
+	})
+	return size
+}
+
+// FlushDB deletes every key of database db.
+func FlushDB(db int) int64 {
+	return store.Flush(func(key any) bool {
+		keyDB, _ := common.SplitDBKey(key.(string))
+		return keyDB == db
+	})
+}
+
+// FlushAll deletes every key of every database.
+func FlushAll() int64 {
+	return store.Flush(nil)
+}
+
+// SwapDB swaps the contents of two databases.
+func SwapDB(first, second int) {
+	if first == second {
+		return
+	}
+	store.RenameKeys(func(key any) (any, bool) {
+		db, name := common.SplitDBKey(key.(string))
+		switch db {
+		case first:
+			return common.DBKey(second, name), true
+		case second:
+			return common.DBKey(first, name), true
+		default:
+			return nil, false
+		}
+	})
+}
+
+// TypeOf returns the name of the data type of a stored value.
+func TypeOf(value any) string {
+	switch value.(type) {
+	case string, int64:
+		return "string"
+	case *lists.List:
+		return "list"
+	case *hashes.Hash:
+		return "hash"
+	case *sets.Set:
+		return "set"
+	case *arrays.Array:
+		return "array"
+	case *streams.Stream:
+		return "stream"
+	case *zsets.ZSet:
+		return "zset"
+	case *json.Document:
+		return "ReJSON-RL"
+	default:
+		return "none"
+	}
+}
+
+// KeysPerType counts the keys in the store by data type.
+func KeysPerType() map[string]int64 {
+	counts := map[string]int64{}
+	store.Scan(func(_ any, value *store.Value) {
+		counts[TypeOf(value.Val)]++
+	})
+	return counts
+}
@@ -6,15 +6,21 @@ import (
 	"time"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/types/arrays"
 	"github.com/divy-sh/animus/types/generics"
+	"github.com/divy-sh/animus/types/geo"
 	"github.com/divy-sh/animus/types/hashes"
+	"github.com/divy-sh/animus/types/json"
 	"github.com/divy-sh/animus/types/lists"
+	"github.com/divy-sh/animus/types/sets"
+	"github.com/divy-sh/animus/types/streams"
 	"github.com/divy-sh/animus/types/strings"
+	"github.com/divy-sh/animus/types/zsets"
 )
 
 func TestStringCopy(t *testing.T) {
 	strings.Set("TestStringCopy", "expected")
-	generics.Copy("TestStringCopy", "TestStringCopy2")
+	generics.Copy("TestStringCopy", "TestStringCopy2", false)
 	val, err := strings.Get("TestStringCopy2")
 	if err != nil || val != "expected" {
 		t.Errorf("Expected value: expected, got: %v", val)
@@ -23,7 +29,7 @@ func TestStringCopy(t *testing.T) {
 
 func TestHashCopy(t *testing.T) {
 	hashes.HSet("TestHashCopy", "pizza", "expected")
-	generics.Copy("TestHashCopy", "TestHashCopy2")
+	generics.Copy("TestHashCopy", "TestHashCopy2", false)
 	val, err := hashes.HGet("TestHashCopy2", "pizza")
 	if err != nil || val != "expected" {
 		t.Errorf("Expected value: expected, got: %v", val)
@@ -32,7 +38,7 @@ func TestHashCopy(t *testing.T) {
 
 func TestListCopy(t *testing.T) {
 	lists.RPush("TestListCopy", &[]string{"expected"})
-	generics.Copy("TestListCopy", "TestListCopy2")
+	generics.Copy("TestListCopy", "TestListCopy2", false)
 	val, err := lists.RPop("TestListCopy2", "1")
 	if err != nil || val[0] != "expected" {
 		t.Errorf("Expected value: expected, got: %v", val)
@@ -40,7 +46,7 @@ func TestListCopy(t *testing.T) {
 }
 
 func TestInvalidKeyCopy(t *testing.T) {
-	val, err := generics.Copy("TestInvalidKeyCopy", "TestInvalidKeyCopy2")
+	val, err := generics.Copy("TestInvalidKeyCopy", "TestInvalidKeyCopy2", false)
 	if err == nil || err.Error() != common.ERR_SOURCE_KEY_NOT_FOUND {
 		t.Errorf("%v, %v", val, err)
 	}
@@ -478,7 +484,7 @@ func TestGenerics_ExpireAt(t *testing.T) {
 }
 
 func TestGenerics_KeysNoKeys(t *testing.T) {
-	keys, err := generics.Keys("nonExisting")
+	keys, err := generics.Keys(0, "nonExisting")
 	if err != nil || len(*keys) > 0 {
 		t.Errorf("expected no keys, got keys: %v, error: %v", keys, err)
 	}
@@ -488,14 +494,14 @@ func TestGenerics_Keys(t *testing.T) {
 	strings.Set("TestGenerics_Keys", "value")
 	hashes.HSet("TestGenerics_Keys1", "a", "b")
 	lists.RPush("non_matching_key", &[]string{"a"})
-	keys, err := generics.Keys("TestGenerics_Key")
+	keys, err := generics.Keys(0, "TestGenerics_Key")
 	if err != nil || len(*keys) != 2 {
 		t.Errorf("expected multiple keys, got: %v, error: %v", keys, err)
 	}
 }
 
 func TestGenerics_Keys_invalidRegex(t *testing.T) {
-	_, err := generics.Keys("[a-b")
+	_, err := generics.Keys(0, "[a-b")
 	if err == nil || err.Error() != common.ERR_INVALID_REGEX {
 		t.Errorf("expected error: %v, got: %v", common.ERR_INVALID_REGEX, err)
 	}
@@ -515,3 +521,265 @@ func Test_Generics_ExpireTime_InvalidKey(t *testing.T) {
 		t.Errorf("expected error: %s, got value: %d, error: %v", common.ERR_SOURCE_KEY_NOT_FOUND, val, err)
 	}
 }
+
+func TestGenerics_TypeOf(t *testing.T) {
+	cases := map[string]any{
+		"string":    "value",
+		"list":      lists.NewList(),
+		"hash":      hashes.NewHash(0),
+		"set":       sets.NewSet(),
+		"array":     arrays.NewArray(),
+		"stream":    streams.NewStream(),
+		"zset":      zsets.NewZSet(),
+		"ReJSON-RL": &json.Document{},
+		"none":      42,
+	}
+	for expected, value := range cases {
+		if got := generics.TypeOf(value); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
+
+func TestGenerics_KeysPerType(t *testing.T) {
+	strings.Set("TestGenerics_KeysPerType", "value")
+	hashes.HSet("TestGenerics_KeysPerTypeHash", "field", "value")
+	counts := generics.KeysPerType()
+	if counts["string"] < 1 || counts["hash"] < 1 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestGenerics_CopyIsDeep(t *testing.T) {
+	hashes.HSet("TestGenerics_CopyIsDeep", "field", "original")
+	if n, err := generics.Copy("TestGenerics_CopyIsDeep", "TestGenerics_CopyIsDeep2", false); err != nil || n != 1 {
+		t.Fatalf("expected the key to be copied, got %d, %v", n, err)
+	}
+	hashes.HSet("TestGenerics_CopyIsDeep2", "field", "changed")
+	if val, _ := hashes.HGet("TestGenerics_CopyIsDeep", "field"); val != "original" {
+		t.Errorf("expected the source to be unchanged, got %s", val)
+	}
+}
+
+func TestGenerics_CopyReplace(t *testing.T) {
+	strings.Set("TestGenerics_CopyReplace", "source")
+	strings.Set("TestGenerics_CopyReplace2", "destination")
+	if n, _ := generics.Copy("TestGenerics_CopyReplace", "TestGenerics_CopyReplace2", false); n != 0 {
+		t.Errorf("expected no copy over an existing key, got %d", n)
+	}
+	if n, _ := generics.Copy("TestGenerics_CopyReplace", "TestGenerics_CopyReplace2", true); n != 1 {
+		t.Errorf("expected the key to be replaced, got %d", n)
+	}
+	if val, _ := strings.Get("TestGenerics_CopyReplace2"); val != "source" {
+		t.Errorf("expected source, got %s", val)
+	}
+}
+
+func TestGenerics_DeleteCount(t *testing.T) {
+	strings.Set("TestGenerics_DeleteCount1", "value")
+	strings.Set("TestGenerics_DeleteCount2", "value")
+	keys := []string{"TestGenerics_DeleteCount1", "TestGenerics_DeleteCount2", "TestGenerics_DeleteCountMissing"}
+	if n := generics.Delete(&keys); n != 2 {
+		t.Errorf("expected 2 deleted keys, got %d", n)
+	}
+}
+
+func TestGenerics_Rename(t *testing.T) {
+	strings.Set("TestGenerics_Rename", "value")
+	generics.Expire("TestGenerics_Rename", "100", "")
+	if n, err := generics.Rename("TestGenerics_Rename", "TestGenerics_Rename2", false); err != nil || n != 1 {
+		t.Fatalf("expected the key to be renamed, got %d, %v", n, err)
+	}
+	if generics.TTL("TestGenerics_Rename") != -2 {
+		t.Errorf("expected the old key to be gone")
+	}
+	if ttl := generics.TTL("TestGenerics_Rename2"); ttl <= 0 || ttl > 100 {
+		t.Errorf("expected the expiry to be kept, got %d", ttl)
+	}
+	if _, err := generics.Rename("TestGenerics_Rename", "TestGenerics_Rename3", false); err == nil || err.Error() != common.ERR_KEY_NOT_FOUND {
+		t.Errorf("expected %s, got %v", common.ERR_KEY_NOT_FOUND, err)
+	}
+	if n, err := generics.Rename("TestGenerics_Rename2", "TestGenerics_Rename2", false); err != nil || n != 1 {
+		t.Errorf("expected renaming a key to itself to succeed, got %d, %v", n, err)
+	}
+}
+
+func TestGenerics_RenameNx(t *testing.T) {
+	strings.Set("TestGenerics_RenameNx", "value")
+	strings.Set("TestGenerics_RenameNx2", "other")
+	if n, _ := generics.Rename("TestGenerics_RenameNx", "TestGenerics_RenameNx2", true); n != 0 {
+		t.Errorf("expected no rename over an existing key, got %d", n)
+	}
+	if val, _ := strings.Get("TestGenerics_RenameNx2"); val != "other" {
+		t.Errorf("expected the destination to be unchanged, got %s", val)
+	}
+}
+
+func TestGenerics_PersistAndTTL(t *testing.T) {
+	strings.Set("TestGenerics_PersistAndTTL", "value")
+	if generics.Persist("TestGenerics_PersistAndTTL") != 0 {
+		t.Errorf("expected 0 for a key without expiry")
+	}
+	if generics.TTL("TestGenerics_PersistAndTTL") != -1 {
+		t.Errorf("expected -1 for a key without expiry")
+	}
+	generics.Expire("TestGenerics_PersistAndTTL", "100", "")
+	if ttl := generics.TTL("TestGenerics_PersistAndTTL"); ttl < 99 {
+		t.Errorf("expected about 100 seconds, got %d", ttl)
+	}
+	if generics.Persist("TestGenerics_PersistAndTTL") != 1 {
+		t.Errorf("expected the expiry to be removed")
+	}
+	if generics.TTL("TestGenerics_PersistAndTTL") != -1 {
+		t.Errorf("expected no expiry after PERSIST")
+	}
+	if generics.TTL("TestGenerics_PersistAndTTLMissing") != -2 {
+		t.Errorf("expected -2 for a missing key")
+	}
+}
+
+func TestGenerics_Type(t *testing.T) {
+	lists.RPush("TestGenerics_Type", &[]string{"a"})
+	if typ := generics.Type("TestGenerics_Type"); typ != "list" {
+		t.Errorf("expected list, got %s", typ)
+	}
+	if typ := generics.Type("TestGenerics_TypeMissing"); typ != "none" {
+		t.Errorf("expected none, got %s", typ)
+	}
+}
+
+func TestGenerics_RandomKey(t *testing.T) {
+	strings.Set(common.DBKey(9, "TestGenerics_RandomKey"), "value")
+	if key, ok := generics.RandomKey(9); !ok || key != "TestGenerics_RandomKey" {
+		t.Errorf("expected TestGenerics_RandomKey, got %q", key)
+	}
+	if _, ok := generics.RandomKey(10); ok {
+		t.Errorf("expected no key in an empty database")
+	}
+}
+
+func TestGenerics_Touch(t *testing.T) {
+	strings.Set("TestGenerics_Touch", "value")
+	if n := generics.Touch(&[]string{"TestGenerics_Touch", "TestGenerics_TouchMissing"}); n != 1 {
+		t.Errorf("expected 1 existing key, got %d", n)
+	}
+}
+
+func TestGenerics_Unlink(t *testing.T) {
+	values := make([]string, 1000)
+	for i := range values {
+		values[i] = fmt.Sprint(i)
+	}
+	lists.RPush("TestGenerics_Unlink", &values)
+	strings.Set("TestGenerics_Unlink2", "value")
+	if n := generics.Unlink(&[]string{"TestGenerics_Unlink", "TestGenerics_Unlink2", "TestGenerics_UnlinkMissing"}); n != 2 {
+		t.Errorf("expected 2 unlinked keys, got %d", n)
+	}
+	if generics.Exists(&[]string{"TestGenerics_Unlink", "TestGenerics_Unlink2"}) != 0 {
+		t.Errorf("expected the keys to be gone")
+	}
+	for generics.LazyfreePendingObjects() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGenerics_ObjectEncoding(t *testing.T) {
+	strings.Set("TestGenerics_ObjectEncodingInt", "1234")
+	strings.Set("TestGenerics_ObjectEncodingLargeInt", "123456")
+	strings.Set("TestGenerics_ObjectEncodingNegativeInt", "-5")
+	strings.Set("TestGenerics_ObjectEncodingPaddedInt", "0123")
+	strings.Set("TestGenerics_ObjectEncodingOverflowInt", "9223372036854775808")
+	strings.Set("TestGenerics_ObjectEncodingEmbstr", "value")
+	strings.Set("TestGenerics_ObjectEncodingRaw", fmt.Sprintf("%050d", 0)+"x")
+	lists.RPush("TestGenerics_ObjectEncodingList", &[]string{"a"})
+	lists.RPush("TestGenerics_ObjectEncodingDeque", &[]string{string(make([]byte, 10000))})
+	hashes.HSet("TestGenerics_ObjectEncodingHash", "field", "value")
+	hashes.HSet("TestGenerics_ObjectEncodingHashtable", "field", string(make([]byte, 100)))
+	sets.Sadd("TestGenerics_ObjectEncodingIntset", []string{"1", "2"})
+	sets.Sadd("TestGenerics_ObjectEncodingSet", []string{"1", "a"})
+	streams.XAdd("TestGenerics_ObjectEncodingStream", "*", []string{"field", "value"}, false, nil)
+	geo.GeoAdd("TestGenerics_ObjectEncodingGeo", []geo.Location{{Longitude: 1, Latitude: 1, Member: "a"}}, false, false, false)
+	root, _ := json.ParsePath("$")
+	json.Set("TestGenerics_ObjectEncodingJSON", root, json.NewObject(), false, false)
+	for key, expected := range map[string]string{
+		"TestGenerics_ObjectEncodingInt":         "int",
+		"TestGenerics_ObjectEncodingLargeInt":    "int",
+		"TestGenerics_ObjectEncodingNegativeInt": "int",
+		"TestGenerics_ObjectEncodingPaddedInt":   "embstr",
+		"TestGenerics_ObjectEncodingOverflowInt": "embstr",
+		"TestGenerics_ObjectEncodingEmbstr":      "embstr",
+		"TestGenerics_ObjectEncodingRaw":         "raw",
+		"TestGenerics_ObjectEncodingList":        "listpack",
+		"TestGenerics_ObjectEncodingDeque":       "deque",
+		"TestGenerics_ObjectEncodingHash":        "listpack",
+		"TestGenerics_ObjectEncodingHashtable":   "hashtable",
+		"TestGenerics_ObjectEncodingIntset":      "intset",
+		"TestGenerics_ObjectEncodingSet":         "hashtable",
+		"TestGenerics_ObjectEncodingStream":      "stream",
+		"TestGenerics_ObjectEncodingGeo":         "skiplist",
+		"TestGenerics_ObjectEncodingJSON":        "json",
+	} {
+		if encoding, ok := generics.ObjectEncoding(key); !ok || encoding != expected {
+			t.Errorf("expected %s for %s, got %s", expected, key, encoding)
+		}
+	}
+	if _, ok := generics.ObjectEncoding("TestGenerics_ObjectEncodingMissing"); ok {
+		t.Errorf("expected a missing key")
+	}
+}
+
+func TestGenerics_MemoryUsage(t *testing.T) {
+	strings.Set("TestGenerics_MemoryUsageSmall", "a")
+	strings.Set("TestGenerics_MemoryUsageLarge", fmt.Sprintf("%01000d", 0))
+	small, _ := generics.MemoryUsage("TestGenerics_MemoryUsageSmall", 0)
+	large, _ := generics.MemoryUsage("TestGenerics_MemoryUsageLarge", 0)
+	if large-small != 999 {
+		t.Errorf("expected the sizes to differ by the value length, got %d and %d", small, large)
+	}
+
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = "0123456789"
+	}
+	lists.RPush("TestGenerics_MemoryUsageList", &values)
+	sampled, _ := generics.MemoryUsage("TestGenerics_MemoryUsageList", 5)
+	all, _ := generics.MemoryUsage("TestGenerics_MemoryUsageList", 0)
+	if sampled != all || all < 1000 {
+		t.Errorf("expected equal elements to give the same estimate, got %d and %d", sampled, all)
+	}
+	if _, ok := generics.MemoryUsage("TestGenerics_MemoryUsageMissing", 0); ok {
+		t.Errorf("expected a missing key")
+	}
+}
+
+func TestGenerics_DumpAndRestore(t *testing.T) {
+	hashes.HSet("TestGenerics_DumpAndRestore", "field", "value")
+	payload, ok, err := generics.Dump("TestGenerics_DumpAndRestore")
+	if !ok || err != nil {
+		t.Fatalf("expected a payload, got %v", err)
+	}
+	options := generics.RestoreOptions{ExpireAt: time.Now().Unix() + 100, IdleTime: time.Hour, Freq: -1}
+	if err := generics.Restore("TestGenerics_DumpAndRestore2", payload, options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, _ := hashes.HGet("TestGenerics_DumpAndRestore2", "field"); value != "value" {
+		t.Errorf("expected the hash to be restored, got %q", value)
+	}
+	if ttl := generics.TTL("TestGenerics_DumpAndRestore2"); ttl < 99 {
+		t.Errorf("expected the expiry to be set, got %d", ttl)
+	}
+
+	if err := generics.Restore("TestGenerics_DumpAndRestore2", payload, options); err == nil || err.Error() != common.ERR_BUSY_KEY {
+		t.Errorf("expected %s, got %v", common.ERR_BUSY_KEY, err)
+	}
+	options = generics.RestoreOptions{Replace: true, ExpireAt: time.Now().Unix() - 1, IdleTime: -1, Freq: -1}
+	if err := generics.Restore("TestGenerics_DumpAndRestore2", payload, options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if generics.Exists(&[]string{"TestGenerics_DumpAndRestore2"}) != 0 {
+		t.Errorf("expected a key restored with a past expiry to be removed")
+	}
+	if _, ok, _ := generics.Dump("TestGenerics_DumpAndRestoreMissing"); ok {
+		t.Errorf("expected no payload for a missing key")
+	}
+}
@@ -0,0 +1,276 @@
+package generics
+
+import (
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/arrays"
+	"github.com/divy-sh/animus/types/hashes"
+	"github.com/divy-sh/animus/types/json"
+	"github.com/divy-sh/animus/types/lists"
+	"github.com/divy-sh/animus/types/sets"
+	"github.com/divy-sh/animus/types/streams"
+	"github.com/divy-sh/animus/types/zsets"
+)
+
+// Rough sizes in bytes of the Go structures behind the stored values, used to estimate memory usage.
+const (
+	stringHeaderSize = 16
+	sliceHeaderSize  = 24
+	interfaceSize    = 16
+	mapHeaderSize    = 48
+	dequeHeaderSize  = 48
+	// A listpack is a slice header and an entry count, its entries are counted byte for byte.
+	listpackHeaderSize = sliceHeaderSize + 8
+	// A hash or set entry takes a tophash byte, the field and the value in a map bucket,
+	// with about a fifth of the bucket slots left free.
+	hashEntrySize = (1 + 2*stringHeaderSize) * 5 / 4
+	setEntrySize  = (2 + stringHeaderSize) * 5 / 4
+	// A field expiry shares the field name with the hash entry and adds an int64.
+	expiryEntrySize = (1 + stringHeaderSize + 8) * 5 / 4
+	// An array leaf holds its values, two slice headers and a count. The other nodes of the
+	// tree add about one pointer per leaf.
+	arrayPageSize   = arrays.PageSize*interfaceSize + 2*sliceHeaderSize + 8 + 8
+	arrayHeaderSize = 48
+	// A stream holds its entries in a slice, three IDs, a counter and a map of consumer groups.
+	// An entry is its ID and a slice of field names and values.
+	streamHeaderSize = sliceHeaderSize + 3*16 + 8 + mapHeaderSize
+	streamEntrySize  = 16 + sliceHeaderSize
+	// A consumer group has a map of pending entries, each keyed by its ID and pointing to its
+	// consumer, delivery time and count, and a map of consumers and their two times.
+	groupHeaderSize  = 16 + 8 + 2*mapHeaderSize
+	pendingEntrySize = (1+16+8)*5/4 + stringHeaderSize + 16
+	consumerSize     = (1+stringHeaderSize+8)*5/4 + 16
+	// A sorted set member has a map entry with its score and a skiplist node holding the member,
+	// the score and a slice of 4/3 next pointers on average.
+	zsetHeaderSize = mapHeaderSize + 8 + 8
+	zsetEntrySize  = (1+stringHeaderSize+8)*5/4 + 8 + stringHeaderSize + 8 + sliceHeaderSize + 11
+	// keyOverhead is the store.Value holding a value plus its entry in the LRU cache.
+	keyOverhead = 112
+)
+
+// DefaultMemorySamples is the number of elements MEMORY USAGE looks at when estimating a container.
+const DefaultMemorySamples = 5
+
+// Encoding returns the name of the internal representation of a stored value, as reported by OBJECT ENCODING.
+func Encoding(value any) string {
+	switch v := value.(type) {
+	case int64:
+		// Strings holding an integer are stored as one, see strings.Encode.
+		return "int"
+	case string:
+		if len(v) <= 44 {
+			return "embstr"
+		}
+		return "raw"
+	case *lists.List:
+		if _, packed := v.PackedSize(); packed {
+			return "listpack"
+		}
+		return "deque"
+	case *hashes.Hash:
+		if _, packed := v.PackedSize(); packed {
+			return "listpack"
+		}
+		return "hashtable"
+	case *sets.Set:
+		if v.Intset() {
+			return "intset"
+		}
+		return "hashtable"
+	case *arrays.Array:
+		return "array"
+	case *streams.Stream:
+		return "stream"
+	case *zsets.ZSet:
+		return "skiplist"
+	case *json.Document:
+		return "json"
+	default:
+		return "unknown"
+	}
+}
+
+// ObjectEncoding returns the encoding of the value stored at key.
+func ObjectEncoding(key string) (string, bool) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	value, ok := store.Inspect(key)
+	if !ok {
+		return "", false
+	}
+	return Encoding(value.Val), true
+}
+
+// ObjectIdleTime returns the number of seconds since the key was last accessed.
+// Looking a key up with OBJECT doesn't count as an access.
+func ObjectIdleTime(key string) (int64, bool) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	value, ok := store.Inspect(key)
+	if !ok {
+		return 0, false
+	}
+	return int64(value.IdleTime().Seconds()), true
+}
+
+// ObjectFreq returns the logarithmic access frequency counter of the key.
+func ObjectFreq(key string) (int64, bool) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	value, ok := store.Inspect(key)
+	if !ok {
+		return 0, false
+	}
+	return value.Freq(), true
+}
+
+// ObjectRefCount returns the number of references to the value of the key. References
+// aren't counted, small integers shared between keys included, so it is always 1.
+func ObjectRefCount(key string) (int64, bool) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	if _, ok := store.Inspect(key); !ok {
+		return 0, false
+	}
+	return 1, true
+}
+
+// MemoryUsage estimates the number of bytes used by a key and its value. Containers are
+// estimated from their first samples elements, or from all of them if samples is 0.
+func MemoryUsage(key string, samples int) (int64, bool) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+	value, ok := store.Inspect(key)
+	if !ok {
+		return 0, false
+	}
+	return keyOverhead + stringHeaderSize + int64(len(key)) + valueSize(value.Val, samples), true
+}
+
+// DatasetBytes estimates the memory used by all the keys and values of every database.
+func DatasetBytes() int64 {
+	var total int64
+	for _, key := range *store.GetKeys[string]() {
+		if size, ok := MemoryUsage(key, DefaultMemorySamples); ok {
+			total += size
+		}
+	}
+	return total
+}
+
+func valueSize(value any, samples int) int64 {
+	switch v := value.(type) {
+	case int64:
+		return 8
+	case string:
+		return stringHeaderSize + int64(len(v))
+	case *lists.List:
+		if bytes, packed := v.PackedSize(); packed {
+			return listpackHeaderSize + int64(bytes)
+		}
+		size := int64(dequeHeaderSize) + int64(v.Cap())*stringHeaderSize
+		return size + sampled(v.Len(), samples, func(i int) int64 {
+			element, _ := v.Get(i)
+			return int64(len(element))
+		})
+	case *hashes.Hash:
+		size := int64(listpackHeaderSize)
+		if bytes, packed := v.PackedSize(); packed {
+			size += int64(bytes)
+		} else {
+			size += int64(mapHeaderSize) + sampledEach(v.Len(), samples, func(yield func(int64) bool) {
+				v.Each(func(field, val string) bool {
+					return yield(hashEntrySize + int64(len(field)+len(val)))
+				})
+			})
+		}
+		if expires := v.Expires(); expires != nil {
+			size += int64(mapHeaderSize) + int64(len(expires))*expiryEntrySize
+		}
+		return size
+	case *sets.Set:
+		if v.Intset() {
+			return sliceHeaderSize + int64(v.Len())*8
+		}
+		return int64(mapHeaderSize) + sampledEach(v.Len(), samples, func(yield func(int64) bool) {
+			v.Each(func(member string) bool {
+				return yield(setEntrySize + int64(len(member)))
+			})
+		})
+	case *arrays.Array:
+		size := int64(arrayHeaderSize) + int64(v.Pages())*arrayPageSize
+		if v.Count() == 0 {
+			return size
+		}
+		if samples <= 0 || int64(samples) > v.Count() {
+			samples = int(v.Count())
+		}
+		var total int64
+		seen := 0
+		v.Each(0, func(_ int64, element any) bool {
+			total += valueSize(element, samples)
+			seen++
+			return seen < samples
+		})
+		return size + total*v.Count()/int64(samples)
+	case *streams.Stream:
+		size := int64(streamHeaderSize) + int64(v.Len())*streamEntrySize
+		size += sampledEach(v.Len(), samples, func(yield func(int64) bool) {
+			v.Each(func(e streams.Entry) bool {
+				var n int64
+				for _, field := range e.Fields {
+					n += stringHeaderSize + int64(len(field))
+				}
+				return yield(n)
+			})
+		})
+		for _, name := range v.GroupNames() {
+			g, _ := v.Group(name)
+			size += groupHeaderSize + int64(len(name)) + int64(g.PendingLen())*pendingEntrySize + int64(len(g.Consumers))*consumerSize
+		}
+		return size
+	case *zsets.ZSet:
+		return int64(zsetHeaderSize) + sampledEach(v.Len(), samples, func(yield func(int64) bool) {
+			v.Each(func(member string, _ float64) bool {
+				return yield(zsetEntrySize + int64(len(member)))
+			})
+		})
+	case *json.Document:
+		return v.Size()
+	default:
+		return interfaceSize
+	}
+}
+
+// sampled sums the size of n elements, extrapolating from the first samples ones.
+func sampled(n, samples int, size func(i int) int64) int64 {
+	if samples <= 0 || samples > n {
+		samples = n
+	}
+	if samples == 0 {
+		return 0
+	}
+	var total int64
+	for i := 0; i < samples; i++ {
+		total += size(i)
+	}
+	return total * int64(n) / int64(samples)
+}
+
+// sampledEach sums the size of the n elements walked by each, extrapolating from the first
+// samples ones. each calls yield with the size of every element until it returns false.
+func sampledEach(n, samples int, each func(yield func(int64) bool)) int64 {
+	if samples <= 0 || samples > n {
+		samples = n
+	}
+	if samples == 0 {
+		return 0
+	}
+	var total int64
+	seen := 0
+	each(func(size int64) bool {
+		total += size
+		seen++
+		return seen < samples
+	})
+	return total * int64(n) / int64(samples)
+}
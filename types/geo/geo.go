@@ -0,0 +1,265 @@
+// Package geo implements geospatial indexes, sorted sets of members scored by the geohash of
+// their position.
+package geo
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/zsets"
+)
+
+// Sort orders of search results.
+const (
+	SORT_NONE = ""
+	SORT_ASC  = "ASC"
+	SORT_DESC = "DESC"
+)
+
+// units are the distance units and their length in meters.
+var units = map[string]float64{"m": 1, "km": 1000, "ft": 0.3048, "mi": 1609.34}
+
+// UnitConversion returns the length in meters of a distance unit: m, km, ft or mi.
+func UnitConversion(unit string) (float64, bool) {
+	conversion, ok := units[strings.ToLower(unit)]
+	return conversion, ok
+}
+
+// Location is a member and its position.
+type Location struct {
+	Longitude float64
+	Latitude  float64
+	Member    string
+}
+
+// Point is a position.
+type Point struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// Search selects the members within Radius of a position, or within a box Width wide and Height
+// high centered on it with ByBox. The position is the one of Member with FromMember, distances
+// are in a unit Conversion meters long. Count limits the number of results, 0 meaning no limit:
+// the closest ones are returned unless Any is set, then the search stops at the first Count found.
+type Search struct {
+	Member     string
+	FromMember bool
+	Longitude  float64
+	Latitude   float64
+	ByBox      bool
+	Radius     float64
+	Width      float64
+	Height     float64
+	Conversion float64
+	Sort       string
+	Count      int64
+	Any        bool
+}
+
+// Match is a member found by a search, its distance to the center of the search in the unit
+// of the search, its geohash score and its position.
+type Match struct {
+	Member    string
+	Distance  float64
+	Score     float64
+	Longitude float64
+	Latitude  float64
+}
+
+func get(key string) (*zsets.ZSet, bool) {
+	return store.Get[string, *zsets.ZSet](key)
+}
+
+// GeoAdd adds members at their positions to the index stored at key, creating it if needed,
+// and moves the members already in it. With nx members are only added, with xx only moved.
+// It returns the number of members added, or added and moved with ch.
+// The positions must be valid.
+func GeoAdd(key string, locations []Location, nx, xx, ch bool) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	z, exists := get(key)
+	if !exists {
+		z = zsets.NewZSet()
+	}
+	var added, moved int64
+	for _, l := range locations {
+		score := Score(l.Longitude, l.Latitude)
+		previous, ok := z.Score(l.Member)
+		if (ok && nx) || (!ok && xx) {
+			continue
+		}
+		if z.Add(l.Member, score) {
+			added++
+		} else if previous != score {
+			moved++
+		}
+	}
+	if !exists && z.Len() > 0 {
+		store.Set(key, z)
+	}
+	if added+moved > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ZSET, "zadd", key)
+	}
+	if ch {
+		return added + moved
+	}
+	return added
+}
+
+// GeoPos returns the positions of members in the index stored at key, nil for missing members.
+func GeoPos(key string, members []string) []*Point {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	points := make([]*Point, len(members))
+	z, ok := get(key)
+	if !ok {
+		return points
+	}
+	for i, member := range members {
+		if score, ok := z.Score(member); ok {
+			longitude, latitude := Position(score)
+			points[i] = &Point{Longitude: longitude, Latitude: latitude}
+		}
+	}
+	return points
+}
+
+// GeoDist returns the distance in meters between two members of the index stored at key,
+// false if one of them is missing.
+func GeoDist(key, member1, member2 string) (float64, bool) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	z, ok := get(key)
+	if !ok {
+		return 0, false
+	}
+	score1, ok1 := z.Score(member1)
+	score2, ok2 := z.Score(member2)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	lon1, lat1 := Position(score1)
+	lon2, lat2 := Position(score2)
+	return Distance(lon1, lat1, lon2, lat2), true
+}
+
+// GeoHash returns the geohash strings of members in the index stored at key, nil for missing members.
+func GeoHash(key string, members []string) []*string {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	hashes := make([]*string, len(members))
+	z, ok := get(key)
+	if !ok {
+		return hashes
+	}
+	for i, member := range members {
+		if score, ok := z.Score(member); ok {
+			hash := Hash(score)
+			hashes[i] = &hash
+		}
+	}
+	return hashes
+}
+
+// run searches the index z. Only the members in the geohash cells covering the searched shape
+// are looked at, rather than the whole index.
+func (s *Search) run(z *zsets.ZSet) ([]Match, error) {
+	if s.FromMember {
+		score, ok := z.Score(s.Member)
+		if !ok {
+			return nil, errors.New(common.ERR_GEO_MEMBER)
+		}
+		s.Longitude, s.Latitude = Position(score)
+	}
+	limited := s.Any && s.Count > 0
+	matches := []Match{}
+	for _, cell := range s.areas() {
+		low, high := scoreRange(cell)
+		z.Range(low, high, func(member string, score float64) bool {
+			longitude, latitude := Position(score)
+			if distance, ok := s.contains(longitude, latitude); ok {
+				matches = append(matches, Match{
+					Member:    member,
+					Distance:  distance / s.Conversion,
+					Score:     score,
+					Longitude: longitude,
+					Latitude:  latitude,
+				})
+			}
+			return !limited || int64(len(matches)) < s.Count
+		})
+		if limited && int64(len(matches)) >= s.Count {
+			break
+		}
+	}
+	order := s.Sort
+	// The closest members are returned when their number is limited.
+	if order == SORT_NONE && s.Count > 0 && !s.Any {
+		order = SORT_ASC
+	}
+	switch order {
+	case SORT_ASC:
+		slices.SortStableFunc(matches, func(a, b Match) int { return cmp.Compare(a.Distance, b.Distance) })
+	case SORT_DESC:
+		slices.SortStableFunc(matches, func(a, b Match) int { return cmp.Compare(b.Distance, a.Distance) })
+	}
+	if s.Count > 0 && int64(len(matches)) > s.Count {
+		matches = matches[:s.Count]
+	}
+	return matches, nil
+}
+
+// GeoSearch returns the members of the index stored at key selected by s.
+func GeoSearch(key string, s Search) ([]Match, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	z, ok := get(key)
+	if !ok {
+		return []Match{}, nil
+	}
+	return s.run(z)
+}
+
+// GeoSearchStore stores at dest the members of the index stored at key selected by s, scored
+// by their distance to the center of the search with storeDist, and returns their number.
+// dest is deleted when no member is selected.
+func GeoSearchStore(dest, key string, s Search, storeDist bool) (int64, error) {
+	store.LockKeys(dest, key)
+	defer store.UnlockKeys(dest, key)
+
+	matches := []Match{}
+	if z, ok := get(key); ok {
+		var err error
+		if matches, err = s.run(z); err != nil {
+			return 0, err
+		}
+	}
+	if len(matches) == 0 {
+		if store.Delete(dest) {
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", dest)
+		}
+		return 0, nil
+	}
+	result := zsets.NewZSet()
+	for _, m := range matches {
+		if storeDist {
+			result.Add(m.Member, m.Distance)
+		} else {
+			result.Add(m.Member, m.Score)
+		}
+	}
+	store.Set(dest, result)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_ZSET, "geosearchstore", dest)
+	return int64(len(matches)), nil
+}
@@ -0,0 +1,155 @@
+package geo_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/geo"
+	"github.com/divy-sh/animus/types/zsets"
+)
+
+// sicily adds the positions of the examples of the Redis documentation.
+func sicily(key string) {
+	geo.GeoAdd(key, []geo.Location{
+		{Longitude: 13.361389, Latitude: 38.115556, Member: "Palermo"},
+		{Longitude: 15.087269, Latitude: 37.502669, Member: "Catania"},
+		{Longitude: 12.758489, Latitude: 38.788135, Member: "edge1"},
+		{Longitude: 17.241510, Latitude: 38.788135, Member: "edge2"},
+	}, false, false, false)
+}
+
+func TestGeoAdd(t *testing.T) {
+	key := "TestGeoAdd"
+	if added := geo.GeoAdd(key, []geo.Location{{Longitude: 1, Latitude: 1, Member: "a"}}, false, true, false); added != 0 {
+		t.Errorf("expected XX not to add members, got %d", added)
+	}
+	if added := geo.GeoAdd(key, []geo.Location{{Longitude: 1, Latitude: 1, Member: "a"}, {Longitude: 2, Latitude: 2, Member: "b"}}, false, false, false); added != 2 {
+		t.Errorf("expected 2 members added, got %d", added)
+	}
+	if changed := geo.GeoAdd(key, []geo.Location{{Longitude: 3, Latitude: 3, Member: "a"}, {Longitude: 2, Latitude: 2, Member: "b"}}, false, false, true); changed != 1 {
+		t.Errorf("expected 1 member moved, got %d", changed)
+	}
+	if changed := geo.GeoAdd(key, []geo.Location{{Longitude: 4, Latitude: 4, Member: "a"}}, true, false, true); changed != 0 {
+		t.Errorf("expected NX not to move members, got %d", changed)
+	}
+	if pos := geo.GeoPos(key, []string{"a", "missing"}); pos[0] == nil || math.Abs(pos[0].Longitude-3) > 1e-5 || pos[1] != nil {
+		t.Errorf("expected a at 3,3 and no position for missing, got %v %v", pos[0], pos[1])
+	}
+}
+
+func TestGeoPosDistAndHash(t *testing.T) {
+	key := "TestGeoPosDistAndHash"
+	sicily(key)
+	pos := geo.GeoPos(key, []string{"Palermo", "Catania"})
+	if geo.FormatCoordinate(pos[0].Longitude) != "13.36138933897018433" || geo.FormatCoordinate(pos[0].Latitude) != "38.11555639549629859" {
+		t.Errorf("unexpected position of Palermo %v", pos[0])
+	}
+	if geo.FormatCoordinate(pos[1].Longitude) != "15.08726745843887329" || geo.FormatCoordinate(pos[1].Latitude) != "37.50266842333162032" {
+		t.Errorf("unexpected position of Catania %v", pos[1])
+	}
+	if dist, ok := geo.GeoDist(key, "Palermo", "Catania"); !ok || fmt.Sprintf("%.4f", dist) != "166274.1516" {
+		t.Errorf("expected 166274.1516, got %.4f", dist)
+	}
+	if _, ok := geo.GeoDist(key, "Palermo", "missing"); ok {
+		t.Errorf("expected no distance to a missing member")
+	}
+	hashes := geo.GeoHash(key, []string{"Palermo", "Catania", "missing"})
+	if *hashes[0] != "sqc8b49rny0" || *hashes[1] != "sqdtr74hyu0" || hashes[2] != nil {
+		t.Errorf("unexpected geohashes %v %v %v", *hashes[0], *hashes[1], hashes[2])
+	}
+}
+
+func TestGeoSearch(t *testing.T) {
+	key := "TestGeoSearch"
+	sicily(key)
+	matches, err := geo.GeoSearch(key, geo.Search{Longitude: 15, Latitude: 37, Radius: 200, Conversion: 1000, Sort: geo.SORT_ASC})
+	if err != nil || len(matches) != 2 || matches[0].Member != "Catania" || matches[1].Member != "Palermo" {
+		t.Fatalf("expected Catania and Palermo, got %v %v", matches, err)
+	}
+	if fmt.Sprintf("%.4f", matches[0].Distance) != "56.4413" || matches[0].Score != 3479447370796909 {
+		t.Errorf("unexpected match %v", matches[0])
+	}
+
+	matches, _ = geo.GeoSearch(key, geo.Search{Longitude: 15, Latitude: 37, ByBox: true, Width: 400, Height: 400, Conversion: 1000, Sort: geo.SORT_DESC})
+	members := []string{}
+	for _, m := range matches {
+		members = append(members, m.Member)
+	}
+	if !slices.Equal(members, []string{"edge1", "edge2", "Palermo", "Catania"}) {
+		t.Errorf("unexpected box search results %v", members)
+	}
+
+	matches, _ = geo.GeoSearch(key, geo.Search{Member: "Palermo", FromMember: true, Radius: 200, Conversion: 1000, Count: 1})
+	if len(matches) != 1 || matches[0].Member != "Palermo" || matches[0].Distance != 0 {
+		t.Errorf("expected the closest member with COUNT 1, got %v", matches)
+	}
+	if matches, _ := geo.GeoSearch(key, geo.Search{Longitude: 15, Latitude: 37, Radius: 500, Conversion: 1000, Count: 2, Any: true}); len(matches) != 2 {
+		t.Errorf("expected 2 members with COUNT 2 ANY, got %v", matches)
+	}
+	if _, err := geo.GeoSearch(key, geo.Search{Member: "missing", FromMember: true, Radius: 1, Conversion: 1}); err == nil || err.Error() != common.ERR_GEO_MEMBER {
+		t.Errorf("expected %s, got %v", common.ERR_GEO_MEMBER, err)
+	}
+	if matches, err := geo.GeoSearch("TestGeoSearchMissing", geo.Search{Member: "missing", FromMember: true, Radius: 1, Conversion: 1}); err != nil || len(matches) != 0 {
+		t.Errorf("expected no match on a missing key, got %v %v", matches, err)
+	}
+}
+
+// TestGeoSearch_MatchesScan checks searches over the cells of the index against the distance to every member.
+func TestGeoSearch_MatchesScan(t *testing.T) {
+	key := "TestGeoSearch_MatchesScan"
+	r := rand.New(rand.NewSource(1))
+	locations := []geo.Location{}
+	for i := range 2000 {
+		locations = append(locations, geo.Location{
+			Longitude: -10 + r.Float64()*20,
+			Latitude:  40 + r.Float64()*20,
+			Member:    strconv.Itoa(i),
+		})
+	}
+	geo.GeoAdd(key, locations, false, false, false)
+	for _, radius := range []float64{10, 100, 500} {
+		s := geo.Search{Longitude: 0.5, Latitude: 50.5, Radius: radius, Conversion: 1000}
+		matches, _ := geo.GeoSearch(key, s)
+		expected := 0
+		for _, pos := range geo.GeoPos(key, func() []string {
+			members := []string{}
+			for _, l := range locations {
+				members = append(members, l.Member)
+			}
+			return members
+		}()) {
+			if geo.Distance(0.5, 50.5, pos.Longitude, pos.Latitude) <= radius*1000 {
+				expected++
+			}
+		}
+		if len(matches) != expected {
+			t.Errorf("expected %d members within %vkm, got %d", expected, radius, len(matches))
+		}
+	}
+}
+
+func TestGeoSearchStore(t *testing.T) {
+	key := "TestGeoSearchStore"
+	sicily(key)
+	s := geo.Search{Longitude: 15, Latitude: 37, Radius: 200, Conversion: 1000}
+	if n, err := geo.GeoSearchStore("TestGeoSearchStoreDest", key, s, true); err != nil || n != 2 {
+		t.Fatalf("expected 2 members stored, got %d %v", n, err)
+	}
+	if z, _ := store.Get[string, *zsets.ZSet]("TestGeoSearchStoreDest"); z == nil {
+		t.Errorf("expected the members to be stored")
+	} else if score, _ := z.Score("Catania"); fmt.Sprintf("%.4f", score) != "56.4413" {
+		t.Errorf("expected the distance as score, got %v", score)
+	}
+	if n, _ := geo.GeoSearchStore("TestGeoSearchStoreDest", key, geo.Search{Longitude: 0, Latitude: 0, Radius: 1, Conversion: 1}, false); n != 0 {
+		t.Errorf("expected no member stored, got %d", n)
+	}
+	if pos := geo.GeoPos("TestGeoSearchStoreDest", []string{"Catania"}); pos[0] != nil {
+		t.Errorf("expected the destination to be deleted")
+	}
+}
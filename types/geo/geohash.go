@@ -0,0 +1,310 @@
+package geo
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Positions are indexed by their 52 bits geohash, the interleaved bits of their latitude and
+// longitude, used as their score in a sorted set like Redis does. Latitudes are limited to the
+// range of the Web Mercator projection.
+const (
+	MinLongitude = -180.0
+	MaxLongitude = 180.0
+	MinLatitude  = -85.05112878
+	MaxLatitude  = 85.05112878
+
+	hashStep          = 26
+	earthRadiusMeters = 6372797.560856
+	mercatorMax       = 20037726.37
+	base32            = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// hashBits is a geohash of step*2 bits, the latitude in the even bits and the longitude in the odd ones.
+type hashBits struct {
+	bits uint64
+	step uint
+}
+
+type coordRange struct {
+	min, max float64
+}
+
+// area is the rectangle of coordinates a geohash covers.
+type area struct {
+	longitude, latitude coordRange
+}
+
+var (
+	longitudeRange = coordRange{MinLongitude, MaxLongitude}
+	latitudeRange  = coordRange{MinLatitude, MaxLatitude}
+)
+
+// ValidCoordinates reports whether a position can be indexed.
+func ValidCoordinates(longitude, latitude float64) bool {
+	return longitude >= MinLongitude && longitude <= MaxLongitude && latitude >= MinLatitude && latitude <= MaxLatitude
+}
+
+// interleave spreads the bits of x over the even bits of the result and those of y over the odd ones.
+func interleave(x, y uint32) uint64 {
+	var result uint64
+	for i := range 32 {
+		result |= uint64(x>>i&1)<<(2*i) | uint64(y>>i&1)<<(2*i+1)
+	}
+	return result
+}
+
+// deinterleave is the reverse of interleave.
+func deinterleave(bits uint64) (uint32, uint32) {
+	var x, y uint32
+	for i := range 32 {
+		x |= uint32(bits>>(2*i)&1) << i
+		y |= uint32(bits>>(2*i+1)&1) << i
+	}
+	return x, y
+}
+
+func encode(longitudes, latitudes coordRange, longitude, latitude float64, step uint) hashBits {
+	latOffset := (latitude - latitudes.min) / (latitudes.max - latitudes.min) * float64(uint64(1)<<step)
+	lonOffset := (longitude - longitudes.min) / (longitudes.max - longitudes.min) * float64(uint64(1)<<step)
+	return hashBits{bits: interleave(uint32(latOffset), uint32(lonOffset)), step: step}
+}
+
+func decode(longitudes, latitudes coordRange, hash hashBits) area {
+	lat, lon := deinterleave(hash.bits)
+	cells := float64(uint64(1) << hash.step)
+	latScale, lonScale := latitudes.max-latitudes.min, longitudes.max-longitudes.min
+	return area{
+		latitude:  coordRange{latitudes.min + float64(lat)/cells*latScale, latitudes.min + float64(lat+1)/cells*latScale},
+		longitude: coordRange{longitudes.min + float64(lon)/cells*lonScale, longitudes.min + float64(lon+1)/cells*lonScale},
+	}
+}
+
+// Score returns the 52 bits geohash a position is indexed by.
+func Score(longitude, latitude float64) float64 {
+	return float64(encode(longitudeRange, latitudeRange, longitude, latitude, hashStep).bits)
+}
+
+// Position returns the center of the area the geohash score covers, within a meter
+// of the position it was computed from.
+func Position(score float64) (float64, float64) {
+	a := decode(longitudeRange, latitudeRange, hashBits{bits: uint64(score), step: hashStep})
+	longitude := min(max((a.longitude.min+a.longitude.max)/2, MinLongitude), MaxLongitude)
+	latitude := min(max((a.latitude.min+a.latitude.max)/2, MinLatitude), MaxLatitude)
+	return longitude, latitude
+}
+
+// Hash returns the standard 11 characters geohash of the position a score stands for. It is
+// computed over the full -90 to 90 range of latitudes, unlike the score, so it can be used
+// with other geohash services.
+func Hash(score float64) string {
+	longitude, latitude := Position(score)
+	bits := encode(longitudeRange, coordRange{-90, 90}, longitude, latitude, hashStep).bits
+	var b strings.Builder
+	for i := range 11 {
+		// The 52 bits only fill 10 characters and a bit, the last one is padded with zeros.
+		idx := 0
+		if i < 10 {
+			idx = int(bits>>(52-(i+1)*5)) & 0x1f
+		}
+		b.WriteByte(base32[idx])
+	}
+	return b.String()
+}
+
+// FormatCoordinate formats a coordinate with 17 decimals, dropping trailing zeros, like Redis.
+func FormatCoordinate(x float64) string {
+	s := strconv.FormatFloat(x, 'f', 17, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func degToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+func latitudeDistance(lat1, lat2 float64) float64 {
+	return earthRadiusMeters * math.Abs(degToRad(lat2)-degToRad(lat1))
+}
+
+// Distance returns the distance in meters between two positions with the haversine formula.
+func Distance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lon1r := degToRad(lat1), degToRad(lon1)
+	lat2r, lon2r := degToRad(lat2), degToRad(lon2)
+	v := math.Sin((lon2r - lon1r) / 2)
+	if v == 0 {
+		return latitudeDistance(lat1, lat2)
+	}
+	u := math.Sin((lat2r - lat1r) / 2)
+	a := u*u + math.Cos(lat1r)*math.Cos(lat2r)*v*v
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// moveX moves a geohash d cells east, or west if d is negative.
+func moveX(hash hashBits, d int) hashBits {
+	x := hash.bits & 0xaaaaaaaaaaaaaaaa
+	y := hash.bits & 0x5555555555555555
+	zz := uint64(0x5555555555555555) >> (64 - hash.step*2)
+	if d > 0 {
+		x += zz + 1
+	} else {
+		x |= zz
+		x -= zz + 1
+	}
+	x &= 0xaaaaaaaaaaaaaaaa >> (64 - hash.step*2)
+	return hashBits{bits: x | y, step: hash.step}
+}
+
+// moveY moves a geohash d cells north, or south if d is negative.
+func moveY(hash hashBits, d int) hashBits {
+	x := hash.bits & 0xaaaaaaaaaaaaaaaa
+	y := hash.bits & 0x5555555555555555
+	zz := uint64(0xaaaaaaaaaaaaaaaa) >> (64 - hash.step*2)
+	if d > 0 {
+		y += zz + 1
+	} else {
+		y |= zz
+		y -= zz + 1
+	}
+	y &= 0x5555555555555555 >> (64 - hash.step*2)
+	return hashBits{bits: x | y, step: hash.step}
+}
+
+// estimateStep returns the precision of the geohash cells to search around a position at the
+// latitude for a radius, so the cell and its neighbors cover the radius.
+func estimateStep(radius, latitude float64) uint {
+	if radius == 0 {
+		return hashStep
+	}
+	step := 1
+	for radius < mercatorMax {
+		radius *= 2
+		step++
+	}
+	// Make sure the range is included in most of the base cases.
+	step -= 2
+	// Cells get narrower towards the poles.
+	if latitude > 66 || latitude < -66 {
+		step--
+		if latitude > 80 || latitude < -80 {
+			step--
+		}
+	}
+	return uint(min(max(step, 1), hashStep))
+}
+
+// boundingBox returns the coordinates that contain the searched shape: the minimum and
+// maximum longitude and latitude.
+func (s *Search) boundingBox() (float64, float64, float64, float64) {
+	height, width := s.Radius, s.Radius
+	if s.ByBox {
+		height, width = s.Height/2, s.Width/2
+	}
+	height *= s.Conversion
+	width *= s.Conversion
+	latDelta := radToDeg(height / earthRadiusMeters)
+	lonDeltaTop := radToDeg(width / earthRadiusMeters / math.Cos(degToRad(s.Latitude+latDelta)))
+	lonDeltaBottom := radToDeg(width / earthRadiusMeters / math.Cos(degToRad(s.Latitude-latDelta)))
+	// Longitudes are closer together towards the pole, so the box is widest on that side.
+	lonDelta := lonDeltaTop
+	if s.Latitude < 0 {
+		lonDelta = lonDeltaBottom
+	}
+	return s.Longitude - lonDelta, s.Latitude - latDelta, s.Longitude + lonDelta, s.Latitude + latDelta
+}
+
+// areas returns the geohash cells that cover the searched shape: the cell of its center and
+// the neighbors of that cell that intersect its bounding box.
+func (s *Search) areas() []hashBits {
+	minLon, minLat, maxLon, maxLat := s.boundingBox()
+	radius := s.Radius
+	if s.ByBox {
+		radius = math.Sqrt(s.Width/2*s.Width/2 + s.Height/2*s.Height/2)
+	}
+	step := estimateStep(radius*s.Conversion, s.Latitude)
+
+	hash := encode(longitudeRange, latitudeRange, s.Longitude, s.Latitude, step)
+	// Near the edges of the cell its neighbors may not reach far enough, larger cells are
+	// needed then.
+	north := decode(longitudeRange, latitudeRange, moveY(hash, 1))
+	south := decode(longitudeRange, latitudeRange, moveY(hash, -1))
+	east := decode(longitudeRange, latitudeRange, moveX(hash, 1))
+	west := decode(longitudeRange, latitudeRange, moveX(hash, -1))
+	if step > 1 && (north.latitude.max < maxLat || south.latitude.min > minLat ||
+		east.longitude.max < maxLon || west.longitude.min > minLon) {
+		step--
+		hash = encode(longitudeRange, latitudeRange, s.Longitude, s.Latitude, step)
+	}
+	center := decode(longitudeRange, latitudeRange, hash)
+
+	// The neighbors are skipped on the sides where the cell already covers the bounding box.
+	northOK, southOK, eastOK, westOK := true, true, true, true
+	if step >= 2 {
+		southOK = center.latitude.min >= minLat
+		northOK = center.latitude.max <= maxLat
+		westOK = center.longitude.min >= minLon
+		eastOK = center.longitude.max <= maxLon
+	}
+	cells := []hashBits{hash}
+	for _, neighbor := range []struct {
+		dx, dy int
+		ok     bool
+	}{
+		{0, 1, northOK},
+		{0, -1, southOK},
+		{1, 0, eastOK},
+		{-1, 0, westOK},
+		{1, 1, northOK && eastOK},
+		{-1, 1, northOK && westOK},
+		{1, -1, southOK && eastOK},
+		{-1, -1, southOK && westOK},
+	} {
+		if !neighbor.ok {
+			continue
+		}
+		cell := hash
+		if neighbor.dx != 0 {
+			cell = moveX(cell, neighbor.dx)
+		}
+		if neighbor.dy != 0 {
+			cell = moveY(cell, neighbor.dy)
+		}
+		// With large cells neighbors can wrap around to the same cell.
+		duplicate := false
+		for _, c := range cells {
+			duplicate = duplicate || c == cell
+		}
+		if !duplicate {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// scoreRange returns the range of scores of the positions in a cell, min included and max excluded.
+func scoreRange(cell hashBits) (float64, float64) {
+	shift := 2 * (hashStep - cell.step)
+	return float64(cell.bits << shift), float64((cell.bits + 1) << shift)
+}
+
+// contains returns the distance from the center of the searched shape to a position, false
+// if the position is outside the shape.
+func (s *Search) contains(longitude, latitude float64) (float64, bool) {
+	if !s.ByBox {
+		distance := Distance(s.Longitude, s.Latitude, longitude, latitude)
+		return distance, distance <= s.Radius*s.Conversion
+	}
+	// The latitude distance is cheaper to compute, so it is checked first.
+	if latitudeDistance(latitude, s.Latitude) > s.Height*s.Conversion/2 {
+		return 0, false
+	}
+	if Distance(longitude, latitude, s.Longitude, latitude) > s.Width*s.Conversion/2 {
+		return 0, false
+	}
+	return Distance(s.Longitude, s.Latitude, longitude, latitude), true
+}
@@ -0,0 +1,132 @@
+package hashes
+
+import (
+	"time"
+
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+// Replies of the field expiry commands for every field.
+const (
+	FieldMissing    int64 = -2
+	FieldNoExpiry   int64 = -1
+	FieldNotUpdated int64 = 0
+	FieldUpdated    int64 = 1
+	FieldDeleted    int64 = 2
+)
+
+// HExpire sets the unix time in milliseconds the given fields of a hash expire at, if the
+// condition holds: NX if the field has no expiry, XX if it has one, GT if the new expiry is
+// later and LT if it is earlier, a field without expiry counting as expiring never.
+// An empty condition always holds. A time in the past deletes the fields right away.
+// It returns FieldMissing, FieldNotUpdated, FieldUpdated or FieldDeleted for every field.
+func HExpire(hash string, at int64, condition string, fields []string) []int64 {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	result := make([]int64, len(fields))
+	hashVal, ok := get(hash)
+	if !ok {
+		for i := range result {
+			result[i] = FieldMissing
+		}
+		return result
+	}
+	now := time.Now().UnixMilli()
+	updated, deleted := false, false
+	for i, field := range fields {
+		if _, exists := hashVal.Get(field); !exists {
+			result[i] = FieldMissing
+			continue
+		}
+		current, hasExpiry := hashVal.ExpireAt(field)
+		if !expiryConditionHolds(condition, current, hasExpiry, at) {
+			result[i] = FieldNotUpdated
+			continue
+		}
+		if at <= now {
+			hashVal.Delete(field)
+			result[i] = FieldDeleted
+			deleted = true
+			continue
+		}
+		hashVal.SetExpireAt(field, at)
+		result[i] = FieldUpdated
+		updated = true
+	}
+	if updated {
+		store.TrackFieldExpiry(hash)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hexpire", hash)
+	}
+	if deleted {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hdel", hash)
+		if hashVal.Len() == 0 {
+			store.Delete(hash)
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", hash)
+		}
+	}
+	return result
+}
+
+func expiryConditionHolds(condition string, current int64, hasExpiry bool, at int64) bool {
+	switch condition {
+	case "NX":
+		return !hasExpiry
+	case "XX":
+		return hasExpiry
+	case "GT":
+		return hasExpiry && at > current
+	case "LT":
+		return !hasExpiry || at < current
+	default:
+		return true
+	}
+}
+
+// HExpireTimes returns the unix time in milliseconds the given fields of a hash expire at,
+// FieldNoExpiry for the fields without expiry and FieldMissing for the missing ones.
+func HExpireTimes(hash string, fields []string) []int64 {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	result := make([]int64, len(fields))
+	hashVal, ok := get(hash)
+	for i, field := range fields {
+		if !ok {
+			result[i] = FieldMissing
+		} else if _, exists := hashVal.Get(field); !exists {
+			result[i] = FieldMissing
+		} else if at, hasExpiry := hashVal.ExpireAt(field); hasExpiry {
+			result[i] = at
+		} else {
+			result[i] = FieldNoExpiry
+		}
+	}
+	return result
+}
+
+// HPersist removes the expiry of the given fields of a hash. It returns FieldUpdated for
+// the fields whose expiry was removed, FieldNoExpiry for the fields without expiry and
+// FieldMissing for the missing ones.
+func HPersist(hash string, fields []string) []int64 {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	result := make([]int64, len(fields))
+	hashVal, ok := get(hash)
+	persisted := false
+	for i, field := range fields {
+		if !ok {
+			result[i] = FieldMissing
+		} else if _, exists := hashVal.Get(field); !exists {
+			result[i] = FieldMissing
+		} else if hashVal.Persist(field) {
+			result[i] = FieldUpdated
+			persisted = true
+		} else {
+			result[i] = FieldNoExpiry
+		}
+	}
+	if persisted {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hpersist", hash)
+	}
+	return result
+}
@@ -0,0 +1,249 @@
+package hashes
+
+import (
+	"maps"
+	"sync/atomic"
+
+	"github.com/divy-sh/animus/types/listpack"
+)
+
+// Limits past which a hash is converted from a listpack to a hashtable: the number of fields,
+// and the length of a field or a value.
+var maxListpackEntries, maxListpackValue atomic.Int64
+
+func init() {
+	maxListpackEntries.Store(128)
+	maxListpackValue.Store(64)
+}
+
+// SetMaxListpackEntries sets the number of fields past which a hash is converted to a hashtable.
+func SetMaxListpackEntries(n int64) {
+	maxListpackEntries.Store(n)
+}
+
+// SetMaxListpackValue sets the length of a field or value past which a hash is converted to a hashtable.
+func SetMaxListpackValue(n int64) {
+	maxListpackValue.Store(n)
+}
+
+// Hash is the value stored for a hash. Small hashes pack their fields and values in a listpack,
+// and are converted to a map once they have too many fields or a field or value is too long.
+// Fields can expire on their own, their expiry times are kept in a separate map since most
+// hashes have none.
+type Hash struct {
+	// packed holds each field followed by its value while fields is nil.
+	packed listpack.Listpack
+	fields map[string]string
+	// expires holds the unix time in milliseconds the fields with an expiry expire at.
+	expires map[string]int64
+	// nextExpiry is no later than the earliest time in expires, 0 if there is none.
+	nextExpiry int64
+}
+
+func NewHash(capHint int) *Hash {
+	if int64(capHint) > maxListpackEntries.Load() {
+		return &Hash{fields: make(map[string]string, capHint)}
+	}
+	return &Hash{}
+}
+
+func (h *Hash) Len() int {
+	if h.fields == nil {
+		return h.packed.Len() / 2
+	}
+	return len(h.fields)
+}
+
+// find returns the position in the listpack of a field, -1 if it doesn't exist.
+func (h *Hash) find(field string) int {
+	return h.packed.Find(field, 0, 2)
+}
+
+func (h *Hash) Get(field string) (string, bool) {
+	if h.fields == nil {
+		if i := h.find(field); i >= 0 {
+			return h.packed.Get(i + 1)
+		}
+		return "", false
+	}
+	value, ok := h.fields[field]
+	return value, ok
+}
+
+// Set sets a field and removes its expiry. It returns true if the field is new.
+func (h *Hash) Set(field, value string) bool {
+	h.Persist(field)
+	return h.update(field, value)
+}
+
+// update sets a field and keeps its expiry. It returns true if the field is new.
+func (h *Hash) update(field, value string) bool {
+	if h.fields == nil {
+		limit := maxListpackValue.Load()
+		if int64(len(field)) <= limit && int64(len(value)) <= limit {
+			if i := h.find(field); i >= 0 {
+				h.packed.Replace(i+1, value)
+				return false
+			}
+			if int64(h.Len()) < maxListpackEntries.Load() {
+				h.packed.Insert(h.packed.Len(), field, value)
+				return true
+			}
+		}
+		h.convert()
+	}
+	_, exists := h.fields[field]
+	h.fields[field] = value
+	return !exists
+}
+
+// convert moves the fields of the listpack to a map.
+func (h *Hash) convert() {
+	h.fields = make(map[string]string, h.Len()+1)
+	var field string
+	h.packed.Each(func(i int, entry string) bool {
+		if i%2 == 0 {
+			field = entry
+		} else {
+			h.fields[field] = entry
+		}
+		return true
+	})
+	h.packed.Clear()
+}
+
+// Delete removes a field, it returns false if the field doesn't exist.
+func (h *Hash) Delete(field string) bool {
+	if !h.remove(field) {
+		return false
+	}
+	h.Persist(field)
+	return true
+}
+
+// remove removes a field and leaves its expiry, it returns false if the field doesn't exist.
+func (h *Hash) remove(field string) bool {
+	if h.fields == nil {
+		i := h.find(field)
+		if i < 0 {
+			return false
+		}
+		h.packed.Remove(i, 2)
+		return true
+	}
+	if _, ok := h.fields[field]; !ok {
+		return false
+	}
+	delete(h.fields, field)
+	return true
+}
+
+// Each calls fn with the fields of the hash and their values until fn returns false.
+func (h *Hash) Each(fn func(field, value string) bool) {
+	if h.fields != nil {
+		for field, value := range h.fields {
+			if !fn(field, value) {
+				return
+			}
+		}
+		return
+	}
+	var field string
+	h.packed.Each(func(i int, entry string) bool {
+		if i%2 == 0 {
+			field = entry
+			return true
+		}
+		return fn(field, entry)
+	})
+}
+
+// PackedSize returns the number of bytes of the listpack holding the fields, false if the
+// hash is a hashtable.
+func (h *Hash) PackedSize() (int, bool) {
+	return h.packed.Bytes(), h.fields == nil
+}
+
+// Expires returns the expiry times of the fields that have one. The map must not be modified.
+func (h *Hash) Expires() map[string]int64 {
+	return h.expires
+}
+
+// ExpireAt returns the unix time in milliseconds a field expires at, false if it has no expiry.
+func (h *Hash) ExpireAt(field string) (int64, bool) {
+	at, ok := h.expires[field]
+	return at, ok
+}
+
+// SetExpireAt sets the unix time in milliseconds an existing field expires at.
+func (h *Hash) SetExpireAt(field string, at int64) {
+	if h.expires == nil {
+		h.expires = map[string]int64{}
+	}
+	h.expires[field] = at
+	if h.nextExpiry == 0 || at < h.nextExpiry {
+		h.nextExpiry = at
+	}
+}
+
+// Persist removes the expiry of a field, it returns false if the field has none.
+func (h *Hash) Persist(field string) bool {
+	if _, ok := h.expires[field]; !ok {
+		return false
+	}
+	delete(h.expires, field)
+	if len(h.expires) == 0 {
+		h.expires, h.nextExpiry = nil, 0
+	}
+	return true
+}
+
+// HasFieldExpiry reports whether any field has an expiry, so the expiry cleaner looks at the hash.
+func (h *Hash) HasFieldExpiry() bool {
+	return len(h.expires) > 0
+}
+
+// RemoveExpired removes the fields expired at now, in unix milliseconds, and returns their names.
+func (h *Hash) RemoveExpired(now int64) []string {
+	if h.nextExpiry == 0 || now < h.nextExpiry {
+		return nil
+	}
+	var removed []string
+	var next int64
+	for field, at := range h.expires {
+		if at <= now {
+			h.remove(field)
+			delete(h.expires, field)
+			removed = append(removed, field)
+		} else if next == 0 || at < next {
+			next = at
+		}
+	}
+	h.nextExpiry = next
+	if len(h.expires) == 0 {
+		h.expires = nil
+	}
+	return removed
+}
+
+// Clone returns a copy of the hash, with the same field expiries.
+func (h *Hash) Clone() *Hash {
+	return &Hash{packed: h.packed.Clone(), fields: maps.Clone(h.fields), expires: maps.Clone(h.expires), nextExpiry: h.nextExpiry}
+}
+
+// Clear removes every field.
+func (h *Hash) Clear() {
+	h.packed.Clear()
+	clear(h.fields)
+	h.expires, h.nextExpiry = nil, 0
+}
+
+// keys returns the fields of the hash.
+func (h *Hash) keys() []string {
+	keys := make([]string, 0, h.Len())
+	h.Each(func(field, _ string) bool {
+		keys = append(keys, field)
+		return true
+	})
+	return keys
+}
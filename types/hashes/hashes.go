@@ -2,79 +2,316 @@ package hashes
 
 import (
 	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
 	"github.com/divy-sh/animus/store"
-	"github.com/divy-sh/animus/types/generics"
 )
 
+func init() {
+	// The cleaner inspects the hash rather than getting it, so it doesn't count as an access.
+	store.SetFieldExpiryHandler(func(key any) {
+		hash := key.(string)
+		store.LockKeys(hash)
+		defer store.UnlockKeys(hash)
+		value, ok := store.Inspect(hash)
+		if !ok {
+			store.UntrackFieldExpiry(hash)
+			return
+		}
+		if hashVal, ok := value.Val.(*Hash); !ok || !removeExpired(hash, hashVal) || !hashVal.HasFieldExpiry() {
+			store.UntrackFieldExpiry(hash)
+		}
+	})
+}
+
+// get returns the hash stored at key, after removing its expired fields.
+// The caller must hold the write lock of the key, since reading a hash can modify it.
+func get(hash string) (*Hash, bool) {
+	hashVal, ok := store.Get[string, *Hash](hash)
+	if !ok || !removeExpired(hash, hashVal) {
+		return nil, false
+	}
+	return hashVal, true
+}
+
+// removeExpired removes the expired fields of the hash stored at key, deleting the hash
+// if none are left. It returns false if the hash was deleted.
+func removeExpired(hash string, hashVal *Hash) bool {
+	if expired := hashVal.RemoveExpired(time.Now().UnixMilli()); len(expired) > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hexpired", hash)
+		if hashVal.Len() == 0 {
+			store.Delete(hash)
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", hash)
+			return false
+		}
+	}
+	return true
+}
+
+// getOrCreate returns the hash stored at key, or a new empty hash stored at key.
+func getOrCreate(hash string, capHint int) *Hash {
+	hashVal, ok := get(hash)
+	if !ok {
+		hashVal = NewHash(capHint)
+		store.Set(hash, hashVal)
+	}
+	return hashVal
+}
+
 func HGet(hash, key string) (string, error) {
-	store.RLockKeys(hash)
-	defer store.RUnlockKeys(hash)
-	value, ok := store.Get[string, map[string]string](hash)
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	hashVal, ok := get(hash)
 	if !ok {
 		return "", errors.New(common.ERR_HASH_NOT_FOUND)
 	}
-	if val, ok := value[key]; ok {
+	if val, ok := hashVal.Get(key); ok {
 		return val, nil
 	}
 	return "", errors.New(common.ERR_HASH_NOT_FOUND)
 }
 
 func HSet(hash, key, value string) {
+	HMSet(hash, &[]string{key, value})
+}
+
+// HMSet sets the fields of a hash from a list of field value pairs and returns the number of fields added.
+// Setting a field removes its expiry.
+func HMSet(hash string, pairs *[]string) int64 {
 	store.LockKeys(hash)
 	defer store.UnlockKeys(hash)
-	hashVal, ok := store.Get[string, map[string]string](hash)
-	if ok {
-		hashVal[key] = value
-	} else {
-		hashVal = map[string]string{key: value}
+	hashVal := getOrCreate(hash, len(*pairs)/2)
+	var added int64
+	for i := 0; i+1 < len(*pairs); i += 2 {
+		if hashVal.Set((*pairs)[i], (*pairs)[i+1]) {
+			added++
+		}
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hset", hash)
+	return added
+}
+
+// HSetNx sets a field only if it doesn't exist yet, it returns 1 if the field was set.
+func HSetNx(hash, key, value string) int64 {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	if hashVal, ok := get(hash); ok {
+		if _, exists := hashVal.Get(key); exists {
+			return 0
+		}
 	}
-	store.Set(hash, hashVal)
+	getOrCreate(hash, 1).Set(key, value)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hset", hash)
+	return 1
 }
 
+// HMGet returns the values of the given fields, nil for the fields that don't exist.
+func HMGet(hash string, keys *[]string) ([]*string, error) {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	hashVal, ok := get(hash)
+	if !ok {
+		return nil, errors.New(common.ERR_HASH_NOT_FOUND)
+	}
+	values := make([]*string, len(*keys))
+	for i, key := range *keys {
+		if val, ok := hashVal.Get(key); ok {
+			values[i] = &val
+		}
+	}
+	return values, nil
+}
+
+// HExists returns 1 if the field exists in the hash and 0 if it doesn't.
 func HExists(hash, key string) (int64, error) {
 	store.LockKeys(hash)
 	defer store.UnlockKeys(hash)
-	_, ok := store.Get[string, map[string]string](hash)
-	if ok {
-		return 1, nil
-	} else {
+	hashVal, ok := get(hash)
+	if !ok {
 		return 0, errors.New(common.ERR_HASH_NOT_FOUND)
 	}
+	if _, ok := hashVal.Get(key); ok {
+		return 1, nil
+	}
+	return 0, nil
 }
 
-func HExpire(key, seconds, flag string) error {
-	return generics.Expire(key, seconds, flag)
+// HIncrBy increments the integer stored in a field and returns the new value.
+// A missing field counts as 0. The field keeps its expiry.
+func HIncrBy(hash, key, increment string) (int64, error) {
+	incrVal, err := strconv.ParseInt(increment, 10, 64)
+	if err != nil {
+		return 0, errors.New(common.ERR_INVALID_INTEGER)
+	}
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	var current int64
+	if hashVal, ok := get(hash); ok {
+		if val, exists := hashVal.Get(key); exists {
+			if current, err = strconv.ParseInt(val, 10, 64); err != nil {
+				return 0, errors.New(common.ERR_HASH_VALUE_NOT_INTEGER)
+			}
+		}
+	}
+	if (incrVal > 0 && current > math.MaxInt64-incrVal) || (incrVal < 0 && current < math.MinInt64-incrVal) {
+		return 0, errors.New(common.ERR_INCREMENT_OVERFLOW)
+	}
+	current += incrVal
+	getOrCreate(hash, 1).update(key, strconv.FormatInt(current, 10))
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hincrby", hash)
+	return current, nil
+}
+
+// HIncrByFloat increments the number stored in a field and returns the new value.
+// A missing field counts as 0. The field keeps its expiry.
+func HIncrByFloat(hash, key, increment string) (string, error) {
+	incrVal, err := strconv.ParseFloat(increment, 64)
+	if err != nil || math.IsNaN(incrVal) || math.IsInf(incrVal, 0) {
+		return "", errors.New(common.ERR_INVALID_FLOAT)
+	}
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	var current float64
+	if hashVal, ok := get(hash); ok {
+		if val, exists := hashVal.Get(key); exists {
+			if current, err = strconv.ParseFloat(val, 64); err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+				return "", errors.New(common.ERR_HASH_VALUE_NOT_FLOAT)
+			}
+		}
+	}
+	current += incrVal
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return "", errors.New(common.ERR_INCREMENT_NAN_OR_INFINITY)
+	}
+	result := strconv.FormatFloat(current, 'f', -1, 64)
+	getOrCreate(hash, 1).update(key, result)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hincrbyfloat", hash)
+	return result, nil
 }
 
 func HDel(hash, key string) error {
 	store.LockKeys(hash)
 	defer store.UnlockKeys(hash)
-	hashVal, ok := store.Get[string, map[string]string](hash)
+	hashVal, ok := get(hash)
 	if !ok {
 		return errors.New(common.ERR_HASH_NOT_FOUND)
 	}
 
-	if _, ok := hashVal[key]; !ok {
+	if !hashVal.Delete(key) {
 		return errors.New(common.ERR_KEY_NOT_FOUND)
 	}
-	delete(hashVal, key)
-	if len(hashVal) == 0 {
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_HASH, "hdel", hash)
+	if hashVal.Len() == 0 {
 		store.Delete(hash)
-		return nil
-	} else {
-		store.Set(hash, hashVal)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", hash)
 	}
 	return nil
 }
 
 func HGetAll(key string) (map[string]string, error) {
-	store.RLockKeys(key)
-	defer store.RUnlockKeys(key)
-	hashVal, ok := store.Get[string, map[string]string](key)
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+	hashVal, ok := get(key)
+	if !ok {
+		return nil, errors.New(common.ERR_HASH_NOT_FOUND)
+	}
+	fields := make(map[string]string, hashVal.Len())
+	hashVal.Each(func(field, value string) bool {
+		fields[field] = value
+		return true
+	})
+	return fields, nil
+}
+
+// HKeys returns the fields of a hash.
+func HKeys(hash string) ([]string, error) {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	hashVal, ok := get(hash)
 	if !ok {
 		return nil, errors.New(common.ERR_HASH_NOT_FOUND)
 	}
-	return hashVal, nil
+	return hashVal.keys(), nil
+}
+
+// HVals returns the values of a hash.
+func HVals(hash string) ([]string, error) {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	hashVal, ok := get(hash)
+	if !ok {
+		return nil, errors.New(common.ERR_HASH_NOT_FOUND)
+	}
+	values := make([]string, 0, hashVal.Len())
+	hashVal.Each(func(_, value string) bool {
+		values = append(values, value)
+		return true
+	})
+	return values, nil
+}
+
+// HLen returns the number of fields in a hash.
+func HLen(hash string) (int64, error) {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	hashVal, ok := get(hash)
+	if !ok {
+		return 0, errors.New(common.ERR_HASH_NOT_FOUND)
+	}
+	return int64(hashVal.Len()), nil
+}
+
+// HStrlen returns the length of the value of a field, 0 if the field doesn't exist.
+func HStrlen(hash, key string) (int64, error) {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	hashVal, ok := get(hash)
+	if !ok {
+		return 0, errors.New(common.ERR_HASH_NOT_FOUND)
+	}
+	val, _ := hashVal.Get(key)
+	return int64(len(val)), nil
+}
+
+// MaxRandomCount is the largest number of fields HRANDFIELD returns for a negative count,
+// as they are all held in memory until the reply is written.
+const MaxRandomCount = 1 << 20
+
+// HRandField returns count random fields of a hash, followed each by its value if withValues is set.
+// A positive count returns distinct fields, at most as many as the hash has, while a
+// negative count returns -count fields that may repeat. The caller must keep -count within MaxRandomCount.
+func HRandField(hash string, count int64, withValues bool) ([]string, error) {
+	store.LockKeys(hash)
+	defer store.UnlockKeys(hash)
+	hashVal, ok := get(hash)
+	if !ok {
+		return nil, errors.New(common.ERR_HASH_NOT_FOUND)
+	}
+	keys := hashVal.keys()
+	var picked []string
+	if count >= 0 {
+		if count < int64(len(keys)) {
+			rand.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+			keys = keys[:count]
+		}
+		picked = keys
+	} else {
+		for ; count < 0; count++ {
+			picked = append(picked, keys[rand.Intn(len(keys))])
+		}
+	}
+	if !withValues {
+		return picked, nil
+	}
+	result := make([]string, 0, 2*len(picked))
+	for _, key := range picked {
+		val, _ := hashVal.Get(key)
+		result = append(result, key, val)
+	}
+	return result, nil
 }
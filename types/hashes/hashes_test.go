@@ -1,11 +1,13 @@
 package hashes_test
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/store"
 	"github.com/divy-sh/animus/types/hashes"
-	"github.com/divy-sh/animus/types/strings"
 )
 
 func TestHashEssentia_HSetAndHGet(t *testing.T) {
@@ -73,170 +75,134 @@ func Test_HashExists_Nope(t *testing.T) {
 	}
 }
 
-func Test_Hashes_ExpireNoFlagKeyWithNoExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireNoFlagKeyWithNoExpiry", "Test_Hashes_ExpireNoFlagKeyWithNoExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireNoFlagKeyWithNoExpiry", "0", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
-	}
-	val, err := strings.Get("Test_Hashes_ExpireNoFlagKeyWithNoExpiry")
-	if err == nil || err.Error() != common.ERR_STRING_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_STRING_NOT_FOUND, val)
-	}
-}
-
-func Test_Hashes_ExpireNoFlagKeyWithExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireNoFlagKeyWithExpiry", "Test_Hashes_ExpireNoFlagKeyWithExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireNoFlagKeyWithExpiry", "100", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
-	}
-	err = hashes.HExpire("Test_Hashes_ExpireNoFlagKeyWithExpiry", "0", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
-	}
-	val, err := strings.Get("Test_Hashes_ExpireNoFlagKeyWithExpiry")
-	if err == nil || err.Error() != common.ERR_STRING_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_STRING_NOT_FOUND, val)
-	}
-}
-
-func Test_Hashes_ExpireNoFlagInvalidKey(t *testing.T) {
-	err := hashes.HExpire("Test_Hashes_ExpireNoFlagInvalidKey", "10", "")
-	if err == nil || err.Error() != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, err)
-	}
+// in returns the unix time in milliseconds d from now.
+func in(d time.Duration) int64 {
+	return time.Now().Add(d).UnixMilli()
 }
 
-func Test_Hashes_ExpireNXKeyWithNoExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireNXKeyWithNoExpiry", "Test_Hashes_ExpireNXKeyWithNoExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireNXKeyWithNoExpiry", "10", "NX")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+func Test_Hashes_ExpireNoFlagFieldWithNoExpiry(t *testing.T) {
+	hashes.HMSet("Test_Hashes_ExpireNoFlagFieldWithNoExpiry", &[]string{"field", "value", "other", "value"})
+	result := hashes.HExpire("Test_Hashes_ExpireNoFlagFieldWithNoExpiry", in(-time.Second), "", []string{"field"})
+	if !slices.Equal(result, []int64{hashes.FieldDeleted}) {
+		t.Errorf("Expected the field to be deleted, got: %v", result)
 	}
-}
-
-func Test_Hashes_ExpireNXKeyWithExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireNXKeyWithExpiry", "Test_Hashes_ExpireNXKeyWithExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireNXKeyWithExpiry", "100", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+	if _, err := hashes.HGet("Test_Hashes_ExpireNoFlagFieldWithNoExpiry", "field"); err == nil {
+		t.Errorf("Expected the field to be gone")
 	}
-	err = hashes.HExpire("Test_Hashes_ExpireNXKeyWithExpiry", "10", "NX")
-	if err == nil || err.Error() != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, err)
+	if val, _ := hashes.HGet("Test_Hashes_ExpireNoFlagFieldWithNoExpiry", "other"); val != "value" {
+		t.Errorf("Expected the other field to be kept, got: %s", val)
 	}
 }
 
-func Test_Hashes_ExpireNXInvalidKey(t *testing.T) {
-	err := hashes.HExpire("Test_Hashes_ExpireNXInvalidKey", "10", "NX")
-	if err == nil || err.Error() != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, err)
+func Test_Hashes_ExpireNoFlagFieldWithExpiry(t *testing.T) {
+	hashes.HSet("Test_Hashes_ExpireNoFlagFieldWithExpiry", "field", "value")
+	hashes.HExpire("Test_Hashes_ExpireNoFlagFieldWithExpiry", in(100*time.Second), "", []string{"field"})
+	result := hashes.HExpire("Test_Hashes_ExpireNoFlagFieldWithExpiry", in(10*time.Second), "", []string{"field", "missing"})
+	if !slices.Equal(result, []int64{hashes.FieldUpdated, hashes.FieldMissing}) {
+		t.Errorf("Expected the expiry to be updated, got: %v", result)
 	}
 }
 
-func Test_Hashes_ExpireXXKeyWithNoExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireXXKeyWithNoExpiry", "Test_Hashes_ExpireXXKeyWithNoExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireXXKeyWithNoExpiry", "10", "XX")
-	if err == nil || err.Error() != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, err)
+func Test_Hashes_ExpireNoFlagInvalidKey(t *testing.T) {
+	result := hashes.HExpire("Test_Hashes_ExpireNoFlagInvalidKey", in(10*time.Second), "", []string{"a", "b"})
+	if !slices.Equal(result, []int64{hashes.FieldMissing, hashes.FieldMissing}) {
+		t.Errorf("Expected every field to be missing, got: %v", result)
 	}
 }
 
-func Test_Hashes_ExpireXXKeyWithExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireXXKeyWithExpiry", "Test_Hashes_ExpireXXKeyWithExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireXXKeyWithExpiry", "100", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
-	}
-	err = hashes.HExpire("Test_Hashes_ExpireXXKeyWithExpiry", "10", "XX")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+func Test_Hashes_ExpireConditions(t *testing.T) {
+	tests := []struct {
+		condition string
+		current   time.Duration // 0 for no expiry
+		new       time.Duration
+		expected  int64
+	}{
+		{"NX", 0, 10 * time.Second, hashes.FieldUpdated},
+		{"NX", 100 * time.Second, 10 * time.Second, hashes.FieldNotUpdated},
+		{"XX", 0, 10 * time.Second, hashes.FieldNotUpdated},
+		{"XX", 100 * time.Second, 10 * time.Second, hashes.FieldUpdated},
+		{"GT", 0, 10 * time.Second, hashes.FieldNotUpdated},
+		{"GT", 100 * time.Second, 10 * time.Second, hashes.FieldNotUpdated},
+		{"GT", 100 * time.Second, 200 * time.Second, hashes.FieldUpdated},
+		{"LT", 0, 10 * time.Second, hashes.FieldUpdated},
+		{"LT", 100 * time.Second, 10 * time.Second, hashes.FieldUpdated},
+		{"LT", 100 * time.Second, 200 * time.Second, hashes.FieldNotUpdated},
+	}
+	for _, test := range tests {
+		hashes.HSet("Test_Hashes_ExpireConditions", "field", "value")
+		if test.current > 0 {
+			hashes.HExpire("Test_Hashes_ExpireConditions", in(test.current), "", []string{"field"})
+		}
+		result := hashes.HExpire("Test_Hashes_ExpireConditions", in(test.new), test.condition, []string{"field"})
+		if result[0] != test.expected {
+			t.Errorf("%s from %v to %v: expected %d, got %d", test.condition, test.current, test.new, test.expected, result[0])
+		}
 	}
 }
 
-func Test_Hashes_ExpireXXInvalidKey(t *testing.T) {
-	err := hashes.HExpire("Test_Hashes_ExpireNXInvalidKey", "10", "XX")
-	if err == nil || err.Error() != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, err)
+func Test_Hashes_FieldExpiresLazily(t *testing.T) {
+	hashes.HMSet("Test_Hashes_FieldExpiresLazily", &[]string{"token", "secret", "name", "value"})
+	hashes.HExpire("Test_Hashes_FieldExpiresLazily", in(20*time.Millisecond), "", []string{"token"})
+	time.Sleep(30 * time.Millisecond)
+	if exists, _ := hashes.HExists("Test_Hashes_FieldExpiresLazily", "token"); exists != 0 {
+		t.Errorf("Expected the field to have expired")
 	}
-}
-
-func Test_Hashes_ExpireGTKeyWithNoExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireGTKeyWithNoExpiry", "Test_Hashes_ExpireGTKeyWithNoExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireGTKeyWithNoExpiry", "10", "GT")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+	if n, _ := hashes.HLen("Test_Hashes_FieldExpiresLazily"); n != 1 {
+		t.Errorf("Expected 1 field left, got: %d", n)
 	}
 }
 
-func Test_Hashes_ExpireGTKeyWithExpiryNewTimeSmaller(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireGTKeyWithExpiryNewTimeSmaller", "Test_Hashes_ExpireGTKeyWithExpiryNewTimeSmaller", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireGTKeyWithExpiryNewTimeSmaller", "100", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
-	}
-	err = hashes.HExpire("Test_Hashes_ExpireGTKeyWithExpiryNewTimeSmaller", "10", "GT")
-	if err == nil || err.Error() != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, err)
+func Test_Hashes_LastFieldExpiryDeletesHash(t *testing.T) {
+	hashes.HSet("Test_Hashes_LastFieldExpiryDeletesHash", "field", "value")
+	hashes.HExpire("Test_Hashes_LastFieldExpiryDeletesHash", in(20*time.Millisecond), "", []string{"field"})
+	time.Sleep(30 * time.Millisecond)
+	if _, err := hashes.HGetAll("Test_Hashes_LastFieldExpiryDeletesHash"); err == nil || err.Error() != common.ERR_HASH_NOT_FOUND {
+		t.Errorf("Expected error: %s, got: %v", common.ERR_HASH_NOT_FOUND, err)
 	}
 }
 
-func Test_Hashes_ExpireGTKeyWithExpiryNewTimeGreater(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireGTKeyWithExpiryNewTimeGreater", "Test_Hashes_ExpireGTKeyWithExpiryNewTimeGreater", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireGTKeyWithExpiryNewTimeGreater", "100", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
-	}
-	err = hashes.HExpire("Test_Hashes_ExpireGTKeyWithExpiryNewTimeGreater", "200", "GT")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+func Test_Hashes_CleanerDoesNotAccessHash(t *testing.T) {
+	key := "Test_Hashes_CleanerDoesNotAccessHash"
+	hashes.HMSet(key, &[]string{"a", "1", "b", "2"})
+	hashes.HExpire(key, in(20*time.Millisecond), "", []string{"a"})
+	hits := store.GetStats().KeyspaceHits
+	time.Sleep(250 * time.Millisecond)
+	value, ok := store.Inspect(key)
+	if !ok || value.Val.(*hashes.Hash).Len() != 1 {
+		t.Fatalf("Expected the cleaner to remove the expired field")
 	}
-}
-
-func Test_Hashes_ExpireGTInvalidKey(t *testing.T) {
-	err := hashes.HExpire("Test_Hashes_ExpireGTInvalidKey", "10", "GT")
-	if err == nil || err.Error() != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, err)
+	if store.GetStats().KeyspaceHits != hits {
+		t.Errorf("Expected the cleaner not to count as a keyspace hit")
 	}
 }
 
-func Test_Hashes_ExpireLTKeyWithNoExpiry(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireLTKeyWithNoExpiry", "Test_Hashes_ExpireLTKeyWithNoExpiry", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireLTKeyWithNoExpiry", "10", "LT")
-	if err == nil || err.Error() != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, err)
+func Test_Hashes_ExpireTimesAndPersist(t *testing.T) {
+	hashes.HMSet("Test_Hashes_ExpireTimesAndPersist", &[]string{"a", "1", "b", "2"})
+	at := in(time.Hour)
+	hashes.HExpire("Test_Hashes_ExpireTimesAndPersist", at, "", []string{"a"})
+	times := hashes.HExpireTimes("Test_Hashes_ExpireTimesAndPersist", []string{"a", "b", "c"})
+	if !slices.Equal(times, []int64{at, hashes.FieldNoExpiry, hashes.FieldMissing}) {
+		t.Errorf("Unexpected expiry times: %v", times)
 	}
-}
-
-func Test_Hashes_ExpireLTKeyWithExpiryNewTimeSmaller(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireLTKeyWithExpiryNewTimeSmaller", "Test_Hashes_ExpireLTKeyWithExpiryNewTimeSmaller", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireLTKeyWithExpiryNewTimeSmaller", "100", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+	result := hashes.HPersist("Test_Hashes_ExpireTimesAndPersist", []string{"a", "b", "c"})
+	if !slices.Equal(result, []int64{hashes.FieldUpdated, hashes.FieldNoExpiry, hashes.FieldMissing}) {
+		t.Errorf("Unexpected persist result: %v", result)
 	}
-	err = hashes.HExpire("Test_Hashes_ExpireLTKeyWithExpiryNewTimeSmaller", "10", "LT")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+	if times := hashes.HExpireTimes("Test_Hashes_ExpireTimesAndPersist", []string{"a"}); times[0] != hashes.FieldNoExpiry {
+		t.Errorf("Expected the expiry to be removed, got: %v", times)
 	}
 }
 
-func Test_Hashes_ExpireLTKeyWithExpiryNewTimeGreater(t *testing.T) {
-	hashes.HSet("Test_Hashes_ExpireLTKeyWithExpiryNewTimeGreater", "Test_Hashes_ExpireLTKeyWithExpiryNewTimeGreater", "value")
-	err := hashes.HExpire("Test_Hashes_ExpireLTKeyWithExpiryNewTimeGreater", "100", "")
-	if err != nil {
-		t.Errorf("Expected no error, got: %s", err.Error())
+func Test_Hashes_SetRemovesFieldExpiry(t *testing.T) {
+	hashes.HSet("Test_Hashes_SetRemovesFieldExpiry", "field", "1")
+	hashes.HExpire("Test_Hashes_SetRemovesFieldExpiry", in(time.Hour), "", []string{"field"})
+	hashes.HIncrBy("Test_Hashes_SetRemovesFieldExpiry", "field", "1")
+	if times := hashes.HExpireTimes("Test_Hashes_SetRemovesFieldExpiry", []string{"field"}); times[0] < 0 {
+		t.Errorf("Expected HINCRBY to keep the expiry, got: %v", times)
 	}
-	err = hashes.HExpire("Test_Hashes_ExpireLTKeyWithExpiryNewTimeGreater", "200", "LT")
-	if err == nil || err.Error() != common.ERR_EXPIRY_TYPE {
-		t.Errorf("Expected error: %s, got %v", common.ERR_EXPIRY_TYPE, err)
-	}
-}
-
-func Test_Hashes_ExpireLTInvalidKey(t *testing.T) {
-	err := hashes.HExpire("Test_Hashes_ExpireLTInvalidKey", "10", "LT")
-	if err == nil || err.Error() != common.ERR_SOURCE_KEY_NOT_FOUND {
-		t.Errorf("Expected error: %s, got: %v", common.ERR_SOURCE_KEY_NOT_FOUND, err)
+	hashes.HSet("Test_Hashes_SetRemovesFieldExpiry", "field", "value")
+	if times := hashes.HExpireTimes("Test_Hashes_SetRemovesFieldExpiry", []string{"field"}); times[0] != hashes.FieldNoExpiry {
+		t.Errorf("Expected HSET to remove the expiry, got: %v", times)
 	}
 }
 
@@ -330,3 +296,169 @@ func Test_HGetAll_EmptyHash(t *testing.T) {
 		t.Errorf("expected 0 fields, got %d", len(result))
 	}
 }
+
+func Test_HashExists_FieldMissing(t *testing.T) {
+	hashes.HSet("Test_HashExists_FieldMissing", "field", "value")
+	exists, err := hashes.HExists("Test_HashExists_FieldMissing", "other")
+	if exists != 0 || err != nil {
+		t.Errorf("Expected the field to not exist, got: %d, %v", exists, err)
+	}
+}
+
+func Test_HashMSetAndMGet(t *testing.T) {
+	added := hashes.HMSet("Test_HashMSetAndMGet", &[]string{"a", "1", "b", "2"})
+	if added != 2 {
+		t.Errorf("expected 2 new fields, got %d", added)
+	}
+	if added := hashes.HMSet("Test_HashMSetAndMGet", &[]string{"b", "3", "c", "4"}); added != 1 {
+		t.Errorf("expected 1 new field, got %d", added)
+	}
+	values, err := hashes.HMGet("Test_HashMSetAndMGet", &[]string{"a", "missing", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *values[0] != "1" || values[1] != nil || *values[2] != "3" {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func Test_HashSetNx(t *testing.T) {
+	if n := hashes.HSetNx("Test_HashSetNx", "field", "first"); n != 1 {
+		t.Errorf("expected the field to be set, got %d", n)
+	}
+	if n := hashes.HSetNx("Test_HashSetNx", "field", "second"); n != 0 {
+		t.Errorf("expected the field to be kept, got %d", n)
+	}
+	if val, _ := hashes.HGet("Test_HashSetNx", "field"); val != "first" {
+		t.Errorf("expected first, got %s", val)
+	}
+}
+
+func Test_HashIncrBy(t *testing.T) {
+	if val, err := hashes.HIncrBy("Test_HashIncrBy", "counter", "5"); val != 5 || err != nil {
+		t.Errorf("expected 5, got %d, %v", val, err)
+	}
+	if val, err := hashes.HIncrBy("Test_HashIncrBy", "counter", "-7"); val != -2 || err != nil {
+		t.Errorf("expected -2, got %d, %v", val, err)
+	}
+	if _, err := hashes.HIncrBy("Test_HashIncrBy", "counter", "1.5"); err == nil || err.Error() != common.ERR_INVALID_INTEGER {
+		t.Errorf("expected %s, got %v", common.ERR_INVALID_INTEGER, err)
+	}
+	hashes.HSet("Test_HashIncrBy", "text", "abc")
+	if _, err := hashes.HIncrBy("Test_HashIncrBy", "text", "1"); err == nil || err.Error() != common.ERR_HASH_VALUE_NOT_INTEGER {
+		t.Errorf("expected %s, got %v", common.ERR_HASH_VALUE_NOT_INTEGER, err)
+	}
+	hashes.HSet("Test_HashIncrBy", "max", "9223372036854775807")
+	if _, err := hashes.HIncrBy("Test_HashIncrBy", "max", "1"); err == nil || err.Error() != common.ERR_INCREMENT_OVERFLOW {
+		t.Errorf("expected %s, got %v", common.ERR_INCREMENT_OVERFLOW, err)
+	}
+}
+
+func Test_HashIncrByFloat(t *testing.T) {
+	if val, err := hashes.HIncrByFloat("Test_HashIncrByFloat", "value", "10.5"); val != "10.5" || err != nil {
+		t.Errorf("expected 10.5, got %s, %v", val, err)
+	}
+	if val, err := hashes.HIncrByFloat("Test_HashIncrByFloat", "value", "0.1"); val != "10.6" || err != nil {
+		t.Errorf("expected 10.6, got %s, %v", val, err)
+	}
+	if _, err := hashes.HIncrByFloat("Test_HashIncrByFloat", "value", "inf"); err == nil || err.Error() != common.ERR_INVALID_FLOAT {
+		t.Errorf("expected %s, got %v", common.ERR_INVALID_FLOAT, err)
+	}
+	hashes.HSet("Test_HashIncrByFloat", "text", "abc")
+	if _, err := hashes.HIncrByFloat("Test_HashIncrByFloat", "text", "1"); err == nil || err.Error() != common.ERR_HASH_VALUE_NOT_FLOAT {
+		t.Errorf("expected %s, got %v", common.ERR_HASH_VALUE_NOT_FLOAT, err)
+	}
+	hashes.HSet("Test_HashIncrByFloat", "large", "1.7e308")
+	if _, err := hashes.HIncrByFloat("Test_HashIncrByFloat", "large", "1.7e308"); err == nil || err.Error() != common.ERR_INCREMENT_NAN_OR_INFINITY {
+		t.Errorf("expected %s, got %v", common.ERR_INCREMENT_NAN_OR_INFINITY, err)
+	}
+}
+
+func Test_HashKeysValsLenStrlen(t *testing.T) {
+	hashes.HMSet("Test_HashKeysValsLenStrlen", &[]string{"a", "one", "b", "three"})
+	keys, _ := hashes.HKeys("Test_HashKeysValsLenStrlen")
+	values, _ := hashes.HVals("Test_HashKeysValsLenStrlen")
+	slices.Sort(keys)
+	slices.Sort(values)
+	if !slices.Equal(keys, []string{"a", "b"}) || !slices.Equal(values, []string{"one", "three"}) {
+		t.Errorf("unexpected keys %v and values %v", keys, values)
+	}
+	if n, _ := hashes.HLen("Test_HashKeysValsLenStrlen"); n != 2 {
+		t.Errorf("expected 2 fields, got %d", n)
+	}
+	if n, _ := hashes.HStrlen("Test_HashKeysValsLenStrlen", "b"); n != 5 {
+		t.Errorf("expected 5, got %d", n)
+	}
+	if n, _ := hashes.HStrlen("Test_HashKeysValsLenStrlen", "missing"); n != 0 {
+		t.Errorf("expected 0, got %d", n)
+	}
+	if _, err := hashes.HLen("Test_HashKeysValsLenStrlenMissing"); err == nil {
+		t.Errorf("expected an error for a missing hash")
+	}
+}
+
+func Test_HashRandField(t *testing.T) {
+	hashes.HMSet("Test_HashRandField", &[]string{"a", "1", "b", "2", "c", "3"})
+	fields, _ := hashes.HRandField("Test_HashRandField", 2, false)
+	if len(fields) != 2 || fields[0] == fields[1] {
+		t.Errorf("expected 2 distinct fields, got %v", fields)
+	}
+	if fields, _ := hashes.HRandField("Test_HashRandField", 10, false); len(fields) != 3 {
+		t.Errorf("expected every field, got %v", fields)
+	}
+	if fields, _ := hashes.HRandField("Test_HashRandField", -10, false); len(fields) != 10 {
+		t.Errorf("expected 10 fields, got %v", fields)
+	}
+	pairs, _ := hashes.HRandField("Test_HashRandField", 1, true)
+	if len(pairs) != 2 {
+		t.Fatalf("expected a field and its value, got %v", pairs)
+	}
+	if val, _ := hashes.HGet("Test_HashRandField", pairs[0]); val != pairs[1] {
+		t.Errorf("expected the value of %s, got %s", pairs[0], pairs[1])
+	}
+}
+
+func TestHash_ListpackConversion(t *testing.T) {
+	hashes.SetMaxListpackEntries(2)
+	t.Cleanup(func() { hashes.SetMaxListpackEntries(128) })
+
+	hash := hashes.NewHash(0)
+	hash.Set("a", "1")
+	hash.Set("b", "2")
+	hash.Set("a", "3")
+	if _, packed := hash.PackedSize(); !packed || hash.Len() != 2 {
+		t.Fatalf("Expected a listpack of 2 fields")
+	}
+	if !hash.Delete("b") || hash.Delete("b") {
+		t.Errorf("Expected b to be deleted once")
+	}
+	hash.Set("b", "2")
+	hash.Set("c", "4")
+	if _, packed := hash.PackedSize(); packed {
+		t.Fatalf("Expected a hashtable past 2 fields")
+	}
+	for field, expected := range map[string]string{"a": "3", "b": "2", "c": "4"} {
+		if val, ok := hash.Get(field); !ok || val != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, field, val)
+		}
+	}
+
+	long := hashes.NewHash(0)
+	long.Set("field", string(make([]byte, 65)))
+	if _, packed := long.PackedSize(); packed {
+		t.Errorf("Expected a value longer than 64 bytes to need a hashtable")
+	}
+}
+
+func TestHash_ListpackFieldExpiry(t *testing.T) {
+	hash := hashes.NewHash(0)
+	hash.Set("a", "1")
+	hash.Set("b", "2")
+	hash.SetExpireAt("a", 1)
+	if removed := hash.RemoveExpired(time.Now().UnixMilli()); !slices.Equal(removed, []string{"a"}) {
+		t.Fatalf("Expected a to expire, got %v", removed)
+	}
+	if _, ok := hash.Get("a"); ok || hash.Len() != 1 {
+		t.Errorf("Expected only b to be left")
+	}
+}
@@ -0,0 +1,541 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+func get(key string) (*Document, bool) {
+	return store.Get[string, *Document](key)
+}
+
+// getExisting returns the document stored at key, or an error if there is none.
+func getExisting(key string) (*Document, error) {
+	d, ok := get(key)
+	if !ok {
+		return nil, errors.New(common.ERR_JSON_NO_KEY)
+	}
+	return d, nil
+}
+
+// check returns the error a legacy path replies with when it selects nothing, or a value
+// the operation can't be applied to. JSONPaths reply with null for those values instead.
+func (p *Path) check(matches []match, valid func(any) bool) error {
+	if !p.legacy {
+		return nil
+	}
+	if len(matches) == 0 {
+		return errors.New(common.ERR_JSON_PATH_MISSING)
+	}
+	if !valid(matches[0].value) {
+		return errors.New(common.ERR_JSON_WRONG_TYPE)
+	}
+	return nil
+}
+
+func isArray(v any) bool {
+	_, ok := v.(*Array)
+	return ok
+}
+
+func isObject(v any) bool {
+	_, ok := v.(*Object)
+	return ok
+}
+
+func isString(v any) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+// Set sets the values the path selects in the document stored at key to value, and adds it to
+// the objects missing the member a path ending with a name selects. A missing key is created
+// with value as its document, the path must then be the root. With nx values are only added,
+// with xx only replaced. It returns false if nothing was set.
+func Set(key string, path *Path, value any, nx, xx bool) (bool, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		if !path.isRoot() {
+			return false, errors.New(common.ERR_JSON_ROOT)
+		}
+		if xx {
+			return false, nil
+		}
+		store.Set(key, &Document{root: value})
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.set", key)
+		return true, nil
+	}
+	updated := false
+	if !nx {
+		matches := path.find(d)
+		for i := range matches {
+			matches[i].set(d, clone(value))
+			updated = true
+		}
+	}
+	if !xx {
+		objects, name := path.parents(d)
+		for _, o := range objects {
+			o.Set(name, clone(value))
+			updated = true
+		}
+	}
+	if updated {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.set", key)
+	}
+	return updated, nil
+}
+
+// values returns what the paths select in d: the first value for a legacy path, an array of
+// all of them for a JSONPath, and an object keyed by path for several paths.
+func values(d *Document, paths []*Path) (any, error) {
+	legacy := true
+	for _, p := range paths {
+		legacy = legacy && p.legacy
+	}
+	selected := make([]any, len(paths))
+	for i, p := range paths {
+		matches := p.find(d)
+		if legacy {
+			if len(matches) == 0 {
+				return nil, errors.New(common.ERR_JSON_PATH_MISSING)
+			}
+			selected[i] = matches[0].value
+			continue
+		}
+		a := &Array{elements: make([]any, len(matches))}
+		for j, m := range matches {
+			a.elements[j] = m.value
+		}
+		selected[i] = a
+	}
+	if len(paths) == 1 {
+		return selected[0], nil
+	}
+	o := NewObject()
+	for i, p := range paths {
+		o.Set(p.text, selected[i])
+	}
+	return o, nil
+}
+
+// Get returns the JSON text of what the paths select in the document stored at key, the whole
+// document when there are none. It returns false if the key doesn't exist.
+func Get(key string, paths []*Path, f Format) (string, bool, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		return "", false, nil
+	}
+	if len(paths) == 0 {
+		return Serialize(d.root, f), true, nil
+	}
+	v, err := values(d, paths)
+	if err != nil {
+		return "", true, err
+	}
+	return Serialize(v, f), true, nil
+}
+
+// MGet returns the JSON text of what the path selects in the documents stored at keys,
+// nil for the keys that don't exist or in which a legacy path selects nothing.
+func MGet(keys []string, path *Path) []*string {
+	store.RLockKeys(keys...)
+	defer store.RUnlockKeys(keys...)
+
+	texts := make([]*string, len(keys))
+	for i, key := range keys {
+		d, ok := get(key)
+		if !ok {
+			continue
+		}
+		if v, err := values(d, []*Path{path}); err == nil {
+			text := Serialize(v, Format{})
+			texts[i] = &text
+		}
+	}
+	return texts
+}
+
+type tombstone struct{ byte }
+
+// deleted marks the array elements being deleted until the arrays are compacted.
+var deleted = &tombstone{}
+
+// remove deletes the matched values from their parents and returns their number.
+// The root has no parent, it is removed by deleting the key.
+func remove(matches []match) int64 {
+	var n int64
+	arrays := []*Array{}
+	for _, m := range matches {
+		switch p := m.parent.(type) {
+		case *Object:
+			if p.Delete(m.key) {
+				n++
+			}
+		case *Array:
+			if p.elements[m.index] != deleted {
+				p.elements[m.index] = deleted
+				arrays = append(arrays, p)
+				n++
+			}
+		}
+	}
+	for _, a := range arrays {
+		a.elements = slices.DeleteFunc(a.elements, func(v any) bool { return v == deleted })
+	}
+	return n
+}
+
+// Del deletes the values the path selects in the document stored at key, and the key when the
+// path is the root. It returns the number of values deleted.
+func Del(key string, path *Path) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		return 0
+	}
+	var n int64
+	if path.isRoot() {
+		store.Delete(key)
+		n = 1
+	} else {
+		n = remove(path.find(d))
+	}
+	if n > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.del", key)
+	}
+	return n
+}
+
+// Type returns the types of the values the path selects in the document stored at key,
+// false if the key doesn't exist.
+func Type(key string, path *Path) ([]string, bool) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		return nil, false
+	}
+	matches := path.find(d)
+	types := make([]string, len(matches))
+	for i, m := range matches {
+		types[i] = typeName(m.value)
+	}
+	return types, true
+}
+
+// add returns a+b, an integer if both are and the sum doesn't overflow.
+func add(a, b any) (any, error) {
+	x, xInt := a.(int64)
+	y, yInt := b.(int64)
+	if xInt && yInt {
+		sum := x + y
+		if (y >= 0) == (sum >= x) {
+			return sum, nil
+		}
+	}
+	sum := toFloat(a) + toFloat(b)
+	if math.IsInf(sum, 0) || math.IsNaN(sum) {
+		return nil, errors.New(common.ERR_JSON_NOT_FINITE)
+	}
+	return sum, nil
+}
+
+func toFloat(v any) float64 {
+	if n, ok := v.(int64); ok {
+		return float64(n)
+	}
+	return v.(float64)
+}
+
+// NumIncrBy adds increment to the numbers the path selects in the document stored at key and
+// returns their new values, nil for the values that aren't numbers.
+func NumIncrBy(key string, path *Path, increment any) ([]any, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	d, err := getExisting(key)
+	if err != nil {
+		return nil, err
+	}
+	matches := path.find(d)
+	if err := path.check(matches, isNumber); err != nil {
+		return nil, err
+	}
+	results := make([]any, len(matches))
+	for i, m := range matches {
+		if isNumber(m.value) {
+			if results[i], err = add(m.value, increment); err != nil {
+				return nil, err
+			}
+		}
+	}
+	updated := false
+	for i := range matches {
+		if results[i] != nil {
+			matches[i].set(d, results[i])
+			updated = true
+		}
+	}
+	if updated {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.numincrby", key)
+	}
+	return results, nil
+}
+
+// StrAppend appends s to the strings the path selects in the document stored at key and
+// returns their new lengths, nil for the values that aren't strings.
+func StrAppend(key string, path *Path, s string) ([]*int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	d, err := getExisting(key)
+	if err != nil {
+		return nil, err
+	}
+	matches := path.find(d)
+	if err := path.check(matches, isString); err != nil {
+		return nil, err
+	}
+	lengths := make([]*int64, len(matches))
+	for i := range matches {
+		if value, ok := matches[i].value.(string); ok {
+			matches[i].set(d, value+s)
+			n := int64(len(value) + len(s))
+			lengths[i] = &n
+		}
+	}
+	if slices.ContainsFunc(lengths, func(n *int64) bool { return n != nil }) {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.strappend", key)
+	}
+	return lengths, nil
+}
+
+// arrayLengths returns the lengths of the arrays matched, nil for the values that aren't arrays.
+func arrayLengths(matches []match) []*int64 {
+	lengths := make([]*int64, len(matches))
+	for i, m := range matches {
+		if a, ok := m.value.(*Array); ok {
+			n := int64(len(a.elements))
+			lengths[i] = &n
+		}
+	}
+	return lengths
+}
+
+// ArrAppend appends values to the arrays the path selects in the document stored at key and
+// returns their new lengths, nil for the values that aren't arrays.
+func ArrAppend(key string, path *Path, values []any) ([]*int64, error) {
+	return insert(key, path, values, "json.arrappend", func(length int) int { return length })
+}
+
+// ArrInsert inserts values before index in the arrays the path selects in the document stored
+// at key, counting from the end when index is negative. It returns the new lengths of the
+// arrays, nil for the values that aren't arrays.
+func ArrInsert(key string, path *Path, index int, values []any) ([]*int64, error) {
+	return insert(key, path, values, "json.arrinsert", func(length int) int {
+		if index < 0 {
+			return index + length
+		}
+		return index
+	})
+}
+
+// insert inserts values in the arrays the path selects at the position returned for their
+// length. No array is changed when a position is out of bounds.
+func insert(key string, path *Path, values []any, event string, position func(length int) int) ([]*int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	d, err := getExisting(key)
+	if err != nil {
+		return nil, err
+	}
+	matches := path.find(d)
+	if err := path.check(matches, isArray); err != nil {
+		return nil, err
+	}
+	positions := make([]int, len(matches))
+	for i, m := range matches {
+		if a, ok := m.value.(*Array); ok {
+			positions[i] = position(len(a.elements))
+			if positions[i] < 0 || positions[i] > len(a.elements) {
+				return nil, errors.New(common.ERR_INDEX_OUT_OF_BOUNDS)
+			}
+		}
+	}
+	for i, m := range matches {
+		if a, ok := m.value.(*Array); ok {
+			inserted := make([]any, len(values))
+			for j, v := range values {
+				inserted[j] = clone(v)
+			}
+			a.elements = slices.Insert(a.elements, positions[i], inserted...)
+		}
+	}
+	lengths := arrayLengths(matches)
+	if slices.ContainsFunc(lengths, func(n *int64) bool { return n != nil }) {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, event, key)
+	}
+	return lengths, nil
+}
+
+// ArrLen returns the lengths of the arrays the path selects in the document stored at key,
+// nil for the values that aren't arrays. It returns false if the key doesn't exist.
+func ArrLen(key string, path *Path) ([]*int64, bool, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	matches := path.find(d)
+	if err := path.check(matches, isArray); err != nil {
+		return nil, true, err
+	}
+	return arrayLengths(matches), true, nil
+}
+
+// ArrPop removes the element at index, clamped to the bounds of the arrays, from the arrays the
+// path selects in the document stored at key and returns their JSON text, nil for the values
+// that aren't arrays or are empty. It returns false if the key doesn't exist.
+func ArrPop(key string, path *Path, index int) ([]*string, bool, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	matches := path.find(d)
+	if err := path.check(matches, isArray); err != nil {
+		return nil, true, err
+	}
+	popped := make([]*string, len(matches))
+	for i, m := range matches {
+		a, ok := m.value.(*Array)
+		if !ok || len(a.elements) == 0 {
+			continue
+		}
+		position := index
+		if position < 0 {
+			position += len(a.elements)
+		}
+		position = min(max(position, 0), len(a.elements)-1)
+		text := Serialize(a.elements[position], Format{})
+		popped[i] = &text
+		a.elements = slices.Delete(a.elements, position, position+1)
+	}
+	if slices.ContainsFunc(popped, func(s *string) bool { return s != nil }) {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.arrpop", key)
+	}
+	return popped, true, nil
+}
+
+// ObjKeys returns the keys of the objects the path selects in the document stored at key,
+// nil for the values that aren't objects. It returns false if the key doesn't exist.
+func ObjKeys(key string, path *Path) ([][]string, bool, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	matches := path.find(d)
+	if err := path.check(matches, isObject); err != nil {
+		return nil, true, err
+	}
+	keys := make([][]string, len(matches))
+	for i, m := range matches {
+		if o, ok := m.value.(*Object); ok {
+			keys[i] = o.Keys()
+		}
+	}
+	return keys, true, nil
+}
+
+// mergePatch applies patch to target as described by RFC 7386: the members of an object patch
+// are merged into target, a null member removing the one of target, any other patch replaces it.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(*Object)
+	if !ok {
+		return clone(patch)
+	}
+	t, ok := target.(*Object)
+	if !ok {
+		t = NewObject()
+	}
+	for _, key := range p.keys {
+		value := p.values[key]
+		if value == nil {
+			t.Delete(key)
+			continue
+		}
+		current := t.values[key]
+		t.Set(key, mergePatch(current, value))
+	}
+	return t
+}
+
+// Merge merges patch into the values the path selects in the document stored at key, and adds
+// it to the objects missing the member a path ending with a name selects, like Set does.
+// A null patch deletes the values. A missing key is created when the path is the root.
+func Merge(key string, path *Path, patch any) error {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	d, ok := get(key)
+	if !ok {
+		if !path.isRoot() {
+			return errors.New(common.ERR_JSON_ROOT)
+		}
+		if patch != nil {
+			store.Set(key, &Document{root: mergePatch(nil, patch)})
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.merge", key)
+		}
+		return nil
+	}
+	matches := path.find(d)
+	if patch == nil {
+		if path.isRoot() {
+			store.Delete(key)
+		} else {
+			remove(matches)
+		}
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.merge", key)
+		return nil
+	}
+	for i := range matches {
+		matches[i].set(d, mergePatch(matches[i].value, patch))
+	}
+	objects, name := path.parents(d)
+	for _, o := range objects {
+		o.Set(name, mergePatch(nil, patch))
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_JSON, "json.merge", key)
+	return nil
+}
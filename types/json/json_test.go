@@ -0,0 +1,362 @@
+package json_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/types/json"
+)
+
+func path(t *testing.T, text string) *json.Path {
+	t.Helper()
+	p, err := json.ParsePath(text)
+	if err != nil {
+		t.Fatalf("unexpected error parsing %s: %v", text, err)
+	}
+	return p
+}
+
+func parse(t *testing.T, text string) any {
+	t.Helper()
+	v, err := json.Parse(text)
+	if err != nil {
+		t.Fatalf("unexpected error parsing %s: %v", text, err)
+	}
+	return v
+}
+
+// set stores a document at key.
+func set(t *testing.T, key, text string) {
+	t.Helper()
+	if ok, err := json.Set(key, path(t, "$"), parse(t, text), false, false); !ok || err != nil {
+		t.Fatalf("expected %s to be set, got %v %v", key, ok, err)
+	}
+}
+
+func get(t *testing.T, key string, paths ...string) string {
+	t.Helper()
+	parsed := []*json.Path{}
+	for _, p := range paths {
+		parsed = append(parsed, path(t, p))
+	}
+	text, _, err := json.Get(key, parsed, json.Format{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return text
+}
+
+func lengths(values []*int64) []any {
+	result := []any{}
+	for _, v := range values {
+		if v == nil {
+			result = append(result, nil)
+		} else {
+			result = append(result, *v)
+		}
+	}
+	return result
+}
+
+func TestParseAndSerialize(t *testing.T) {
+	cases := map[string]string{
+		`{"b":1,"a":[true,false,null],"c":{}}`: `{"b":1,"a":[true,false,null],"c":{}}`,
+		` [ 1 , 2.0 , 1e2, -0.5 ] `:            `[1,2.0,100.0,-0.5]`,
+		`"tab\tquote\"é\u0001"`:                `"tab\tquote\"é\u0001"`,
+		`{"a":1,"a":2}`:                        `{"a":2}`,
+		`12345678901234567890`:                 `12345678901234567000.0`,
+		`1e300`:                                `1e+300`,
+	}
+	for text, expected := range cases {
+		if got := json.Serialize(parse(t, text), json.Format{}); got != expected {
+			t.Errorf("expected %s for %s, got %s", expected, text, got)
+		}
+	}
+	for _, text := range []string{``, `{`, `[1,]`, `{"a" 1}`, `1 2`, `nul`, `'a'`} {
+		if _, err := json.Parse(text); err == nil || err.Error() != common.ERR_JSON_INVALID {
+			t.Errorf("expected %s for %q, got %v", common.ERR_JSON_INVALID, text, err)
+		}
+	}
+	formatted := json.Serialize(parse(t, `{"a":[1,{}],"b":[]}`), json.Format{Indent: "  ", Newline: "\n", Space: " "})
+	if expected := "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": []\n}"; formatted != expected {
+		t.Errorf("expected %q, got %q", expected, formatted)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	key := "TestParsePath"
+	set(t, key, `{"a":{"b":[10,20,{"c":"x"}]},"d e":{"c":"y"},"c":"z"}`)
+	cases := map[string]string{
+		`$`:            `[{"a":{"b":[10,20,{"c":"x"}]},"d e":{"c":"y"},"c":"z"}]`,
+		`$.a.b[0]`:     `[10]`,
+		`$.a.b[-1].c`:  `["x"]`,
+		`$['d e'].c`:   `["y"]`,
+		`$["a"]["b"]`:  `[[10,20,{"c":"x"}]]`,
+		`$.a.b[*]`:     `[10,20,{"c":"x"}]`,
+		`$.*.c`:        `["y"]`,
+		`$..c`:         `["z","x","y"]`,
+		`$..[1]`:       `[20]`,
+		`$.a.b[5]`:     `[]`,
+		`$.missing.b`:  `[]`,
+		`.a.b[1]`:      `20`,
+		`a.b`:          `[10,20,{"c":"x"}]`,
+		`.`:            `{"a":{"b":[10,20,{"c":"x"}]},"d e":{"c":"y"},"c":"z"}`,
+		`..c`:          `"z"`,
+		`$.a.b[ 1 ]`:   `[20]`,
+		`$.a["b"][-3]`: `[10]`,
+	}
+	for p, expected := range cases {
+		if got := get(t, key, p); got != expected {
+			t.Errorf("expected %s for %s, got %s", expected, p, got)
+		}
+	}
+	for _, p := range []string{`$.`, `$a`, `$[`, `$[x]`, `$['a'`, `$.a.[0]`, `$..`} {
+		if _, err := json.ParsePath(p); err == nil || err.Error() != common.ERR_JSON_PATH {
+			t.Errorf("expected %s for %s, got %v", common.ERR_JSON_PATH, p, err)
+		}
+	}
+	if got := get(t, key, "$.c", ".c"); got != `{"$.c":["z"],".c":["z"]}` {
+		t.Errorf("unexpected reply for several paths %s", got)
+	}
+	if got := get(t, key, ".c", ".a.b[0]"); got != `{".c":"z",".a.b[0]":10}` {
+		t.Errorf("unexpected reply for several legacy paths %s", got)
+	}
+	if _, _, err := json.Get(key, []*json.Path{path(t, ".missing")}, json.Format{}); err == nil || err.Error() != common.ERR_JSON_PATH_MISSING {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_PATH_MISSING, err)
+	}
+	if _, ok, _ := json.Get("TestParsePathMissing", nil, json.Format{}); ok {
+		t.Errorf("expected a missing key")
+	}
+}
+
+func TestSet(t *testing.T) {
+	key := "TestSet"
+	if _, err := json.Set(key, path(t, "$.a"), int64(1), false, false); err == nil || err.Error() != common.ERR_JSON_ROOT {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_ROOT, err)
+	}
+	if ok, _ := json.Set(key, path(t, "$"), int64(1), false, true); ok {
+		t.Errorf("expected XX not to create the key")
+	}
+	set(t, key, `{"a":{"x":1},"b":{"x":2},"c":[1,2]}`)
+	if ok, _ := json.Set(key, path(t, "$"), int64(1), true, false); ok {
+		t.Errorf("expected NX not to replace the root")
+	}
+	if ok, _ := json.Set(key, path(t, "$.*.x"), parse(t, `"v"`), false, false); !ok {
+		t.Errorf("expected the members to be replaced")
+	}
+	if ok, _ := json.Set(key, path(t, "$.*.y"), parse(t, `[]`), false, true); ok {
+		t.Errorf("expected XX not to add members")
+	}
+	if ok, _ := json.Set(key, path(t, "$.*.y"), parse(t, `[]`), true, false); !ok {
+		t.Errorf("expected the members to be added to the objects")
+	}
+	if ok, _ := json.Set(key, path(t, "$.a.y"), parse(t, `1`), true, false); ok {
+		t.Errorf("expected NX not to replace an existing member")
+	}
+	if ok, _ := json.Set(key, path(t, "$.c[1]"), parse(t, `{"z":null}`), false, false); !ok {
+		t.Errorf("expected the array element to be replaced")
+	}
+	if ok, _ := json.Set(key, path(t, "$.c[2]"), parse(t, `3`), false, false); ok {
+		t.Errorf("expected arrays not to grow")
+	}
+	if ok, _ := json.Set(key, path(t, "$.missing.x"), parse(t, `3`), false, false); ok {
+		t.Errorf("expected no intermediate object to be created")
+	}
+	if got := get(t, key); got != `{"a":{"x":"v","y":[]},"b":{"x":"v","y":[]},"c":[1,{"z":null}]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+	// The values set are copies, changing one doesn't change the others.
+	json.ArrAppend(key, path(t, "$.a.y"), []any{int64(1)})
+	if got := get(t, key, "$.b.y"); got != `[[]]` {
+		t.Errorf("expected the other array to be unchanged, got %s", got)
+	}
+}
+
+func TestDel(t *testing.T) {
+	key := "TestDel"
+	set(t, key, `{"a":[1,2,3,4],"b":{"a":1,"c":2},"c":[{"a":1},{"a":2}]}`)
+	if n := json.Del(key, path(t, "$.a[*]")); n != 4 {
+		t.Errorf("expected 4 elements deleted, got %d", n)
+	}
+	if n := json.Del(key, path(t, "$..a")); n != 4 {
+		t.Errorf("expected 4 members deleted, got %d", n)
+	}
+	if n := json.Del(key, path(t, "$.c[0]")); n != 1 {
+		t.Errorf("expected 1 element deleted, got %d", n)
+	}
+	if n := json.Del(key, path(t, "$.missing")); n != 0 {
+		t.Errorf("expected nothing deleted, got %d", n)
+	}
+	if got := get(t, key); got != `{"b":{"c":2},"c":[{}]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+	if n := json.Del(key, path(t, "$")); n != 1 {
+		t.Errorf("expected the key to be deleted, got %d", n)
+	}
+	if _, ok, _ := json.Get(key, nil, json.Format{}); ok {
+		t.Errorf("expected the key to be deleted")
+	}
+	if n := json.Del(key, path(t, "$")); n != 0 {
+		t.Errorf("expected nothing deleted on a missing key, got %d", n)
+	}
+}
+
+func TestTypeAndObjKeys(t *testing.T) {
+	key := "TestTypeAndObjKeys"
+	set(t, key, `{"o":{"z":1,"a":2},"a":[],"s":"","i":1,"n":1.5,"b":false,"x":null}`)
+	types, _ := json.Type(key, path(t, "$.*"))
+	if !slices.Equal(types, []string{"object", "array", "string", "integer", "number", "boolean", "null"}) {
+		t.Errorf("unexpected types %v", types)
+	}
+	if _, ok := json.Type("TestTypeAndObjKeysMissing", path(t, "$")); ok {
+		t.Errorf("expected a missing key")
+	}
+	keys, _, _ := json.ObjKeys(key, path(t, "$[*]"))
+	if len(keys) != 7 || !slices.Equal(keys[0], []string{"z", "a"}) || keys[1] != nil {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	if _, _, err := json.ObjKeys(key, path(t, ".a")); err == nil || err.Error() != common.ERR_JSON_WRONG_TYPE {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_WRONG_TYPE, err)
+	}
+}
+
+func TestNumIncrBy(t *testing.T) {
+	key := "TestNumIncrBy"
+	if _, err := json.NumIncrBy(key, path(t, "$"), int64(1)); err == nil || err.Error() != common.ERR_JSON_NO_KEY {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_NO_KEY, err)
+	}
+	set(t, key, `{"a":1,"b":1.5,"c":"x","d":9223372036854775807}`)
+	results, err := json.NumIncrBy(key, path(t, "$.*"), int64(2))
+	if err != nil || len(results) != 4 || results[0] != int64(3) || results[1] != 3.5 || results[2] != nil || results[3] != 9223372036854775809.0 {
+		t.Errorf("unexpected results %v %v", results, err)
+	}
+	if results, _ := json.NumIncrBy(key, path(t, "$.a"), 0.5); results[0] != 3.5 {
+		t.Errorf("expected a float, got %v", results)
+	}
+	if _, err := json.NumIncrBy(key, path(t, ".c"), int64(1)); err == nil || err.Error() != common.ERR_JSON_WRONG_TYPE {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_WRONG_TYPE, err)
+	}
+	if _, err := json.NumIncrBy(key, path(t, ".missing"), int64(1)); err == nil || err.Error() != common.ERR_JSON_PATH_MISSING {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_PATH_MISSING, err)
+	}
+	json.Set(key, path(t, "$.b"), 1.7e308, false, false)
+	if _, err := json.NumIncrBy(key, path(t, "$.b"), 1.7e308); err == nil || err.Error() != common.ERR_JSON_NOT_FINITE {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_NOT_FINITE, err)
+	}
+	if got := get(t, key, "$.b"); got != `[1.7e+308]` {
+		t.Errorf("expected the value to be unchanged on error, got %s", got)
+	}
+}
+
+func TestStrAppend(t *testing.T) {
+	key := "TestStrAppend"
+	set(t, key, `{"a":"foo","b":{"a":"é"},"c":1}`)
+	appended, err := json.StrAppend(key, path(t, "$..a"), "bar")
+	if err != nil || !slices.Equal(lengths(appended), []any{int64(6), int64(5)}) {
+		t.Errorf("unexpected lengths %v %v", lengths(appended), err)
+	}
+	if appended, _ := json.StrAppend(key, path(t, "$.c"), "x"); !slices.Equal(lengths(appended), []any{nil}) {
+		t.Errorf("expected null for a number, got %v", lengths(appended))
+	}
+	if got := get(t, key); got != `{"a":"foobar","b":{"a":"ébar"},"c":1}` {
+		t.Errorf("unexpected document %s", got)
+	}
+}
+
+func TestArrays(t *testing.T) {
+	key := "TestArrays"
+	if _, err := json.ArrAppend(key, path(t, "$"), []any{int64(1)}); err == nil || err.Error() != common.ERR_JSON_NO_KEY {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_NO_KEY, err)
+	}
+	set(t, key, `{"a":[1],"b":[],"c":"x"}`)
+	appended, _ := json.ArrAppend(key, path(t, "$.*"), []any{int64(2), parse(t, `{"x":[]}`)})
+	if !slices.Equal(lengths(appended), []any{int64(3), int64(2), nil}) {
+		t.Errorf("unexpected lengths %v", lengths(appended))
+	}
+	inserted, _ := json.ArrInsert(key, path(t, "$.a"), 0, []any{int64(0)})
+	if !slices.Equal(lengths(inserted), []any{int64(4)}) {
+		t.Errorf("unexpected lengths %v", lengths(inserted))
+	}
+	json.ArrInsert(key, path(t, "$.a"), -1, []any{"before last"})
+	if _, err := json.ArrInsert(key, path(t, "$.*"), 3, []any{int64(0)}); err == nil || err.Error() != common.ERR_INDEX_OUT_OF_BOUNDS {
+		t.Errorf("expected %s, got %v", common.ERR_INDEX_OUT_OF_BOUNDS, err)
+	}
+	if got := get(t, key); got != `{"a":[0,1,2,"before last",{"x":[]}],"b":[2,{"x":[]}],"c":"x"}` {
+		t.Errorf("unexpected document %s", got)
+	}
+	n, _, _ := json.ArrLen(key, path(t, "$..*"))
+	if !slices.Equal(lengths(n), []any{int64(5), int64(2), nil, nil, nil, nil, nil, nil, int64(0), nil, nil, int64(0)}) {
+		t.Errorf("unexpected lengths %v", lengths(n))
+	}
+	popped, _, _ := json.ArrPop(key, path(t, "$.a"), 100)
+	if len(popped) != 1 || *popped[0] != `{"x":[]}` {
+		t.Errorf("expected the last element, got %v", popped)
+	}
+	popped, _, _ = json.ArrPop(key, path(t, "$.a"), -100)
+	if len(popped) != 1 || *popped[0] != `0` {
+		t.Errorf("expected the first element, got %v", popped)
+	}
+	popped, _, _ = json.ArrPop(key, path(t, "$.b[1].x"), -1)
+	if len(popped) != 1 || popped[0] != nil {
+		t.Errorf("expected nothing popped from an empty array, got %v", popped)
+	}
+	if _, ok, _ := json.ArrPop("TestArraysMissing", path(t, "$"), -1); ok {
+		t.Errorf("expected a missing key")
+	}
+	if got := get(t, key, "$.a"); got != `[[1,2,"before last"]]` {
+		t.Errorf("unexpected array %s", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	key := "TestMerge"
+	if err := json.Merge(key, path(t, "$.a"), int64(1)); err == nil || err.Error() != common.ERR_JSON_ROOT {
+		t.Errorf("expected %s, got %v", common.ERR_JSON_ROOT, err)
+	}
+	if err := json.Merge(key, path(t, "$"), parse(t, `{"a":{"b":1,"c":null},"d":[1]}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := get(t, key); got != `{"a":{"b":1},"d":[1]}` {
+		t.Errorf("unexpected document %s", got)
+	}
+	json.Merge(key, path(t, "$"), parse(t, `{"a":{"b":null,"e":{"f":2}},"d":[2,3]}`))
+	json.Merge(key, path(t, "$.g"), parse(t, `true`))
+	json.Merge(key, path(t, "$.d"), nil)
+	if got := get(t, key); got != `{"a":{"e":{"f":2}},"g":true}` {
+		t.Errorf("unexpected document %s", got)
+	}
+	json.Merge(key, path(t, "$"), nil)
+	if _, ok, _ := json.Get(key, nil, json.Format{}); ok {
+		t.Errorf("expected a null patch at the root to delete the key")
+	}
+}
+
+func TestMGet(t *testing.T) {
+	set(t, "TestMGet1", `{"a":1}`)
+	set(t, "TestMGet2", `{"a":[2]}`)
+	set(t, "TestMGet3", `{"b":3}`)
+	texts := json.MGet([]string{"TestMGet1", "TestMGet2", "TestMGet3", "TestMGetMissing"}, path(t, "$.a"))
+	if *texts[0] != `[1]` || *texts[1] != `[[2]]` || *texts[2] != `[]` || texts[3] != nil {
+		t.Errorf("unexpected texts %v", texts)
+	}
+	texts = json.MGet([]string{"TestMGet1", "TestMGet3"}, path(t, ".a"))
+	if *texts[0] != `1` || texts[1] != nil {
+		t.Errorf("unexpected texts %v", texts)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	d, err := json.NewDocument(`{"a":[1,2,3],"b":"x"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clone := d.Clone()
+	d.Clear()
+	if clone.String() != `{"a":[1,2,3],"b":"x"}` || clone.Len() != 2 || d.String() != "null" {
+		t.Errorf("expected the clone to be independent, got %s %s", clone, d)
+	}
+	if small, _ := json.NewDocument(`1`); small.Size() >= clone.Size() {
+		t.Errorf("expected a larger document to use more memory")
+	}
+}
@@ -0,0 +1,255 @@
+package json
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+)
+
+// Kinds of path selectors.
+const (
+	selectName = iota
+	selectIndex
+	selectWildcard
+)
+
+// selector picks children of a value: the member called name of an object, the element at
+// index of an array, or all of them. A recursive selector picks them in the value and in all
+// its descendants.
+type selector struct {
+	kind      int
+	name      string
+	index     int
+	recursive bool
+}
+
+// Path is a parsed JSONPath. The supported subset is the root $ followed by .name, ['name'],
+// [index], .* and [*] selectors, each of them made recursive by using .. rather than a single dot.
+// Paths that don't start with $ are legacy paths, like .a.b or a.b, they select the first match only.
+type Path struct {
+	text      string
+	selectors []selector
+	legacy    bool
+}
+
+// ParsePath parses a JSONPath or a legacy path.
+func ParsePath(text string) (*Path, error) {
+	p := &Path{text: text, legacy: !strings.HasPrefix(text, "$")}
+	s := text
+	if p.legacy {
+		if s != "" && s[0] != '.' && s[0] != '[' {
+			s = "." + s
+		}
+		if s == "." {
+			s = ""
+		}
+	} else {
+		s = s[1:]
+	}
+	for i := 0; i < len(s); {
+		var sel selector
+		var err error
+		switch s[i] {
+		case '.':
+			i++
+			if i < len(s) && s[i] == '.' {
+				sel.recursive = true
+				i++
+			}
+			switch {
+			case i < len(s) && s[i] == '[' && sel.recursive:
+				if sel, i, err = parseBracket(s, i); err != nil {
+					return nil, err
+				}
+				sel.recursive = true
+			case i < len(s) && s[i] == '*':
+				sel.kind = selectWildcard
+				i++
+			default:
+				end := i
+				for end < len(s) && s[end] != '.' && s[end] != '[' {
+					end++
+				}
+				if end == i {
+					return nil, errors.New(common.ERR_JSON_PATH)
+				}
+				sel.kind, sel.name = selectName, s[i:end]
+				i = end
+			}
+		case '[':
+			if sel, i, err = parseBracket(s, i); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New(common.ERR_JSON_PATH)
+		}
+		p.selectors = append(p.selectors, sel)
+	}
+	return p, nil
+}
+
+// parseBracket parses the [*], [index], ['name'] or ["name"] selector starting at s[i] and
+// returns it with the position following it.
+func parseBracket(s string, i int) (selector, int, error) {
+	i++
+	if strings.HasPrefix(s[i:], "*]") {
+		return selector{kind: selectWildcard}, i + 2, nil
+	}
+	if i < len(s) && (s[i] == '\'' || s[i] == '"') {
+		q := s[i]
+		var name strings.Builder
+		for i++; i < len(s) && s[i] != q; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			name.WriteByte(s[i])
+		}
+		if i+1 >= len(s) || s[i+1] != ']' {
+			return selector{}, 0, errors.New(common.ERR_JSON_PATH)
+		}
+		return selector{kind: selectName, name: name.String()}, i + 2, nil
+	}
+	end := strings.IndexByte(s[i:], ']')
+	if end < 0 {
+		return selector{}, 0, errors.New(common.ERR_JSON_PATH)
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(s[i : i+end]))
+	if err != nil {
+		return selector{}, 0, errors.New(common.ERR_JSON_PATH)
+	}
+	return selector{kind: selectIndex, index: index}, i + end + 1, nil
+}
+
+// IsLegacy reports whether the path is a legacy path rather than a JSONPath.
+func (p *Path) IsLegacy() bool {
+	return p.legacy
+}
+
+// isRoot reports whether the path selects the root of the document.
+func (p *Path) isRoot() bool {
+	return len(p.selectors) == 0
+}
+
+// match is a value selected by a path, with the container holding it so that it can be replaced
+// or removed. The root of a document has no parent.
+type match struct {
+	value  any
+	parent any
+	key    string
+	index  int
+}
+
+// set replaces the matched value in its parent, or the root of d.
+func (m *match) set(d *Document, value any) {
+	switch p := m.parent.(type) {
+	case *Object:
+		p.values[m.key] = value
+	case *Array:
+		p.elements[m.index] = value
+	default:
+		d.root = value
+	}
+	m.value = value
+}
+
+// children appends the members of an object or the elements of an array to out.
+func children(v any, out []match) []match {
+	switch v := v.(type) {
+	case *Object:
+		for _, key := range v.keys {
+			out = append(out, match{value: v.values[key], parent: v, key: key})
+		}
+	case *Array:
+		for i, element := range v.elements {
+			out = append(out, match{value: element, parent: v, index: i})
+		}
+	}
+	return out
+}
+
+// descend appends m and all the values below it to out, depth first.
+func descend(m match, out []match) []match {
+	out = append(out, m)
+	for _, child := range children(m.value, nil) {
+		out = descend(child, out)
+	}
+	return out
+}
+
+// apply appends to out the values sel picks from the value of m.
+func (sel selector) apply(m match, out []match) []match {
+	switch sel.kind {
+	case selectName:
+		if o, ok := m.value.(*Object); ok {
+			if v, ok := o.values[sel.name]; ok {
+				out = append(out, match{value: v, parent: o, key: sel.name})
+			}
+		}
+	case selectIndex:
+		if a, ok := m.value.(*Array); ok {
+			index := sel.index
+			if index < 0 {
+				index += len(a.elements)
+			}
+			if index >= 0 && index < len(a.elements) {
+				out = append(out, match{value: a.elements[index], parent: a, index: index})
+			}
+		}
+	case selectWildcard:
+		out = children(m.value, out)
+	}
+	return out
+}
+
+func evaluate(selectors []selector, root any) []match {
+	matches := []match{{value: root}}
+	for _, sel := range selectors {
+		next := []match{}
+		for _, m := range matches {
+			if !sel.recursive {
+				next = sel.apply(m, next)
+				continue
+			}
+			for _, node := range descend(m, nil) {
+				next = sel.apply(node, next)
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// find returns the values the path selects in d, only the first one for legacy paths.
+func (p *Path) find(d *Document) []match {
+	matches := evaluate(p.selectors, d.root)
+	if p.legacy && len(matches) > 1 {
+		matches = matches[:1]
+	}
+	return matches
+}
+
+// parents returns the objects a value could be added to when the path ends with a plain name
+// they don't have yet, along with that name.
+func (p *Path) parents(d *Document) ([]*Object, string) {
+	if p.isRoot() {
+		return nil, ""
+	}
+	last := p.selectors[len(p.selectors)-1]
+	if last.kind != selectName || last.recursive {
+		return nil, ""
+	}
+	objects := []*Object{}
+	for _, m := range evaluate(p.selectors[:len(p.selectors)-1], d.root) {
+		if o, ok := m.value.(*Object); ok {
+			if _, exists := o.values[last.name]; !exists {
+				objects = append(objects, o)
+			}
+		}
+	}
+	if p.legacy && len(objects) > 1 {
+		objects = objects[:1]
+	}
+	return objects, last.name
+}
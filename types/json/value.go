@@ -0,0 +1,365 @@
+// Package json implements the JSON document value and the JSONPath queries run on it.
+package json
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/divy-sh/animus/common"
+)
+
+// JSON values are held as nil, bool, int64, float64, string, *Object and *Array, so integers
+// and floats keep their type and containers can be changed in place.
+
+// Object is a JSON object, it keeps its keys in insertion order like RedisJSON does.
+type Object struct {
+	keys   []string
+	values map[string]any
+}
+
+func NewObject() *Object {
+	return &Object{values: map[string]any{}}
+}
+
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+func (o *Object) Get(key string) (any, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// Set sets the value of key, adding it after the other keys if it is new.
+func (o *Object) Set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete removes key, it returns false if the object doesn't have it.
+func (o *Object) Delete(key string) bool {
+	if _, ok := o.values[key]; !ok {
+		return false
+	}
+	delete(o.values, key)
+	o.keys = slices.DeleteFunc(o.keys, func(k string) bool { return k == key })
+	return true
+}
+
+// Keys returns the keys of the object in insertion order.
+func (o *Object) Keys() []string {
+	return append([]string{}, o.keys...)
+}
+
+// Array is a JSON array.
+type Array struct {
+	elements []any
+}
+
+func NewArray(elements []any) *Array {
+	return &Array{elements: elements}
+}
+
+func (a *Array) Len() int {
+	return len(a.elements)
+}
+
+// Document is the value stored for a JSON key.
+type Document struct {
+	root any
+}
+
+// NewDocument parses a JSON text into a document.
+func NewDocument(text string) (*Document, error) {
+	root, err := Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{root: root}, nil
+}
+
+// Len returns the number of elements of the root of the document, 1 if it isn't a container.
+func (d *Document) Len() int {
+	switch v := d.root.(type) {
+	case *Object:
+		return v.Len()
+	case *Array:
+		return v.Len()
+	}
+	return 1
+}
+
+func (d *Document) Clone() *Document {
+	return &Document{root: clone(d.root)}
+}
+
+// Clear drops the references the document holds to its values.
+func (d *Document) Clear() {
+	d.root = nil
+}
+
+// String returns the compact JSON text of the document.
+func (d *Document) String() string {
+	return Serialize(d.root, Format{})
+}
+
+// Rough sizes in bytes of the Go values a document is made of.
+const (
+	interfaceSize   = 16
+	stringSize      = 16
+	sliceSize       = 24
+	mapHeaderSize   = 48
+	objectEntrySize = (1+stringSize+interfaceSize)*5/4 + stringSize
+)
+
+// Size estimates the memory used by the document.
+func (d *Document) Size() int64 {
+	return size(d.root)
+}
+
+func size(v any) int64 {
+	switch v := v.(type) {
+	case string:
+		return interfaceSize + stringSize + int64(len(v))
+	case *Object:
+		n := int64(interfaceSize + sliceSize + mapHeaderSize)
+		for _, key := range v.keys {
+			n += objectEntrySize + int64(len(key)) + size(v.values[key])
+		}
+		return n
+	case *Array:
+		n := int64(interfaceSize + sliceSize)
+		for _, element := range v.elements {
+			n += size(element)
+		}
+		return n
+	}
+	return interfaceSize
+}
+
+func clone(v any) any {
+	switch v := v.(type) {
+	case *Object:
+		o := &Object{keys: slices.Clone(v.keys), values: make(map[string]any, len(v.values))}
+		for key, value := range v.values {
+			o.values[key] = clone(value)
+		}
+		return o
+	case *Array:
+		a := &Array{elements: make([]any, len(v.elements))}
+		for i, element := range v.elements {
+			a.elements[i] = clone(element)
+		}
+		return a
+	}
+	return v
+}
+
+// typeName returns the name JSON.TYPE gives to the type of a value.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *Object:
+		return "object"
+	case *Array:
+		return "array"
+	}
+	return "unknown"
+}
+
+// Parse parses a JSON text into a value.
+func Parse(text string) (any, error) {
+	dec := stdjson.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	v, err := parseValue(dec)
+	if err != nil {
+		return nil, errors.New(common.ERR_JSON_INVALID)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New(common.ERR_JSON_INVALID)
+	}
+	return v, nil
+}
+
+func parseValue(dec *stdjson.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case stdjson.Delim:
+		switch t {
+		case '{':
+			o := NewObject()
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := parseValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				o.Set(key.(string), value)
+			}
+			_, err := dec.Token()
+			return o, err
+		case '[':
+			a := &Array{elements: []any{}}
+			for dec.More() {
+				element, err := parseValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				a.elements = append(a.elements, element)
+			}
+			_, err := dec.Token()
+			return a, err
+		}
+		return nil, errors.New(common.ERR_JSON_INVALID)
+	case stdjson.Number:
+		return parseNumber(string(t))
+	}
+	return token, nil
+}
+
+// parseNumber returns an int64 for integers that fit one and a float64 otherwise.
+func parseNumber(s string) (any, error) {
+	if !strings.ContainsAny(s, ".eE") {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Format sets how JSON.GET lays out the JSON text: the string nested levels are indented with,
+// the one written after each element of a container and the one written after each object key.
+type Format struct {
+	Indent  string
+	Newline string
+	Space   string
+}
+
+// Serialize returns the JSON text of a value.
+func Serialize(v any, f Format) string {
+	var b strings.Builder
+	serialize(&b, v, f, 0)
+	return b.String()
+}
+
+func serialize(b *strings.Builder, v any, f Format, depth int) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		b.WriteString(formatFloat(v))
+	case string:
+		quote(b, v)
+	case *Object:
+		b.WriteByte('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(f.Newline)
+			b.WriteString(strings.Repeat(f.Indent, depth+1))
+			quote(b, key)
+			b.WriteByte(':')
+			b.WriteString(f.Space)
+			serialize(b, v.values[key], f, depth+1)
+		}
+		if len(v.keys) > 0 {
+			b.WriteString(f.Newline)
+			b.WriteString(strings.Repeat(f.Indent, depth))
+		}
+		b.WriteByte('}')
+	case *Array:
+		b.WriteByte('[')
+		for i, element := range v.elements {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(f.Newline)
+			b.WriteString(strings.Repeat(f.Indent, depth+1))
+			serialize(b, element, f, depth+1)
+		}
+		if len(v.elements) > 0 {
+			b.WriteString(f.Newline)
+			b.WriteString(strings.Repeat(f.Indent, depth))
+		}
+		b.WriteByte(']')
+	}
+}
+
+// formatFloat formats a float so it reads back as a float, 2.0 rather than 2.
+func formatFloat(f float64) string {
+	var s string
+	if abs := math.Abs(f); abs == 0 || (abs >= 1e-5 && abs < 1e21) {
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	} else {
+		s = strconv.FormatFloat(f, 'e', -1, 64)
+	}
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func quote(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case utf8.RuneError:
+			b.WriteString(`�`)
+		default:
+			if r < 0x20 {
+				b.WriteString(`\u00`)
+				b.WriteByte("0123456789abcdef"[r>>4])
+				b.WriteByte("0123456789abcdef"[r&0xf])
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
@@ -0,0 +1,137 @@
+// Package listpack implements the compact encoding of small hashes and lists: their entries
+// are packed one after the other in a single byte slice, each prefixed by its length as a uvarint.
+// Reaching an entry walks the ones before it, which is cheap for the few entries it is used for
+// and saves the headers and per entry allocations of maps and slices of strings.
+package listpack
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// Listpack is a sequence of strings packed in a byte slice. The zero value is an empty listpack.
+type Listpack struct {
+	buf []byte
+	n   int
+}
+
+// Len returns the number of entries.
+func (l *Listpack) Len() int {
+	return l.n
+}
+
+// Bytes returns the number of bytes the entries take.
+func (l *Listpack) Bytes() int {
+	return len(l.buf)
+}
+
+// entry returns the bounds of the data of the entry whose length starts at off.
+func (l *Listpack) entry(off int) (int, int) {
+	length, n := binary.Uvarint(l.buf[off:])
+	return off + n, off + n + int(length)
+}
+
+// offset returns the position in buf of entry i, len(buf) for i == Len().
+func (l *Listpack) offset(i int) int {
+	off := 0
+	for range i {
+		_, off = l.entry(off)
+	}
+	return off
+}
+
+func encode(values []string) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.AppendUvarint(b, uint64(len(v)))
+		b = append(b, v...)
+	}
+	return b
+}
+
+// Get returns entry i.
+func (l *Listpack) Get(i int) (string, bool) {
+	if i < 0 || i >= l.n {
+		return "", false
+	}
+	start, end := l.entry(l.offset(i))
+	return string(l.buf[start:end]), true
+}
+
+// Find returns the index of the first entry at or after from, stepping by step entries,
+// equal to value, -1 if there is none.
+func (l *Listpack) Find(value string, from, step int) int {
+	off := l.offset(from)
+	for i := from; i < l.n; i++ {
+		start, end := l.entry(off)
+		if (i-from)%step == 0 && string(l.buf[start:end]) == value {
+			return i
+		}
+		off = end
+	}
+	return -1
+}
+
+// Each calls fn with the entries in order until fn returns false.
+func (l *Listpack) Each(fn func(i int, value string) bool) {
+	off := 0
+	for i := range l.n {
+		start, end := l.entry(off)
+		if !fn(i, string(l.buf[start:end])) {
+			return
+		}
+		off = end
+	}
+}
+
+// EachReverse calls fn with the entries from the last one to the first until fn returns false.
+// Entries can only be walked forward, so their bounds are collected first.
+func (l *Listpack) EachReverse(fn func(i int, value string) bool) {
+	bounds := make([][2]int, l.n)
+	off := 0
+	for i := range bounds {
+		start, end := l.entry(off)
+		bounds[i] = [2]int{start, end}
+		off = end
+	}
+	for i := l.n - 1; i >= 0; i-- {
+		if !fn(i, string(l.buf[bounds[i][0]:bounds[i][1]])) {
+			return
+		}
+	}
+}
+
+// Insert inserts values before entry i, i == Len() appending them.
+func (l *Listpack) Insert(i int, values ...string) {
+	off := l.offset(i)
+	l.buf = slices.Insert(l.buf, off, encode(values)...)
+	l.n += len(values)
+}
+
+// Replace replaces entry i with value.
+func (l *Listpack) Replace(i int, value string) {
+	off := l.offset(i)
+	_, end := l.entry(off)
+	l.buf = slices.Replace(l.buf, off, end, encode([]string{value})...)
+}
+
+// Remove removes count entries from entry i.
+func (l *Listpack) Remove(i, count int) {
+	start := l.offset(i)
+	end := start
+	for range count {
+		_, end = l.entry(end)
+	}
+	l.buf = slices.Delete(l.buf, start, end)
+	l.n -= count
+}
+
+// Clone returns a copy of the listpack.
+func (l *Listpack) Clone() Listpack {
+	return Listpack{buf: slices.Clip(slices.Clone(l.buf)), n: l.n}
+}
+
+// Clear removes every entry.
+func (l *Listpack) Clear() {
+	l.buf, l.n = nil, 0
+}
@@ -0,0 +1,79 @@
+package listpack_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/divy-sh/animus/types/listpack"
+)
+
+func entries(l *listpack.Listpack) []string {
+	out := []string{}
+	l.Each(func(_ int, value string) bool {
+		out = append(out, value)
+		return true
+	})
+	return out
+}
+
+func TestListpack_InsertAndGet(t *testing.T) {
+	var l listpack.Listpack
+	l.Insert(0, "c")
+	l.Insert(0, "a", "")
+	l.Insert(3, strings.Repeat("x", 300))
+	if l.Len() != 4 || !slices.Equal(entries(&l), []string{"a", "", "c", strings.Repeat("x", 300)}) {
+		t.Fatalf("unexpected entries %v", entries(&l))
+	}
+	if v, ok := l.Get(2); !ok || v != "c" {
+		t.Errorf("Expected c, got %v", v)
+	}
+	if _, ok := l.Get(4); ok {
+		t.Errorf("Expected no entry past the end")
+	}
+	if l.Bytes() != 2+1+2+2+300 {
+		t.Errorf("Expected 307 bytes, got %d", l.Bytes())
+	}
+}
+
+func TestListpack_EachReverse(t *testing.T) {
+	var l listpack.Listpack
+	l.Insert(0, "a", "", strings.Repeat("x", 300), "d")
+	out := []string{}
+	l.EachReverse(func(i int, value string) bool {
+		out = append(out, value)
+		return i > 1
+	})
+	if !slices.Equal(out, []string{"d", strings.Repeat("x", 300), ""}) {
+		t.Errorf("unexpected entries %v", out)
+	}
+}
+
+func TestListpack_ReplaceAndRemove(t *testing.T) {
+	var l listpack.Listpack
+	l.Insert(0, "a", "b", "c", "d")
+	l.Replace(1, "bb")
+	l.Remove(2, 1)
+	if !slices.Equal(entries(&l), []string{"a", "bb", "d"}) {
+		t.Fatalf("unexpected entries %v", entries(&l))
+	}
+	clone := l.Clone()
+	l.Clear()
+	if l.Len() != 0 || l.Bytes() != 0 || clone.Len() != 3 {
+		t.Errorf("Expected the clone to keep its entries")
+	}
+}
+
+func TestListpack_Find(t *testing.T) {
+	var l listpack.Listpack
+	l.Insert(0, "f1", "v", "f2", "v")
+	if i := l.Find("v", 0, 2); i != -1 {
+		t.Errorf("Expected v not to be a field, got %d", i)
+	}
+	if i := l.Find("v", 1, 2); i != 1 {
+		t.Errorf("Expected 1, got %d", i)
+	}
+	if i := l.Find("f2", 0, 2); i != 2 {
+		t.Errorf("Expected 2, got %d", i)
+	}
+}
@@ -84,6 +84,26 @@ func (d *Deque[T]) Set(i int, v T) bool {
 	return true
 }
 
+func (d *Deque[T]) Clone() *Deque[T] {
+	clone := &Deque[T]{
+		buf:  make([]T, len(d.buf)),
+		head: d.head,
+		tail: d.tail,
+		size: d.size,
+	}
+	copy(clone.buf, d.buf)
+	return clone
+}
+
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.tail, d.size = 0, 0, 0
+}
+
+func (d *Deque[T]) Cap() int {
+	return len(d.buf)
+}
+
 func (d *Deque[T]) Len() int {
 	return d.size
 }
@@ -0,0 +1,204 @@
+package lists
+
+import (
+	"sync/atomic"
+
+	"github.com/divy-sh/animus/types/listpack"
+)
+
+// maxListpackSize limits the size of a list held in a listpack, as set by list-max-listpack-size:
+// a positive value is a number of elements, -1 to -5 a number of bytes from 4KB to 64KB.
+// 0 always uses a deque.
+var maxListpackSize atomic.Int64
+
+func init() {
+	maxListpackSize.Store(-2)
+}
+
+// SetMaxListpackSize sets the size past which a list is converted from a listpack to a deque.
+func SetMaxListpackSize(n int64) {
+	maxListpackSize.Store(n)
+}
+
+// fitsListpack reports whether a listpack of n elements taking size bytes is within the limit.
+func fitsListpack(n, size int) bool {
+	limit := maxListpackSize.Load()
+	if limit >= 0 {
+		return int64(n) <= limit
+	}
+	return size <= 4096<<(min(-limit, 5)-1)
+}
+
+// List is the value stored for a list. Small lists pack their elements in a listpack and
+// are converted to a deque once they grow past list-max-listpack-size.
+type List struct {
+	// packed holds the elements while deque is nil.
+	packed listpack.Listpack
+	deque  *Deque[string]
+}
+
+func NewList() *List {
+	return &List{}
+}
+
+// convertIfFull converts the list to a deque if holding n more elements of size bytes would
+// take it past the listpack limit.
+func (l *List) convertIfFull(n, size int) {
+	if l.deque != nil || fitsListpack(l.packed.Len()+n, l.packed.Bytes()+size) {
+		return
+	}
+	l.deque = NewDeque[string](l.packed.Len() + n)
+	l.packed.Each(func(_ int, v string) bool {
+		l.deque.PushBack(v)
+		return true
+	})
+	l.packed.Clear()
+}
+
+func (l *List) Len() int {
+	if l.deque == nil {
+		return l.packed.Len()
+	}
+	return l.deque.Len()
+}
+
+func (l *List) PushFront(v string) {
+	l.InsertAt(0, v)
+}
+
+func (l *List) PushBack(v string) {
+	l.InsertAt(l.Len(), v)
+}
+
+func (l *List) PopFront() (string, bool) {
+	if l.deque != nil {
+		return l.deque.PopFront()
+	}
+	return l.RemoveAt(0)
+}
+
+func (l *List) PopBack() (string, bool) {
+	if l.deque != nil {
+		return l.deque.PopBack()
+	}
+	return l.RemoveAt(l.Len() - 1)
+}
+
+func (l *List) Get(i int) (string, bool) {
+	if l.deque != nil {
+		return l.deque.Get(i)
+	}
+	return l.packed.Get(i)
+}
+
+func (l *List) Set(i int, v string) bool {
+	if i < 0 || i >= l.Len() {
+		return false
+	}
+	l.convertIfFull(0, len(v))
+	if l.deque != nil {
+		return l.deque.Set(i, v)
+	}
+	l.packed.Replace(i, v)
+	return true
+}
+
+func (l *List) InsertAt(i int, v string) bool {
+	if i < 0 || i > l.Len() {
+		return false
+	}
+	l.convertIfFull(1, len(v))
+	if l.deque != nil {
+		return l.deque.InsertAt(i, v)
+	}
+	l.packed.Insert(i, v)
+	return true
+}
+
+func (l *List) RemoveAt(i int) (string, bool) {
+	if l.deque != nil {
+		return l.deque.RemoveAt(i)
+	}
+	v, ok := l.packed.Get(i)
+	if ok {
+		l.packed.Remove(i, 1)
+	}
+	return v, ok
+}
+
+// Each calls fn with the elements from the head of the list until fn returns false.
+func (l *List) Each(fn func(i int, v string) bool) {
+	if l.deque == nil {
+		l.packed.Each(fn)
+		return
+	}
+	for i := range l.deque.Len() {
+		v, _ := l.deque.Get(i)
+		if !fn(i, v) {
+			return
+		}
+	}
+}
+
+// EachReverse calls fn with the elements from the tail of the list until fn returns false.
+func (l *List) EachReverse(fn func(i int, v string) bool) {
+	if l.deque == nil {
+		l.packed.EachReverse(fn)
+		return
+	}
+	for i := l.deque.Len() - 1; i >= 0; i-- {
+		v, _ := l.deque.Get(i)
+		if !fn(i, v) {
+			return
+		}
+	}
+}
+
+func (l *List) ToSlice() []string {
+	return l.SliceRange(0, l.Len()-1)
+}
+
+func (l *List) SliceRange(start, end int) []string {
+	if l.deque != nil {
+		return l.deque.SliceRange(start, end)
+	}
+	out := []string{}
+	if start > end {
+		return out
+	}
+	l.packed.Each(func(i int, v string) bool {
+		if i >= start {
+			out = append(out, v)
+		}
+		return i < end
+	})
+	return out
+}
+
+func (l *List) Clone() *List {
+	if l.deque != nil {
+		return &List{deque: l.deque.Clone()}
+	}
+	return &List{packed: l.packed.Clone()}
+}
+
+func (l *List) Clear() {
+	l.packed.Clear()
+	if l.deque != nil {
+		l.deque.Clear()
+	}
+}
+
+// Cap returns the number of slots allocated in the deque holding the elements, 0 for a listpack.
+func (l *List) Cap() int {
+	if l.deque == nil {
+		return 0
+	}
+	return l.deque.Cap()
+}
+
+// PackedSize returns the number of bytes of the listpack holding the elements, false if the
+// list is a deque.
+func (l *List) PackedSize() (int, bool) {
+	return l.packed.Bytes(), l.deque == nil
+}
@@ -5,26 +5,36 @@ import (
 	"strconv"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
 	"github.com/divy-sh/animus/store"
 )
 
-func getOrCreate(key string) *Deque[string] {
-	dq, ok := store.Get[string, *Deque[string]](key)
+func getOrCreate(key string) *List {
+	dq, ok := store.Get[string, *List](key)
 	if !ok {
-		dq = NewDeque[string](4)
+		dq = NewList()
 		store.Set(key, dq)
 	}
 	return dq
 }
 
-func get(key string) (*Deque[string], error) {
-	dq, ok := store.Get[string, *Deque[string]](key)
+func get(key string) (*List, error) {
+	dq, ok := store.Get[string, *List](key)
 	if !ok {
 		return nil, errors.New(common.ERR_LIST_NOT_FOUND)
 	}
 	return dq, nil
 }
 
+// deleteIfEmpty removes a list that has no elements left, lists are never stored empty.
+// The caller must hold the write lock of the key.
+func deleteIfEmpty(key string, dq *List) {
+	if dq.Len() == 0 {
+		store.Delete(key)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+	}
+}
+
 func Lindex(key string, index int64) (string, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
@@ -73,6 +83,7 @@ func Linsert(key string, position string, pivot string, value string) (int64, er
 	default:
 		return 0, errors.New(common.ERR_WRONG_ARGUMENT_COUNT)
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "linsert", key)
 
 	return int64(dq.Len()), nil
 }
@@ -117,56 +128,48 @@ func Lmove(source, destination, direction string) (string, error) {
 
 	if direction == "RIGHT" {
 		dest.PushFront(val)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "rpop", source)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lpush", destination)
 	} else {
 		dest.PushBack(val)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lpop", source)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "rpush", destination)
 	}
+	deleteIfEmpty(source, src)
 
 	return val, nil
 }
 
-func Lmpop(source string, destinations []string, direction string) (map[string]string, error) {
-	lockKeys := make([]string, 0, 1+len(destinations))
-
-	store.LockKeys(lockKeys...)
-	defer store.UnlockKeys(lockKeys...)
-
-	src, err := get(source)
-	if err != nil || src.Len() == 0 {
-		return nil, errors.New(common.ERR_SOURCE_KEY_NOT_FOUND)
-	}
-
-	result := make(map[string]string)
+// Lmpop pops up to count elements from the first non-empty list among keys, from the left
+// or the right. It returns the key the elements were popped from, or false if every list is empty.
+func Lmpop(keys []string, direction string, count int64) (string, []string, bool) {
+	store.LockKeys(keys...)
+	defer store.UnlockKeys(keys...)
 
-	for _, destKey := range destinations {
-		if src.Len() == 0 {
-			break
-		}
-
-		var val string
-		var ok bool
-		switch direction {
-		case "RIGHT":
-			val, ok = src.PopBack()
-		case "LEFT":
-			val, ok = src.PopFront()
-		default:
-			return nil, errors.New(common.ERR_WRONG_ARGUMENT_COUNT)
+	for _, key := range keys {
+		dq, err := get(key)
+		if err != nil || dq.Len() == 0 {
+			continue
 		}
-		if !ok {
-			break
+		out := make([]string, 0, min(count, int64(dq.Len())))
+		for int64(len(out)) < count && dq.Len() > 0 {
+			var v string
+			if direction == "LEFT" {
+				v, _ = dq.PopFront()
+			} else {
+				v, _ = dq.PopBack()
+			}
+			out = append(out, v)
 		}
-
-		dest := getOrCreate(destKey)
-		if direction == "RIGHT" {
-			dest.PushFront(val)
+		if direction == "LEFT" {
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lpop", key)
 		} else {
-			dest.PushBack(val)
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "rpop", key)
 		}
-
-		result[destKey] = val
+		deleteIfEmpty(key, dq)
+		return key, out, true
 	}
-
-	return result, nil
+	return "", nil, false
 }
 
 func LPop(key string, count string) ([]string, error) {
@@ -188,25 +191,56 @@ func LPop(key string, count string) ([]string, error) {
 		v, _ := dq.PopFront()
 		out[i] = v
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lpop", key)
+	deleteIfEmpty(key, dq)
 	return out, nil
 }
 
-func Lpos(key string, value string) (int64, error) {
+// LposOptions are the options of LPOS. Rank is the match to start from, negative ranks count
+// matches from the end of the list. Count is the number of matches to return, 0 for all of them.
+// MaxLen is the number of elements to compare, 0 for the whole list.
+type LposOptions struct {
+	Rank   int64
+	Count  int64
+	MaxLen int64
+}
+
+// Lpos returns the indexes of the elements equal to value, at most options.Count of them.
+func Lpos(key string, value string, options LposOptions) ([]int64, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
 	dq, err := get(key)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
-	for i := 0; i < dq.Len(); i++ {
-		v, _ := dq.Get(i)
-		if v == value {
-			return int64(i), nil
-		}
+	n := int64(dq.Len())
+	if options.MaxLen > 0 {
+		n = min(n, options.MaxLen)
+	}
+	rank, each := options.Rank, dq.Each
+	if rank < 0 {
+		rank, each = -rank, dq.EachReverse
 	}
-	return -1, nil
+	matches := []int64{}
+	checked := int64(0)
+	each(func(i int, v string) bool {
+		if checked == n {
+			return false
+		}
+		checked++
+		if v != value {
+			return true
+		}
+		if rank > 1 {
+			rank--
+			return true
+		}
+		matches = append(matches, int64(i))
+		return options.Count <= 0 || int64(len(matches)) < options.Count
+	})
+	return matches, nil
 }
 
 func LPush(key string, values *[]string) {
@@ -218,21 +252,25 @@ func LPush(key string, values *[]string) {
 	for i := len(*values) - 1; i >= 0; i-- {
 		dq.PushFront((*values)[i])
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lpush", key)
 }
 
-func LPushx(key string, values *[]string) error {
+// LPushx pushes values at the head of an existing list and returns its new length, or 0
+// without creating the list if the key doesn't exist.
+func LPushx(key string, values *[]string) (int64, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	dq, err := get(key)
-	if err != nil {
-		return err
+	dq, ok := store.Get[string, *List](key)
+	if !ok {
+		return 0, nil
 	}
 
 	for i := len(*values) - 1; i >= 0; i-- {
 		dq.PushFront((*values)[i])
 	}
-	return nil
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lpush", key)
+	return int64(dq.Len()), nil
 }
 
 func Lrange(key string, start int64, stop int64) ([]string, error) {
@@ -265,67 +303,48 @@ func Lrange(key string, start int64, stop int64) ([]string, error) {
 	return dq.SliceRange(int(start), int(stop)), nil
 }
 
+// Lrem removes the elements equal to value and returns the number removed. A positive count
+// removes the first count matches, a negative one the last -count matches and 0 all of them.
+// A missing key is an empty list.
 func Lrem(key string, count int64, value string) (int64, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	dq, err := get(key)
-	if err != nil {
-		return 0, err
+	dq, ok := store.Get[string, *List](key)
+	if !ok {
+		return 0, nil
 	}
 
+	values := dq.ToSlice()
+	remove := make([]bool, len(values))
 	removed := int64(0)
-
-	if count == 0 {
-		// remove all
-		newdq := NewDeque[string](dq.Len())
-		for i := 0; i < dq.Len(); i++ {
-			v, _ := dq.Get(i)
-			if v == value {
-				removed++
-			} else {
-				newdq.PushBack(v)
-			}
-		}
-		store.Set(key, newdq)
-		return removed, nil
-	}
-
-	if count > 0 {
-		// left to right
-		newdq := NewDeque[string](dq.Len())
-		for i := 0; i < dq.Len(); i++ {
-			v, _ := dq.Get(i)
-			if v == value && removed < count {
-				removed++
-			} else {
-				newdq.PushBack(v)
-			}
+	limit := count
+	if count < 0 {
+		limit = -count
+	}
+	for n := 0; n < len(values); n++ {
+		i := n
+		if count < 0 {
+			i = len(values) - 1 - n
 		}
-		store.Set(key, newdq)
-		return removed, nil
-	}
-
-	// count < 0 — remove from right
-	newdq := NewDeque[string](dq.Len())
-	toRemove := -count
-
-	// collect values
-	values := dq.ToSlice()
-	for i := dq.Len() - 1; i >= 0; i-- {
-		if values[i] == value && removed < toRemove {
+		if values[i] == value && (limit == 0 || removed < limit) {
+			remove[i] = true
 			removed++
-			values[i] = "" // skip
 		}
 	}
+	if removed == 0 {
+		return 0, nil
+	}
 
-	for _, v := range values {
-		if v != "" {
-			newdq.PushBack(v)
+	// Rebuild the list in place so the key keeps its time to live.
+	dq.Clear()
+	for i, v := range values {
+		if !remove[i] {
+			dq.PushBack(v)
 		}
 	}
-
-	store.Set(key, newdq)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lrem", key)
+	deleteIfEmpty(key, dq)
 	return removed, nil
 }
 
@@ -344,16 +363,18 @@ func Lset(key string, index int64, value string) error {
 	if !dq.Set(int(index), value) {
 		return errors.New(common.ERR_INDEX_OUT_OF_RANGE)
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "lset", key)
 	return nil
 }
 
+// Ltrim keeps only the elements between start and stop, both inclusive. A missing key is left as is.
 func Ltrim(key string, start int64, stop int64) error {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	dq, err := get(key)
-	if err != nil {
-		return err
+	dq, ok := store.Get[string, *List](key)
+	if !ok {
+		return nil
 	}
 
 	l := int64(dq.Len())
@@ -372,17 +393,17 @@ func Ltrim(key string, start int64, stop int64) error {
 	}
 
 	if start > stop || start >= l {
-		store.Set(key, NewDeque[string](4))
-		return nil
-	}
-
-	out := NewDeque[string](int(stop-start) + 1)
-	for i := start; i <= stop; i++ {
-		v, _ := dq.Get(int(i))
-		out.PushBack(v)
+		dq.Clear()
+	} else {
+		for range start {
+			dq.PopFront()
+		}
+		for range l - 1 - stop {
+			dq.PopBack()
+		}
 	}
-
-	store.Set(key, out)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "ltrim", key)
+	deleteIfEmpty(key, dq)
 	return nil
 }
 
@@ -405,6 +426,8 @@ func RPop(key string, count string) ([]string, error) {
 		v, _ := dq.PopBack()
 		out[i] = v
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "rpop", key)
+	deleteIfEmpty(key, dq)
 	return out, nil
 }
 
@@ -417,19 +440,23 @@ func RPush(key string, values *[]string) {
 	for _, v := range *values {
 		dq.PushBack(v)
 	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "rpush", key)
 }
 
-func RPushx(key string, values *[]string) error {
+// RPushx pushes values at the tail of an existing list and returns its new length, or 0
+// without creating the list if the key doesn't exist.
+func RPushx(key string, values *[]string) (int64, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	dq, err := get(key)
-	if err != nil {
-		return err
+	dq, ok := store.Get[string, *List](key)
+	if !ok {
+		return 0, nil
 	}
 
 	for _, v := range *values {
 		dq.PushBack(v)
 	}
-	return nil
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_LIST, "rpush", key)
+	return int64(dq.Len()), nil
 }
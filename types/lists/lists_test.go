@@ -1,9 +1,11 @@
 package lists
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/store"
 )
 
 func TestRPush(t *testing.T) {
@@ -250,7 +252,7 @@ func Test_RPushX(t *testing.T) {
 	values := []string{"a", "b"}
 	RPush(key, &values)
 
-	err := RPushx(key, &[]string{"d", "e"})
+	_, err := RPushx(key, &[]string{"d", "e"})
 	if err != nil {
 		t.Errorf("Expected no error for valid RPushX")
 	}
@@ -260,8 +262,160 @@ func Test_RPushX(t *testing.T) {
 		t.Errorf("Expected list length 3 after RPushX, got %d", length)
 	}
 
-	err = RPushx("nonExistentKey", &[]string{"d", "e"})
-	if err == nil {
-		t.Errorf("Expected error for RPushX on non-existent key")
+	length, err = RPushx("nonExistentKey", &[]string{"d", "e"})
+	if err != nil || length != 0 {
+		t.Errorf("Expected 0 for RPushX on non-existent key, got %d, %v", length, err)
+	}
+	if _, err := LLen("nonExistentKey"); err == nil {
+		t.Errorf("Expected RPushX not to create the list")
+	}
+}
+
+func Test_Lpos(t *testing.T) {
+	key := "testLpos"
+	RPush(key, &[]string{"a", "b", "c", "b", "b", "a"})
+
+	tests := []struct {
+		options  LposOptions
+		expected []int64
+	}{
+		{LposOptions{Rank: 1, Count: 1}, []int64{1}},
+		{LposOptions{Rank: 2, Count: 1}, []int64{3}},
+		{LposOptions{Rank: -1, Count: 1}, []int64{4}},
+		{LposOptions{Rank: 1}, []int64{1, 3, 4}},
+		{LposOptions{Rank: -2}, []int64{3, 1}},
+		{LposOptions{Rank: 1, MaxLen: 3}, []int64{1}},
+		{LposOptions{Rank: -1, MaxLen: 1}, []int64{}},
+		{LposOptions{Rank: 4}, []int64{}},
+	}
+	// Run the cases against both encodings of the list.
+	SetMaxListpackSize(0)
+	RPush(key+"Deque", &[]string{"a", "b", "c", "b", "b", "a"})
+	SetMaxListpackSize(-2)
+	for _, key := range []string{key, key + "Deque"} {
+		for _, tc := range tests {
+			matches, err := Lpos(key, "b", tc.options)
+			if err != nil || !slices.Equal(matches, tc.expected) {
+				t.Errorf("%s %+v: expected %v, got %v (%v)", key, tc.options, tc.expected, matches, err)
+			}
+		}
+	}
+}
+
+func Test_Lrem(t *testing.T) {
+	tests := []struct {
+		count    int64
+		removed  int64
+		expected []string
+	}{
+		{0, 3, []string{"", "b", "c"}},
+		{2, 2, []string{"", "b", "c", "a"}},
+		{-2, 2, []string{"a", "", "b", "c"}},
+	}
+	for _, tc := range tests {
+		key := "testLrem"
+		RPush(key, &[]string{"a", "", "b", "a", "c", "a"})
+		removed, err := Lrem(key, tc.count, "a")
+		if err != nil || removed != tc.removed {
+			t.Errorf("count %d: expected %d removed, got %d (%v)", tc.count, tc.removed, removed, err)
+		}
+		values, _ := Lrange(key, 0, -1)
+		if !slices.Equal(values, tc.expected) {
+			t.Errorf("count %d: expected %v, got %v", tc.count, tc.expected, values)
+		}
+		Ltrim(key, 1, 0)
+	}
+}
+
+func Test_LtrimAndLset(t *testing.T) {
+	key := "testLtrim"
+	RPush(key, &[]string{"a", "b", "c", "d", "e"})
+
+	if err := Ltrim(key, 1, -2); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := Lset(key, -1, "z"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if values, _ := Lrange(key, 0, -1); !slices.Equal(values, []string{"b", "c", "z"}) {
+		t.Errorf("Unexpected list: %v", values)
+	}
+	if err := Lset(key, 3, "z"); err == nil || err.Error() != common.ERR_INDEX_OUT_OF_RANGE {
+		t.Errorf("Expected %s, got %v", common.ERR_INDEX_OUT_OF_RANGE, err)
+	}
+	Ltrim(key, 5, 10)
+	if _, err := LLen(key); err == nil {
+		t.Errorf("Expected the empty list to be deleted")
+	}
+}
+
+func Test_Lmpop(t *testing.T) {
+	RPush("testLmpop2", &[]string{"a", "b", "c"})
+
+	key, values, ok := Lmpop([]string{"testLmpop1", "testLmpop2"}, "LEFT", 2)
+	if !ok || key != "testLmpop2" || !slices.Equal(values, []string{"a", "b"}) {
+		t.Errorf("Unexpected pop: %s %v %v", key, values, ok)
+	}
+	key, values, ok = Lmpop([]string{"testLmpop1", "testLmpop2"}, "RIGHT", 5)
+	if !ok || key != "testLmpop2" || !slices.Equal(values, []string{"c"}) {
+		t.Errorf("Unexpected pop: %s %v %v", key, values, ok)
+	}
+	if _, _, ok := Lmpop([]string{"testLmpop1", "testLmpop2"}, "LEFT", 1); ok {
+		t.Errorf("Expected nothing to pop once the lists are empty")
+	}
+}
+
+func Test_PopDeletesEmptyList(t *testing.T) {
+	key := "testPopDeletesEmptyList"
+	RPush(key, &[]string{"a", "b"})
+	RPop(key, "2")
+	if _, ok := store.Get[string, *List](key); ok {
+		t.Errorf("Expected the empty list to be deleted")
+	}
+	RPush(key, &[]string{"a"})
+	Lmove(key, "testPopDeletesEmptyListDest", "LEFT")
+	if _, ok := store.Get[string, *List](key); ok {
+		t.Errorf("Expected the empty source list to be deleted")
+	}
+}
+
+func TestList_ConvertsToDeque(t *testing.T) {
+	SetMaxListpackSize(3)
+	t.Cleanup(func() { SetMaxListpackSize(-2) })
+
+	list := NewList()
+	list.PushBack("b")
+	list.PushFront("a")
+	list.InsertAt(2, "c")
+	if _, packed := list.PackedSize(); !packed {
+		t.Fatalf("Expected a list of 3 elements to be a listpack")
+	}
+	if v, ok := list.PopBack(); !ok || v != "c" {
+		t.Errorf("Expected c, got %v", v)
+	}
+	list.PushBack("c")
+	list.PushBack("d")
+	if _, packed := list.PackedSize(); packed {
+		t.Fatalf("Expected a list of 4 elements to be a deque")
+	}
+	if !slices.Equal(list.ToSlice(), []string{"a", "b", "c", "d"}) {
+		t.Errorf("Expected [a b c d], got %v", list.ToSlice())
+	}
+}
+
+func TestList_ConvertsPastByteLimit(t *testing.T) {
+	list := NewList()
+	for range 100 {
+		list.PushBack("0123456789")
+	}
+	if _, packed := list.PackedSize(); !packed {
+		t.Fatalf("Expected 1KB of elements to fit a listpack")
+	}
+	list.Set(50, string(make([]byte, 8192)))
+	if _, packed := list.PackedSize(); packed {
+		t.Fatalf("Expected a list past 8KB to be a deque")
+	}
+	if v, _ := list.Get(51); v != "0123456789" || list.Len() != 100 {
+		t.Errorf("Expected the elements to be kept, got %v and length %d", v, list.Len())
 	}
 }
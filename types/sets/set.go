@@ -0,0 +1,167 @@
+package sets
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync/atomic"
+)
+
+// maxIntsetEntries is the number of members past which a set of integers is converted to a hashtable.
+var maxIntsetEntries atomic.Int64
+
+func init() {
+	maxIntsetEntries.Store(512)
+}
+
+// SetMaxIntsetEntries sets the number of members past which a set of integers is converted to a hashtable.
+func SetMaxIntsetEntries(n int64) {
+	maxIntsetEntries.Store(n)
+}
+
+// Set is the value stored for a set. A set whose members are all integers keeps them sorted
+// in a slice, an intset, and is converted to a map once a member isn't an integer or it grows
+// past set-max-intset-entries.
+type Set struct {
+	// ints holds the members in ascending order while members is nil.
+	ints    []int64
+	members map[string]bool
+}
+
+func NewSet() *Set {
+	return &Set{}
+}
+
+// integer returns the integer a member is the canonical representation of, false if it isn't one,
+// so members like "007" that read back differently stay strings.
+func integer(member string) (int64, bool) {
+	n, err := strconv.ParseInt(member, 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != member {
+		return 0, false
+	}
+	return n, true
+}
+
+func (s *Set) Len() int {
+	if s.members == nil {
+		return len(s.ints)
+	}
+	return len(s.members)
+}
+
+func (s *Set) Has(member string) bool {
+	if s.members != nil {
+		return s.members[member]
+	}
+	n, ok := integer(member)
+	if !ok {
+		return false
+	}
+	_, found := slices.BinarySearch(s.ints, n)
+	return found
+}
+
+// Add adds a member, it returns false if it is already in the set.
+func (s *Set) Add(member string) bool {
+	if s.members == nil {
+		if n, ok := integer(member); ok {
+			i, found := slices.BinarySearch(s.ints, n)
+			if found {
+				return false
+			}
+			if int64(len(s.ints)) < maxIntsetEntries.Load() {
+				s.ints = slices.Insert(s.ints, i, n)
+				return true
+			}
+		}
+		s.convert()
+	}
+	if s.members[member] {
+		return false
+	}
+	s.members[member] = true
+	return true
+}
+
+// convert moves the members of the intset to a map.
+func (s *Set) convert() {
+	s.members = make(map[string]bool, len(s.ints)+1)
+	for _, n := range s.ints {
+		s.members[strconv.FormatInt(n, 10)] = true
+	}
+	s.ints = nil
+}
+
+// Remove removes a member, it returns false if it isn't in the set.
+func (s *Set) Remove(member string) bool {
+	if s.members != nil {
+		if !s.members[member] {
+			return false
+		}
+		delete(s.members, member)
+		return true
+	}
+	n, ok := integer(member)
+	if !ok {
+		return false
+	}
+	i, found := slices.BinarySearch(s.ints, n)
+	if found {
+		s.ints = slices.Delete(s.ints, i, i+1)
+	}
+	return found
+}
+
+// Each calls fn with the members of the set until fn returns false.
+func (s *Set) Each(fn func(member string) bool) {
+	if s.members != nil {
+		for member := range s.members {
+			if !fn(member) {
+				return
+			}
+		}
+		return
+	}
+	for _, n := range s.ints {
+		if !fn(strconv.FormatInt(n, 10)) {
+			return
+		}
+	}
+}
+
+// Members returns the members of the set.
+func (s *Set) Members() []string {
+	members := make([]string, 0, s.Len())
+	s.Each(func(member string) bool {
+		members = append(members, member)
+		return true
+	})
+	return members
+}
+
+// Random returns up to count distinct members picked at random, each member as likely as any other.
+func (s *Set) Random(count int64) []string {
+	members := s.Members()
+	if count < int64(len(members)) {
+		rand.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+		members = members[:count]
+	}
+	return members
+}
+
+// Clone returns a copy of the set.
+func (s *Set) Clone() *Set {
+	return &Set{ints: slices.Clone(s.ints), members: maps.Clone(s.members)}
+}
+
+// Clear removes every member.
+func (s *Set) Clear() {
+	s.ints = nil
+	clear(s.members)
+}
+
+// Intset reports whether the members are held in an intset rather than a hashtable.
+func (s *Set) Intset() bool {
+	return s.members == nil
+}
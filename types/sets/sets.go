@@ -1,95 +1,334 @@
 package sets
 
-import "github.com/divy-sh/animus/store"
+import (
+	"math/rand"
+
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+// get returns the set stored at key. The caller must hold a lock on the key.
+func get(key string) (*Set, bool) {
+	return store.Get[string, *Set](key)
+}
+
+// deleteIfEmpty removes a set that has no members left, sets are never stored empty.
+// The caller must hold the write lock of the key.
+func deleteIfEmpty(key string, set *Set) {
+	if set.Len() == 0 {
+		store.Delete(key)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+	}
+}
+
+// storeResult stores the result of a set operation at key, replacing its value.
+// An empty result deletes the key. The caller must hold the write lock of the key.
+func storeResult(key string, members []string, event string) int64 {
+	if len(members) == 0 {
+		if store.Delete(key) {
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+		}
+		return 0
+	}
+	set := NewSet()
+	for _, member := range members {
+		set.Add(member)
+	}
+	store.Set(key, set)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_SET, event, key)
+	return int64(len(members))
+}
 
 func Sadd(key string, values []string) int64 {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	hashVal, ok := store.Get[string, map[string]bool](key)
-	count := 0
+	set, ok := get(key)
 	if !ok {
-		hashVal = map[string]bool{}
-		for _, value := range values {
-			hashVal[value] = true
+		set = NewSet()
+		store.Set(key, set)
+	}
+	count := 0
+	for _, value := range values {
+		if set.Add(value) {
 			count++
 		}
-	} else {
-		for _, value := range values {
-			if _, exists := hashVal[value]; exists {
-				continue
-			}
-			hashVal[value] = true
+	}
+	if count > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_SET, "sadd", key)
+	}
+	return int64(count)
+}
+
+// Srem removes members from a set and returns the number of members removed.
+func Srem(key string, members []string) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	set, ok := get(key)
+	if !ok {
+		return 0
+	}
+	var count int64
+	for _, member := range members {
+		if set.Remove(member) {
 			count++
 		}
 	}
-	store.Set(key, hashVal)
-	return int64(count)
+	if count > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_SET, "srem", key)
+		deleteIfEmpty(key, set)
+	}
+	return count
 }
 
 func Scard(key string) int64 {
 	store.RLockKeys(key)
 	defer store.RUnlockKeys(key)
 
-	hashVal, ok := store.Get[string, map[string]bool](key)
+	set, ok := get(key)
 	if !ok {
 		return 0
 	}
-	return int64(len(hashVal))
+	return int64(set.Len())
+}
+
+// Smembers returns the members of a set.
+func Smembers(key string) []string {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	set, ok := get(key)
+	if !ok {
+		return []string{}
+	}
+	return set.Members()
 }
 
 func Sdiff(keys []string) []string {
 	store.RLockKeys(keys...)
 	defer store.RUnlockKeys(keys...)
+	return diff(keys)
+}
 
+// diff returns the members of the first set that are in none of the others.
+// The caller must hold a lock on every key.
+func diff(keys []string) []string {
 	if len(keys) == 0 {
 		return []string{}
 	}
-	baseSet, ok := store.Get[string, map[string]bool](keys[0])
+	baseSet, ok := get(keys[0])
 	if !ok {
 		return []string{}
 	}
-	resultSet := map[string]bool{}
-	for k := range baseSet {
-		resultSet[k] = true
-	}
+	others := make([]*Set, 0, len(keys)-1)
 	for _, key := range keys[1:] {
-		otherSet, ok := store.Get[string, map[string]bool](key)
-		if !ok {
-			continue
-		}
-		for k := range otherSet {
-			delete(resultSet, k)
+		if otherSet, ok := get(key); ok {
+			others = append(others, otherSet)
 		}
 	}
 	diffValues := []string{}
-	for k := range resultSet {
-		diffValues = append(diffValues, k)
-	}
+	baseSet.Each(func(member string) bool {
+		for _, otherSet := range others {
+			if otherSet.Has(member) {
+				return true
+			}
+		}
+		diffValues = append(diffValues, member)
+		return true
+	})
 	return diffValues
 }
 
+// SdiffStore stores the difference of the sets at destKey and returns its size.
 func SdiffStore(destKey string, keys []string) int64 {
-	diffValues := Sdiff(keys)
-	store.LockKeys(destKey)
-	defer store.UnlockKeys(destKey)
+	allKeys := append([]string{destKey}, keys...)
+	store.LockKeys(allKeys...)
+	defer store.UnlockKeys(allKeys...)
+	return storeResult(destKey, diff(keys), "sdiffstore")
+}
+
+// Sinter returns the members that are in every set. A missing key is an empty set.
+func Sinter(keys []string) []string {
+	store.RLockKeys(keys...)
+	defer store.RUnlockKeys(keys...)
+	return inter(keys, 0)
+}
+
+// inter returns the members that are in every set, at most limit of them if limit is positive.
+// The caller must hold a lock on every key.
+func inter(keys []string, limit int) []string {
+	sets := make([]*Set, len(keys))
+	smallest := 0
+	for i, key := range keys {
+		set, ok := get(key)
+		if !ok {
+			return []string{}
+		}
+		sets[i] = set
+		if set.Len() < sets[smallest].Len() {
+			smallest = i
+		}
+	}
+	members := []string{}
+	if len(sets) == 0 {
+		return members
+	}
+	// Checking the members of the smallest set keeps the work proportional to its size.
+	sets[smallest].Each(func(member string) bool {
+		for _, set := range sets {
+			if !set.Has(member) {
+				return true
+			}
+		}
+		members = append(members, member)
+		return limit <= 0 || len(members) < limit
+	})
+	return members
+}
+
+// SinterStore stores the intersection of the sets at destKey and returns its size.
+func SinterStore(destKey string, keys []string) int64 {
+	allKeys := append([]string{destKey}, keys...)
+	store.LockKeys(allKeys...)
+	defer store.UnlockKeys(allKeys...)
+	return storeResult(destKey, inter(keys, 0), "sinterstore")
+}
+
+// Sintercard returns the size of the intersection of the sets, counting no further than limit
+// if limit is positive.
+func Sintercard(keys []string, limit int) int64 {
+	store.RLockKeys(keys...)
+	defer store.RUnlockKeys(keys...)
+	return int64(len(inter(keys, limit)))
+}
+
+// Sunion returns the members that are in any of the sets.
+func Sunion(keys []string) []string {
+	store.RLockKeys(keys...)
+	defer store.RUnlockKeys(keys...)
+	return union(keys)
+}
+
+// union returns the members that are in any of the sets. The caller must hold a lock on every key.
+func union(keys []string) []string {
+	resultSet := map[string]bool{}
+	for _, key := range keys {
+		if set, ok := get(key); ok {
+			set.Each(func(member string) bool {
+				resultSet[member] = true
+				return true
+			})
+		}
+	}
+	members := make([]string, 0, len(resultSet))
+	for member := range resultSet {
+		members = append(members, member)
+	}
+	return members
+}
+
+// SunionStore stores the union of the sets at destKey and returns its size.
+func SunionStore(destKey string, keys []string) int64 {
+	allKeys := append([]string{destKey}, keys...)
+	store.LockKeys(allKeys...)
+	defer store.UnlockKeys(allKeys...)
+	return storeResult(destKey, union(keys), "sunionstore")
+}
+
+// Spop removes and returns up to count random members of a set.
+func Spop(key string, count int64) []string {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	set, ok := get(key)
+	if !ok || count <= 0 {
+		return []string{}
+	}
+	members := set.Random(count)
+	for _, member := range members {
+		set.Remove(member)
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_SET, "spop", key)
+	deleteIfEmpty(key, set)
+	return members
+}
+
+// MaxRandomCount is the largest number of members SRANDMEMBER returns for a negative count,
+// as they are all held in memory until the reply is written.
+const MaxRandomCount = 1 << 20
+
+// Srandmember returns random members of a set without removing them. A positive count
+// returns distinct members, at most as many as the set has, while a negative count
+// returns -count members that may repeat. The caller must keep -count within MaxRandomCount.
+func Srandmember(key string, count int64) []string {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
 
-	hashVal := map[string]bool{}
-	for _, value := range diffValues {
-		hashVal[value] = true
+	set, ok := get(key)
+	if !ok || count == 0 {
+		return []string{}
+	}
+	if count > 0 {
+		return set.Random(count)
+	}
+	all := set.Members()
+	var members []string
+	for ; count < 0; count++ {
+		members = append(members, all[rand.Intn(len(all))])
 	}
-	store.Set(destKey, hashVal)
-	return int64(len(diffValues))
+	return members
+}
+
+// Smove moves a member from the source set to the destination set, it returns 1 if the
+// member was moved and 0 if it isn't in the source set.
+func Smove(source, destination, member string) int64 {
+	store.LockKeys(source, destination)
+	defer store.UnlockKeys(source, destination)
+
+	sourceSet, ok := get(source)
+	if !ok || !sourceSet.Has(member) {
+		return 0
+	}
+	if source == destination {
+		return 1
+	}
+	sourceSet.Remove(member)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_SET, "srem", source)
+	deleteIfEmpty(source, sourceSet)
+	destinationSet, ok := get(destination)
+	if !ok {
+		destinationSet = NewSet()
+		store.Set(destination, destinationSet)
+	}
+	destinationSet.Add(member)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_SET, "sadd", destination)
+	return 1
 }
 
 func Sismember(key string, value string) bool {
 	store.RLockKeys(key)
 	defer store.RUnlockKeys(key)
 
-	hashVal, ok := store.Get[string, map[string]bool](key)
+	set, ok := get(key)
 	if !ok {
 		return false
 	}
-	_, exists := hashVal[value]
-	return exists
+	return set.Has(value)
+}
+
+// Smismember reports for each member whether it is in the set.
+func Smismember(key string, members []string) []bool {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	result := make([]bool, len(members))
+	set, ok := get(key)
+	if !ok {
+		return result
+	}
+	for i, member := range members {
+		result[i] = set.Has(member)
+	}
+	return result
 }
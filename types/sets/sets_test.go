@@ -1,6 +1,8 @@
 package sets
 
 import (
+	"slices"
+	"strconv"
 	"testing"
 )
 
@@ -220,3 +222,204 @@ func TestSismemberEmptySet(t *testing.T) {
 		t.Errorf("Expected %s to not be a member of the empty set", value)
 	}
 }
+
+func TestSrem(t *testing.T) {
+	key := "TestSrem"
+	Sadd(key, []string{"value1", "value2"})
+
+	if count := Srem(key, []string{"value1", "missing"}); count != 1 {
+		t.Errorf("Expected 1 element removed, got %d", count)
+	}
+	if count := Srem(key, []string{"value2"}); count != 1 {
+		t.Errorf("Expected 1 element removed, got %d", count)
+	}
+	if _, ok := get(key); ok {
+		t.Errorf("Expected the empty set to be deleted")
+	}
+}
+
+func TestSmembers(t *testing.T) {
+	key := "TestSmembers"
+	Sadd(key, []string{"value1", "value2"})
+
+	members := Smembers(key)
+	slices.Sort(members)
+	if !slices.Equal(members, []string{"value1", "value2"}) {
+		t.Errorf("Unexpected members: %v", members)
+	}
+	if members := Smembers("TestSmembersMissing"); len(members) != 0 {
+		t.Errorf("Expected no members, got %v", members)
+	}
+}
+
+func TestSinterAndSunion(t *testing.T) {
+	Sadd("TestSinterAndSunion1", []string{"a", "b", "c"})
+	Sadd("TestSinterAndSunion2", []string{"b", "c", "d"})
+	Sadd("TestSinterAndSunion3", []string{"c", "d", "e"})
+	keys := []string{"TestSinterAndSunion1", "TestSinterAndSunion2", "TestSinterAndSunion3"}
+
+	if inter := Sinter(keys); !slices.Equal(inter, []string{"c"}) {
+		t.Errorf("Unexpected intersection: %v", inter)
+	}
+	if inter := Sinter(append(keys, "TestSinterAndSunionMissing")); len(inter) != 0 {
+		t.Errorf("Expected an empty intersection with a missing set, got %v", inter)
+	}
+	union := Sunion(append(keys, "TestSinterAndSunionMissing"))
+	slices.Sort(union)
+	if !slices.Equal(union, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("Unexpected union: %v", union)
+	}
+	if count := Sintercard(keys[:2], 0); count != 2 {
+		t.Errorf("Expected 2 members in the intersection, got %d", count)
+	}
+	if count := Sintercard(keys[:2], 1); count != 1 {
+		t.Errorf("Expected the count to stop at the limit, got %d", count)
+	}
+}
+
+func TestSinterStoreAndSunionStore(t *testing.T) {
+	Sadd("TestSinterStore1", []string{"a", "b"})
+	Sadd("TestSinterStore2", []string{"b", "c"})
+
+	if count := SinterStore("TestSinterStoreDest", []string{"TestSinterStore1", "TestSinterStore2"}); count != 1 {
+		t.Errorf("Expected 1 member stored, got %d", count)
+	}
+	if count := SunionStore("TestSinterStore1", []string{"TestSinterStore1", "TestSinterStore2"}); count != 3 {
+		t.Errorf("Expected 3 members stored, got %d", count)
+	}
+	if count := Scard("TestSinterStore1"); count != 3 {
+		t.Errorf("Expected the destination to be overwritten, got %d members", count)
+	}
+	if count := SinterStore("TestSinterStoreDest", []string{"TestSinterStore2", "TestSinterStoreMissing"}); count != 0 {
+		t.Errorf("Expected an empty intersection, got %d", count)
+	}
+	if _, ok := get("TestSinterStoreDest"); ok {
+		t.Errorf("Expected an empty result to delete the destination")
+	}
+}
+
+func TestSpop(t *testing.T) {
+	key := "TestSpop"
+	Sadd(key, []string{"a", "b", "c"})
+
+	popped := Spop(key, 2)
+	if len(popped) != 2 || Scard(key) != 1 {
+		t.Errorf("Expected 2 members popped and 1 left, got %v and %d", popped, Scard(key))
+	}
+	for _, member := range popped {
+		if Sismember(key, member) {
+			t.Errorf("Expected %s to be removed", member)
+		}
+	}
+	if popped := Spop(key, 5); len(popped) != 1 {
+		t.Errorf("Expected the last member, got %v", popped)
+	}
+	if _, ok := get(key); ok {
+		t.Errorf("Expected the empty set to be deleted")
+	}
+}
+
+func TestSrandmember(t *testing.T) {
+	key := "TestSrandmember"
+	Sadd(key, []string{"a", "b", "c"})
+
+	members := Srandmember(key, 5)
+	slices.Sort(members)
+	if !slices.Equal(members, []string{"a", "b", "c"}) {
+		t.Errorf("Expected every member once, got %v", members)
+	}
+	if members := Srandmember(key, -7); len(members) != 7 {
+		t.Errorf("Expected 7 members, got %v", members)
+	}
+	if Scard(key) != 3 {
+		t.Errorf("Expected the set to be unchanged")
+	}
+}
+
+func TestSetRandomIsUniform(t *testing.T) {
+	const members, draws = 50, 50000
+	hashtable, intset := NewSet(), NewSet()
+	for i := range members {
+		hashtable.Add("member" + strconv.Itoa(i))
+		intset.Add(strconv.Itoa(i))
+	}
+	for _, set := range []*Set{hashtable, intset} {
+		counts := map[string]int{}
+		for range draws {
+			counts[set.Random(1)[0]]++
+		}
+		// Each member is expected draws/members = 1000 times, with a standard deviation of about 31.
+		for member, count := range counts {
+			if count < 800 || count > 1200 {
+				t.Errorf("expected %s to be picked about 1000 times, got %d", member, count)
+			}
+		}
+		if len(counts) != members {
+			t.Errorf("expected all %d members to be picked, got %d", members, len(counts))
+		}
+	}
+}
+
+func TestSmove(t *testing.T) {
+	Sadd("TestSmoveSource", []string{"a"})
+	Sadd("TestSmoveDest", []string{"b"})
+
+	if moved := Smove("TestSmoveSource", "TestSmoveDest", "missing"); moved != 0 {
+		t.Errorf("Expected nothing moved, got %d", moved)
+	}
+	if moved := Smove("TestSmoveSource", "TestSmoveDest", "a"); moved != 1 {
+		t.Errorf("Expected the member moved, got %d", moved)
+	}
+	if !Sismember("TestSmoveDest", "a") {
+		t.Errorf("Expected the member in the destination")
+	}
+	if _, ok := get("TestSmoveSource"); ok {
+		t.Errorf("Expected the empty source to be deleted")
+	}
+	if moved := Smove("TestSmoveDest", "TestSmoveDest", "a"); moved != 1 || !Sismember("TestSmoveDest", "a") {
+		t.Errorf("Expected moving to the same set to keep the member")
+	}
+}
+
+func TestSmismember(t *testing.T) {
+	Sadd("TestSmismember", []string{"a", "b"})
+	if found := Smismember("TestSmismember", []string{"a", "c", "b"}); !slices.Equal(found, []bool{true, false, true}) {
+		t.Errorf("Unexpected result: %v", found)
+	}
+}
+
+func TestSet_Intset(t *testing.T) {
+	set := NewSet()
+	for _, member := range []string{"3", "-1", "2", "3"} {
+		set.Add(member)
+	}
+	if !set.Intset() || !slices.Equal(set.Members(), []string{"-1", "2", "3"}) {
+		t.Fatalf("Expected an intset [-1 2 3], got %v", set.Members())
+	}
+	if set.Has("02") || !set.Has("2") {
+		t.Errorf("Expected only the canonical form of an integer to be a member")
+	}
+	if !set.Remove("-1") || set.Remove("-1") || set.Len() != 2 {
+		t.Errorf("Expected -1 to be removed once")
+	}
+
+	set.Add("02")
+	if set.Intset() || !set.Has("02") || !set.Has("2") || set.Len() != 3 {
+		t.Errorf("Expected a hashtable holding 02, 2 and 3, got %v", set.Members())
+	}
+}
+
+func TestSet_IntsetConvertsPastMaxEntries(t *testing.T) {
+	SetMaxIntsetEntries(2)
+	t.Cleanup(func() { SetMaxIntsetEntries(512) })
+
+	key := "TestSet_IntsetConvertsPastMaxEntries"
+	Sadd(key, []string{"1", "2"})
+	if set, _ := get(key); !set.Intset() {
+		t.Fatalf("Expected an intset")
+	}
+	Sadd(key, []string{"3"})
+	if set, _ := get(key); set.Intset() || set.Len() != 3 {
+		t.Fatalf("Expected a hashtable of 3 members")
+	}
+}
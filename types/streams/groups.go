@@ -0,0 +1,551 @@
+package streams
+
+import (
+	"errors"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+// ClaimOptions are the options of XCLAIM.
+type ClaimOptions struct {
+	// DeliveredAt is the delivery time set on the claimed entries, 0 for now.
+	DeliveredAt int64
+	// RetryCount is the delivery count set on the claimed entries, -1 to increment it.
+	RetryCount int64
+	// Force claims entries that aren't pending in the group as long as they exist.
+	Force bool
+	// JustID leaves the delivery count alone and returns entries without their fields.
+	JustID bool
+	// LastID is set as the last delivered ID of the group if it is greater.
+	LastID ID
+}
+
+// PendingSummary sums up the entries pending in a group.
+type PendingSummary struct {
+	Count     int64
+	Smallest  ID
+	Largest   ID
+	Consumers []ConsumerPending
+}
+
+// ConsumerPending is the number of entries pending for a consumer.
+type ConsumerPending struct {
+	Name  string
+	Count int64
+}
+
+// PendingInfo is an entry pending in a group, idle for Idle milliseconds.
+type PendingInfo struct {
+	ID         ID
+	Consumer   string
+	Idle       int64
+	Deliveries int64
+}
+
+// StreamInfo is the XINFO STREAM reply, First and Last are nil for an empty stream.
+type StreamInfo struct {
+	Length       int64
+	LastID       ID
+	MaxDeletedID ID
+	EntriesAdded int64
+	FirstID      ID
+	Groups       int64
+	First        *Entry
+	Last         *Entry
+}
+
+// GroupInfo is a group of the XINFO GROUPS reply. EntriesRead is -1 and HasLag false when unknown.
+type GroupInfo struct {
+	Name        string
+	Consumers   int64
+	Pending     int64
+	LastID      ID
+	EntriesRead int64
+	Lag         int64
+	HasLag      bool
+}
+
+// ConsumerInfo is a consumer of the XINFO CONSUMERS reply, Inactive is -1 if it never read
+// or claimed an entry.
+type ConsumerInfo struct {
+	Name     string
+	Pending  int64
+	Idle     int64
+	Inactive int64
+}
+
+// getGroup returns the stream stored at key and one of its consumer groups.
+func getGroup(key, group string) (*Stream, *Group, error) {
+	s, ok := get(key)
+	if !ok {
+		return nil, nil, errors.New(common.ERR_NO_GROUP)
+	}
+	g, ok := s.groups[group]
+	if !ok {
+		return nil, nil, errors.New(common.ERR_NO_GROUP)
+	}
+	return s, g, nil
+}
+
+// groupID parses the ID a group is set to, $ standing for the last ID of the stream.
+func (s *Stream) groupID(spec string) (ID, error) {
+	if spec == "$" {
+		return s.LastID, nil
+	}
+	return ParseID(spec)
+}
+
+// advance records that a group read the entry with an ID.
+func (s *Stream) advance(g *Group, id ID) {
+	if id.Compare(g.LastID) <= 0 {
+		return
+	}
+	if g.EntriesRead >= 0 && !s.tombstonesAfter(id) {
+		g.EntriesRead++
+	} else if read, ok := s.entriesReadUntil(id); ok {
+		g.EntriesRead = read
+	} else {
+		g.EntriesRead = -1
+	}
+	g.LastID = id
+}
+
+// XGroupCreate creates a consumer group delivering the entries after id, $ for the entries
+// added from now on. A missing stream is created if mkStream is set. entriesRead sets the
+// number of entries the group read, it is unknown if nil.
+func XGroupCreate(key, group, id string, mkStream bool, entriesRead *int64) error {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, exists := get(key)
+	if !exists {
+		if !mkStream {
+			return errors.New(common.ERR_XGROUP_KEY)
+		}
+		s = NewStream()
+	}
+	lastID, err := s.groupID(id)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.groups[group]; ok {
+		return errors.New(common.ERR_BUSY_GROUP)
+	}
+	read := int64(-1)
+	if entriesRead != nil {
+		read = *entriesRead
+	}
+	s.SetGroup(group, NewGroup(lastID, read))
+	if !exists {
+		store.Set(key, s)
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xgroup-create", key)
+	return nil
+}
+
+// XGroupSetID sets the last delivered ID of a consumer group, $ for the last entry of the stream.
+func XGroupSetID(key, group, id string, entriesRead *int64) error {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return errors.New(common.ERR_XGROUP_KEY)
+	}
+	lastID, err := s.groupID(id)
+	if err != nil {
+		return err
+	}
+	g, ok := s.groups[group]
+	if !ok {
+		return errors.New(common.ERR_NO_GROUP)
+	}
+	g.LastID, g.EntriesRead = lastID, -1
+	if entriesRead != nil {
+		g.EntriesRead = *entriesRead
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xgroup-setid", key)
+	return nil
+}
+
+// XGroupDestroy destroys a consumer group, it returns 0 if there is no such group.
+// Clients blocked reading from the group are woken up.
+func XGroupDestroy(key, group string) (int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return 0, errors.New(common.ERR_XGROUP_KEY)
+	}
+	if _, ok := s.groups[group]; !ok {
+		return 0, nil
+	}
+	delete(s.groups, group)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xgroup-destroy", key)
+	signal(key)
+	return 1, nil
+}
+
+// XGroupCreateConsumer creates a consumer in a group, it returns 0 if it already exists.
+func XGroupCreateConsumer(key, group, consumer string) (int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	if _, ok := get(key); !ok {
+		return 0, errors.New(common.ERR_XGROUP_KEY)
+	}
+	_, g, err := getGroup(key, group)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := g.Consumers[consumer]; ok {
+		return 0, nil
+	}
+	g.consumer(consumer, now())
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xgroup-createconsumer", key)
+	return 1, nil
+}
+
+// XGroupDelConsumer deletes a consumer from a group along with its pending entries, it returns
+// the number of entries that were pending.
+func XGroupDelConsumer(key, group, consumer string) (int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	if _, ok := get(key); !ok {
+		return 0, errors.New(common.ERR_XGROUP_KEY)
+	}
+	_, g, err := getGroup(key, group)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := g.Consumers[consumer]; !ok {
+		return 0, nil
+	}
+	pending := g.removeConsumerPending(consumer)
+	delete(g.Consumers, consumer)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xgroup-delconsumer", key)
+	return pending, nil
+}
+
+// XReadGroup reads entries from the streams stored at keys on behalf of a consumer of a group.
+// The ID > reads up to count entries, all of them if count is negative, never delivered to the
+// group and adds them to the pending entries of the consumer unless noAck is set. Streams
+// without such entries are left out. Any other ID reads the entries pending for the consumer
+// after it, the ones deleted from the stream since being returned without fields.
+func XReadGroup(group, consumer string, keys, ids []string, count int64, noAck bool) ([]StreamEntries, error) {
+	store.LockKeys(keys...)
+	defer store.UnlockKeys(keys...)
+
+	history := make([]ID, len(keys))
+	for i, key := range keys {
+		if _, _, err := getGroup(key, group); err != nil {
+			return nil, err
+		}
+		if ids[i] == ">" {
+			continue
+		}
+		id, err := ParseID(ids[i])
+		if err != nil {
+			return nil, err
+		}
+		history[i] = id
+	}
+	t := now()
+	results := []StreamEntries{}
+	for i, key := range keys {
+		s, g, _ := getGroup(key, group)
+		c, created := g.consumer(consumer, t)
+		if created {
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xgroup-createconsumer", key)
+		}
+		if ids[i] != ">" {
+			results = append(results, StreamEntries{Key: key, Entries: s.pendingEntries(g, consumer, history[i], count, t)})
+			continue
+		}
+		start, ok := g.LastID.next()
+		if !ok {
+			continue
+		}
+		entries := s.rangeEntries(start, MaxID, count, false)
+		if len(entries) == 0 {
+			continue
+		}
+		for _, e := range entries {
+			s.advance(g, e.ID)
+			if !noAck {
+				g.AddPending(e.ID, &PendingEntry{Consumer: consumer, DeliveredAt: t, Deliveries: 1})
+			}
+		}
+		c.ActiveAt = t
+		results = append(results, StreamEntries{Key: key, Entries: entries})
+	}
+	return results, nil
+}
+
+// pendingEntries delivers again up to count entries pending for a consumer after an ID.
+func (s *Stream) pendingEntries(g *Group, consumer string, after ID, count int64, t int64) []Entry {
+	entries := []Entry{}
+	start, ok := after.next()
+	if !ok {
+		return entries
+	}
+	g.EachPending(start, func(id ID, p *PendingEntry) bool {
+		if count >= 0 && int64(len(entries)) == count {
+			return false
+		}
+		if p.Consumer != consumer {
+			return true
+		}
+		e, ok := s.lookup(id)
+		if !ok {
+			e = Entry{ID: id}
+		}
+		p.DeliveredAt = t
+		p.Deliveries++
+		entries = append(entries, e)
+		return true
+	})
+	return entries
+}
+
+// XAck acknowledges entries pending in a group and returns the number acknowledged.
+func XAck(key, group string, ids []ID) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	_, g, err := getGroup(key, group)
+	if err != nil {
+		return 0
+	}
+	var acked int64
+	for _, id := range ids {
+		if g.removePending(id) {
+			acked++
+		}
+	}
+	return acked
+}
+
+// XPendingSummary returns the number of entries pending in a group, their lowest and highest
+// IDs and the number pending for each consumer, in order of name.
+func XPendingSummary(key, group string) (PendingSummary, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	_, g, err := getGroup(key, group)
+	if err != nil {
+		return PendingSummary{}, err
+	}
+	summary := PendingSummary{Count: int64(g.PendingLen()), Consumers: []ConsumerPending{}}
+	ids := g.pendingIDs
+	if len(ids) == 0 {
+		return summary, nil
+	}
+	summary.Smallest, summary.Largest = ids[0], ids[len(ids)-1]
+	counts := map[string]int64{}
+	for _, p := range g.pending {
+		counts[p.Consumer]++
+	}
+	for _, name := range sortedNames(counts) {
+		summary.Consumers = append(summary.Consumers, ConsumerPending{Name: name, Count: counts[name]})
+	}
+	return summary, nil
+}
+
+// XPending returns up to count entries pending in a group with IDs from start to end, idle for
+// at least minIdle milliseconds and, unless consumer is empty, pending for that consumer.
+func XPending(key, group string, start, end ID, count int64, consumer string, minIdle int64) ([]PendingInfo, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	_, g, err := getGroup(key, group)
+	if err != nil {
+		return nil, err
+	}
+	t := now()
+	pending := []PendingInfo{}
+	g.EachPending(start, func(id ID, p *PendingEntry) bool {
+		if int64(len(pending)) >= count || id.Compare(end) > 0 {
+			return false
+		}
+		idle := t - p.DeliveredAt
+		if idle >= minIdle && (consumer == "" || p.Consumer == consumer) {
+			pending = append(pending, PendingInfo{ID: id, Consumer: p.Consumer, Idle: idle, Deliveries: p.Deliveries})
+		}
+		return true
+	})
+	return pending, nil
+}
+
+// claim gives a pending entry to a consumer.
+func claim(p *PendingEntry, consumer string, deliveredAt, retryCount int64, justID bool) {
+	p.Consumer, p.DeliveredAt = consumer, deliveredAt
+	if retryCount >= 0 {
+		p.Deliveries = retryCount
+	} else if !justID {
+		p.Deliveries++
+	}
+}
+
+// XClaim gives to a consumer the entries among ids pending in a group for at least minIdle
+// milliseconds and returns them. Entries deleted from the stream are removed from the pending
+// entries instead.
+func XClaim(key, group, consumer string, minIdle int64, ids []ID, opts ClaimOptions) ([]Entry, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, g, err := getGroup(key, group)
+	if err != nil {
+		return nil, err
+	}
+	t := now()
+	deliveredAt := opts.DeliveredAt
+	if deliveredAt == 0 {
+		deliveredAt = t
+	}
+	if opts.LastID.Compare(g.LastID) > 0 {
+		g.LastID = opts.LastID
+	}
+	c, _ := g.consumer(consumer, t)
+	claimed := []Entry{}
+	for _, id := range ids {
+		e, exists := s.lookup(id)
+		p, pending := g.Pending(id)
+		switch {
+		case !pending && (!opts.Force || !exists):
+			continue
+		case !exists:
+			g.removePending(id)
+			continue
+		case !pending:
+			p = &PendingEntry{}
+			g.AddPending(id, p)
+		case t-p.DeliveredAt < minIdle:
+			continue
+		}
+		claim(p, consumer, deliveredAt, opts.RetryCount, opts.JustID)
+		claimed = append(claimed, e)
+	}
+	if len(claimed) > 0 {
+		c.ActiveAt = t
+	}
+	return claimed, nil
+}
+
+// XAutoClaim gives to a consumer up to count entries pending in a group for at least minIdle
+// milliseconds, looking at up to 10 times count entries from start. It returns the ID to start
+// the next call from, 0-0 once every pending entry was looked at, the claimed entries and the
+// IDs of the entries deleted from the stream, which are removed from the pending entries.
+func XAutoClaim(key, group, consumer string, minIdle int64, start ID, count int64, justID bool) (ID, []Entry, []ID, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, g, err := getGroup(key, group)
+	if err != nil {
+		return ID{}, nil, nil, err
+	}
+	t := now()
+	c, _ := g.consumer(consumer, t)
+	claimed, deleted := []Entry{}, []ID{}
+	attempts := count * 10
+	next := ID{}
+	g.EachPending(start, func(id ID, p *PendingEntry) bool {
+		if attempts == 0 || int64(len(claimed)) == count {
+			next = id
+			return false
+		}
+		attempts--
+		e, exists := s.lookup(id)
+		if !exists {
+			deleted = append(deleted, id)
+			return true
+		}
+		if t-p.DeliveredAt >= minIdle {
+			claim(p, consumer, t, -1, justID)
+			claimed = append(claimed, e)
+		}
+		return true
+	})
+	for _, id := range deleted {
+		g.removePending(id)
+	}
+	if len(claimed) > 0 {
+		c.ActiveAt = t
+	}
+	return next, claimed, deleted, nil
+}
+
+// XInfoStream describes the stream stored at key.
+func XInfoStream(key string) (StreamInfo, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return StreamInfo{}, errors.New(common.ERR_KEY_NOT_FOUND)
+	}
+	info := StreamInfo{
+		Length:       int64(s.Len()),
+		LastID:       s.LastID,
+		MaxDeletedID: s.MaxDeletedID,
+		EntriesAdded: s.EntriesAdded,
+		Groups:       int64(len(s.groups)),
+	}
+	if s.Len() > 0 {
+		first, last := s.entries[0], s.entries[s.Len()-1]
+		info.FirstID, info.First, info.Last = first.ID, &first, &last
+	}
+	return info, nil
+}
+
+// XInfoGroups describes the consumer groups of the stream stored at key, in order of name.
+func XInfoGroups(key string) ([]GroupInfo, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return nil, errors.New(common.ERR_KEY_NOT_FOUND)
+	}
+	groups := []GroupInfo{}
+	for _, name := range s.GroupNames() {
+		g := s.groups[name]
+		lag, hasLag := s.Lag(g)
+		groups = append(groups, GroupInfo{
+			Name:        name,
+			Consumers:   int64(len(g.Consumers)),
+			Pending:     int64(g.PendingLen()),
+			LastID:      g.LastID,
+			EntriesRead: g.EntriesRead,
+			Lag:         lag,
+			HasLag:      hasLag,
+		})
+	}
+	return groups, nil
+}
+
+// XInfoConsumers describes the consumers of a group, in order of name.
+func XInfoConsumers(key, group string) ([]ConsumerInfo, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	_, g, err := getGroup(key, group)
+	if err != nil {
+		return nil, err
+	}
+	t := now()
+	consumers := []ConsumerInfo{}
+	for _, name := range sortedNames(g.Consumers) {
+		c := g.Consumers[name]
+		info := ConsumerInfo{Name: name, Pending: g.pendingCount(name), Idle: t - c.SeenAt, Inactive: -1}
+		if c.ActiveAt >= 0 {
+			info.Inactive = t - c.ActiveAt
+		}
+		consumers = append(consumers, info)
+	}
+	return consumers, nil
+}
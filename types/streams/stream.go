@@ -0,0 +1,416 @@
+package streams
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/divy-sh/animus/common"
+)
+
+// ID identifies a stream entry: the unix time in milliseconds it was added at and a sequence
+// number telling apart the entries added in the same millisecond.
+type ID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+// MaxID is the highest possible entry ID, the + of a range.
+var MaxID = ID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+
+func (id ID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id ID) Compare(other ID) int {
+	return cmp.Or(cmp.Compare(id.Ms, other.Ms), cmp.Compare(id.Seq, other.Seq))
+}
+
+// next returns the ID following id, false if id is MaxID.
+func (id ID) next() (ID, bool) {
+	switch {
+	case id == MaxID:
+		return id, false
+	case id.Seq == math.MaxUint64:
+		return ID{Ms: id.Ms + 1}, true
+	}
+	return ID{Ms: id.Ms, Seq: id.Seq + 1}, true
+}
+
+// prev returns the ID preceding id, false if id is 0-0.
+func (id ID) prev() (ID, bool) {
+	switch {
+	case id == ID{}:
+		return id, false
+	case id.Seq == 0:
+		return ID{Ms: id.Ms - 1, Seq: math.MaxUint64}, true
+	}
+	return ID{Ms: id.Ms, Seq: id.Seq - 1}, true
+}
+
+// parseID parses an ID given as ms-seq, or as ms alone in which case seq is set to defaultSeq.
+func parseID(s string, defaultSeq uint64) (ID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return ID{}, errors.New(common.ERR_STREAM_ID_INVALID)
+	}
+	if !hasSeq {
+		return ID{Ms: ms, Seq: defaultSeq}, nil
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return ID{}, errors.New(common.ERR_STREAM_ID_INVALID)
+	}
+	return ID{Ms: ms, Seq: seq}, nil
+}
+
+// ParseID parses an entry ID, a missing sequence number defaulting to 0.
+func ParseID(s string) (ID, error) {
+	return parseID(s, 0)
+}
+
+// ParseRange parses the start and end of an XRANGE interval. - and + stand for the lowest
+// and highest IDs, an ID prefixed with ( excludes it and the sequence number of an ID
+// given as ms alone defaults to the lowest for start and the highest for end.
+// It returns false if the interval is empty.
+func ParseRange(start, end string) (ID, ID, bool, error) {
+	from, ok, err := parseBound(start, false)
+	if err != nil || !ok {
+		return ID{}, ID{}, false, err
+	}
+	to, ok, err := parseBound(end, true)
+	if err != nil || !ok {
+		return ID{}, ID{}, false, err
+	}
+	return from, to, from.Compare(to) <= 0, nil
+}
+
+func parseBound(s string, end bool) (ID, bool, error) {
+	switch s {
+	case "-":
+		return ID{}, true, nil
+	case "+":
+		return MaxID, true, nil
+	}
+	exclusive := strings.HasPrefix(s, "(")
+	s = strings.TrimPrefix(s, "(")
+	defaultSeq := uint64(0)
+	if end {
+		defaultSeq = math.MaxUint64
+	}
+	id, err := parseID(s, defaultSeq)
+	if err != nil || !exclusive {
+		return id, true, err
+	}
+	var ok bool
+	if end {
+		id, ok = id.prev()
+	} else {
+		id, ok = id.next()
+	}
+	if !ok {
+		return ID{}, false, errors.New(common.ERR_STREAM_INTERVAL)
+	}
+	return id, true, nil
+}
+
+// Entry is a stream entry, Fields holding its field names and values in turn.
+// The Fields of an entry deleted since it was delivered to a consumer are nil.
+type Entry struct {
+	ID     ID
+	Fields []string
+}
+
+// PendingEntry is an entry delivered to a consumer of a group and not acknowledged yet.
+type PendingEntry struct {
+	Consumer string
+	// DeliveredAt is the unix time in milliseconds of the last delivery.
+	DeliveredAt int64
+	Deliveries  int64
+}
+
+// Consumer holds the unix times in milliseconds a consumer last attempted an interaction
+// and last successfully read or claimed an entry, -1 if it never did.
+type Consumer struct {
+	SeenAt   int64
+	ActiveAt int64
+}
+
+// Group is a consumer group, delivering the entries of the stream after LastID to its consumers.
+type Group struct {
+	LastID ID
+	// EntriesRead is the number of entries the group read, counting the ones deleted since,
+	// -1 when it isn't known.
+	EntriesRead int64
+	Consumers   map[string]*Consumer
+	// pending holds the entries delivered and not acknowledged yet, pendingIDs their IDs in
+	// ascending order.
+	pending    map[ID]*PendingEntry
+	pendingIDs []ID
+}
+
+func NewGroup(lastID ID, entriesRead int64) *Group {
+	return &Group{LastID: lastID, EntriesRead: entriesRead, Consumers: map[string]*Consumer{}, pending: map[ID]*PendingEntry{}}
+}
+
+// Pending returns the pending entry with an ID.
+func (g *Group) Pending(id ID) (*PendingEntry, bool) {
+	p, ok := g.pending[id]
+	return p, ok
+}
+
+// PendingLen returns the number of pending entries.
+func (g *Group) PendingLen() int {
+	return len(g.pending)
+}
+
+// AddPending adds a pending entry, replacing the one with the same ID if any.
+func (g *Group) AddPending(id ID, p *PendingEntry) {
+	if _, ok := g.pending[id]; !ok {
+		// Entries are mostly delivered in ascending order, the ID usually goes last.
+		if n := len(g.pendingIDs); n == 0 || g.pendingIDs[n-1].Compare(id) < 0 {
+			g.pendingIDs = append(g.pendingIDs, id)
+		} else {
+			i, _ := slices.BinarySearchFunc(g.pendingIDs, id, ID.Compare)
+			g.pendingIDs = slices.Insert(g.pendingIDs, i, id)
+		}
+	}
+	g.pending[id] = p
+}
+
+// removePending removes a pending entry and reports whether there was one.
+func (g *Group) removePending(id ID) bool {
+	if _, ok := g.pending[id]; !ok {
+		return false
+	}
+	delete(g.pending, id)
+	i, _ := slices.BinarySearchFunc(g.pendingIDs, id, ID.Compare)
+	g.pendingIDs = slices.Delete(g.pendingIDs, i, i+1)
+	return true
+}
+
+// removeConsumerPending removes the entries pending for a consumer and returns their number.
+func (g *Group) removeConsumerPending(consumer string) int64 {
+	n := len(g.pendingIDs)
+	g.pendingIDs = slices.DeleteFunc(g.pendingIDs, func(id ID) bool {
+		if g.pending[id].Consumer != consumer {
+			return false
+		}
+		delete(g.pending, id)
+		return true
+	})
+	return int64(n - len(g.pendingIDs))
+}
+
+// EachPending calls fn with the pending entries with IDs from start on, in ascending order,
+// until fn returns false. fn must not add or remove pending entries.
+func (g *Group) EachPending(start ID, fn func(id ID, p *PendingEntry) bool) {
+	i, _ := slices.BinarySearchFunc(g.pendingIDs, start, ID.Compare)
+	for _, id := range g.pendingIDs[i:] {
+		if !fn(id, g.pending[id]) {
+			return
+		}
+	}
+}
+
+// consumer returns the named consumer, creating it if needed, and whether it was created.
+func (g *Group) consumer(name string, now int64) (*Consumer, bool) {
+	c, ok := g.Consumers[name]
+	if !ok {
+		c = &Consumer{SeenAt: now, ActiveAt: -1}
+		g.Consumers[name] = c
+	}
+	c.SeenAt = now
+	return c, !ok
+}
+
+// pendingCount returns the number of entries pending for a consumer.
+func (g *Group) pendingCount(consumer string) int64 {
+	var n int64
+	for _, p := range g.pending {
+		if p.Consumer == consumer {
+			n++
+		}
+	}
+	return n
+}
+
+func (g *Group) clone() *Group {
+	c := NewGroup(g.LastID, g.EntriesRead)
+	for id, p := range g.pending {
+		pending := *p
+		c.pending[id] = &pending
+	}
+	c.pendingIDs = slices.Clone(g.pendingIDs)
+	for name, consumer := range g.Consumers {
+		cc := *consumer
+		c.Consumers[name] = &cc
+	}
+	return c
+}
+
+// Stream is the value stored for a stream, an append only log of entries in ascending ID order.
+type Stream struct {
+	entries []Entry
+	// LastID is the ID of the last entry added, which later entries must be greater than
+	// even if it was deleted.
+	LastID ID
+	// MaxDeletedID is the highest ID deleted by XDEL.
+	MaxDeletedID ID
+	// EntriesAdded counts every entry added over the lifetime of the stream.
+	EntriesAdded int64
+	groups       map[string]*Group
+}
+
+func NewStream() *Stream {
+	return &Stream{groups: map[string]*Group{}}
+}
+
+func (s *Stream) Len() int {
+	return len(s.entries)
+}
+
+// Append adds an entry after the last one. The caller must keep the entries in ascending order.
+func (s *Stream) Append(e Entry) {
+	s.entries = append(s.entries, e)
+}
+
+// Each calls fn with the entries in order until fn returns false.
+func (s *Stream) Each(fn func(e Entry) bool) {
+	for _, e := range s.entries {
+		if !fn(e) {
+			return
+		}
+	}
+}
+
+// Group returns a consumer group.
+func (s *Stream) Group(name string) (*Group, bool) {
+	g, ok := s.groups[name]
+	return g, ok
+}
+
+// SetGroup adds or replaces a consumer group.
+func (s *Stream) SetGroup(name string, g *Group) {
+	s.groups[name] = g
+}
+
+// GroupNames returns the names of the consumer groups in order.
+func (s *Stream) GroupNames() []string {
+	return sortedNames(s.groups)
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// search returns the index of the first entry whose ID is at least id.
+func (s *Stream) search(id ID) int {
+	i, _ := slices.BinarySearchFunc(s.entries, id, func(e Entry, id ID) int {
+		return e.ID.Compare(id)
+	})
+	return i
+}
+
+// lookup returns the entry with an ID.
+func (s *Stream) lookup(id ID) (Entry, bool) {
+	i := s.search(id)
+	if i < len(s.entries) && s.entries[i].ID == id {
+		return s.entries[i], true
+	}
+	return Entry{}, false
+}
+
+// rangeEntries returns up to count entries, all of them if count is negative, from start to end.
+func (s *Stream) rangeEntries(start, end ID, count int64, rev bool) []Entry {
+	from, to := s.search(start), s.search(end)
+	if to < len(s.entries) && s.entries[to].ID == end {
+		to++
+	}
+	entries := []Entry{}
+	if from >= to {
+		return entries
+	}
+	n := to - from
+	if count >= 0 && int64(n) > count {
+		n = int(count)
+	}
+	if rev {
+		for i := to - 1; i >= to-n; i-- {
+			entries = append(entries, s.entries[i])
+		}
+		return entries
+	}
+	return append(entries, s.entries[from:from+n]...)
+}
+
+// remove deletes the entry with an ID, it returns false if there is none.
+func (s *Stream) remove(id ID) bool {
+	i := s.search(id)
+	if i == len(s.entries) || s.entries[i].ID != id {
+		return false
+	}
+	s.entries = slices.Delete(s.entries, i, i+1)
+	if id.Compare(s.MaxDeletedID) > 0 {
+		s.MaxDeletedID = id
+	}
+	return true
+}
+
+// tombstonesAfter reports whether entries of the stream after id were deleted.
+func (s *Stream) tombstonesAfter(id ID) bool {
+	return len(s.entries) > 0 && s.MaxDeletedID.Compare(s.entries[0].ID) >= 0 && s.MaxDeletedID.Compare(id) > 0
+}
+
+// entriesReadUntil returns the number of entries added to the stream up to id, counting the
+// deleted ones, false if deletions make it unknown.
+func (s *Stream) entriesReadUntil(id ID) (int64, bool) {
+	if len(s.entries) == 0 || id.Compare(s.LastID) >= 0 {
+		return s.EntriesAdded, true
+	}
+	if s.tombstonesAfter(id) {
+		return 0, false
+	}
+	i := s.search(id)
+	if i < len(s.entries) && s.entries[i].ID == id {
+		i++
+	}
+	return s.EntriesAdded - int64(len(s.entries)-i), true
+}
+
+// Lag returns the number of entries of the stream a group has yet to read, false if it isn't known.
+func (s *Stream) Lag(g *Group) (int64, bool) {
+	if s.EntriesAdded == 0 {
+		return 0, true
+	}
+	if g.EntriesRead >= 0 && !s.tombstonesAfter(g.LastID) {
+		return s.EntriesAdded - g.EntriesRead, true
+	}
+	read, ok := s.entriesReadUntil(g.LastID)
+	return s.EntriesAdded - read, ok
+}
+
+func (s *Stream) Clone() *Stream {
+	c := &Stream{
+		entries:      slices.Clone(s.entries),
+		LastID:       s.LastID,
+		MaxDeletedID: s.MaxDeletedID,
+		EntriesAdded: s.EntriesAdded,
+		groups:       make(map[string]*Group, len(s.groups)),
+	}
+	for name, g := range s.groups {
+		c.groups[name] = g.clone()
+	}
+	return c
+}
+
+// Clear drops the entries and consumer groups.
+func (s *Stream) Clear() {
+	s.entries = nil
+	clear(s.groups)
+}
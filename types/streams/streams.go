@@ -0,0 +1,284 @@
+package streams
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+// Trimming strategies of XADD and XTRIM.
+const (
+	TRIM_MAXLEN = "MAXLEN"
+	TRIM_MINID  = "MINID"
+)
+
+// Trim removes the oldest entries of a stream, past MaxLen entries with TRIM_MAXLEN or below
+// MinID with TRIM_MINID. An Approx trim removes at most Limit entries, 0 meaning no limit.
+type Trim struct {
+	Strategy string
+	MaxLen   int64
+	MinID    ID
+	Approx   bool
+	Limit    int64
+}
+
+// StreamEntries are the entries read from the stream stored at Key.
+type StreamEntries struct {
+	Key     string
+	Entries []Entry
+}
+
+func get(key string) (*Stream, bool) {
+	return store.Get[string, *Stream](key)
+}
+
+// now returns the current unix time in milliseconds.
+func now() int64 {
+	return time.Now().UnixMilli()
+}
+
+// trim removes the entries t selects and returns their number.
+func (s *Stream) trim(t Trim) int64 {
+	n := 0
+	switch t.Strategy {
+	case TRIM_MAXLEN:
+		n = max(len(s.entries)-int(min(t.MaxLen, math.MaxInt32)), 0)
+	case TRIM_MINID:
+		n = s.search(t.MinID)
+	}
+	if t.Approx && t.Limit > 0 {
+		n = min(n, int(min(t.Limit, math.MaxInt32)))
+	}
+	// The entries are dropped from the front without shifting the others, the space they took
+	// is reclaimed when appending reallocates the slice.
+	clear(s.entries[:n])
+	s.entries = s.entries[n:]
+	return int64(n)
+}
+
+// nextID returns the ID of an entry added to s with the ID given to XADD: * to generate it,
+// ms-* to only generate the sequence number, or a complete ID greater than the last one.
+func (s *Stream) nextID(spec string) (ID, error) {
+	if spec == "*" {
+		id := ID{Ms: uint64(now())}
+		if id.Compare(s.LastID) > 0 {
+			return id, nil
+		}
+		id, ok := s.LastID.next()
+		if !ok {
+			return ID{}, errors.New(common.ERR_STREAM_EXHAUSTED)
+		}
+		return id, nil
+	}
+	if msPart, ok := strings.CutSuffix(spec, "-*"); ok {
+		ms, err := strconv.ParseUint(msPart, 10, 64)
+		if err != nil {
+			return ID{}, errors.New(common.ERR_STREAM_ID_INVALID)
+		}
+		switch {
+		case ms < s.LastID.Ms || (ms == s.LastID.Ms && s.LastID.Seq == math.MaxUint64):
+			return ID{}, errors.New(common.ERR_STREAM_ID_TOO_SMALL)
+		case ms == s.LastID.Ms && s.EntriesAdded > 0:
+			return ID{Ms: ms, Seq: s.LastID.Seq + 1}, nil
+		case ms == 0:
+			return ID{Seq: 1}, nil
+		}
+		return ID{Ms: ms}, nil
+	}
+	id, err := ParseID(spec)
+	if err != nil {
+		return ID{}, err
+	}
+	if id == (ID{}) {
+		return ID{}, errors.New(common.ERR_STREAM_ID_ZERO)
+	}
+	if id.Compare(s.LastID) <= 0 {
+		return ID{}, errors.New(common.ERR_STREAM_ID_TOO_SMALL)
+	}
+	return id, nil
+}
+
+// XAdd adds an entry to the stream stored at key, creating the stream unless noMkStream is set,
+// and trims the stream if trim is not nil. It returns the ID of the entry, false if the stream
+// doesn't exist and noMkStream is set.
+func XAdd(key, id string, fields []string, noMkStream bool, trim *Trim) (ID, bool, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, exists := get(key)
+	if !exists {
+		if noMkStream {
+			return ID{}, false, nil
+		}
+		s = NewStream()
+	}
+	entryID, err := s.nextID(id)
+	if err != nil {
+		return ID{}, false, err
+	}
+	s.Append(Entry{ID: entryID, Fields: fields})
+	s.LastID = entryID
+	s.EntriesAdded++
+	if !exists {
+		store.Set(key, s)
+	}
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xadd", key)
+	if trim != nil && s.trim(*trim) > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xtrim", key)
+	}
+	signal(key)
+	return entryID, true, nil
+}
+
+// XLen returns the number of entries of the stream stored at key.
+func XLen(key string) int64 {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return 0
+	}
+	return int64(s.Len())
+}
+
+// XRange returns up to count entries, all of them if count is negative, of the stream stored at
+// key with IDs from start to end, in descending order if rev is set.
+func XRange(key string, start, end ID, count int64, rev bool) []Entry {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return []Entry{}
+	}
+	return s.rangeEntries(start, end, count, rev)
+}
+
+// XDel deletes entries of the stream stored at key and returns the number deleted.
+func XDel(key string, ids []ID) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return 0
+	}
+	var deleted int64
+	for _, id := range ids {
+		if s.remove(id) {
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xdel", key)
+	}
+	return deleted
+}
+
+// XTrim trims the stream stored at key and returns the number of entries removed.
+func XTrim(key string, trim Trim) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	s, ok := get(key)
+	if !ok {
+		return 0
+	}
+	removed := s.trim(trim)
+	if removed > 0 {
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STREAM, "xtrim", key)
+	}
+	return removed
+}
+
+// LastIDs returns the ID of the last entry added to each stream, 0-0 for missing keys.
+// It resolves the $ IDs of XREAD and XGROUP.
+func LastIDs(keys []string) []ID {
+	store.RLockKeys(keys...)
+	defer store.RUnlockKeys(keys...)
+
+	ids := make([]ID, len(keys))
+	for i, key := range keys {
+		if s, ok := get(key); ok {
+			ids[i] = s.LastID
+		}
+	}
+	return ids
+}
+
+// XRead returns up to count entries, all of them if count is negative, with IDs greater than
+// ids[i] from each of the streams stored at keys. Streams without such entries are left out.
+func XRead(keys []string, ids []ID, count int64) []StreamEntries {
+	store.RLockKeys(keys...)
+	defer store.RUnlockKeys(keys...)
+
+	results := []StreamEntries{}
+	for i, key := range keys {
+		s, ok := get(key)
+		if !ok {
+			continue
+		}
+		start, ok := ids[i].next()
+		if !ok {
+			continue
+		}
+		if entries := s.rangeEntries(start, MaxID, count, false); len(entries) > 0 {
+			results = append(results, StreamEntries{Key: key, Entries: entries})
+		}
+	}
+	return results
+}
+
+// watcher is a client blocked on streams, its channel is closed once when one of them changes.
+type watcher struct {
+	ch   chan struct{}
+	once sync.Once
+}
+
+var (
+	watchers      = map[string]map[*watcher]struct{}{}
+	watchersMutex sync.Mutex
+)
+
+// Watch returns a channel closed the next time entries are added to one of the streams stored
+// at keys, or one of their consumer groups is destroyed, and a function to stop watching them.
+// Clients blocked in XREAD and XREADGROUP watch the keys before reading them so they can't
+// miss an entry added in between.
+func Watch(keys []string) (<-chan struct{}, func()) {
+	w := &watcher{ch: make(chan struct{})}
+	watchersMutex.Lock()
+	defer watchersMutex.Unlock()
+	for _, key := range keys {
+		if watchers[key] == nil {
+			watchers[key] = map[*watcher]struct{}{}
+		}
+		watchers[key][w] = struct{}{}
+	}
+	return w.ch, func() {
+		watchersMutex.Lock()
+		defer watchersMutex.Unlock()
+		for _, key := range keys {
+			delete(watchers[key], w)
+			if len(watchers[key]) == 0 {
+				delete(watchers, key)
+			}
+		}
+	}
+}
+
+// signal wakes up the clients watching key.
+func signal(key string) {
+	watchersMutex.Lock()
+	defer watchersMutex.Unlock()
+	for w := range watchers[key] {
+		w.once.Do(func() { close(w.ch) })
+	}
+}
@@ -0,0 +1,408 @@
+package streams_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/types/streams"
+)
+
+func id(ms, seq uint64) streams.ID {
+	return streams.ID{Ms: ms, Seq: seq}
+}
+
+func ids(entries []streams.Entry) []streams.ID {
+	out := []streams.ID{}
+	for _, e := range entries {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+func add(t *testing.T, key, entryID string, fields ...string) streams.ID {
+	t.Helper()
+	added, ok, err := streams.XAdd(key, entryID, fields, false, nil)
+	if err != nil || !ok {
+		t.Fatalf("Expected %s to be added, got %v", entryID, err)
+	}
+	return added
+}
+
+func TestXAdd_IDs(t *testing.T) {
+	key := "TestXAdd_IDs"
+	if _, _, err := streams.XAdd(key, "0-0", []string{"f", "v"}, false, nil); err == nil || err.Error() != common.ERR_STREAM_ID_ZERO {
+		t.Errorf("Expected %s, got %v", common.ERR_STREAM_ID_ZERO, err)
+	}
+	if got := add(t, key, "0-*", "f", "v"); got != id(0, 1) {
+		t.Errorf("Expected 0-1, got %v", got)
+	}
+	if got := add(t, key, "5", "f", "v"); got != id(5, 0) {
+		t.Errorf("Expected 5-0, got %v", got)
+	}
+	if got := add(t, key, "5-*", "f", "v"); got != id(5, 1) {
+		t.Errorf("Expected 5-1, got %v", got)
+	}
+	for _, tooSmall := range []string{"5-1", "4-9", "4-*"} {
+		if _, _, err := streams.XAdd(key, tooSmall, []string{"f", "v"}, false, nil); err == nil || err.Error() != common.ERR_STREAM_ID_TOO_SMALL {
+			t.Errorf("Expected %s for %s, got %v", common.ERR_STREAM_ID_TOO_SMALL, tooSmall, err)
+		}
+	}
+	if _, _, err := streams.XAdd(key, "x-1", []string{"f", "v"}, false, nil); err == nil || err.Error() != common.ERR_STREAM_ID_INVALID {
+		t.Errorf("Expected %s, got %v", common.ERR_STREAM_ID_INVALID, err)
+	}
+	before := uint64(time.Now().UnixMilli())
+	if got := add(t, key, "*", "f", "v"); got.Ms < before {
+		t.Errorf("Expected an ID from the current time, got %v", got)
+	}
+	if _, ok, _ := streams.XAdd("TestXAdd_IDsMissing", "*", []string{"f", "v"}, true, nil); ok {
+		t.Errorf("Expected NOMKSTREAM not to create the stream")
+	}
+	if n := streams.XLen(key); n != 4 {
+		t.Errorf("Expected 4 entries, got %d", n)
+	}
+}
+
+func TestXAdd_AutoIDAfterFutureID(t *testing.T) {
+	key := "TestXAdd_AutoIDAfterFutureID"
+	future := uint64(time.Now().Add(time.Hour).UnixMilli())
+	add(t, key, id(future, 7).String(), "f", "v")
+	if got := add(t, key, "*", "f", "v"); got != id(future, 8) {
+		t.Errorf("Expected %d-8, got %v", future, got)
+	}
+}
+
+func TestXAdd_Trims(t *testing.T) {
+	key := "TestXAdd_Trims"
+	for i := range uint64(10) {
+		add(t, key, id(i+1, 0).String(), "f", "v")
+	}
+	if _, _, err := streams.XAdd(key, "11", []string{"f", "v"}, false, &streams.Trim{Strategy: streams.TRIM_MAXLEN, MaxLen: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(streams.XRange(key, id(0, 0), streams.MaxID, -1, false)); !reflect.DeepEqual(got, []streams.ID{id(7, 0), id(8, 0), id(9, 0), id(10, 0), id(11, 0)}) {
+		t.Errorf("Expected entries 7 to 11, got %v", got)
+	}
+	if n := streams.XTrim(key, streams.Trim{Strategy: streams.TRIM_MINID, MinID: id(9, 0)}); n != 2 {
+		t.Errorf("Expected 2 entries trimmed, got %d", n)
+	}
+	if n := streams.XTrim(key, streams.Trim{Strategy: streams.TRIM_MAXLEN, MaxLen: 0, Approx: true, Limit: 1}); n != 1 {
+		t.Errorf("Expected LIMIT to cap the trim at 1 entry, got %d", n)
+	}
+	if n := streams.XLen(key); n != 2 {
+		t.Errorf("Expected 2 entries, got %d", n)
+	}
+}
+
+func TestXRange(t *testing.T) {
+	key := "TestXRange"
+	for i := range uint64(5) {
+		add(t, key, id(1, i).String(), "n", string(rune('a'+i)))
+	}
+	entries := streams.XRange(key, id(1, 1), id(1, 3), -1, false)
+	if !reflect.DeepEqual(ids(entries), []streams.ID{id(1, 1), id(1, 2), id(1, 3)}) || entries[0].Fields[1] != "b" {
+		t.Errorf("Expected entries 1-1 to 1-3, got %v", entries)
+	}
+	if got := ids(streams.XRange(key, id(0, 0), streams.MaxID, 2, true)); !reflect.DeepEqual(got, []streams.ID{id(1, 4), id(1, 3)}) {
+		t.Errorf("Expected the last 2 entries in reverse, got %v", got)
+	}
+
+	start, end, ok, err := streams.ParseRange("(1-1", "1")
+	if err != nil || !ok {
+		t.Fatalf("Expected a valid range, got %v", err)
+	}
+	if got := ids(streams.XRange(key, start, end, -1, false)); !reflect.DeepEqual(got, []streams.ID{id(1, 2), id(1, 3), id(1, 4)}) {
+		t.Errorf("Expected entries after 1-1, got %v", got)
+	}
+	if _, _, ok, _ := streams.ParseRange("2", "1"); ok {
+		t.Errorf("Expected an empty range")
+	}
+	if _, _, _, err := streams.ParseRange("(18446744073709551615-18446744073709551615", "+"); err == nil || err.Error() != common.ERR_STREAM_INTERVAL {
+		t.Errorf("Expected %s, got %v", common.ERR_STREAM_INTERVAL, err)
+	}
+}
+
+func TestXDel(t *testing.T) {
+	key := "TestXDel"
+	add(t, key, "1", "f", "v")
+	add(t, key, "2", "f", "v")
+	if n := streams.XDel(key, []streams.ID{id(2, 0), id(3, 0)}); n != 1 {
+		t.Errorf("Expected 1 entry deleted, got %d", n)
+	}
+	info, err := streams.XInfoStream(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Length != 1 || info.LastID != id(2, 0) || info.MaxDeletedID != id(2, 0) || info.EntriesAdded != 2 {
+		t.Errorf("Unexpected stream info %+v", info)
+	}
+	if _, _, err := streams.XAdd(key, "2", []string{"f", "v"}, false, nil); err == nil {
+		t.Errorf("Expected the ID of a deleted entry not to be reused")
+	}
+}
+
+func TestXRead(t *testing.T) {
+	key1, key2 := "TestXRead1", "TestXRead2"
+	add(t, key1, "1", "f", "v")
+	add(t, key1, "2", "f", "v")
+	add(t, key2, "1", "f", "v")
+	results := streams.XRead([]string{key1, key2, "TestXReadMissing"}, []streams.ID{id(1, 0), id(1, 0), id(0, 0)}, -1)
+	if len(results) != 1 || results[0].Key != key1 || !reflect.DeepEqual(ids(results[0].Entries), []streams.ID{id(2, 0)}) {
+		t.Errorf("Expected entry 2 of %s only, got %v", key1, results)
+	}
+	if last := streams.LastIDs([]string{key1, "TestXReadMissing"}); !reflect.DeepEqual(last, []streams.ID{id(2, 0), id(0, 0)}) {
+		t.Errorf("Expected the last IDs, got %v", last)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	key := "TestWatch"
+	changed, stop := streams.Watch([]string{key, "TestWatchOther"})
+	defer stop()
+	select {
+	case <-changed:
+		t.Fatalf("Expected no change yet")
+	default:
+	}
+	add(t, key, "*", "f", "v")
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected XADD to signal the watcher")
+	}
+	add(t, key, "*", "f", "v")
+}
+
+func TestXReadGroup(t *testing.T) {
+	key := "TestXReadGroup"
+	if err := streams.XGroupCreate(key, "g", "$", false, nil); err == nil || err.Error() != common.ERR_XGROUP_KEY {
+		t.Fatalf("Expected %s, got %v", common.ERR_XGROUP_KEY, err)
+	}
+	if err := streams.XGroupCreate(key, "g", "$", true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := streams.XGroupCreate(key, "g", "0", false, nil); err == nil || err.Error() != common.ERR_BUSY_GROUP {
+		t.Fatalf("Expected %s, got %v", common.ERR_BUSY_GROUP, err)
+	}
+	add(t, key, "1", "f", "v")
+	add(t, key, "2", "f", "v")
+	add(t, key, "3", "f", "v")
+
+	results, err := streams.XReadGroup("g", "alice", []string{key}, []string{">"}, 2, false)
+	if err != nil || len(results) != 1 || !reflect.DeepEqual(ids(results[0].Entries), []streams.ID{id(1, 0), id(2, 0)}) {
+		t.Fatalf("Expected entries 1 and 2, got %v %v", results, err)
+	}
+	results, _ = streams.XReadGroup("g", "bob", []string{key}, []string{">"}, -1, true)
+	if len(results) != 1 || !reflect.DeepEqual(ids(results[0].Entries), []streams.ID{id(3, 0)}) {
+		t.Fatalf("Expected entry 3, got %v", results)
+	}
+	if results, _ = streams.XReadGroup("g", "bob", []string{key}, []string{">"}, -1, false); len(results) != 0 {
+		t.Errorf("Expected no new entries, got %v", results)
+	}
+
+	streams.XDel(key, []streams.ID{id(1, 0)})
+	results, _ = streams.XReadGroup("g", "alice", []string{key}, []string{"0"}, -1, false)
+	history := results[0].Entries
+	if !reflect.DeepEqual(ids(history), []streams.ID{id(1, 0), id(2, 0)}) || history[0].Fields != nil || history[1].Fields == nil {
+		t.Errorf("Expected the pending entries with 1 deleted, got %v", history)
+	}
+	if results, _ = streams.XReadGroup("g", "bob", []string{key}, []string{"0"}, -1, false); len(results) != 1 || len(results[0].Entries) != 0 {
+		t.Errorf("Expected an empty history for NOACK reads, got %v", results)
+	}
+
+	if n := streams.XAck(key, "g", []streams.ID{id(1, 0), id(1, 0), id(9, 0)}); n != 1 {
+		t.Errorf("Expected 1 entry acknowledged, got %d", n)
+	}
+	if _, err := streams.XReadGroup("missing", "alice", []string{key}, []string{">"}, -1, false); err == nil || err.Error() != common.ERR_NO_GROUP {
+		t.Errorf("Expected %s, got %v", common.ERR_NO_GROUP, err)
+	}
+
+	groups, _ := streams.XInfoGroups(key)
+	if len(groups) != 1 || groups[0].Consumers != 2 || groups[0].Pending != 1 || groups[0].LastID != id(3, 0) || !groups[0].HasLag || groups[0].Lag != 0 {
+		t.Errorf("Unexpected group info %+v", groups)
+	}
+	consumers, _ := streams.XInfoConsumers(key, "g")
+	if len(consumers) != 2 || consumers[0].Name != "alice" || consumers[0].Pending != 1 || consumers[1].Pending != 0 {
+		t.Errorf("Unexpected consumer info %+v", consumers)
+	}
+}
+
+func TestXGroup_LagAndEntriesRead(t *testing.T) {
+	key := "TestXGroup_LagAndEntriesRead"
+	for i := range uint64(4) {
+		add(t, key, id(i+1, 0).String(), "f", "v")
+	}
+	streams.XGroupCreate(key, "g", "0", false, nil)
+	streams.XReadGroup("g", "c", []string{key}, []string{">"}, 1, true)
+	groups, _ := streams.XInfoGroups(key)
+	if groups[0].EntriesRead != 1 || !groups[0].HasLag || groups[0].Lag != 3 {
+		t.Errorf("Expected 1 entry read and a lag of 3, got %+v", groups[0])
+	}
+
+	streams.XDel(key, []streams.ID{id(3, 0)})
+	groups, _ = streams.XInfoGroups(key)
+	if groups[0].HasLag {
+		t.Errorf("Expected the lag to be unknown with a deleted entry ahead, got %+v", groups[0])
+	}
+
+	entriesRead := int64(2)
+	if err := streams.XGroupSetID(key, "g", "$", &entriesRead); err != nil {
+		t.Fatal(err)
+	}
+	groups, _ = streams.XInfoGroups(key)
+	if groups[0].EntriesRead != 2 || groups[0].LastID != id(4, 0) || groups[0].Lag != 2 {
+		t.Errorf("Expected the lag to follow ENTRIESREAD, got %+v", groups[0])
+	}
+	if n, _ := streams.XGroupDestroy(key, "g"); n != 1 {
+		t.Errorf("Expected the group to be destroyed")
+	}
+	if n, _ := streams.XGroupDestroy(key, "g"); n != 0 {
+		t.Errorf("Expected no group to destroy")
+	}
+}
+
+func TestXGroup_Consumers(t *testing.T) {
+	key := "TestXGroup_Consumers"
+	streams.XGroupCreate(key, "g", "0", true, nil)
+	if n, err := streams.XGroupCreateConsumer(key, "g", "c"); err != nil || n != 1 {
+		t.Errorf("Expected the consumer to be created, got %d %v", n, err)
+	}
+	if n, _ := streams.XGroupCreateConsumer(key, "g", "c"); n != 0 {
+		t.Errorf("Expected the consumer to exist")
+	}
+	add(t, key, "1", "f", "v")
+	add(t, key, "2", "f", "v")
+	streams.XReadGroup("g", "c", []string{key}, []string{">"}, -1, false)
+	if n, err := streams.XGroupDelConsumer(key, "g", "c"); err != nil || n != 2 {
+		t.Errorf("Expected 2 entries pending for the deleted consumer, got %d %v", n, err)
+	}
+	if summary, _ := streams.XPendingSummary(key, "g"); summary.Count != 0 {
+		t.Errorf("Expected no pending entries, got %+v", summary)
+	}
+	if _, err := streams.XGroupCreateConsumer(key, "missing", "c"); err == nil || err.Error() != common.ERR_NO_GROUP {
+		t.Errorf("Expected %s, got %v", common.ERR_NO_GROUP, err)
+	}
+}
+
+func TestXPending(t *testing.T) {
+	key := "TestXPending"
+	streams.XGroupCreate(key, "g", "0", true, nil)
+	for i := range uint64(3) {
+		add(t, key, id(i+1, 0).String(), "f", "v")
+	}
+	streams.XReadGroup("g", "alice", []string{key}, []string{">"}, 2, false)
+	streams.XReadGroup("g", "bob", []string{key}, []string{">"}, -1, false)
+
+	summary, err := streams.XPendingSummary(key, "g")
+	expected := streams.PendingSummary{Count: 3, Smallest: id(1, 0), Largest: id(3, 0), Consumers: []streams.ConsumerPending{{Name: "alice", Count: 2}, {Name: "bob", Count: 1}}}
+	if err != nil || !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Expected %+v, got %+v %v", expected, summary, err)
+	}
+	pending, _ := streams.XPending(key, "g", id(0, 0), streams.MaxID, 10, "alice", 0)
+	if len(pending) != 2 || pending[0].ID != id(1, 0) || pending[0].Deliveries != 1 || pending[1].Consumer != "alice" {
+		t.Errorf("Unexpected pending entries %+v", pending)
+	}
+	if pending, _ = streams.XPending(key, "g", id(0, 0), streams.MaxID, 10, "", time.Hour.Milliseconds()); len(pending) != 0 {
+		t.Errorf("Expected no entry idle for an hour, got %+v", pending)
+	}
+	if _, err := streams.XPendingSummary("TestXPendingMissing", "g"); err == nil || err.Error() != common.ERR_NO_GROUP {
+		t.Errorf("Expected %s, got %v", common.ERR_NO_GROUP, err)
+	}
+}
+
+func TestXClaim(t *testing.T) {
+	key := "TestXClaim"
+	streams.XGroupCreate(key, "g", "0", true, nil)
+	for i := range uint64(3) {
+		add(t, key, id(i+1, 0).String(), "f", "v")
+	}
+	streams.XReadGroup("g", "alice", []string{key}, []string{">"}, 2, false)
+
+	claimed, _ := streams.XClaim(key, "g", "bob", time.Hour.Milliseconds(), []streams.ID{id(1, 0)}, streams.ClaimOptions{RetryCount: -1})
+	if len(claimed) != 0 {
+		t.Errorf("Expected entries idle for less than an hour not to be claimed, got %v", claimed)
+	}
+	claimed, _ = streams.XClaim(key, "g", "bob", 0, []streams.ID{id(1, 0), id(3, 0)}, streams.ClaimOptions{RetryCount: -1})
+	if !reflect.DeepEqual(ids(claimed), []streams.ID{id(1, 0)}) {
+		t.Errorf("Expected entry 1 to be claimed, got %v", claimed)
+	}
+	claimed, _ = streams.XClaim(key, "g", "bob", 0, []streams.ID{id(3, 0)}, streams.ClaimOptions{RetryCount: 5, Force: true, LastID: id(3, 0)})
+	if !reflect.DeepEqual(ids(claimed), []streams.ID{id(3, 0)}) {
+		t.Errorf("Expected FORCE to claim entry 3, got %v", claimed)
+	}
+	pending, _ := streams.XPending(key, "g", id(0, 0), streams.MaxID, 10, "bob", 0)
+	if len(pending) != 2 || pending[0].Deliveries != 2 || pending[1].Deliveries != 5 {
+		t.Errorf("Unexpected pending entries %+v", pending)
+	}
+
+	streams.XDel(key, []streams.ID{id(2, 0)})
+	if claimed, _ = streams.XClaim(key, "g", "bob", 0, []streams.ID{id(2, 0)}, streams.ClaimOptions{RetryCount: -1}); len(claimed) != 0 {
+		t.Errorf("Expected a deleted entry not to be claimed, got %v", claimed)
+	}
+	if summary, _ := streams.XPendingSummary(key, "g"); summary.Count != 2 {
+		t.Errorf("Expected the deleted entry to be removed from the pending entries, got %+v", summary)
+	}
+	if groups, _ := streams.XInfoGroups(key); groups[0].LastID != id(3, 0) {
+		t.Errorf("Expected LASTID to move the group, got %+v", groups[0])
+	}
+}
+
+func TestXAutoClaim(t *testing.T) {
+	key := "TestXAutoClaim"
+	streams.XGroupCreate(key, "g", "0", true, nil)
+	for i := range uint64(5) {
+		add(t, key, id(i+1, 0).String(), "f", "v")
+	}
+	streams.XReadGroup("g", "alice", []string{key}, []string{">"}, -1, false)
+	streams.XDel(key, []streams.ID{id(2, 0)})
+
+	next, claimed, deleted, err := streams.XAutoClaim(key, "g", "bob", 0, id(0, 0), 2, false)
+	if err != nil || next != id(4, 0) || !reflect.DeepEqual(ids(claimed), []streams.ID{id(1, 0), id(3, 0)}) || !reflect.DeepEqual(deleted, []streams.ID{id(2, 0)}) {
+		t.Errorf("Unexpected XAUTOCLAIM result %v %v %v %v", next, ids(claimed), deleted, err)
+	}
+	next, claimed, _, _ = streams.XAutoClaim(key, "g", "bob", 0, next, 10, true)
+	if next != id(0, 0) || !reflect.DeepEqual(ids(claimed), []streams.ID{id(4, 0), id(5, 0)}) {
+		t.Errorf("Expected the remaining entries and a 0-0 cursor, got %v %v", next, ids(claimed))
+	}
+	if pending, _ := streams.XPending(key, "g", id(4, 0), id(4, 0), 1, "", 0); pending[0].Deliveries != 1 {
+		t.Errorf("Expected JUSTID not to count a delivery, got %+v", pending)
+	}
+}
+
+func TestStream_Clone(t *testing.T) {
+	s := streams.NewStream()
+	s.Append(streams.Entry{ID: id(1, 0), Fields: []string{"f", "v"}})
+	s.SetGroup("g", streams.NewGroup(id(1, 0), 1))
+	g, _ := s.Group("g")
+	g.AddPending(id(1, 0), &streams.PendingEntry{Consumer: "c", Deliveries: 1})
+
+	c := s.Clone()
+	cg, _ := c.Group("g")
+	cp, _ := cg.Pending(id(1, 0))
+	cp.Deliveries = 2
+	cg.AddPending(id(2, 0), &streams.PendingEntry{Consumer: "c", Deliveries: 1})
+	c.Append(streams.Entry{ID: id(2, 0)})
+	if p, _ := g.Pending(id(1, 0)); s.Len() != 1 || p.Deliveries != 1 || g.PendingLen() != 1 {
+		t.Errorf("Expected the clone to be independent of the original")
+	}
+}
+
+func TestGroup_PendingInIDOrder(t *testing.T) {
+	g := streams.NewGroup(id(0, 0), 0)
+	for _, ms := range []uint64{3, 1, 5, 2, 4} {
+		g.AddPending(id(ms, 0), &streams.PendingEntry{Consumer: "c"})
+	}
+	g.AddPending(id(2, 0), &streams.PendingEntry{Consumer: "d"})
+	got := []streams.ID{}
+	g.EachPending(id(2, 0), func(pendingID streams.ID, _ *streams.PendingEntry) bool {
+		got = append(got, pendingID)
+		return true
+	})
+	if g.PendingLen() != 5 || !reflect.DeepEqual(got, []streams.ID{id(2, 0), id(3, 0), id(4, 0), id(5, 0)}) {
+		t.Errorf("Expected the pending entries from 2-0 in ID order, got %v of %d", got, g.PendingLen())
+	}
+	if p, _ := g.Pending(id(2, 0)); p.Consumer != "d" {
+		t.Errorf("Expected adding an ID again to replace its entry")
+	}
+}
@@ -0,0 +1,383 @@
+package strings
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+// MaxBitOffset is the highest bit offset that can be addressed, bitmaps are limited to 512MB.
+const MaxBitOffset = 1<<32 - 1
+
+// MaxStringLength is the largest length, in bytes, a string can be grown to by SETRANGE.
+const MaxStringLength = 512 << 20
+
+// Bit operations supported by BitOp.
+const (
+	BITOP_AND  = "AND"
+	BITOP_OR   = "OR"
+	BITOP_XOR  = "XOR"
+	BITOP_NOT  = "NOT"
+	BITOP_DIFF = "DIFF"
+)
+
+// Overflow behaviors of the bitfield SET and INCRBY operations.
+const (
+	OVERFLOW_WRAP = "WRAP"
+	OVERFLOW_SAT  = "SAT"
+	OVERFLOW_FAIL = "FAIL"
+)
+
+// BitRange is a range of a string, in bytes or with Bits set in bits. Negative positions count
+// from the end of the string. Without HasEnd the range extends to the end of the string.
+type BitRange struct {
+	Start  int64
+	End    int64
+	HasEnd bool
+	Bits   bool
+}
+
+// BitFieldType is the type of an integer field of a bitfield, Width bits wide.
+type BitFieldType struct {
+	Signed bool
+	Width  int
+}
+
+// BitFieldOp is a GET, SET or INCRBY operation on an integer field of a bitfield. Value is
+// the value set or the increment, Overflow how SET and INCRBY handle values out of range.
+type BitFieldOp struct {
+	Kind     string
+	Type     BitFieldType
+	Offset   int64
+	Value    int64
+	Overflow string
+}
+
+// loadBits returns the bytes of the string stored at key, or nil, along with its expiry.
+// The caller must hold a lock on the key.
+func loadBits(key string) ([]byte, int64) {
+	val, ttl, ok := getWithTTL(key)
+	if !ok {
+		return nil, -1
+	}
+	return []byte(val), ttl
+}
+
+// grow pads b with zero bytes so it holds the bit at offset.
+func grow(b []byte, offset int64) []byte {
+	if need := int(offset/8) + 1; need > len(b) {
+		b = append(b, make([]byte, need-len(b))...)
+	}
+	return b
+}
+
+func bitAt(b []byte, offset int64) int64 {
+	if offset/8 >= int64(len(b)) {
+		return 0
+	}
+	return int64(b[offset/8]>>(7-offset%8)) & 1
+}
+
+func setBitAt(b []byte, offset int64, bit int64) {
+	mask := byte(1) << (7 - offset%8)
+	if bit == 1 {
+		b[offset/8] |= mask
+	} else {
+		b[offset/8] &^= mask
+	}
+}
+
+// SetBit sets the bit at offset of the string stored at key, padding it with zero bytes as needed,
+// and returns the previous bit. The string keeps its expiry.
+func SetBit(key string, offset int64, bit int64) int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	b, ttl := loadBits(key)
+	b = grow(b, offset)
+	previous := bitAt(b, offset)
+	setBitAt(b, offset, bit)
+	store.SetWithTTLAsUnixTimeStamp(key, Encode(string(b)), ttl)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "setbit", key)
+	return previous
+}
+
+// GetBit returns the bit at offset of the string stored at key, 0 past its end or for a missing key.
+func GetBit(key string, offset int64) int64 {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	b, _ := loadBits(key)
+	return bitAt(b, offset)
+}
+
+// bitBounds returns the first and last bit of a range of a string of n bytes, false if the range is empty.
+func bitBounds(n int64, r *BitRange) (int64, int64, bool) {
+	if r == nil {
+		return 0, n*8 - 1, n > 0
+	}
+	length := n
+	if r.Bits {
+		length = n * 8
+	}
+	start, end := r.Start, r.End
+	if !r.HasEnd {
+		end = length - 1
+	}
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if end < 0 {
+		end = max(length+end, 0)
+	}
+	end = min(end, length-1)
+	if start > end || length == 0 {
+		return 0, 0, false
+	}
+	if !r.Bits {
+		start, end = start*8, end*8+7
+	}
+	return start, end, true
+}
+
+// BitCount returns the number of bits set in a range of the string stored at key, or in the
+// whole string if r is nil.
+func BitCount(key string, r *BitRange) int64 {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	b, _ := loadBits(key)
+	start, end, ok := bitBounds(int64(len(b)), r)
+	if !ok {
+		return 0
+	}
+	var count int64
+	for i := start; i <= end; {
+		if i%8 == 0 && i+7 <= end {
+			count += int64(bits.OnesCount8(b[i/8]))
+			i += 8
+			continue
+		}
+		count += bitAt(b, i)
+		i++
+	}
+	return count
+}
+
+// BitPos returns the position of the first bit equal to bit in a range of the string stored at key,
+// or in the whole string if r is nil, and -1 if there is none. Looking for a clear bit without
+// the end of the range given treats the string as padded with zeros, so the position past its end
+// is returned when all its bits are set.
+func BitPos(key string, bit int64, r *BitRange) int64 {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	b, _ := loadBits(key)
+	if b == nil {
+		if bit == 0 {
+			return 0
+		}
+		return -1
+	}
+	start, end, ok := bitBounds(int64(len(b)), r)
+	if !ok {
+		return -1
+	}
+	skip := byte(0)
+	if bit == 0 {
+		skip = 0xff
+	}
+	for i := start; i <= end; {
+		if i%8 == 0 && i+7 <= end && b[i/8] == skip {
+			i += 8
+			continue
+		}
+		if bitAt(b, i) == bit {
+			return i
+		}
+		i++
+	}
+	if bit == 0 && (r == nil || !r.HasEnd) {
+		return end + 1
+	}
+	return -1
+}
+
+// BitOp stores at destKey the result of a bitwise operation between the strings stored at keys
+// and returns its length. Shorter strings and missing keys count as zero bytes. DIFF keeps the
+// bits set in the first string and none of the others. An empty result deletes destKey.
+func BitOp(op string, destKey string, keys []string) (int64, error) {
+	if op == BITOP_NOT && len(keys) != 1 {
+		return 0, errors.New(common.ERR_BITOP_NOT)
+	}
+	if op == BITOP_DIFF && len(keys) < 2 {
+		return 0, errors.New(common.ERR_BITOP_DIFF)
+	}
+	allKeys := append([]string{destKey}, keys...)
+	store.LockKeys(allKeys...)
+	defer store.UnlockKeys(allKeys...)
+
+	sources := make([][]byte, len(keys))
+	length := 0
+	for i, key := range keys {
+		sources[i], _ = loadBits(key)
+		length = max(length, len(sources[i]))
+	}
+	result := make([]byte, length)
+	for i := range result {
+		switch op {
+		case BITOP_NOT:
+			result[i] = ^byteAt(sources[0], i)
+			continue
+		case BITOP_DIFF:
+			var others byte
+			for _, source := range sources[1:] {
+				others |= byteAt(source, i)
+			}
+			result[i] = byteAt(sources[0], i) &^ others
+			continue
+		}
+		result[i] = byteAt(sources[0], i)
+		for _, source := range sources[1:] {
+			switch op {
+			case BITOP_AND:
+				result[i] &= byteAt(source, i)
+			case BITOP_OR:
+				result[i] |= byteAt(source, i)
+			case BITOP_XOR:
+				result[i] ^= byteAt(source, i)
+			}
+		}
+	}
+	if length == 0 {
+		if store.Delete(destKey) {
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", destKey)
+		}
+		return 0, nil
+	}
+	store.Set(destKey, Encode(string(result)))
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "set", destKey)
+	return int64(length), nil
+}
+
+func byteAt(b []byte, i int) byte {
+	if i < len(b) {
+		return b[i]
+	}
+	return 0
+}
+
+// getField returns the integer held by a field of a bitfield, bits past the end of b being zeros.
+func getField(b []byte, t BitFieldType, offset int64) int64 {
+	var value uint64
+	for i := range int64(t.Width) {
+		value = value<<1 | uint64(bitAt(b, offset+i))
+	}
+	if t.Signed && t.Width < 64 {
+		// Sign extend the value from its width.
+		shift := 64 - t.Width
+		return int64(value<<shift) >> shift
+	}
+	return int64(value)
+}
+
+func setField(b []byte, t BitFieldType, offset int64, value int64) {
+	for i := range int64(t.Width) {
+		setBitAt(b, offset+i, int64(uint64(value)>>(int64(t.Width)-1-i))&1)
+	}
+}
+
+// addField returns the value of a field of type t holding current after adding incr, handling
+// an overflow as set by overflow. It returns false if the value overflows with OVERFLOW_FAIL.
+func addField(t BitFieldType, current, incr int64, overflow string) (int64, bool) {
+	var low, high int64
+	if t.Signed {
+		low, high = -1<<(t.Width-1), 1<<(t.Width-1)-1
+		if t.Width == 64 {
+			low, high = math.MinInt64, math.MaxInt64
+		}
+	} else {
+		low, high = 0, 1<<t.Width-1
+	}
+	above := incr > 0 && current > high-incr
+	below := incr < 0 && current < low-incr
+	if !above && !below {
+		return current + incr, true
+	}
+	switch overflow {
+	case OVERFLOW_SAT:
+		if above {
+			return high, true
+		}
+		return low, true
+	case OVERFLOW_FAIL:
+		return 0, false
+	}
+	// Wrap around by keeping the low bits of the two's complement sum.
+	sum := uint64(current) + uint64(incr)
+	if t.Signed {
+		shift := 64 - t.Width
+		return int64(sum<<shift) >> shift, true
+	}
+	return int64(sum & uint64(high)), true
+}
+
+// BitField runs operations on integer fields of the string stored at key and returns the result
+// of each: the value read by GET, the previous value for SET and the new value for INCRBY.
+// A result is nil when OVERFLOW_FAIL prevented the operation. The string is only created or
+// padded with zero bytes when an operation writes to it, and keeps its expiry.
+func BitField(key string, ops []BitFieldOp) []*int64 {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	b, ttl := loadBits(key)
+	written := false
+	results := make([]*int64, len(ops))
+	for i, op := range ops {
+		current := getField(b, op.Type, op.Offset)
+		if op.Kind == "GET" {
+			results[i] = &current
+			continue
+		}
+		var value int64
+		var ok bool
+		if op.Kind == "SET" {
+			value, ok = addField(op.Type, 0, op.Value, op.Overflow)
+		} else {
+			value, ok = addField(op.Type, current, op.Value, op.Overflow)
+		}
+		if !ok {
+			continue
+		}
+		b = grow(b, op.Offset+int64(op.Type.Width)-1)
+		setField(b, op.Type, op.Offset, value)
+		written = true
+		if op.Kind == "SET" {
+			results[i] = &current
+		} else {
+			results[i] = &value
+		}
+	}
+	if written {
+		store.SetWithTTLAsUnixTimeStamp(key, Encode(string(b)), ttl)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "setbit", key)
+	}
+	return results
+}
+
+// BitFieldRO runs GET operations on integer fields of the string stored at key.
+func BitFieldRO(key string, ops []BitFieldOp) []int64 {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	b, _ := loadBits(key)
+	results := make([]int64, len(ops))
+	for i, op := range ops {
+		results[i] = getField(b, op.Type, op.Offset)
+	}
+	return results
+}
@@ -0,0 +1,164 @@
+package strings_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/divy-sh/animus/store"
+	"github.com/divy-sh/animus/types/strings"
+)
+
+func TestSetBitAndGetBit(t *testing.T) {
+	key := "TestSetBit"
+	if previous := strings.SetBit(key, 7, 1); previous != 0 {
+		t.Errorf("expected previous bit 0, got %d", previous)
+	}
+	if previous := strings.SetBit(key, 7, 0); previous != 1 {
+		t.Errorf("expected previous bit 1, got %d", previous)
+	}
+	strings.SetBit(key, 17, 1)
+	if val, _ := strings.Get(key); val != "\x00\x00\x40" {
+		t.Errorf("expected the string to be padded with zero bytes, got %q", val)
+	}
+	if bit := strings.GetBit(key, 17); bit != 1 {
+		t.Errorf("expected bit 1, got %d", bit)
+	}
+	if bit := strings.GetBit(key, 1000); bit != 0 {
+		t.Errorf("expected bit 0 past the end, got %d", bit)
+	}
+}
+
+func TestSetBitKeepsExpiry(t *testing.T) {
+	key := "TestSetBitKeepsExpiry"
+	store.SetWithTTL(key, "a", 100)
+	strings.SetBit(key, 0, 1)
+	if _, ttl, _ := store.GetWithTTL[string, string](key); ttl == -1 {
+		t.Errorf("expected the string to keep its expiry")
+	}
+}
+
+func TestBitCount(t *testing.T) {
+	key := "TestBitCount"
+	strings.Set(key, "foobar")
+	cases := []struct {
+		r        *strings.BitRange
+		expected int64
+	}{
+		{nil, 26},
+		{&strings.BitRange{Start: 0, End: 0, HasEnd: true}, 4},
+		{&strings.BitRange{Start: 1, End: 1, HasEnd: true}, 6},
+		{&strings.BitRange{Start: -2, End: -1, HasEnd: true}, 7},
+		{&strings.BitRange{Start: 5, End: 30, HasEnd: true, Bits: true}, 17},
+		{&strings.BitRange{Start: 4, End: 2, HasEnd: true}, 0},
+	}
+	for _, c := range cases {
+		if count := strings.BitCount(key, c.r); count != c.expected {
+			t.Errorf("expected %d bits set in %+v, got %d", c.expected, c.r, count)
+		}
+	}
+	if count := strings.BitCount("TestBitCountMissing", nil); count != 0 {
+		t.Errorf("expected 0 for a missing key, got %d", count)
+	}
+}
+
+func TestBitPos(t *testing.T) {
+	key := "TestBitPos"
+	strings.Set(key, "\xff\xf0\x00")
+	cases := []struct {
+		bit      int64
+		r        *strings.BitRange
+		expected int64
+	}{
+		{0, nil, 12},
+		{1, &strings.BitRange{Start: 2}, -1},
+		{1, &strings.BitRange{Start: 1, End: -1, HasEnd: true}, 8},
+		{0, &strings.BitRange{Start: 7, End: 15, HasEnd: true, Bits: true}, 12},
+	}
+	for _, c := range cases {
+		if pos := strings.BitPos(key, c.bit, c.r); pos != c.expected {
+			t.Errorf("expected the first %d bit of %+v at %d, got %d", c.bit, c.r, c.expected, pos)
+		}
+	}
+
+	strings.Set("TestBitPosFull", "\xff")
+	if pos := strings.BitPos("TestBitPosFull", 0, nil); pos != 8 {
+		t.Errorf("expected the position past the end, got %d", pos)
+	}
+	if pos := strings.BitPos("TestBitPosFull", 0, &strings.BitRange{Start: 0, End: 0, HasEnd: true}); pos != -1 {
+		t.Errorf("expected -1 with the end given, got %d", pos)
+	}
+	if pos := strings.BitPos("TestBitPosMissing", 1, nil); pos != -1 {
+		t.Errorf("expected -1 for a missing key, got %d", pos)
+	}
+}
+
+func TestBitOp(t *testing.T) {
+	strings.Set("TestBitOp1", "\x0f\xff")
+	strings.Set("TestBitOp2", "\x3c")
+	cases := []struct {
+		op       string
+		keys     []string
+		expected string
+	}{
+		{strings.BITOP_AND, []string{"TestBitOp1", "TestBitOp2"}, "\x0c\x00"},
+		{strings.BITOP_OR, []string{"TestBitOp1", "TestBitOp2"}, "\x3f\xff"},
+		{strings.BITOP_XOR, []string{"TestBitOp1", "TestBitOp2"}, "\x33\xff"},
+		{strings.BITOP_NOT, []string{"TestBitOp2"}, "\xc3"},
+		{strings.BITOP_DIFF, []string{"TestBitOp1", "TestBitOp2", "TestBitOpMissing"}, "\x03\xff"},
+	}
+	for _, c := range cases {
+		length, err := strings.BitOp(c.op, "TestBitOpDest", c.keys)
+		if err != nil || length != int64(len(c.expected)) {
+			t.Fatalf("expected length %d for %s, got %d (%v)", len(c.expected), c.op, length, err)
+		}
+		if val, _ := strings.Get("TestBitOpDest"); val != c.expected {
+			t.Errorf("expected %q for %s, got %q", c.expected, c.op, val)
+		}
+	}
+
+	if _, err := strings.BitOp(strings.BITOP_NOT, "TestBitOpDest", []string{"TestBitOp1", "TestBitOp2"}); err == nil {
+		t.Errorf("expected an error for NOT with two keys")
+	}
+	if _, err := strings.BitOp(strings.BITOP_DIFF, "TestBitOpDest", []string{"TestBitOp1"}); err == nil {
+		t.Errorf("expected an error for DIFF with one key")
+	}
+	if length, _ := strings.BitOp(strings.BITOP_OR, "TestBitOpDest", []string{"TestBitOpMissing"}); length != 0 {
+		t.Errorf("expected an empty result, got length %d", length)
+	}
+	if _, err := strings.Get("TestBitOpDest"); err == nil {
+		t.Errorf("expected an empty result to delete the destination")
+	}
+}
+
+func TestBitField(t *testing.T) {
+	key := "TestBitField"
+	u8 := strings.BitFieldType{Width: 8}
+	i8 := strings.BitFieldType{Signed: true, Width: 8}
+	u4 := strings.BitFieldType{Width: 4}
+	i64 := strings.BitFieldType{Signed: true, Width: 64}
+
+	results := strings.BitField(key, []strings.BitFieldOp{
+		{Kind: "SET", Type: u8, Offset: 0, Value: 200, Overflow: strings.OVERFLOW_WRAP},
+		{Kind: "GET", Type: i8, Offset: 0},
+		{Kind: "INCRBY", Type: u8, Offset: 0, Value: 100, Overflow: strings.OVERFLOW_WRAP},
+		{Kind: "INCRBY", Type: u4, Offset: 8, Value: 20, Overflow: strings.OVERFLOW_SAT},
+		{Kind: "INCRBY", Type: i8, Offset: 16, Value: -200, Overflow: strings.OVERFLOW_SAT},
+		{Kind: "INCRBY", Type: u4, Offset: 8, Value: 1, Overflow: strings.OVERFLOW_FAIL},
+		{Kind: "SET", Type: i64, Offset: 24, Value: math.MinInt64, Overflow: strings.OVERFLOW_WRAP},
+		{Kind: "INCRBY", Type: i64, Offset: 24, Value: -1, Overflow: strings.OVERFLOW_WRAP},
+	})
+	expected := []any{int64(0), int64(-56), int64(44), int64(15), int64(-128), nil, int64(0), int64(math.MaxInt64)}
+	for i, result := range results {
+		if (result == nil) != (expected[i] == nil) || (result != nil && *result != expected[i]) {
+			t.Errorf("expected %v for operation %d, got %v", expected[i], i, result)
+		}
+	}
+
+	if values := strings.BitFieldRO(key, []strings.BitFieldOp{{Kind: "GET", Type: u4, Offset: 8}}); values[0] != 15 {
+		t.Errorf("expected 15, got %v", values)
+	}
+	strings.BitField("TestBitFieldGetOnly", []strings.BitFieldOp{{Kind: "GET", Type: u8, Offset: 100}})
+	if _, err := strings.Get("TestBitFieldGetOnly"); err == nil {
+		t.Errorf("expected GET not to create the key")
+	}
+}
@@ -0,0 +1,462 @@
+package strings
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
+	"github.com/divy-sh/animus/store"
+)
+
+// HyperLogLogs are stored as strings laid out like Redis ones, so GET, SET and DUMP work on them
+// and they can be exchanged with Redis: a 16 bytes header holding the magic "HYLL", the encoding
+// and the cached cardinality, followed by 16384 registers of 6 bits. The dense encoding packs
+// the registers, the sparse one run-length encodes them with the opcodes:
+//
+//	ZERO  00xxxxxx           x+1 registers set to 0, up to 64
+//	XZERO 01xxxxxx yyyyyyyy  xy+1 registers set to 0, up to 16384
+//	VAL   1vvvvvxx           x+1 registers set to v+1, up to 4 registers up to 32
+const (
+	hllP           = 14
+	hllQ           = 64 - hllP
+	hllRegisters   = 1 << hllP
+	hllBits        = 6
+	hllHeaderSize  = 16
+	hllDenseSize   = hllHeaderSize + (hllRegisters*hllBits+7)/8
+	hllDense       = 0
+	hllSparse      = 1
+	hllSparseMax   = 32
+	hllAlphaInf    = 0.721347520444481703680
+	hllInvalidCard = 1 << 7
+)
+
+// Encodings reported by PfDebugEncoding.
+const (
+	HLL_DENSE  = "dense"
+	HLL_SPARSE = "sparse"
+)
+
+// sparseMaxBytes is the size past which a sparse HyperLogLog is converted to the dense encoding,
+// as set by hll-sparse-max-bytes.
+var sparseMaxBytes atomic.Int64
+
+func init() {
+	sparseMaxBytes.Store(3000)
+}
+
+// SetHllSparseMaxBytes sets the size past which a sparse HyperLogLog is converted to the dense encoding.
+func SetHllSparseMaxBytes(n int64) {
+	sparseMaxBytes.Store(n)
+}
+
+type registers [hllRegisters]uint8
+
+// merge sets each register of r to the maximum of its value in r and in other.
+func (r *registers) merge(other *registers) {
+	for i, v := range other {
+		r[i] = max(r[i], v)
+	}
+}
+
+// count estimates the cardinality of r with the estimator of "New cardinality estimation
+// algorithms for HyperLogLog sketches" by Otmar Ertl, as Redis does.
+func (r *registers) count() uint64 {
+	var histogram [hllQ + 2]int
+	for _, v := range r {
+		histogram[v]++
+	}
+	m := float64(hllRegisters)
+	z := m * hllTau((m-float64(histogram[hllQ+1]))/m)
+	for j := hllQ; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histogram[0])/m)
+	return uint64(math.Round(hllAlphaInf * m * m / z))
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		previous := z
+		z += x * y
+		y += y
+		if z == previous {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		previous := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == previous {
+			return z / 3
+		}
+	}
+}
+
+// murmurHash64A is the hash Redis uses for HyperLogLog elements.
+func murmurHash64A(data string, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+	h := seed ^ uint64(len(data))*m
+	for ; len(data) >= 8; data = data[8:] {
+		k := binary.LittleEndian.Uint64([]byte(data[:8]))
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+	}
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * i)
+		}
+		h *= m
+	}
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+// hllPatLen returns the register element selects and the value it sets it to: the position of
+// the first bit set in the rest of its hash.
+func hllPatLen(element string) (int, uint8) {
+	hash := murmurHash64A(element, 0xadc83b19)
+	index := int(hash & (hllRegisters - 1))
+	hash >>= hllP
+	hash |= 1 << hllQ
+	return index, uint8(bits.TrailingZeros64(hash) + 1)
+}
+
+// hll is a decoded HyperLogLog, card is its cached cardinality, only meaningful when valid.
+type hll struct {
+	regs  registers
+	dense bool
+	card  uint64
+	valid bool
+}
+
+// parseHLL decodes a HyperLogLog from the string stored for it.
+func parseHLL(s string) (*hll, error) {
+	if len(s) < hllHeaderSize || s[:4] != "HYLL" || s[4] > hllSparse || (s[4] == hllDense && len(s) != hllDenseSize) {
+		return nil, errors.New(common.ERR_HLL_WRONG_TYPE)
+	}
+	h := &hll{dense: s[4] == hllDense, valid: s[15]&hllInvalidCard == 0}
+	var card [8]byte
+	copy(card[:], s[8:hllHeaderSize])
+	card[7] &^= hllInvalidCard
+	h.card = binary.LittleEndian.Uint64(card[:])
+	if h.dense {
+		for i := range h.regs {
+			// A register can't count more than the bits of the hash left after the index.
+			if h.regs[i] = denseRegister(s[hllHeaderSize:], i); h.regs[i] > hllQ+1 {
+				return nil, errors.New(common.ERR_HLL_CORRUPTED)
+			}
+		}
+		return h, nil
+	}
+	i := 0
+	for p := hllHeaderSize; p < len(s); p++ {
+		op := s[p]
+		n, v := 0, uint8(0)
+		switch {
+		case op&0xc0 == 0x00:
+			n = int(op&0x3f) + 1
+		case op&0xc0 == 0x40:
+			if p++; p == len(s) {
+				return nil, errors.New(common.ERR_HLL_CORRUPTED)
+			}
+			n = (int(op&0x3f)<<8 | int(s[p])) + 1
+		default:
+			n, v = int(op&0x03)+1, (op>>2)&0x1f+1
+		}
+		if i+n > hllRegisters {
+			return nil, errors.New(common.ERR_HLL_CORRUPTED)
+		}
+		for ; n > 0; n-- {
+			h.regs[i] = v
+			i++
+		}
+	}
+	if i != hllRegisters {
+		return nil, errors.New(common.ERR_HLL_CORRUPTED)
+	}
+	return h, nil
+}
+
+// denseRegister returns register i of the packed registers b. Registers are stored least
+// significant bit first and may straddle two bytes.
+func denseRegister(b string, i int) uint8 {
+	byteIndex, shift := i*hllBits/8, uint(i*hllBits%8)
+	v := uint16(b[byteIndex])
+	if byteIndex+1 < len(b) {
+		v |= uint16(b[byteIndex+1]) << 8
+	}
+	return uint8(v>>shift) & (1<<hllBits - 1)
+}
+
+func setDenseRegister(b []byte, i int, value uint8) {
+	byteIndex, shift := i*hllBits/8, uint(i*hllBits%8)
+	v := uint16(value) << shift
+	b[byteIndex] |= byte(v)
+	if byteIndex+1 < len(b) {
+		b[byteIndex+1] |= byte(v >> 8)
+	}
+}
+
+func (h *hll) header(encoding byte) []byte {
+	b := []byte{'H', 'Y', 'L', 'L', encoding, 0, 0, 0}
+	b = binary.LittleEndian.AppendUint64(b, h.card)
+	if !h.valid {
+		b[15] |= hllInvalidCard
+	}
+	return b
+}
+
+// sparse encodes h sparsely, false if one of its registers is too large for the sparse
+// encoding or the result would be larger than hll-sparse-max-bytes.
+func (h *hll) sparse() ([]byte, bool) {
+	b := h.header(hllSparse)
+	for i := 0; i < hllRegisters; {
+		v, run := h.regs[i], 1
+		for i+run < hllRegisters && h.regs[i+run] == v {
+			run++
+		}
+		i += run
+		switch {
+		case v > hllSparseMax:
+			return nil, false
+		case v == 0 && run <= 64:
+			b = append(b, byte(run-1))
+		case v == 0:
+			b = append(b, 0x40|byte((run-1)>>8), byte(run-1))
+		default:
+			for ; run > 0; run -= 4 {
+				b = append(b, 0x80|(v-1)<<2|byte(min(run, 4)-1))
+			}
+		}
+	}
+	return b, int64(len(b)) <= sparseMaxBytes.Load()
+}
+
+// bytes encodes h in its encoding, converting it to the dense one if it no longer fits the sparse one.
+func (h *hll) bytes() []byte {
+	if !h.dense {
+		if b, ok := h.sparse(); ok {
+			return b
+		}
+		h.dense = true
+	}
+	b := append(h.header(hllDense), make([]byte, hllDenseSize-hllHeaderSize)...)
+	for i, v := range h.regs {
+		setDenseRegister(b[hllHeaderSize:], i, v)
+	}
+	return b
+}
+
+// loadHLL returns the HyperLogLog stored at key, nil if there is none, along with its expiry.
+// The caller must hold a lock on the key.
+func loadHLL(key string) (*hll, int64, error) {
+	val, ttl, ok := getWithTTL(key)
+	if !ok {
+		return nil, -1, nil
+	}
+	h, err := parseHLL(val)
+	return h, ttl, err
+}
+
+// PfAdd adds elements to the HyperLogLog stored at key, creating it if needed, and reports
+// whether it changed. The HyperLogLog keeps its expiry.
+func PfAdd(key string, elements []string) (bool, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	h, ttl, err := loadHLL(key)
+	if err != nil {
+		return false, err
+	}
+	updated := h == nil
+	if h == nil {
+		h = &hll{valid: true}
+	}
+	for _, element := range elements {
+		index, count := hllPatLen(element)
+		if count > h.regs[index] {
+			h.regs[index] = count
+			h.valid = false
+			updated = true
+		}
+	}
+	if !updated {
+		return false, nil
+	}
+	store.SetWithTTLAsUnixTimeStamp(key, string(h.bytes()), ttl)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "pfadd", key)
+	return true, nil
+}
+
+// PfCount returns the approximate number of distinct elements added to the HyperLogLogs stored
+// at keys, 0 for missing keys. The cardinality of a single HyperLogLog is cached in its header.
+func PfCount(keys []string) (int64, error) {
+	store.LockKeys(keys...)
+	defer store.UnlockKeys(keys...)
+
+	if len(keys) == 1 {
+		h, ttl, err := loadHLL(keys[0])
+		if err != nil || h == nil {
+			return 0, err
+		}
+		if !h.valid {
+			h.card, h.valid = h.regs.count(), true
+			store.SetWithTTLAsUnixTimeStamp(keys[0], string(h.bytes()), ttl)
+		}
+		return int64(h.card), nil
+	}
+	var merged registers
+	for _, key := range keys {
+		h, _, err := loadHLL(key)
+		if err != nil {
+			return 0, err
+		}
+		if h != nil {
+			merged.merge(&h.regs)
+		}
+	}
+	return int64(merged.count()), nil
+}
+
+// PfMerge stores at dest the union of the HyperLogLogs stored at dest and sources. The result
+// is dense if one of them is.
+func PfMerge(dest string, sources []string) error {
+	keys := append([]string{dest}, sources...)
+	store.LockKeys(keys...)
+	defer store.UnlockKeys(keys...)
+
+	merged := &hll{}
+	destTTL := int64(-1)
+	for i, key := range keys {
+		h, ttl, err := loadHLL(key)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			destTTL = ttl
+		}
+		if h != nil {
+			merged.regs.merge(&h.regs)
+			merged.dense = merged.dense || h.dense
+		}
+	}
+	store.SetWithTTLAsUnixTimeStamp(dest, string(merged.bytes()), destTTL)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "pfadd", dest)
+	return nil
+}
+
+// loadExistingHLL is loadHLL for the PFDEBUG subcommands, which fail on missing keys.
+func loadExistingHLL(key string) (*hll, int64, error) {
+	h, ttl, err := loadHLL(key)
+	if err == nil && h == nil {
+		err = errors.New(common.ERR_KEY_NOT_FOUND)
+	}
+	return h, ttl, err
+}
+
+// PfDebugGetReg returns the registers of the HyperLogLog stored at key, converting it to the
+// dense encoding like Redis does.
+func PfDebugGetReg(key string) ([]int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	h, ttl, err := loadExistingHLL(key)
+	if err != nil {
+		return nil, err
+	}
+	if !h.dense {
+		h.dense = true
+		store.SetWithTTLAsUnixTimeStamp(key, string(h.bytes()), ttl)
+	}
+	regs := make([]int64, hllRegisters)
+	for i, v := range h.regs {
+		regs[i] = int64(v)
+	}
+	return regs, nil
+}
+
+// PfDebugDecode describes the opcodes of the sparse HyperLogLog stored at key.
+func PfDebugDecode(key string) (string, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	h, _, err := loadExistingHLL(key)
+	if err != nil {
+		return "", err
+	}
+	if h.dense {
+		return "", errors.New(common.ERR_HLL_NOT_SPARSE)
+	}
+	b, _ := get(key)
+	ops := []string{}
+	for p := hllHeaderSize; p < len(b); p++ {
+		op := b[p]
+		switch {
+		case op&0xc0 == 0x00:
+			ops = append(ops, "Z:"+strconv.Itoa(int(op&0x3f)+1))
+		case op&0xc0 == 0x40:
+			p++
+			ops = append(ops, "XZ:"+strconv.Itoa((int(op&0x3f)<<8|int(b[p]))+1))
+		default:
+			ops = append(ops, "v:"+strconv.Itoa(int((op>>2)&0x1f)+1)+","+strconv.Itoa(int(op&0x03)+1))
+		}
+	}
+	return strings.Join(ops, " "), nil
+}
+
+// PfDebugEncoding returns the encoding of the HyperLogLog stored at key, HLL_DENSE or HLL_SPARSE.
+func PfDebugEncoding(key string) (string, error) {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	h, _, err := loadExistingHLL(key)
+	if err != nil {
+		return "", err
+	}
+	if h.dense {
+		return HLL_DENSE, nil
+	}
+	return HLL_SPARSE, nil
+}
+
+// PfDebugToDense converts the HyperLogLog stored at key to the dense encoding and reports
+// whether it was sparse.
+func PfDebugToDense(key string) (bool, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	h, ttl, err := loadExistingHLL(key)
+	if err != nil || h.dense {
+		return false, err
+	}
+	h.dense = true
+	store.SetWithTTLAsUnixTimeStamp(key, string(h.bytes()), ttl)
+	return true, nil
+}
@@ -0,0 +1,150 @@
+package strings_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/types/strings"
+)
+
+func TestPfAddAndCount(t *testing.T) {
+	key := "TestPfAddAndCount"
+	if updated, err := strings.PfAdd(key, []string{"a", "b", "c"}); err != nil || !updated {
+		t.Fatalf("expected the HyperLogLog to be updated, got %v %v", updated, err)
+	}
+	if updated, _ := strings.PfAdd(key, []string{"a", "b"}); updated {
+		t.Errorf("expected no update when adding known elements")
+	}
+	if count, err := strings.PfCount([]string{key}); err != nil || count != 3 {
+		t.Errorf("expected 3, got %d %v", count, err)
+	}
+	if val, _ := strings.Get(key); val[:4] != "HYLL" || val[15]&0x80 != 0 {
+		t.Errorf("expected a HyperLogLog string with its cardinality cached, got %q", val[:16])
+	}
+	if encoding, _ := strings.PfDebugEncoding(key); encoding != strings.HLL_SPARSE {
+		t.Errorf("expected a small HyperLogLog to be sparse, got %s", encoding)
+	}
+	if updated, _ := strings.PfAdd("TestPfAddAndCountEmpty", nil); !updated {
+		t.Errorf("expected adding no elements to create the key")
+	}
+	if decoded, _ := strings.PfDebugDecode("TestPfAddAndCountEmpty"); decoded != "XZ:16384" {
+		t.Errorf("expected XZ:16384, got %s", decoded)
+	}
+	if count, _ := strings.PfCount([]string{"TestPfAddAndCountMissing"}); count != 0 {
+		t.Errorf("expected 0 for a missing key, got %d", count)
+	}
+}
+
+func TestPfCount_ErrorRate(t *testing.T) {
+	key := "TestPfCount_ErrorRate"
+	elements := make([]string, 0, 1000)
+	for i := range 100000 {
+		elements = append(elements, "element:"+strconv.Itoa(i))
+		if len(elements) == cap(elements) {
+			strings.PfAdd(key, elements)
+			elements = elements[:0]
+		}
+		if i == 999 {
+			// The standard error with 16384 registers is 0.81%, small cardinalities are far more accurate.
+			if count, _ := strings.PfCount([]string{key}); math.Abs(float64(count)-1000) > 10 {
+				t.Errorf("expected about 1000, got %d", count)
+			}
+		}
+	}
+	count, _ := strings.PfCount([]string{key})
+	if math.Abs(float64(count)-100000)/100000 > 0.025 {
+		t.Errorf("expected about 100000, got %d", count)
+	}
+	if encoding, _ := strings.PfDebugEncoding(key); encoding != strings.HLL_DENSE {
+		t.Errorf("expected a large HyperLogLog to be dense, got %s", encoding)
+	}
+	if updated, _ := strings.PfAdd(key, []string{"element:1"}); updated {
+		t.Errorf("expected no update when adding a known element to a dense HyperLogLog")
+	}
+}
+
+func TestPfMerge(t *testing.T) {
+	strings.PfAdd("TestPfMerge1", []string{"a", "b", "c"})
+	strings.PfAdd("TestPfMerge2", []string{"c", "d"})
+	if count, _ := strings.PfCount([]string{"TestPfMerge1", "TestPfMerge2", "TestPfMergeMissing"}); count != 4 {
+		t.Errorf("expected 4 across keys, got %d", count)
+	}
+	strings.PfAdd("TestPfMergeDest", []string{"e"})
+	if err := strings.PfMerge("TestPfMergeDest", []string{"TestPfMerge1", "TestPfMerge2"}); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := strings.PfCount([]string{"TestPfMergeDest"}); count != 5 {
+		t.Errorf("expected 5 after merging, got %d", count)
+	}
+	strings.PfDebugToDense("TestPfMerge2")
+	strings.PfMerge("TestPfMergeDense", []string{"TestPfMerge1", "TestPfMerge2"})
+	if encoding, _ := strings.PfDebugEncoding("TestPfMergeDense"); encoding != strings.HLL_DENSE {
+		t.Errorf("expected merging a dense HyperLogLog to give a dense one, got %s", encoding)
+	}
+	if count, _ := strings.PfCount([]string{"TestPfMergeDense"}); count != 4 {
+		t.Errorf("expected 4, got %d", count)
+	}
+}
+
+func TestPfDebug(t *testing.T) {
+	key := "TestPfDebug"
+	strings.PfAdd(key, []string{"a"})
+	regs, err := strings.PfDebugGetReg(key)
+	if err != nil || len(regs) != 16384 {
+		t.Fatalf("expected 16384 registers, got %d %v", len(regs), err)
+	}
+	set := 0
+	for _, v := range regs {
+		if v != 0 {
+			set++
+		}
+	}
+	if set != 1 {
+		t.Errorf("expected a single register set, got %d", set)
+	}
+	if encoding, _ := strings.PfDebugEncoding(key); encoding != strings.HLL_DENSE {
+		t.Errorf("expected GETREG to convert to dense, got %s", encoding)
+	}
+	if _, err := strings.PfDebugDecode(key); err == nil || err.Error() != common.ERR_HLL_NOT_SPARSE {
+		t.Errorf("expected %s, got %v", common.ERR_HLL_NOT_SPARSE, err)
+	}
+	if converted, _ := strings.PfDebugToDense(key); converted {
+		t.Errorf("expected a dense HyperLogLog not to be converted")
+	}
+	if _, err := strings.PfDebugEncoding("TestPfDebugMissing"); err == nil || err.Error() != common.ERR_KEY_NOT_FOUND {
+		t.Errorf("expected %s, got %v", common.ERR_KEY_NOT_FOUND, err)
+	}
+}
+
+func TestPfAdd_InvalidValues(t *testing.T) {
+	strings.Set("TestPfAdd_NotHLL", "value")
+	if _, err := strings.PfAdd("TestPfAdd_NotHLL", []string{"a"}); err == nil || err.Error() != common.ERR_HLL_WRONG_TYPE {
+		t.Errorf("expected %s, got %v", common.ERR_HLL_WRONG_TYPE, err)
+	}
+	// A sparse HyperLogLog whose opcodes only cover a single register.
+	strings.Set("TestPfAdd_Corrupted", "HYLL\x01"+string(make([]byte, 11))+"\x00")
+	if _, err := strings.PfCount([]string{"TestPfAdd_Corrupted"}); err == nil || err.Error() != common.ERR_HLL_CORRUPTED {
+		t.Errorf("expected %s, got %v", common.ERR_HLL_CORRUPTED, err)
+	}
+	// A dense HyperLogLog with every 6 bit register at 63, beyond the 51 a register can reach.
+	dense := make([]byte, 16+16384*6/8)
+	copy(dense, "HYLL")
+	for i := 16; i < len(dense); i++ {
+		dense[i] = 0xff
+	}
+	strings.Set("TestPfAdd_CorruptedDense", string(dense))
+	if _, err := strings.PfCount([]string{"TestPfAdd_CorruptedDense"}); err == nil || err.Error() != common.ERR_HLL_CORRUPTED {
+		t.Errorf("expected %s, got %v", common.ERR_HLL_CORRUPTED, err)
+	}
+}
+
+func TestPfAdd_SparseMaxBytes(t *testing.T) {
+	strings.SetHllSparseMaxBytes(0)
+	defer strings.SetHllSparseMaxBytes(3000)
+	strings.PfAdd("TestPfAdd_SparseMaxBytes", []string{"a"})
+	if encoding, _ := strings.PfDebugEncoding("TestPfAdd_SparseMaxBytes"); encoding != strings.HLL_DENSE {
+		t.Errorf("expected dense past hll-sparse-max-bytes, got %s", encoding)
+	}
+}
@@ -0,0 +1,69 @@
+package strings
+
+import (
+	"strconv"
+
+	"github.com/divy-sh/animus/store"
+)
+
+// sharedIntegers is the number of small integers whose strings are shared by every read of
+// a value equal to one of them, rather than formatted each time.
+const sharedIntegers = 10000
+
+var shared [sharedIntegers]string
+
+func init() {
+	for i := range shared {
+		shared[i] = strconv.Itoa(i)
+	}
+}
+
+// Encode returns the value a string is stored as. A string holding a 64-bit integer, written
+// the way strconv.FormatInt writes it, is stored as an int64, which takes less memory than its digits.
+func Encode(value string) any {
+	if len(value) == 0 || len(value) > 20 {
+		return value
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(n, 10) == value {
+		return n
+	}
+	return value
+}
+
+// decode returns the string a stored value holds, false if it isn't a string.
+func decode(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int64:
+		if v >= 0 && v < sharedIntegers {
+			return shared[v], true
+		}
+		return strconv.FormatInt(v, 10), true
+	default:
+		return "", false
+	}
+}
+
+// get returns the string stored at key. The caller must hold a lock on the key.
+func get(key string) (string, bool) {
+	val, ok := store.Get[string, any](key)
+	if !ok {
+		return "", false
+	}
+	return decode(val)
+}
+
+// getWithTTL returns the string stored at key along with its expiry.
+// The caller must hold a lock on the key.
+func getWithTTL(key string) (string, int64, bool) {
+	val, ttl, ok := store.GetWithTTL[string, any](key)
+	if !ok {
+		return "", -1, false
+	}
+	s, ok := decode(val)
+	if !ok {
+		return "", -1, false
+	}
+	return s, ttl, true
+}
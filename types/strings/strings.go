@@ -3,10 +3,13 @@ package strings
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/pubsub"
 	"github.com/divy-sh/animus/store"
 )
 
@@ -15,44 +18,36 @@ func Append(key, value string) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	val, ok := store.Get[string, string](key)
+	val, ok := get(key)
 	if !ok {
-		store.Set(key, value)
-		return
+		store.Set(key, Encode(value))
+	} else {
+		store.Set(key, Encode(val+value))
 	}
-	store.Set(key, val+value)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "append", key)
 }
 
-func Decr(key string) error {
+func Decr(key string) (int64, error) {
 	return DecrBy(key, "1")
 }
 
-func DecrBy(key, value string) error {
-	store.LockKeys(key)
-	defer store.UnlockKeys(key)
-
+// DecrBy decrements the integer stored at key, 0 if it doesn't exist, and returns the new value.
+func DecrBy(key, value string) (int64, error) {
 	decrVal, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return errors.New("ERR invalid decrement value")
+		return 0, errors.New("ERR invalid decrement value")
 	}
-	val, ok := store.Get[string, string](key)
-	if !ok {
-		store.Set(key, fmt.Sprint(-decrVal))
-		return nil
+	if decrVal == math.MinInt64 {
+		return 0, errors.New(common.ERR_INCREMENT_OVERFLOW)
 	}
-	intVal, err := strconv.ParseInt(val, 10, 64)
-	if err != nil {
-		return errors.New("ERR value is not an integer or out of range")
-	}
-	store.Set(key, fmt.Sprint(intVal-decrVal))
-	return nil
+	return incrBy(key, -decrVal, "decrby")
 }
 
 func Get(key string) (string, error) {
 	store.RLockKeys(key)
 	defer store.RUnlockKeys(key)
 
-	val, ok := store.Get[string, string](key)
+	val, ok := get(key)
 	if !ok {
 		return "", errors.New(common.ERR_STRING_NOT_FOUND)
 	}
@@ -63,27 +58,40 @@ func GetDel(key string) (string, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	val, ok := store.Get[string, string](key)
+	val, ok := get(key)
 	if !ok {
 		return "", errors.New(common.ERR_STRING_NOT_FOUND)
 	}
 	store.Delete(key)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
 	return val, nil
 }
 
-func GetEx(key, exp string) (string, error) {
+// GetEx returns the string stored at key and updates its expiry. expireAt is the unix time in
+// seconds the key expires at, -1 removes its expiry and 0 leaves it unchanged. A time in the
+// past deletes the key.
+func GetEx(key string, expireAt int64) (string, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	val, ok := store.Get[string, string](key)
+	val, ttl, ok := getWithTTL(key)
 	if !ok {
 		return "", errors.New(common.ERR_STRING_NOT_FOUND)
 	}
-	expSeconds, err := strconv.ParseInt(exp, 10, 64)
-	if err != nil {
-		return "", errors.New("ERR invalid expire time")
+	switch {
+	case expireAt == 0:
+	case expireAt == -1:
+		if ttl != -1 {
+			store.Set(key, Encode(val))
+			pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "persist", key)
+		}
+	case expireAt <= time.Now().Unix():
+		store.Delete(key)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "del", key)
+	default:
+		store.SetWithTTLAsUnixTimeStamp(key, Encode(val), expireAt)
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "expire", key)
 	}
-	store.SetWithTTL(key, val, expSeconds)
 	return val, nil
 }
 
@@ -91,7 +99,7 @@ func GetRange(key, start, end string) (string, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	val, ok := store.Get[string, string](key)
+	val, ok := get(key)
 	if !ok {
 		return "", errors.New(common.ERR_STRING_NOT_FOUND)
 	}
@@ -115,41 +123,52 @@ func GetRange(key, start, end string) (string, error) {
 	return val[startInd : endInd+1], nil
 }
 
+// Substr returns the part of the string stored at key from start to end, both inclusive.
+// Negative positions count from the end of the string and the range is clamped to it, so
+// out of range positions or a missing key give an empty string.
+func Substr(key string, start, end int64) string {
+	store.RLockKeys(key)
+	defer store.RUnlockKeys(key)
+
+	val, _ := get(key)
+	length := int64(len(val))
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if end < 0 {
+		end = length + end
+	}
+	end = min(end, length-1)
+	if start > end {
+		return ""
+	}
+	return val[start : end+1]
+}
+
 func GetSet(key, value string) (string, error) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	val, ok := store.Get[string, string](key)
+	val, ok := get(key)
 	if !ok {
 		return "", errors.New(common.ERR_STRING_NOT_FOUND)
 	}
-	store.Set(key, value)
+	store.Set(key, Encode(value))
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "set", key)
 	return val, nil
 }
 
-func Incr(key string) error {
+func Incr(key string) (int64, error) {
 	return IncrBy(key, "1")
 }
 
-func IncrBy(key, value string) error {
-	store.LockKeys(key)
-	defer store.UnlockKeys(key)
-
+// IncrBy increments the integer stored at key, 0 if it doesn't exist, and returns the new value.
+func IncrBy(key, value string) (int64, error) {
 	incrVal, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return errors.New("ERR invalid increment value")
-	}
-	val, ok := store.Get[string, string](key)
-	if !ok {
-		store.Set(key, value)
-		return nil
-	}
-	intVal, err := strconv.ParseInt(val, 10, 64)
-	if err != nil {
-		return errors.New("ERR value is not an integer or out of range")
+		return 0, errors.New("ERR invalid increment value")
 	}
-	store.Set(key, fmt.Sprint(intVal+incrVal))
-	return nil
+	return incrBy(key, incrVal, "incrby")
 }
 
 func IncrByFloat(key, value string) error {
@@ -160,24 +179,40 @@ func IncrByFloat(key, value string) error {
 	if err != nil {
 		return errors.New("ERR invalid increment value")
 	}
-	val, ok := store.Get[string, string](key)
+	val, ok := get(key)
 	if !ok {
-		store.Set(key, value)
+		store.Set(key, Encode(value))
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "incrbyfloat", key)
 		return nil
 	}
 	floatVal, err := strconv.ParseFloat(val, 64)
 	if err != nil {
 		return errors.New("ERR value is not a float or out of range")
 	}
-	store.Set(key, fmt.Sprint(floatVal+incrVal))
+	store.Set(key, Encode(fmt.Sprint(floatVal+incrVal)))
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "incrbyfloat", key)
 	return nil
 }
 
+// SetNx sets key to value only if it doesn't exist, and reports whether it did.
+func SetNx(key, value string) bool {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	if _, ok := store.Get[string, any](key); ok {
+		return false
+	}
+	store.Set(key, Encode(value))
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "set", key)
+	return true
+}
+
 func Set(key, value string) {
 	store.LockKeys(key)
 	defer store.UnlockKeys(key)
 
-	store.Set(key, value)
+	store.Set(key, Encode(value))
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "set", key)
 }
 
 func SetEx(key, value, seconds string) error {
@@ -188,7 +223,9 @@ func SetEx(key, value, seconds string) error {
 	if err != nil {
 		return errors.New(common.ERR_INVALID_TIME_SECONDS)
 	}
-	store.SetWithTTL(key, value, secs)
+	store.SetWithTTL(key, Encode(value), secs)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "set", key)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_GENERIC, "expire", key)
 	return nil
 }
 
@@ -200,7 +237,10 @@ func SetRange(key, offsetStr, value string) error {
 	if err != nil || offset < 0 {
 		return errors.New(common.ERR_OUT_OF_RANGE)
 	}
-	currentVal, ok := store.Get[string, string](key)
+	if offset > MaxStringLength-int64(len(value)) {
+		return errors.New(common.ERR_STRING_TOO_LONG)
+	}
+	currentVal, ok := get(key)
 	if !ok {
 		currentVal = ""
 	}
@@ -211,14 +251,15 @@ func SetRange(key, offsetStr, value string) error {
 	if int64(len(currentVal)) > offset+int64(len(value)) {
 		newVal += currentVal[offset+int64(len(value)):]
 	}
-	store.Set(key, newVal)
+	store.Set(key, Encode(newVal))
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "setrange", key)
 	return nil
 }
 
 func Lcs(key1 string, key2 string, commands []string) (string, error) {
 	store.RLockKeys(key1, key2)
-	val1, ok1 := store.Get[string, string](key1)
-	val2, ok2 := store.Get[string, string](key2)
+	val1, ok1 := get(key1)
+	val2, ok2 := get(key2)
 	store.RUnlockKeys(key1, key2)
 
 	if !ok1 || !ok2 {
@@ -232,13 +273,43 @@ func Lcs(key1 string, key2 string, commands []string) (string, error) {
 	return lcs, nil
 }
 
+// LcsMatch is a common substring of two strings, from A[0] to A[1] in the first one and from
+// B[0] to B[1] in the second one, both inclusive.
+type LcsMatch struct {
+	A   [2]int64
+	B   [2]int64
+	Len int64
+}
+
+// LcsIdx returns the matching substrings making up the longest common subsequence of the values
+// of two keys, from the last one to the first one, along with its length. Matches shorter than
+// minMatchLen are left out, though still counted in the length.
+func LcsIdx(key1, key2 string, minMatchLen int64) ([]LcsMatch, int64, error) {
+	store.RLockKeys(key1, key2)
+	val1, ok1 := get(key1)
+	val2, ok2 := get(key2)
+	store.RUnlockKeys(key1, key2)
+
+	if !ok1 || !ok2 {
+		return nil, 0, errors.New(common.ERR_STRING_NOT_FOUND)
+	}
+	matches, lcsLen := findLcsMatches(val1, val2)
+	kept := []LcsMatch{}
+	for _, match := range matches {
+		if match.Len >= minMatchLen {
+			kept = append(kept, match)
+		}
+	}
+	return kept, lcsLen, nil
+}
+
 func MGet(keys *[]string) *[]string {
 	store.RLockKeys(*keys...)
 	defer store.RUnlockKeys(*keys...)
 
 	values := make([]string, len(*keys))
 	for i, key := range *keys {
-		val, ok := store.Get[string, string](key)
+		val, ok := get(key)
 		if !ok {
 			values[i] = ""
 		} else {
@@ -257,12 +328,56 @@ func MSet(kvPairs *map[string]string) {
 	defer store.UnlockKeys(keys...)
 
 	for key, val := range *kvPairs {
-		store.Set(key, val)
+		store.Set(key, Encode(val))
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "set", key)
+	}
+}
+
+// MSetNx sets all the keys to their values only if none of them exists, and reports whether it did.
+func MSetNx(kvPairs *map[string]string) bool {
+	keys := make([]string, 0, len(*kvPairs))
+	for k := range *kvPairs {
+		keys = append(keys, k)
+	}
+	store.LockKeys(keys...)
+	defer store.UnlockKeys(keys...)
+
+	for _, key := range keys {
+		if _, ok := store.Get[string, any](key); ok {
+			return false
+		}
+	}
+	for key, val := range *kvPairs {
+		store.Set(key, Encode(val))
+		pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, "set", key)
 	}
+	return true
 }
 
 /* PRIVATE FUNCTIONS */
 
+// incrBy adds delta to the integer stored at key and returns the new value, failing rather than
+// overflowing 64 bits.
+func incrBy(key string, delta int64, event string) (int64, error) {
+	store.LockKeys(key)
+	defer store.UnlockKeys(key)
+
+	var intVal int64
+	if val, ok := get(key); ok {
+		var err error
+		if intVal, err = strconv.ParseInt(val, 10, 64); err != nil {
+			return 0, errors.New(common.ERR_INVALID_INTEGER)
+		}
+	}
+	if (delta > 0 && intVal > math.MaxInt64-delta) || (delta < 0 && intVal < math.MinInt64-delta) {
+		return 0, errors.New(common.ERR_INCREMENT_OVERFLOW)
+	}
+	intVal += delta
+	store.Set(key, intVal)
+	pubsub.NotifyKeyspaceEvent(pubsub.NOTIFY_STRING, event, key)
+	return intVal, nil
+}
+
 func findLcs(str1, str2 string) (string, int) {
 	m, n := len(str1), len(str2)
 	if m < n {
@@ -303,11 +418,63 @@ func findLcs(str1, str2 string) (string, int) {
 	return string(lcs), lcsLen
 }
 
+// findLcsMatches returns the matching substrings making up the longest common subsequence of
+// two strings, walking back from their ends, along with its length.
+func findLcsMatches(a, b string) ([]LcsMatch, int64) {
+	m, n := len(a), len(b)
+	// table[i][j] is the length of the longest common subsequence of a[:i] and b[:j].
+	table := make([][]int32, m+1)
+	for i := range table {
+		table[i] = make([]int32, n+1)
+	}
+	for i := 1; i <= m; i++ {
+		for j := 1; j <= n; j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else {
+				table[i][j] = max(table[i-1][j], table[i][j-1])
+			}
+		}
+	}
+
+	matches := []LcsMatch{}
+	var current *LcsMatch
+	i, j := m, n
+	for i > 0 && j > 0 {
+		if a[i-1] == b[j-1] {
+			// Consecutive matches walking back on both strings extend the current range.
+			if current == nil {
+				current = &LcsMatch{A: [2]int64{int64(i - 1), int64(i - 1)}, B: [2]int64{int64(j - 1), int64(j - 1)}}
+			} else {
+				current.A[0], current.B[0] = int64(i-1), int64(j-1)
+			}
+			i--
+			j--
+			continue
+		}
+		if current != nil {
+			current.Len = current.A[1] - current.A[0] + 1
+			matches = append(matches, *current)
+			current = nil
+		}
+		if table[i-1][j] > table[i][j-1] {
+			i--
+		} else {
+			j--
+		}
+	}
+	if current != nil {
+		current.Len = current.A[1] - current.A[0] + 1
+		matches = append(matches, *current)
+	}
+	return matches, int64(table[m][n])
+}
+
 func StrLen(key string) (int64, error) {
 	store.RLockKeys(key)
 	defer store.RUnlockKeys(key)
 
-	val, ok := store.Get[string, string](key)
+	val, ok := get(key)
 	if !ok {
 		return 0, errors.New(common.ERR_STRING_NOT_FOUND)
 	}
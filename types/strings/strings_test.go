@@ -3,11 +3,13 @@ package strings_test
 import (
 	"errors"
 	"math"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/divy-sh/animus/common"
+	"github.com/divy-sh/animus/types/generics"
 	"github.com/divy-sh/animus/types/strings"
 )
 
@@ -70,31 +72,64 @@ func TestGetDelInvalidKey(t *testing.T) {
 func TestGetEx(t *testing.T) {
 	strings.Set("key1", "value1")
 
-	val, err := strings.GetEx("key1", "0")
+	val, err := strings.GetEx("key1", time.Now().Unix()-1)
 	if err != nil || val != "value1" {
 		t.Errorf("Expected value1, got %v, err: %v", val, err)
 	}
-	time.Sleep(50 * time.Millisecond)
 	_, err = strings.Get("key1")
 	if err == nil {
 		t.Errorf("Expected error for deleted key, but got none")
 	}
 }
 
+func TestGetExSetsAndRemovesExpiry(t *testing.T) {
+	strings.Set("key1", "value1")
+	expireAt := time.Now().Unix() + 100
+
+	if _, err := strings.GetEx("key1", expireAt); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ttl := generics.TTL("key1"); ttl < 99 || ttl > 100 {
+		t.Errorf("Expected a ttl of 100 seconds, got %d", ttl)
+	}
+	if _, err := strings.GetEx("key1", 0); err != nil || generics.TTL("key1") < 99 {
+		t.Errorf("Expected the expiry to be kept, got ttl %d, err: %v", generics.TTL("key1"), err)
+	}
+	if _, err := strings.GetEx("key1", -1); err != nil || generics.TTL("key1") != -1 {
+		t.Errorf("Expected the expiry to be removed, got ttl %d, err: %v", generics.TTL("key1"), err)
+	}
+}
+
 func TestGetExInvalidKey(t *testing.T) {
 
-	_, err := strings.GetEx("invalid_key", "1")
+	_, err := strings.GetEx("invalid_key", 0)
 	if err == nil || err.Error() != common.ERR_STRING_NOT_FOUND {
 		t.Errorf("Expected error: %v, got: %v", common.ERR_STRING_NOT_FOUND, err)
 	}
 }
 
-func TestGetExInvalidTime(t *testing.T) {
-	strings.Set("key1", "value1")
+func TestSubstr(t *testing.T) {
+	strings.Set("hello", "Hello, World!")
 
-	_, err := strings.GetEx("key1", "invalid")
-	if err == nil || err.Error() != "ERR invalid expire time" {
-		t.Errorf("Expected error: %v, got: %v", "ERR invalid expire time", err)
+	tests := []struct {
+		start, end int64
+		expected   string
+	}{
+		{0, 4, "Hello"},
+		{-6, -2, "World"},
+		{-50, 4, "Hello"},
+		{7, 50, "World!"},
+		{5, 2, ""},
+		{20, 30, ""},
+		{0, -50, ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Substr("hello", tt.start, tt.end); got != tt.expected {
+			t.Errorf("Substr(%d, %d): expected %q, got %q", tt.start, tt.end, tt.expected, got)
+		}
+	}
+	if got := strings.Substr("TestSubstrMissing", 0, -1); got != "" {
+		t.Errorf("Expected an empty string for a missing key, got %q", got)
 	}
 }
 
@@ -173,7 +208,7 @@ func TestGetRangeEmptyVal(t *testing.T) {
 func TestDecr(t *testing.T) {
 	strings.Set("num", "10")
 
-	err := strings.Decr("num")
+	_, err := strings.Decr("num")
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -187,7 +222,7 @@ func TestDecr(t *testing.T) {
 func TestDecrBy(t *testing.T) {
 	strings.Set("num", "10")
 
-	err := strings.DecrBy("num", "3")
+	_, err := strings.DecrBy("num", "3")
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -200,7 +235,7 @@ func TestDecrBy(t *testing.T) {
 
 func TestDecrByNewKey(t *testing.T) {
 
-	err := strings.DecrBy("TestDecrByNewKey", "3")
+	_, err := strings.DecrBy("TestDecrByNewKey", "3")
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -214,7 +249,7 @@ func TestDecrByNewKey(t *testing.T) {
 func TestDecrByInvalidValue(t *testing.T) {
 	strings.Set("num", "Z")
 
-	err := strings.DecrBy("num", "3")
+	_, err := strings.DecrBy("num", "3")
 	if err == nil {
 		t.Errorf("Expected error for invalid value, got: %v", err)
 	}
@@ -223,7 +258,7 @@ func TestDecrByInvalidValue(t *testing.T) {
 func TestDecrByInvalid(t *testing.T) {
 	strings.Set("num", "10")
 
-	err := strings.DecrBy("num", "invalid")
+	_, err := strings.DecrBy("num", "invalid")
 	if err == nil || err.Error() != "ERR invalid decrement value" {
 		t.Errorf("Expected error for invalid decrement, got: %v", err)
 	}
@@ -255,7 +290,7 @@ func TestGetSetInvalidKey(t *testing.T) {
 func TestIncr(t *testing.T) {
 	strings.Set("num", "10")
 
-	err := strings.Incr("num")
+	_, err := strings.Incr("num")
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -269,7 +304,7 @@ func TestIncr(t *testing.T) {
 func TestIncrBy(t *testing.T) {
 	strings.Set("num", "10")
 
-	err := strings.IncrBy("num", "3")
+	_, err := strings.IncrBy("num", "3")
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -282,7 +317,7 @@ func TestIncrBy(t *testing.T) {
 
 func TestIncrByNewKey(t *testing.T) {
 
-	err := strings.IncrBy("TestIncrByNewKey", "3")
+	_, err := strings.IncrBy("TestIncrByNewKey", "3")
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -296,7 +331,7 @@ func TestIncrByNewKey(t *testing.T) {
 func TestIncrByInvalidValue(t *testing.T) {
 	strings.Set("num", "Z")
 
-	err := strings.IncrBy("num", "3")
+	_, err := strings.IncrBy("num", "3")
 	if err == nil {
 		t.Errorf("Expected error for invalid value, got: %v", err)
 	}
@@ -305,12 +340,48 @@ func TestIncrByInvalidValue(t *testing.T) {
 func TestIncrByInvalid(t *testing.T) {
 	strings.Set("num", "10")
 
-	err := strings.IncrBy("num", "invalid")
+	_, err := strings.IncrBy("num", "invalid")
 	if err == nil || err.Error() != "ERR invalid increment value" {
 		t.Errorf("Expected error for invalid increment, got: %v", err)
 	}
 }
 
+func TestIncrByReturnsNewValue(t *testing.T) {
+	strings.Set("num", "10")
+
+	val, err := strings.IncrBy("num", "-15")
+	if err != nil || val != -5 {
+		t.Errorf("Expected -5, got %v, err: %v", val, err)
+	}
+	val, err = strings.DecrBy("num", "5")
+	if err != nil || val != -10 {
+		t.Errorf("Expected -10, got %v, err: %v", val, err)
+	}
+}
+
+func TestIncrByOverflow(t *testing.T) {
+	strings.Set("num", strconv.FormatInt(math.MaxInt64-1, 10))
+
+	if val, err := strings.Incr("num"); err != nil || val != math.MaxInt64 {
+		t.Fatalf("Expected %d, got %v, err: %v", int64(math.MaxInt64), val, err)
+	}
+	if _, err := strings.Incr("num"); err == nil || err.Error() != common.ERR_INCREMENT_OVERFLOW {
+		t.Errorf("Expected error: %v, got: %v", common.ERR_INCREMENT_OVERFLOW, err)
+	}
+
+	strings.Set("num", strconv.FormatInt(math.MinInt64, 10))
+	if _, err := strings.Decr("num"); err == nil || err.Error() != common.ERR_INCREMENT_OVERFLOW {
+		t.Errorf("Expected error: %v, got: %v", common.ERR_INCREMENT_OVERFLOW, err)
+	}
+	strings.Set("num", "0")
+	if _, err := strings.DecrBy("num", strconv.FormatInt(math.MinInt64, 10)); err == nil || err.Error() != common.ERR_INCREMENT_OVERFLOW {
+		t.Errorf("Expected error: %v, got: %v", common.ERR_INCREMENT_OVERFLOW, err)
+	}
+	if val, _ := strings.Get("num"); val != "0" {
+		t.Errorf("Expected the value to be unchanged, got %v", val)
+	}
+}
+
 func TestIncrByFloat(t *testing.T) {
 	tests := []struct {
 		key      string
@@ -415,6 +486,58 @@ func TestLcsInvalidSecondKey(t *testing.T) {
 	}
 }
 
+func TestLcsIdx(t *testing.T) {
+	strings.Set("key1", "ohmytext")
+	strings.Set("key2", "mynewtext")
+
+	matches, lcsLen, err := strings.LcsIdx("key1", "key2", 0)
+	expected := []strings.LcsMatch{
+		{A: [2]int64{4, 7}, B: [2]int64{5, 8}, Len: 4},
+		{A: [2]int64{2, 3}, B: [2]int64{0, 1}, Len: 2},
+	}
+	if err != nil || lcsLen != 6 || !reflect.DeepEqual(matches, expected) {
+		t.Errorf("Expected %v with length 6, got %v with length %d, err: %v", expected, matches, lcsLen, err)
+	}
+
+	matches, lcsLen, _ = strings.LcsIdx("key1", "key2", 4)
+	if lcsLen != 6 || !reflect.DeepEqual(matches, expected[:1]) {
+		t.Errorf("Expected %v with length 6, got %v with length %d", expected[:1], matches, lcsLen)
+	}
+}
+
+func TestSetNx(t *testing.T) {
+	if !strings.SetNx("TestSetNx", "first") {
+		t.Errorf("Expected the key to be set")
+	}
+	if strings.SetNx("TestSetNx", "second") {
+		t.Errorf("Expected the existing key to be kept")
+	}
+	if val, _ := strings.Get("TestSetNx"); val != "first" {
+		t.Errorf("Expected first, got %v", val)
+	}
+}
+
+func TestMSetNx(t *testing.T) {
+	strings.Set("TestMSetNxExisting", "old")
+
+	pairs := map[string]string{"TestMSetNx1": "a", "TestMSetNxExisting": "new"}
+	if strings.MSetNx(&pairs) {
+		t.Errorf("Expected no key to be set when one exists")
+	}
+	if _, err := strings.Get("TestMSetNx1"); err == nil {
+		t.Errorf("Expected TestMSetNx1 not to be set")
+	}
+
+	pairs = map[string]string{"TestMSetNx1": "a", "TestMSetNx2": "b"}
+	if !strings.MSetNx(&pairs) {
+		t.Errorf("Expected the keys to be set")
+	}
+	values := strings.MGet(&[]string{"TestMSetNx1", "TestMSetNx2", "TestMSetNxExisting"})
+	if !reflect.DeepEqual(*values, []string{"a", "b", "old"}) {
+		t.Errorf("Expected [a b old], got %v", *values)
+	}
+}
+
 func TestMGetAndMSet(t *testing.T) {
 	strings.MSet(&map[string]string{
 		"key1": "value1",
@@ -509,6 +632,18 @@ func TestSetRangeInvalidOffset(t *testing.T) {
 	}
 }
 
+func TestSetRangeTooLong(t *testing.T) {
+	for _, offset := range []string{"9223372036854775807", "4294967296", "536870910"} {
+		err := strings.SetRange("TestSetRangeTooLong", offset, "abc")
+		if err == nil || err.Error() != common.ERR_STRING_TOO_LONG {
+			t.Errorf("Expected error %v for offset %s, got: %v", common.ERR_STRING_TOO_LONG, offset, err)
+		}
+	}
+	if _, err := strings.Get("TestSetRangeTooLong"); err == nil {
+		t.Errorf("Expected the key not to be created")
+	}
+}
+
 func TestSetRangeOffsetExceedsLength(t *testing.T) {
 	strings.Set("key1", "Hello")
 	err := strings.SetRange("key1", "10", "World")
@@ -533,3 +668,39 @@ func TestSetRangeInvalidKey(t *testing.T) {
 		t.Errorf("Expected 'Test', got '%v', err: %v", val, err)
 	}
 }
+
+func TestIntegerEncoding(t *testing.T) {
+	for value, expected := range map[string]any{
+		"123456":              int64(123456),
+		"-5":                  int64(-5),
+		"0":                   int64(0),
+		"9223372036854775807": int64(math.MaxInt64),
+		"9223372036854775808": "9223372036854775808",
+		"0123":                "0123",
+		"-0":                  "-0",
+		"+1":                  "+1",
+		" 1":                  " 1",
+		"":                    "",
+	} {
+		if encoded := strings.Encode(value); encoded != expected {
+			t.Errorf("expected %#v for %q, got %#v", expected, value, encoded)
+		}
+		key := "TestIntegerEncoding" + value
+		strings.Set(key, value)
+		if val, err := strings.Get(key); err != nil || val != value {
+			t.Errorf("expected %q back, got %q, %v", value, val, err)
+		}
+	}
+
+	strings.Set("TestIntegerEncodingAppend", "-5")
+	strings.Append("TestIntegerEncodingAppend", "0")
+	if val, _ := strings.Get("TestIntegerEncodingAppend"); val != "-50" {
+		t.Errorf("expected -50, got %q", val)
+	}
+	if val, err := strings.IncrBy("TestIntegerEncodingAppend", "50"); err != nil || val != 0 {
+		t.Errorf("expected 0, got %d, %v", val, err)
+	}
+	if length, _ := strings.StrLen("TestIntegerEncodingAppend"); length != 1 {
+		t.Errorf("expected length 1, got %d", length)
+	}
+}
@@ -0,0 +1,156 @@
+// Package zsets implements the sorted set value, the structure geospatial indexes are stored in.
+package zsets
+
+import "math/rand"
+
+const (
+	// maxLevel is enough for 4^32 members with levelProbability.
+	maxLevel         = 32
+	levelProbability = 0.25
+)
+
+type node struct {
+	member string
+	score  float64
+	next   []*node
+}
+
+// before reports whether n comes before the member with score in the set, members being
+// ordered by score and then lexicographically.
+func (n *node) before(score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+// ZSet is the value stored for a sorted set. Members are kept in a skiplist ordered by score,
+// so ranges of scores are found in logarithmic time, and in a map to look their score up.
+// Scores are never NaN.
+type ZSet struct {
+	scores map[string]float64
+	head   *node
+	level  int
+}
+
+func NewZSet() *ZSet {
+	return &ZSet{scores: map[string]float64{}, head: &node{next: make([]*node, maxLevel)}, level: 1}
+}
+
+func randomLevel() int {
+	level := 1
+	for level < maxLevel && rand.Float64() < levelProbability {
+		level++
+	}
+	return level
+}
+
+// predecessors returns the last node before the member with score at each level.
+func (z *ZSet) predecessors(score float64, member string) [maxLevel]*node {
+	var update [maxLevel]*node
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && x.next[i].before(score, member) {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	return update
+}
+
+func (z *ZSet) insert(member string, score float64) {
+	update := z.predecessors(score, member)
+	level := randomLevel()
+	for ; z.level < level; z.level++ {
+		update[z.level] = z.head
+	}
+	n := &node{member: member, score: score, next: make([]*node, level)}
+	for i := range level {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+	}
+}
+
+func (z *ZSet) delete(member string, score float64) {
+	update := z.predecessors(score, member)
+	n := update[0].next[0]
+	for i := range len(n.next) {
+		update[i].next[i] = n.next[i]
+	}
+	for z.level > 1 && z.head.next[z.level-1] == nil {
+		z.level--
+	}
+}
+
+func (z *ZSet) Len() int {
+	return len(z.scores)
+}
+
+// Score returns the score of member, false if it isn't in the set.
+func (z *ZSet) Score(member string) (float64, bool) {
+	score, ok := z.scores[member]
+	return score, ok
+}
+
+// Add sets the score of member, it returns false if it was already in the set.
+func (z *ZSet) Add(member string, score float64) bool {
+	previous, exists := z.scores[member]
+	if exists {
+		if previous == score {
+			return false
+		}
+		z.delete(member, previous)
+	}
+	z.insert(member, score)
+	z.scores[member] = score
+	return !exists
+}
+
+// Remove removes member, it returns false if it wasn't in the set.
+func (z *ZSet) Remove(member string) bool {
+	score, ok := z.scores[member]
+	if !ok {
+		return false
+	}
+	z.delete(member, score)
+	delete(z.scores, member)
+	return true
+}
+
+// Each calls fn on every member and its score in ascending order until fn returns false.
+func (z *ZSet) Each(fn func(member string, score float64) bool) {
+	for n := z.head.next[0]; n != nil; n = n.next[0] {
+		if !fn(n.member, n.score) {
+			return
+		}
+	}
+}
+
+// Range calls fn in ascending order on the members with a score from min included to max
+// excluded, until fn returns false.
+func (z *ZSet) Range(min, max float64, fn func(member string, score float64) bool) {
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && x.next[i].score < min {
+			x = x.next[i]
+		}
+	}
+	for n := x.next[0]; n != nil && n.score < max; n = n.next[0] {
+		if !fn(n.member, n.score) {
+			return
+		}
+	}
+}
+
+func (z *ZSet) Clone() *ZSet {
+	clone := NewZSet()
+	z.Each(func(member string, score float64) bool {
+		clone.Add(member, score)
+		return true
+	})
+	return clone
+}
+
+// Clear removes all the members, dropping the references the set holds to them.
+func (z *ZSet) Clear() {
+	clear(z.scores)
+	clear(z.head.next)
+	z.level = 1
+}
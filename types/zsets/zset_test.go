@@ -0,0 +1,89 @@
+package zsets_test
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/divy-sh/animus/types/zsets"
+)
+
+func members(z *zsets.ZSet) []string {
+	result := []string{}
+	z.Each(func(member string, _ float64) bool {
+		result = append(result, member)
+		return true
+	})
+	return result
+}
+
+func TestZSet_Order(t *testing.T) {
+	z := zsets.NewZSet()
+	z.Add("c", 2)
+	z.Add("a", 1)
+	z.Add("b", 2)
+	if z.Add("a", 3) {
+		t.Errorf("expected updating a score not to add a member")
+	}
+	if got := members(z); !slices.Equal(got, []string{"b", "c", "a"}) {
+		t.Errorf("expected members ordered by score then name, got %v", got)
+	}
+	if score, ok := z.Score("a"); !ok || score != 3 {
+		t.Errorf("expected 3, got %v %v", score, ok)
+	}
+	if !z.Remove("c") || z.Remove("c") || z.Len() != 2 {
+		t.Errorf("expected c to be removed once")
+	}
+	if got := members(z); !slices.Equal(got, []string{"b", "a"}) {
+		t.Errorf("expected [b a], got %v", got)
+	}
+}
+
+func TestZSet_Range(t *testing.T) {
+	z := zsets.NewZSet()
+	scores := rand.Perm(1000)
+	for _, score := range scores {
+		z.Add(strconv.Itoa(score), float64(score))
+	}
+	for i := 0; i < 1000; i += 100 {
+		z.Remove(strconv.Itoa(i))
+	}
+	got := []float64{}
+	z.Range(95, 205, func(_ string, score float64) bool {
+		got = append(got, score)
+		return true
+	})
+	expected := []float64{}
+	for score := 95; score < 205; score++ {
+		if score%100 != 0 {
+			expected = append(expected, float64(score))
+		}
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	count := 0
+	z.Range(0, 1000, func(string, float64) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("expected Range to stop when fn returns false, got %d calls", count)
+	}
+}
+
+func TestZSet_CloneAndClear(t *testing.T) {
+	z := zsets.NewZSet()
+	z.Add("a", 1)
+	z.Add("b", 2)
+	clone := z.Clone()
+	clone.Add("c", 0)
+	z.Clear()
+	if z.Len() != 0 || len(members(z)) != 0 {
+		t.Errorf("expected an empty set after Clear")
+	}
+	if got := members(clone); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("expected the clone to be independent, got %v", got)
+	}
+}